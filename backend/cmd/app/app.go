@@ -3,12 +3,15 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
+	"goalify/internal/avatar"
 	"goalify/internal/config"
 	"goalify/internal/db"
 	"goalify/internal/events"
 	"goalify/internal/middleware"
 	"goalify/internal/routes"
+	"goalify/internal/storage"
 	"goalify/pkg/stacktrace"
 	"goalify/seeds"
 	"log/slog"
@@ -26,17 +29,37 @@ import (
 	gSrv "goalify/internal/goals/service"
 	gs "goalify/internal/goals/stores"
 
+	lh "goalify/internal/loot/handler"
+	lSrv "goalify/internal/loot/service"
+	ls "goalify/internal/loot/stores"
+
+	rh "goalify/internal/rewards/handler"
+	rSrv "goalify/internal/rewards/service"
+	rs "goalify/internal/rewards/stores"
+
 	uh "goalify/internal/users/handler"
 	usrSrv "goalify/internal/users/service"
 	us "goalify/internal/users/stores"
 )
 
+// How often the background tasks run. Each catches up on what nothing else triggered, like a
+// recurring goal nobody updated or a streak nobody completed, so its interval bounds how stale
+// the data shown can get. Category resets run up to categoryResetInterval after their boundary.
+const (
+	giftExpiryInterval    = time.Hour
+	effectCleanupInterval = time.Hour
+	goalReopenInterval    = time.Minute
+	categoryResetInterval = time.Minute
+	streakCheckInterval   = time.Minute
+)
+
 func NewServer(userHandler *uh.UserHandler, goalHandler *gh.GoalHandler,
+	lootHandler *lh.LootHandler, rewardHandler *rh.RewardHandler, blobServer *storage.BlobServer,
 	em *events.EventManager, userService usrSrv.UserService,
 ) http.Handler {
 	mux := http.NewServeMux()
 	mw := middleware.SetupMiddleware(userService)
-	routes.AddRoutes(mux, userHandler, goalHandler, em, mw)
+	routes.AddRoutes(mux, userHandler, goalHandler, lootHandler, rewardHandler, blobServer, em, mw)
 	return mux
 }
 
@@ -80,18 +103,27 @@ func Run(ctx context.Context) error {
 
 	// logs for stack trace implementing stacktrace.TraceLogger
 	goalDomainLogger := stacktrace.NewDomainStackTraceLogger("Goals")
+	lootDomainLogger := stacktrace.NewDomainStackTraceLogger("Loot")
+	rewardDomainLogger := stacktrace.NewDomainStackTraceLogger("Rewards")
 
 	eventManager := events.NewEventManager()
 
+	blobStore, err := newBlobStore(configService)
+	if err != nil {
+		return fmt.Errorf("failed to create blob store: %w", err)
+	}
+	urlSigner := storage.NewURLSigner(configService.BlobSigningSecret)
+	blobServer := storage.NewBlobServer(blobStore, urlSigner)
+
 	// Create sqlc queries
 	queries := sqlcdb.New(pgxPool)
 
-	userStore := us.NewUserStore(queries)
+	userStore := us.NewUserStore(pgxPool, queries)
 	userService := usrSrv.NewUserService(userStore, eventManager)
 	userHandler := uh.NewUserHandler(userService)
 
-	goalStore := gs.NewGoalStore(queries)
-	goalCategoryStore := gs.NewGoalCategoryStore(queries)
+	goalStore := gs.NewGoalStore(pgxPool, queries)
+	goalCategoryStore := gs.NewGoalCategoryStore(pgxPool, queries)
 	goalService := gSrv.NewGoalService(
 		goalStore,
 		goalCategoryStore,
@@ -100,7 +132,58 @@ func Run(ctx context.Context) error {
 	)
 	goalHandler := gh.NewGoalHandler(goalService, goalDomainLogger)
 
-	srv := NewServer(userHandler, goalHandler, eventManager, userService)
+	lootStore := ls.NewChestStore(pgxPool, queries)
+	lootService := lSrv.NewLootService(
+		lootStore,
+		lootDomainLogger,
+		eventManager,
+		rand.Reader,
+		blobStore,
+		urlSigner,
+		lSrv.Config{
+			Pity: lSrv.PityConfig{
+				SoftThreshold: configService.PitySoftThreshold,
+				HardThreshold: configService.PityHardThreshold,
+			},
+			GiftExpiry:            time.Duration(configService.GiftExpiryDays) * 24 * time.Hour,
+			MarketplaceFeePercent: configService.MarketplaceFeePercent,
+			CollectionBonus:       configService.CollectionBonus,
+		},
+	)
+	avatarRenderer := avatar.NewRenderer(blobStore)
+	lootHandler := lh.NewLootHandler(lootService, avatarRenderer, lootDomainLogger)
+
+	rewardStore := rs.NewRewardStore(pgxPool, queries)
+	rewardService := rSrv.NewRewardService(rewardStore, rewardDomainLogger, eventManager)
+	rewardHandler := rh.NewRewardHandler(rewardService, rewardDomainLogger)
+
+	// mismatches are logged by the service, startup carries on either way
+	if _, err := userService.ReconcileCash(); err != nil {
+		slog.Error("app.Run: userService.ReconcileCash:", "err", err)
+	}
+
+	// run the background tasks until shutdown
+	go runEvery(ctx, giftExpiryInterval, "lootService.ExpireGifts", func() (int, error) {
+		gifts, err := lootService.ExpireGifts()
+		return len(gifts), err
+	})
+	go runEvery(ctx, effectCleanupInterval, "lootService.DeleteExpiredEffects",
+		lootService.DeleteExpiredEffects)
+	go runEvery(ctx, goalReopenInterval, "goalService.ReopenDueGoals", goalService.ReopenDueGoals)
+	go runEvery(ctx, categoryResetInterval, "goalService.ResetDueCategories",
+		goalService.ResetDueCategories)
+	go runEvery(ctx, streakCheckInterval, "goalService.BreakLapsedStreaks",
+		goalService.BreakLapsedStreaks)
+
+	srv := NewServer(
+		userHandler,
+		goalHandler,
+		lootHandler,
+		rewardHandler,
+		blobServer,
+		eventManager,
+		userService,
+	)
 	port := configService.Port
 	httpServer := &http.Server{
 		Addr:    ":" + port,
@@ -135,3 +218,42 @@ func Run(ctx context.Context) error {
 	wg.Wait()
 	return nil
 }
+
+// newBlobStore opens the blob store picked by the config's storage backend
+func newBlobStore(configService *config.Config) (storage.BlobStore, error) {
+	if configService.StorageBackend == config.StorageS3 {
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:        configService.S3Endpoint,
+			Bucket:          configService.S3Bucket,
+			Region:          configService.S3Region,
+			AccessKeyID:     configService.S3AccessKeyID,
+			SecretAccessKey: configService.S3SecretAccessKey,
+		}, &http.Client{Timeout: 30 * time.Second})
+	}
+	return storage.NewLocalStore(configService.StorageLocalDir), nil
+}
+
+// runEvery calls task straight away and then every interval until ctx is done, logging failures
+// and how many things each run handled under name
+func runEvery[N int | int64](
+	ctx context.Context,
+	interval time.Duration,
+	name string,
+	task func() (N, error),
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := task()
+		if err != nil {
+			slog.Error(fmt.Sprintf("app.Run: %s:", name), "err", err)
+		} else if n > 0 {
+			slog.Info(fmt.Sprintf("app.Run: %s:", name), "count", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"goalify/cmd/app"
 	"os"
+
+	// users pick their timezone by IANA name, and the runtime image ships no zoneinfo
+	_ "time/tzdata"
 )
 
 func main() {
@@ -0,0 +1,252 @@
+// Package avatar draws a user's character: the art of their equipped items layered by slot over a
+// base body, with a badge showing their level. Avatars are laid out on a Canvas unit square and
+// written either as a self-contained SVG or as a PNG rasterized in Go.
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/storage"
+	"image"
+	"image/color"
+	// registers JPEG with image.Decode, PNG is registered by png.go
+	_ "image/jpeg"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// Canvas is the side of the square avatars are laid out on. Item art is drawn over all of it.
+	Canvas = 256
+	// DefaultSize, MinSize and MaxSize bound the side of a rendered avatar in pixels
+	DefaultSize = 256
+	MinSize     = 32
+	MaxSize     = 1024
+
+	FormatSVG = "svg"
+	FormatPNG = "png"
+
+	// version is part of every ETag, so changing how avatars are drawn invalidates cached ones
+	version = "1"
+)
+
+// ErrUnknownFormat is returned when rendering to a format other than FormatSVG or FormatPNG
+var ErrUnknownFormat = errors.New("unknown avatar format")
+
+// Formats lists the formats an avatar can be rendered in
+var Formats = []string{FormatSVG, FormatPNG}
+
+// slotOrder is the order equipped items are drawn in, back to front. The base body goes between
+// the background and everything worn on it.
+var slotOrder = []string{"background", "body", "head", "weapon", "accessory"}
+
+var (
+	backdropColor = color.RGBA{0xe5, 0xe7, 0xeb, 0xff}
+	skinColor     = color.RGBA{0xf1, 0xc2, 0x7d, 0xff}
+	badgeColor    = color.RGBA{0x1f, 0x29, 0x37, 0xff}
+	badgeText     = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	// placeholders for items without art are tinted by rarity
+	rarityColors = map[string]color.RGBA{
+		"common":    {0x9c, 0xa3, 0xaf, 0xff},
+		"rare":      {0x3b, 0x82, 0xf6, 0xff},
+		"epic":      {0xa8, 0x55, 0xf7, 0xff},
+		"legendary": {0xf5, 0x9e, 0x0b, 0xff},
+	}
+)
+
+type layerKind int
+
+const (
+	layerRect layerKind = iota
+	layerCircle
+	layerArt
+	layerText
+)
+
+// layer is one thing drawn on the canvas. A rect spans x, y to x+w, y+h, a circle is centred on
+// x, y with radius w, and text is centred on x, y with a height of h.
+type layer struct {
+	art  *asset
+	text string
+	fill color.RGBA
+	kind layerKind
+	x    float32
+	y    float32
+	w    float32
+	h    float32
+}
+
+// asset is decoded item art, kept with the data URI an SVG embeds it as
+type asset struct {
+	img     image.Image
+	dataURI string
+}
+
+// Renderer draws avatars, loading PNG or JPEG item art from a blob store. An item's image_url is
+// its art's signed blob URL, or a bare blob key. Items whose art is missing are drawn as a
+// placeholder for their slot.
+type Renderer struct {
+	blobs storage.BlobStore
+	// image_url to *asset, nil when there is no art to load
+	cache sync.Map
+}
+
+func NewRenderer(blobs storage.BlobStore) *Renderer {
+	return &Renderer{blobs: blobs}
+}
+
+// Render draws the avatar as format, size pixels square
+func (r *Renderer) Render(avatar *entities.Avatar, format string, size int) ([]byte, error) {
+	layers := r.layers(avatar)
+	switch format {
+	case FormatSVG:
+		return renderSVG(layers, size), nil
+	case FormatPNG:
+		return renderPNG(layers, size)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType returns the media type of an avatar rendered as format
+func ContentType(format string) string {
+	if format == FormatPNG {
+		return "image/png"
+	}
+	return "image/svg+xml"
+}
+
+// ETag identifies an avatar rendering. It changes whenever the level, the equipped items, their
+// art paths or whether their art could be loaded do, so it can be compared without drawing
+// anything, and a placeholder drawn while art was unavailable isn't kept once it loads.
+func (r *Renderer) ETag(avatar *entities.Avatar, format string, size int) string {
+	items := slices.Clone(avatar.Items)
+	slices.SortFunc(items, func(a, b *entities.ChestItem) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s:%s:%d:%d", version, format, size, avatar.Level)
+	for _, item := range items {
+		fmt.Fprintf(h, ":%s:%s:%s:%s", item.ID, item.Slot, item.Rarity, item.ImageURL)
+		fmt.Fprintf(h, ":%t", r.loadArt(item.ImageURL) != nil)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// layers lays the avatar out back to front
+func (r *Renderer) layers(avatar *entities.Avatar) []layer {
+	bySlot := make(map[string]*entities.ChestItem, len(avatar.Items))
+	for _, item := range avatar.Items {
+		bySlot[item.Slot] = item
+	}
+
+	layers := []layer{{kind: layerRect, fill: backdropColor, w: Canvas, h: Canvas}}
+	for _, slot := range slotOrder {
+		if slot == "body" {
+			layers = append(layers, baseBody()...)
+		}
+		item, ok := bySlot[slot]
+		if !ok {
+			continue
+		}
+		if art := r.loadArt(item.ImageURL); art != nil {
+			layers = append(layers, layer{kind: layerArt, art: art})
+		} else {
+			layers = append(layers, placeholder(item))
+		}
+	}
+
+	return append(layers, levelBadge(avatar.Level)...)
+}
+
+func baseBody() []layer {
+	return []layer{
+		{kind: layerRect, fill: skinColor, x: 116, y: 112, w: 24, h: 20},
+		{kind: layerRect, fill: skinColor, x: 88, y: 128, w: 80, h: 96},
+		{kind: layerCircle, fill: skinColor, x: 128, y: 84, w: 36},
+	}
+}
+
+// placeholder stands in for an item without art, roughly where the slot sits on the body
+func placeholder(item *entities.ChestItem) layer {
+	fill, ok := rarityColors[item.Rarity]
+	if !ok {
+		fill = rarityColors["common"]
+	}
+
+	switch item.Slot {
+	case "background":
+		return layer{kind: layerRect, fill: fill, w: Canvas, h: Canvas}
+	case "head":
+		return layer{kind: layerRect, fill: fill, x: 90, y: 44, w: 76, h: 26}
+	case "weapon":
+		return layer{kind: layerRect, fill: fill, x: 184, y: 96, w: 12, h: 128}
+	case "accessory":
+		return layer{kind: layerCircle, fill: fill, x: 128, y: 150, w: 10}
+	default:
+		return layer{kind: layerRect, fill: fill, x: 88, y: 128, w: 80, h: 96}
+	}
+}
+
+func levelBadge(level int) []layer {
+	return []layer{
+		{kind: layerCircle, fill: badgeColor, x: 216, y: 216, w: 30},
+		{kind: layerText, fill: badgeText, text: fmt.Sprint(level), x: 216, y: 216, h: 24},
+	}
+}
+
+// loadArt returns the decoded art at imageURL, or nil when it can't be loaded. Art that loads and
+// art that doesn't exist are cached, so art changed in the store needs a restart to show. Other
+// failures, such as the store timing out, are tried again on the next render.
+func (r *Renderer) loadArt(imageURL string) *asset {
+	if imageURL == "" {
+		return nil
+	}
+	if cached, ok := r.cache.Load(imageURL); ok {
+		return cached.(*asset)
+	}
+
+	art, err := r.decodeArt(storage.KeyFromURL(imageURL))
+	if err != nil {
+		slog.Warn("avatar.loadArt: item art unavailable", "image_url", imageURL, "err", err)
+		if !errors.Is(err, storage.ErrBlobNotFound) && !errors.Is(err, storage.ErrInvalidKey) {
+			return nil
+		}
+	}
+	r.cache.Store(imageURL, art)
+	return art
+}
+
+func (r *Renderer) decodeArt(key string) (*asset, error) {
+	// blob stores reject keys that climb out of their root
+	body, _, err := r.blobs.Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	contentType := http.DetectContentType(data)
+	return &asset{
+		img:     img,
+		dataURI: "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
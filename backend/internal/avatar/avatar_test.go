@@ -0,0 +1,180 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"goalify/internal/entities"
+	"goalify/internal/storage"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func solidImage(c color.RGBA) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for x := range 8 {
+		for y := range 8 {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func solidPNG(t *testing.T, c color.RGBA) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	require.NoError(t, png.Encode(&b, solidImage(c)))
+	return b.Bytes()
+}
+
+// newBlobStore returns a store holding the given blobs
+func newBlobStore(t *testing.T, blobs map[string][]byte) storage.BlobStore {
+	t.Helper()
+	store := storage.NewLocalStore(t.TempDir())
+	for key, data := range blobs {
+		require.NoError(t, store.Put(context.Background(), key, bytes.NewReader(data), ""))
+	}
+	return store
+}
+
+func newItem(slot, rarity, imageURL string) *entities.ChestItem {
+	return &entities.ChestItem{ID: uuid.New(), Slot: slot, Rarity: rarity, ImageURL: imageURL}
+}
+
+func TestRenderSVGLayersBySlot(t *testing.T) {
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	renderer := NewRenderer(newBlobStore(t, map[string][]byte{
+		"items/cape.png": solidPNG(t, red),
+	}))
+	avatar := &entities.Avatar{
+		Items: []*entities.ChestItem{
+			newItem("accessory", "epic", ""),
+			newItem("body", "rare", "/items/cape.png"),
+			newItem("head", "legendary", "items/missing.png"),
+		},
+		Level: 12,
+	}
+
+	out, err := renderer.Render(avatar, FormatSVG, 128)
+	require.NoError(t, err)
+	svg := string(out)
+
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="128"`))
+	assert.Contains(t, svg, `href="data:image/png;base64,`)
+	assert.Contains(t, svg, `>12</text>`)
+
+	// art and placeholders are drawn body, head, accessory, with the badge on top
+	body := strings.Index(svg, "data:image/png")
+	head := strings.Index(svg, hexColor(rarityColors["legendary"]))
+	accessory := strings.Index(svg, hexColor(rarityColors["epic"]))
+	badge := strings.Index(svg, hexColor(badgeColor))
+	assert.Less(t, body, head)
+	assert.Less(t, head, accessory)
+	assert.Less(t, accessory, badge)
+}
+
+func TestRenderPNG(t *testing.T) {
+	renderer := NewRenderer(newBlobStore(t, nil))
+	avatar := &entities.Avatar{
+		Items: []*entities.ChestItem{newItem("background", "legendary", "")},
+		Level: 3,
+	}
+
+	out, err := renderer.Render(avatar, FormatPNG, 64)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+
+	assert.Equal(t, image.Rect(0, 0, 64, 64), img.Bounds())
+	assert.Equal(t, rarityColors["legendary"], color.RGBAModel.Convert(img.At(2, 2)))
+	assert.Equal(t, skinColor, color.RGBAModel.Convert(img.At(32, 45)))
+	assert.Equal(t, badgeColor, color.RGBAModel.Convert(img.At(54, 60)))
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	_, err := NewRenderer(newBlobStore(t, nil)).Render(&entities.Avatar{}, "gif", 64)
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestLoadArtFromSignedURL(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, jpeg.Encode(&b, solidImage(color.RGBA{0x00, 0x00, 0xff, 0xff}), nil))
+	renderer := NewRenderer(newBlobStore(t, map[string][]byte{"items/shield.jpg": b.Bytes()}))
+
+	art := renderer.loadArt(storage.NewURLSigner("secret").URL("items/shield.jpg"))
+	require.NotNil(t, art)
+	assert.True(t, strings.HasPrefix(art.dataURI, "data:image/jpeg;base64,"))
+	assert.Equal(t, image.Rect(0, 0, 8, 8), art.img.Bounds())
+}
+
+func TestLoadArtStaysInStore(t *testing.T) {
+	renderer := NewRenderer(newBlobStore(t, nil))
+	assert.Nil(t, renderer.loadArt("../secrets.png"))
+	assert.Nil(t, renderer.loadArt(""))
+}
+
+func TestETag(t *testing.T) {
+	hat := newItem("head", "rare", "items/hat.png")
+	sword := newItem("weapon", "epic", "")
+	avatar := &entities.Avatar{Items: []*entities.ChestItem{hat, sword}, Level: 4}
+
+	renderer := NewRenderer(newBlobStore(t, nil))
+	etag := renderer.ETag(avatar, FormatSVG, 256)
+	reordered := &entities.Avatar{Items: []*entities.ChestItem{sword, hat}, Level: 4}
+	assert.Equal(t, etag, renderer.ETag(reordered, FormatSVG, 256))
+
+	assert.NotEqual(t, etag, renderer.ETag(avatar, FormatPNG, 256))
+	assert.NotEqual(t, etag, renderer.ETag(avatar, FormatSVG, 128))
+	levelled := &entities.Avatar{Items: avatar.Items, Level: 5}
+	assert.NotEqual(t, etag, renderer.ETag(levelled, FormatSVG, 256))
+	unequipped := &entities.Avatar{Items: []*entities.ChestItem{hat}, Level: 4}
+	assert.NotEqual(t, etag, renderer.ETag(unequipped, FormatSVG, 256))
+}
+
+// flakyStore fails the first failures gets with a timeout before serving from store
+type flakyStore struct {
+	storage.BlobStore
+	failures int
+}
+
+func (s *flakyStore) Get(
+	ctx context.Context,
+	key string,
+) (io.ReadCloser, *storage.BlobInfo, error) {
+	if s.failures > 0 {
+		s.failures--
+		return nil, nil, context.DeadlineExceeded
+	}
+	return s.BlobStore.Get(ctx, key)
+}
+
+func TestLoadArtRetriesTransientFailures(t *testing.T) {
+	store := &flakyStore{
+		BlobStore: newBlobStore(t, map[string][]byte{
+			"items/hat.png": solidPNG(t, color.RGBA{0xff, 0x00, 0x00, 0xff}),
+		}),
+		failures: 1,
+	}
+	renderer := NewRenderer(store)
+	hat := newItem("head", "rare", "items/hat.png")
+	avatar := &entities.Avatar{Items: []*entities.ChestItem{hat}}
+
+	// the placeholder drawn while the store is down gets a different ETag than the art
+	placeholder := renderer.ETag(avatar, FormatSVG, 256)
+	assert.NotNil(t, renderer.loadArt("items/hat.png"))
+	assert.NotEqual(t, placeholder, renderer.ETag(avatar, FormatSVG, 256))
+
+	// missing art is remembered rather than fetched on every render
+	assert.Nil(t, renderer.loadArt("items/missing.png"))
+	store.failures = 1
+	assert.Nil(t, renderer.loadArt("items/missing.png"))
+	assert.Equal(t, 1, store.failures)
+}
@@ -0,0 +1,89 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// circleKappa places cubic Bézier control points so four curves approximate a circle
+const circleKappa = 0.5522847498
+
+// renderPNG rasterizes the layers onto a size pixel square and encodes it as a PNG
+func renderPNG(layers []layer, size int) ([]byte, error) {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	scale := float32(size) / Canvas
+	z := vector.NewRasterizer(size, size)
+
+	for _, l := range layers {
+		switch l.kind {
+		case layerRect:
+			z.Reset(size, size)
+			x0, y0 := l.x*scale, l.y*scale
+			x1, y1 := (l.x+l.w)*scale, (l.y+l.h)*scale
+			z.MoveTo(x0, y0)
+			z.LineTo(x1, y0)
+			z.LineTo(x1, y1)
+			z.LineTo(x0, y1)
+			z.ClosePath()
+			z.Draw(dst, dst.Bounds(), image.NewUniform(l.fill), image.Point{})
+		case layerCircle:
+			z.Reset(size, size)
+			addCircle(z, l.x*scale, l.y*scale, l.w*scale)
+			z.Draw(dst, dst.Bounds(), image.NewUniform(l.fill), image.Point{})
+		case layerArt:
+			art := l.art.img
+			xdraw.CatmullRom.Scale(dst, dst.Bounds(), art, art.Bounds(), xdraw.Over, nil)
+		case layerText:
+			drawText(dst, l, scale)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, dst); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func addCircle(z *vector.Rasterizer, cx, cy, r float32) {
+	k := r * circleKappa
+	z.MoveTo(cx+r, cy)
+	z.CubeTo(cx+r, cy+k, cx+k, cy+r, cx, cy+r)
+	z.CubeTo(cx-k, cy+r, cx-r, cy+k, cx-r, cy)
+	z.CubeTo(cx-r, cy-k, cx-k, cy-r, cx, cy-r)
+	z.CubeTo(cx+k, cy-r, cx+r, cy-k, cx+r, cy)
+	z.ClosePath()
+}
+
+// drawText draws the text with the built in bitmap face, then scales it up to the layer's height
+// centred on its position
+func drawText(dst *image.RGBA, l layer, scale float32) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, l.text).Ceil()
+	height := face.Metrics().Height.Ceil()
+	glyphs := image.NewRGBA(image.Rect(0, 0, width, height))
+	drawer := font.Drawer{
+		Dst:  glyphs,
+		Src:  image.NewUniform(l.fill),
+		Face: face,
+		Dot:  fixed.P(0, face.Metrics().Ascent.Ceil()),
+	}
+	drawer.DrawString(l.text)
+
+	h := l.h * scale
+	w := h * float32(width) / float32(height)
+	target := image.Rect(
+		int(l.x*scale-w/2),
+		int(l.y*scale-h/2),
+		int(l.x*scale+w/2),
+		int(l.y*scale+h/2),
+	)
+	xdraw.BiLinear.Scale(dst, target, glyphs, glyphs.Bounds(), xdraw.Over, nil)
+}
@@ -0,0 +1,59 @@
+package avatar
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+)
+
+// renderSVG writes the layers as an SVG scaled to size pixels. Item art is embedded, so the image
+// stands alone wherever it is served.
+func renderSVG(layers []layer, size int) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(
+		&b,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		size,
+		size,
+		Canvas,
+		Canvas,
+	)
+
+	for _, l := range layers {
+		switch l.kind {
+		case layerRect:
+			fmt.Fprintf(
+				&b,
+				`<rect x="%g" y="%g" width="%g" height="%g" fill="%s"/>`,
+				l.x, l.y, l.w, l.h, hexColor(l.fill),
+			)
+		case layerCircle:
+			fmt.Fprintf(
+				&b,
+				`<circle cx="%g" cy="%g" r="%g" fill="%s"/>`,
+				l.x, l.y, l.w, hexColor(l.fill),
+			)
+		case layerArt:
+			fmt.Fprintf(
+				&b,
+				`<image href="%s" width="%d" height="%d" preserveAspectRatio="none"/>`,
+				l.art.dataURI, Canvas, Canvas,
+			)
+		case layerText:
+			// text is only ever the level number, so it needs no escaping
+			fmt.Fprintf(
+				&b,
+				`<text x="%g" y="%g" font-family="sans-serif" font-size="%g" font-weight="bold" `+
+					`fill="%s" text-anchor="middle" dominant-baseline="central">%s</text>`,
+				l.x, l.y, l.h, hexColor(l.fill), l.text,
+			)
+		}
+	}
+
+	b.WriteString("</svg>")
+	return b.Bytes()
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
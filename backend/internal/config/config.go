@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -26,7 +27,29 @@ type Config struct {
 	Port             string
 	Env              Environment
 	AllowedOrigins   []string
-	IsCI             bool
+	// openings without a top rarity drop before pity raises its odds, and the opening that
+	// guarantees one
+	PitySoftThreshold int
+	PityHardThreshold int
+	// days a gift stays pending before it returns to its sender
+	GiftExpiryDays int
+	// percentage of each marketplace sale kept by the house
+	MarketplaceFeePercent int
+	// cash paid once for discovering every item in a chest's drop table
+	CollectionBonus int
+	// where blobs such as item images are kept, StorageLocal or StorageS3
+	StorageBackend string
+	// directory blobs are kept in by the local backend
+	StorageLocalDir string
+	// bucket blobs are kept in by the s3 backend, S3Endpoint is the service's base URL
+	S3Endpoint        string
+	S3Bucket          string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	// secret blob URLs are signed with, JWTSecret when unset
+	BlobSigningSecret string
+	IsCI              bool
 }
 
 var (
@@ -150,6 +173,66 @@ func loadFromEnvironment() *Config {
 		config.AllowedOrigins = []string{"http://localhost:5173"}
 	}
 
+	config.PitySoftThreshold = intFromEnv(PitySoftThreshold, DefaultPitySoftThreshold)
+	config.PityHardThreshold = intFromEnv(PityHardThreshold, DefaultPityHardThreshold)
+	if config.PitySoftThreshold < 0 || config.PityHardThreshold <= config.PitySoftThreshold {
+		panic(fmt.Errorf(
+			"invalid pity thresholds: %s must be at least 0 and less than %s",
+			PitySoftThreshold,
+			PityHardThreshold,
+		))
+	}
+
+	config.GiftExpiryDays = intFromEnv(GiftExpiryDays, DefaultGiftExpiryDays)
+	if config.GiftExpiryDays < 1 {
+		panic(fmt.Errorf("invalid gift expiry: %s must be at least 1", GiftExpiryDays))
+	}
+
+	config.MarketplaceFeePercent = intFromEnv(MarketplaceFeePercent, DefaultMarketplaceFeePercent)
+	if config.MarketplaceFeePercent < 0 || config.MarketplaceFeePercent > 100 {
+		panic(fmt.Errorf(
+			"invalid marketplace fee: %s must be from 0 to 100",
+			MarketplaceFeePercent,
+		))
+	}
+
+	config.CollectionBonus = intFromEnv(CollectionBonus, DefaultCollectionBonus)
+	if config.CollectionBonus < 0 {
+		panic(fmt.Errorf("invalid collection bonus: %s must be at least 0", CollectionBonus))
+	}
+
+	config.StorageBackend = stringFromEnv(StorageBackend, DefaultStorageBackend)
+	config.StorageLocalDir = stringFromEnv(StorageLocalDir, DefaultStorageLocalDir)
+	config.S3Endpoint = os.Getenv(string(S3Endpoint))
+	config.S3Bucket = os.Getenv(string(S3Bucket))
+	config.S3Region = stringFromEnv(S3Region, DefaultS3Region)
+	config.S3AccessKeyID = os.Getenv(string(S3AccessKeyID))
+	config.S3SecretAccessKey = os.Getenv(string(S3SecretAccessKey))
+	config.BlobSigningSecret = stringFromEnv(BlobSigningSecret, config.JWTSecret)
+	switch config.StorageBackend {
+	case StorageLocal:
+	case StorageS3:
+		// the s3 backend can't run without its bucket and credentials
+		s3Vars := map[ConfigKey]string{
+			S3Endpoint:        config.S3Endpoint,
+			S3Bucket:          config.S3Bucket,
+			S3AccessKeyID:     config.S3AccessKeyID,
+			S3SecretAccessKey: config.S3SecretAccessKey,
+		}
+		for key, value := range s3Vars {
+			if value == "" {
+				missing = append(missing, string(key))
+			}
+		}
+	default:
+		panic(fmt.Errorf(
+			"invalid storage backend: %s must be %s or %s",
+			StorageBackend,
+			StorageLocal,
+			StorageS3,
+		))
+	}
+
 	// Check for missing required variables
 	if len(missing) > 0 {
 		panic(fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", ")))
@@ -158,6 +241,27 @@ func loadFromEnvironment() *Config {
 	return config
 }
 
+// intFromEnv parses an optional integer variable, falling back to def when it is unset
+func intFromEnv(key ConfigKey, def int) int {
+	value := os.Getenv(string(key))
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		panic(fmt.Errorf("invalid value for %s: %w", key, err))
+	}
+	return parsed
+}
+
+// stringFromEnv reads an optional variable, falling back to def when it is unset
+func stringFromEnv(key ConfigKey, def string) string {
+	if value := os.Getenv(string(key)); value != "" {
+		return value
+	}
+	return def
+}
+
 func (c *Config) GetDBConnectionString() string {
 	if c.DBConnString != "" {
 		return c.DBConnString
@@ -7,21 +7,51 @@ import "slices"
 type ConfigKey string
 
 const (
-	DBPassword       ConfigKey = "DB_PASSWORD"
-	DBUser           ConfigKey = "DB_USER"
-	DBName           ConfigKey = "DB_NAME"
-	DBHost           ConfigKey = "DB_HOST"
-	DBConnString     ConfigKey = "DATABASE_URL"
-	JWTSecret        ConfigKey = "JWT_SECRET"
-	Port             ConfigKey = "PORT"
-	AllowedOrigins   ConfigKey = "ALLOWED_ORIGINS"
-	TestDBConnString ConfigKey = "TEST_DB_CONN_STRING"
-	CI               ConfigKey = "CI"
-	TestDBName       ConfigKey = "TEST_DB_NAME"
-	TestDBPassword   ConfigKey = "TEST_DB_PASSWORD"
-	TestDBUser       ConfigKey = "TEST_DB_USER"
-	TestDBHost       ConfigKey = "TEST_DB_HOST"
-	ENV              ConfigKey = "ENV"
+	DBPassword            ConfigKey = "DB_PASSWORD"
+	DBUser                ConfigKey = "DB_USER"
+	DBName                ConfigKey = "DB_NAME"
+	DBHost                ConfigKey = "DB_HOST"
+	DBConnString          ConfigKey = "DATABASE_URL"
+	JWTSecret             ConfigKey = "JWT_SECRET"
+	Port                  ConfigKey = "PORT"
+	AllowedOrigins        ConfigKey = "ALLOWED_ORIGINS"
+	TestDBConnString      ConfigKey = "TEST_DB_CONN_STRING"
+	CI                    ConfigKey = "CI"
+	TestDBName            ConfigKey = "TEST_DB_NAME"
+	TestDBPassword        ConfigKey = "TEST_DB_PASSWORD"
+	TestDBUser            ConfigKey = "TEST_DB_USER"
+	TestDBHost            ConfigKey = "TEST_DB_HOST"
+	ENV                   ConfigKey = "ENV"
+	PitySoftThreshold     ConfigKey = "PITY_SOFT_THRESHOLD"
+	PityHardThreshold     ConfigKey = "PITY_HARD_THRESHOLD"
+	GiftExpiryDays        ConfigKey = "GIFT_EXPIRY_DAYS"
+	MarketplaceFeePercent ConfigKey = "MARKETPLACE_FEE_PERCENT"
+	CollectionBonus       ConfigKey = "COLLECTION_BONUS"
+	StorageBackend        ConfigKey = "STORAGE_BACKEND"
+	StorageLocalDir       ConfigKey = "STORAGE_LOCAL_DIR"
+	S3Endpoint            ConfigKey = "S3_ENDPOINT"
+	S3Bucket              ConfigKey = "S3_BUCKET"
+	S3Region              ConfigKey = "S3_REGION"
+	S3AccessKeyID         ConfigKey = "S3_ACCESS_KEY_ID"
+	S3SecretAccessKey     ConfigKey = "S3_SECRET_ACCESS_KEY"
+	BlobSigningSecret     ConfigKey = "BLOB_SIGNING_SECRET"
+)
+
+const (
+	DefaultPitySoftThreshold     = 30
+	DefaultPityHardThreshold     = 50
+	DefaultGiftExpiryDays        = 7
+	DefaultMarketplaceFeePercent = 0
+	DefaultCollectionBonus       = 500
+	DefaultStorageBackend        = StorageLocal
+	DefaultStorageLocalDir       = "assets"
+	DefaultS3Region              = "us-east-1"
+)
+
+// Storage backends blobs such as item images can be kept in
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
 )
 
 type Environment string
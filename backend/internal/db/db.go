@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"goalify/pkg/options"
 	"math"
+	"time"
 
 	sqlcdb "goalify/internal/db/generated"
 
@@ -108,3 +109,15 @@ func OptionIntToPgxInt4(opt options.Option[int]) (pgtype.Int4, error) {
 	}
 	return pgtype.Int4{}, nil
 }
+
+func TimeToPgxDate(t time.Time) pgtype.Date {
+	return pgtype.Date{Time: t, Valid: true}
+}
+
+// PgxDateToOptionString formats a nullable date as YYYY-MM-DD
+func PgxDateToOptionString(d pgtype.Date) options.Option[string] {
+	if d.Valid {
+		return options.Some(d.Time.Format(time.DateOnly))
+	}
+	return options.None[string]()
+}
@@ -0,0 +1,166 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: active_effects.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createActiveEffect = `-- name: CreateActiveEffect :one
+INSERT INTO active_effects (user_id, effect, value, source_item_id, expires_at)
+VALUES ($1, $2, $3, $4, NOW() + $5::INTEGER * INTERVAL '1 second')
+RETURNING id, user_id, effect, value, source_item_id, expires_at, created_at
+`
+
+type CreateActiveEffectParams struct {
+	UserID          pgtype.UUID
+	Effect          ItemEffect
+	Value           int32
+	SourceItemID    pgtype.UUID
+	DurationSeconds int32
+}
+
+func (q *Queries) CreateActiveEffect(ctx context.Context, arg CreateActiveEffectParams) (ActiveEffect, error) {
+	row := q.db.QueryRow(ctx, createActiveEffect,
+		arg.UserID,
+		arg.Effect,
+		arg.Value,
+		arg.SourceItemID,
+		arg.DurationSeconds,
+	)
+	var i ActiveEffect
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Effect,
+		&i.Value,
+		&i.SourceItemID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteActiveEffectById = `-- name: DeleteActiveEffectById :exec
+DELETE FROM active_effects WHERE id = $1
+`
+
+func (q *Queries) DeleteActiveEffectById(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteActiveEffectById, id)
+	return err
+}
+
+const deleteExpiredActiveEffects = `-- name: DeleteExpiredActiveEffects :execrows
+DELETE FROM active_effects WHERE expires_at <= NOW()
+`
+
+func (q *Queries) DeleteExpiredActiveEffects(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredActiveEffects)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getActiveEffectsByUserId = `-- name: GetActiveEffectsByUserId :many
+SELECT id, user_id, effect, value, source_item_id, expires_at, created_at FROM active_effects
+WHERE user_id = $1 AND expires_at > NOW()
+ORDER BY expires_at, id
+`
+
+func (q *Queries) GetActiveEffectsByUserId(ctx context.Context, userID pgtype.UUID) ([]ActiveEffect, error) {
+	rows, err := q.db.Query(ctx, getActiveEffectsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ActiveEffect
+	for rows.Next() {
+		var i ActiveEffect
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Effect,
+			&i.Value,
+			&i.SourceItemID,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getXpMultiplierByUserId = `-- name: GetXpMultiplierByUserId :one
+SELECT COALESCE(MAX(value), 1)::INTEGER AS multiplier
+FROM active_effects
+WHERE user_id = $1 AND effect = 'xp_multiplier' AND expires_at > NOW()
+`
+
+// multipliers don't stack, the strongest active one applies
+func (q *Queries) GetXpMultiplierByUserId(ctx context.Context, userID pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, getXpMultiplierByUserId, userID)
+	var multiplier int32
+	err := row.Scan(&multiplier)
+	return multiplier, err
+}
+
+const lockStreakFreezesByUserId = `-- name: LockStreakFreezesByUserId :many
+SELECT id, user_id, effect, value, source_item_id, expires_at, created_at FROM active_effects
+WHERE user_id = $1 AND effect = 'streak_freeze' AND expires_at > NOW()
+ORDER BY expires_at, id
+FOR UPDATE
+`
+
+// the user's active streak freezes in the order they're used up, soonest to expire first
+func (q *Queries) LockStreakFreezesByUserId(ctx context.Context, userID pgtype.UUID) ([]ActiveEffect, error) {
+	rows, err := q.db.Query(ctx, lockStreakFreezesByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ActiveEffect
+	for rows.Next() {
+		var i ActiveEffect
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Effect,
+			&i.Value,
+			&i.SourceItemID,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setActiveEffectValue = `-- name: SetActiveEffectValue :exec
+UPDATE active_effects SET value = $2 WHERE id = $1
+`
+
+type SetActiveEffectValueParams struct {
+	ID    int64
+	Value int32
+}
+
+func (q *Queries) SetActiveEffectValue(ctx context.Context, arg SetActiveEffectValueParams) error {
+	_, err := q.db.Exec(ctx, setActiveEffectValue, arg.ID, arg.Value)
+	return err
+}
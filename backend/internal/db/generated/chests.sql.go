@@ -11,10 +11,33 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const archiveDropTable = `-- name: ArchiveDropTable :exec
+INSERT INTO chest_drop_table_history (chest_id, version, item_id, drop_rate)
+SELECT dr.chest_id, $1::INTEGER, dr.item_id, dr.drop_rate
+FROM chest_item_drop_rates dr
+WHERE dr.chest_id = $2
+  AND NOT EXISTS (
+    SELECT 1 FROM chest_drop_table_history h
+    WHERE h.chest_id = $2 AND h.version = $1::INTEGER
+  )
+ON CONFLICT (chest_id, version, item_id) DO NOTHING
+`
+
+type ArchiveDropTableParams struct {
+	Version int32
+	ChestID pgtype.UUID
+}
+
+// records the chest's live drop table as the given version unless that version is already recorded
+func (q *Queries) ArchiveDropTable(ctx context.Context, arg ArchiveDropTableParams) error {
+	_, err := q.db.Exec(ctx, archiveDropTable, arg.Version, arg.ChestID)
+	return err
+}
+
 const createChest = `-- name: CreateChest :one
 INSERT INTO chests (type, description, price)
 VALUES ($1, $2, $3)
-RETURNING id, type, description, price, created_at, updated_at
+RETURNING id, type, description, price, created_at, updated_at, drop_table_version, slug
 `
 
 type CreateChestParams struct {
@@ -34,14 +57,16 @@ func (q *Queries) CreateChest(ctx context.Context, arg CreateChestParams) (Chest
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DropTableVersion,
+		&i.Slug,
 	)
 	return i, err
 }
 
 const createChestItem = `-- name: CreateChestItem :one
-INSERT INTO chest_items (image_url, title, rarity, price)
-VALUES ($1, $2, $3, $4)
-RETURNING id, image_url, title, rarity, price, created_at, updated_at
+INSERT INTO chest_items (image_url, title, rarity, price, slot)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, image_url, title, rarity, price, created_at, updated_at, slot, slug, effect, effect_value, effect_duration_seconds
 `
 
 type CreateChestItemParams struct {
@@ -49,6 +74,7 @@ type CreateChestItemParams struct {
 	Title    string
 	Rarity   ItemType
 	Price    pgtype.Int4
+	Slot     ItemSlot
 }
 
 // Chest Item Operations
@@ -58,6 +84,7 @@ func (q *Queries) CreateChestItem(ctx context.Context, arg CreateChestItemParams
 		arg.Title,
 		arg.Rarity,
 		arg.Price,
+		arg.Slot,
 	)
 	var i ChestItem
 	err := row.Scan(
@@ -68,6 +95,11 @@ func (q *Queries) CreateChestItem(ctx context.Context, arg CreateChestItemParams
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Slot,
+		&i.Slug,
+		&i.Effect,
+		&i.EffectValue,
+		&i.EffectDurationSeconds,
 	)
 	return i, err
 }
@@ -99,6 +131,31 @@ func (q *Queries) CreateChestItemDropRate(ctx context.Context, arg CreateChestIt
 	return i, err
 }
 
+const createDropTableHistoryEntry = `-- name: CreateDropTableHistoryEntry :exec
+INSERT INTO chest_drop_table_history (chest_id, version, item_id, drop_rate, created_by)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateDropTableHistoryEntryParams struct {
+	ChestID   pgtype.UUID
+	Version   int32
+	ItemID    pgtype.UUID
+	DropRate  float64
+	CreatedBy pgtype.UUID
+}
+
+// Drop Table History Operations
+func (q *Queries) CreateDropTableHistoryEntry(ctx context.Context, arg CreateDropTableHistoryEntryParams) error {
+	_, err := q.db.Exec(ctx, createDropTableHistoryEntry,
+		arg.ChestID,
+		arg.Version,
+		arg.ItemID,
+		arg.DropRate,
+		arg.CreatedBy,
+	)
+	return err
+}
+
 const createUserChest = `-- name: CreateUserChest :one
 INSERT INTO user_chests (user_id, chest_id, quantity_owned)
 VALUES ($1, $2, $3)
@@ -153,6 +210,33 @@ func (q *Queries) CreateUserItem(ctx context.Context, arg CreateUserItemParams)
 	return i, err
 }
 
+const decrementUserChestQuantity = `-- name: DecrementUserChestQuantity :one
+UPDATE user_chests
+SET quantity_owned = quantity_owned - 1,
+    updated_at = NOW()
+WHERE user_id = $1 AND chest_id = $2 AND quantity_owned > 0
+RETURNING id, user_id, chest_id, quantity_owned, created_at, updated_at
+`
+
+type DecrementUserChestQuantityParams struct {
+	UserID  pgtype.UUID
+	ChestID pgtype.UUID
+}
+
+func (q *Queries) DecrementUserChestQuantity(ctx context.Context, arg DecrementUserChestQuantityParams) (UserChest, error) {
+	row := q.db.QueryRow(ctx, decrementUserChestQuantity, arg.UserID, arg.ChestID)
+	var i UserChest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChestID,
+		&i.QuantityOwned,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const deleteChestById = `-- name: DeleteChestById :exec
 DELETE FROM chests WHERE id = $1
 `
@@ -180,6 +264,15 @@ func (q *Queries) DeleteDropRate(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteDropRatesByChestId = `-- name: DeleteDropRatesByChestId :exec
+DELETE FROM chest_item_drop_rates WHERE chest_id = $1
+`
+
+func (q *Queries) DeleteDropRatesByChestId(ctx context.Context, chestID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteDropRatesByChestId, chestID)
+	return err
+}
+
 const deleteUserChest = `-- name: DeleteUserChest :exec
 DELETE FROM user_chests WHERE user_id = $1 AND chest_id = $2
 `
@@ -203,8 +296,17 @@ func (q *Queries) DeleteUserItem(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteUserItemsByIds = `-- name: DeleteUserItemsByIds :exec
+DELETE FROM user_items WHERE id = ANY($1::UUID[])
+`
+
+func (q *Queries) DeleteUserItemsByIds(ctx context.Context, ids []pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserItemsByIds, ids)
+	return err
+}
+
 const getAllChestItems = `-- name: GetAllChestItems :many
-SELECT id, image_url, title, rarity, price, created_at, updated_at FROM chest_items ORDER BY created_at DESC
+SELECT id, image_url, title, rarity, price, created_at, updated_at, slot, slug, effect, effect_value, effect_duration_seconds FROM chest_items ORDER BY created_at DESC
 `
 
 func (q *Queries) GetAllChestItems(ctx context.Context) ([]ChestItem, error) {
@@ -224,6 +326,11 @@ func (q *Queries) GetAllChestItems(ctx context.Context) ([]ChestItem, error) {
 			&i.Price,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Slot,
+			&i.Slug,
+			&i.Effect,
+			&i.EffectValue,
+			&i.EffectDurationSeconds,
 		); err != nil {
 			return nil, err
 		}
@@ -236,7 +343,7 @@ func (q *Queries) GetAllChestItems(ctx context.Context) ([]ChestItem, error) {
 }
 
 const getAllChests = `-- name: GetAllChests :many
-SELECT id, type, description, price, created_at, updated_at FROM chests ORDER BY price ASC
+SELECT id, type, description, price, created_at, updated_at, drop_table_version, slug FROM chests ORDER BY price ASC
 `
 
 func (q *Queries) GetAllChests(ctx context.Context) ([]Chest, error) {
@@ -255,6 +362,8 @@ func (q *Queries) GetAllChests(ctx context.Context) ([]Chest, error) {
 			&i.Price,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DropTableVersion,
+			&i.Slug,
 		); err != nil {
 			return nil, err
 		}
@@ -267,7 +376,7 @@ func (q *Queries) GetAllChests(ctx context.Context) ([]Chest, error) {
 }
 
 const getChestById = `-- name: GetChestById :one
-SELECT id, type, description, price, created_at, updated_at FROM chests WHERE id = $1
+SELECT id, type, description, price, created_at, updated_at, drop_table_version, slug FROM chests WHERE id = $1
 `
 
 func (q *Queries) GetChestById(ctx context.Context, id pgtype.UUID) (Chest, error) {
@@ -280,12 +389,35 @@ func (q *Queries) GetChestById(ctx context.Context, id pgtype.UUID) (Chest, erro
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DropTableVersion,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const getChestByIdForShare = `-- name: GetChestByIdForShare :one
+SELECT id, type, description, price, created_at, updated_at, drop_table_version, slug FROM chests WHERE id = $1 FOR SHARE
+`
+
+// blocks drop table replacements until the transaction ends, so the rates and version read match
+func (q *Queries) GetChestByIdForShare(ctx context.Context, id pgtype.UUID) (Chest, error) {
+	row := q.db.QueryRow(ctx, getChestByIdForShare, id)
+	var i Chest
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Description,
+		&i.Price,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DropTableVersion,
+		&i.Slug,
 	)
 	return i, err
 }
 
 const getChestItemById = `-- name: GetChestItemById :one
-SELECT id, image_url, title, rarity, price, created_at, updated_at FROM chest_items WHERE id = $1
+SELECT id, image_url, title, rarity, price, created_at, updated_at, slot, slug, effect, effect_value, effect_duration_seconds FROM chest_items WHERE id = $1
 `
 
 func (q *Queries) GetChestItemById(ctx context.Context, id pgtype.UUID) (ChestItem, error) {
@@ -299,10 +431,122 @@ func (q *Queries) GetChestItemById(ctx context.Context, id pgtype.UUID) (ChestIt
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Slot,
+		&i.Slug,
+		&i.Effect,
+		&i.EffectValue,
+		&i.EffectDurationSeconds,
 	)
 	return i, err
 }
 
+const getChestItemsByIds = `-- name: GetChestItemsByIds :many
+SELECT id, image_url, title, rarity, price, created_at, updated_at, slot, slug, effect, effect_value, effect_duration_seconds FROM chest_items WHERE id = ANY($1::UUID[])
+`
+
+func (q *Queries) GetChestItemsByIds(ctx context.Context, ids []pgtype.UUID) ([]ChestItem, error) {
+	rows, err := q.db.Query(ctx, getChestItemsByIds, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChestItem
+	for rows.Next() {
+		var i ChestItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageUrl,
+			&i.Title,
+			&i.Rarity,
+			&i.Price,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Slot,
+			&i.Slug,
+			&i.Effect,
+			&i.EffectValue,
+			&i.EffectDurationSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChestItemsBySlugs = `-- name: GetChestItemsBySlugs :many
+SELECT id, image_url, title, rarity, price, created_at, updated_at, slot, slug, effect, effect_value, effect_duration_seconds FROM chest_items WHERE slug = ANY($1::TEXT[])
+`
+
+func (q *Queries) GetChestItemsBySlugs(ctx context.Context, slugs []string) ([]ChestItem, error) {
+	rows, err := q.db.Query(ctx, getChestItemsBySlugs, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChestItem
+	for rows.Next() {
+		var i ChestItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageUrl,
+			&i.Title,
+			&i.Rarity,
+			&i.Price,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Slot,
+			&i.Slug,
+			&i.Effect,
+			&i.EffectValue,
+			&i.EffectDurationSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChestsBySlugs = `-- name: GetChestsBySlugs :many
+SELECT id, type, description, price, created_at, updated_at, drop_table_version, slug FROM chests WHERE slug = ANY($1::TEXT[])
+`
+
+func (q *Queries) GetChestsBySlugs(ctx context.Context, slugs []string) ([]Chest, error) {
+	rows, err := q.db.Query(ctx, getChestsBySlugs, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chest
+	for rows.Next() {
+		var i Chest
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Description,
+			&i.Price,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DropTableVersion,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getDropRatesByChestId = `-- name: GetDropRatesByChestId :many
 SELECT id, item_id, chest_id, drop_rate, created_at, updated_at FROM chest_item_drop_rates WHERE chest_id = $1
 `
@@ -365,6 +609,197 @@ func (q *Queries) GetDropRatesByItemId(ctx context.Context, itemID pgtype.UUID)
 	return items, nil
 }
 
+const getDropTableByChestId = `-- name: GetDropTableByChestId :many
+SELECT dr.id, dr.item_id, dr.chest_id, dr.drop_rate, dr.created_at, dr.updated_at, ci.rarity
+FROM chest_item_drop_rates dr
+JOIN chest_items ci ON ci.id = dr.item_id
+WHERE dr.chest_id = $1
+`
+
+type GetDropTableByChestIdRow struct {
+	ID        pgtype.UUID
+	ItemID    pgtype.UUID
+	ChestID   pgtype.UUID
+	DropRate  float64
+	CreatedAt pgtype.Timestamp
+	UpdatedAt pgtype.Timestamp
+	Rarity    ItemType
+}
+
+func (q *Queries) GetDropTableByChestId(ctx context.Context, chestID pgtype.UUID) ([]GetDropTableByChestIdRow, error) {
+	rows, err := q.db.Query(ctx, getDropTableByChestId, chestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDropTableByChestIdRow
+	for rows.Next() {
+		var i GetDropTableByChestIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemID,
+			&i.ChestID,
+			&i.DropRate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Rarity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDropTableHistoryByChestId = `-- name: GetDropTableHistoryByChestId :many
+SELECT id, chest_id, version, item_id, drop_rate, created_by, created_at FROM chest_drop_table_history
+WHERE chest_id = $1
+ORDER BY version DESC, drop_rate DESC
+`
+
+func (q *Queries) GetDropTableHistoryByChestId(ctx context.Context, chestID pgtype.UUID) ([]ChestDropTableHistory, error) {
+	rows, err := q.db.Query(ctx, getDropTableHistoryByChestId, chestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChestDropTableHistory
+	for rows.Next() {
+		var i ChestDropTableHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChestID,
+			&i.Version,
+			&i.ItemID,
+			&i.DropRate,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEquippedChestItemsByUserId = `-- name: GetEquippedChestItemsByUserId :many
+SELECT chest_items.id, chest_items.image_url, chest_items.title, chest_items.rarity, chest_items.price, chest_items.created_at, chest_items.updated_at, chest_items.slot, chest_items.slug, chest_items.effect, chest_items.effect_value, chest_items.effect_duration_seconds
+FROM user_items
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE user_items.user_id = $1 AND user_items.status = 'equipped'
+ORDER BY chest_items.slot
+`
+
+func (q *Queries) GetEquippedChestItemsByUserId(ctx context.Context, userID pgtype.UUID) ([]ChestItem, error) {
+	rows, err := q.db.Query(ctx, getEquippedChestItemsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChestItem
+	for rows.Next() {
+		var i ChestItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageUrl,
+			&i.Title,
+			&i.Rarity,
+			&i.Price,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Slot,
+			&i.Slug,
+			&i.Effect,
+			&i.EffectValue,
+			&i.EffectDurationSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSellableDuplicateUserItems = `-- name: GetSellableDuplicateUserItems :many
+WITH ranked AS (
+    SELECT ui.id,
+           ROW_NUMBER() OVER (
+               PARTITION BY ui.item_id
+               ORDER BY ui.status = 'equipped' DESC, ui.created_at, ui.id
+           ) AS copy_number
+    FROM user_items ui
+    JOIN chest_items ci ON ci.id = ui.item_id
+    WHERE ui.user_id = $1 AND ci.rarity = $2
+)
+SELECT user_items.id, user_items.user_id, user_items.item_id, user_items.status, user_items.created_at, user_items.updated_at, chest_items.id, chest_items.image_url, chest_items.title, chest_items.rarity, chest_items.price, chest_items.created_at, chest_items.updated_at, chest_items.slot, chest_items.slug, chest_items.effect, chest_items.effect_value, chest_items.effect_duration_seconds
+FROM user_items
+JOIN chest_items ON chest_items.id = user_items.item_id
+JOIN ranked ON ranked.id = user_items.id
+WHERE ranked.copy_number > 1
+  AND user_items.status = 'not_equipped'
+  AND chest_items.price IS NOT NULL
+`
+
+type GetSellableDuplicateUserItemsParams struct {
+	UserID pgtype.UUID
+	Rarity ItemType
+}
+
+type GetSellableDuplicateUserItemsRow struct {
+	UserItem  UserItem
+	ChestItem ChestItem
+}
+
+// every unequipped copy of an item of the rarity beyond the one the user keeps, which is the
+// equipped copy if there is one and the oldest otherwise
+func (q *Queries) GetSellableDuplicateUserItems(ctx context.Context, arg GetSellableDuplicateUserItemsParams) ([]GetSellableDuplicateUserItemsRow, error) {
+	rows, err := q.db.Query(ctx, getSellableDuplicateUserItems, arg.UserID, arg.Rarity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSellableDuplicateUserItemsRow
+	for rows.Next() {
+		var i GetSellableDuplicateUserItemsRow
+		if err := rows.Scan(
+			&i.UserItem.ID,
+			&i.UserItem.UserID,
+			&i.UserItem.ItemID,
+			&i.UserItem.Status,
+			&i.UserItem.CreatedAt,
+			&i.UserItem.UpdatedAt,
+			&i.ChestItem.ID,
+			&i.ChestItem.ImageUrl,
+			&i.ChestItem.Title,
+			&i.ChestItem.Rarity,
+			&i.ChestItem.Price,
+			&i.ChestItem.CreatedAt,
+			&i.ChestItem.UpdatedAt,
+			&i.ChestItem.Slot,
+			&i.ChestItem.Slug,
+			&i.ChestItem.Effect,
+			&i.ChestItem.EffectValue,
+			&i.ChestItem.EffectDurationSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserChestByUserIdAndChestId = `-- name: GetUserChestByUserIdAndChestId :one
 SELECT id, user_id, chest_id, quantity_owned, created_at, updated_at FROM user_chests WHERE user_id = $1 AND chest_id = $2
 `
@@ -388,6 +823,28 @@ func (q *Queries) GetUserChestByUserIdAndChestId(ctx context.Context, arg GetUse
 	return i, err
 }
 
+const getUserChestPity = `-- name: GetUserChestPity :one
+SELECT user_id, chest_id, opens_since_top_drop, updated_at FROM user_chest_pity WHERE user_id = $1 AND chest_id = $2
+`
+
+type GetUserChestPityParams struct {
+	UserID  pgtype.UUID
+	ChestID pgtype.UUID
+}
+
+// Pity Operations
+func (q *Queries) GetUserChestPity(ctx context.Context, arg GetUserChestPityParams) (UserChestPity, error) {
+	row := q.db.QueryRow(ctx, getUserChestPity, arg.UserID, arg.ChestID)
+	var i UserChestPity
+	err := row.Scan(
+		&i.UserID,
+		&i.ChestID,
+		&i.OpensSinceTopDrop,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getUserChestsByUserId = `-- name: GetUserChestsByUserId :many
 SELECT id, user_id, chest_id, quantity_owned, created_at, updated_at FROM user_chests WHERE user_id = $1
 `
@@ -437,6 +894,49 @@ func (q *Queries) GetUserItemById(ctx context.Context, id pgtype.UUID) (UserItem
 	return i, err
 }
 
+const getUserItemWithDetails = `-- name: GetUserItemWithDetails :one
+SELECT user_items.id, user_items.user_id, user_items.item_id, user_items.status, user_items.created_at, user_items.updated_at, chest_items.id, chest_items.image_url, chest_items.title, chest_items.rarity, chest_items.price, chest_items.created_at, chest_items.updated_at, chest_items.slot, chest_items.slug, chest_items.effect, chest_items.effect_value, chest_items.effect_duration_seconds
+FROM user_items
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE user_items.id = $1 AND user_items.user_id = $2
+`
+
+type GetUserItemWithDetailsParams struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
+}
+
+type GetUserItemWithDetailsRow struct {
+	UserItem  UserItem
+	ChestItem ChestItem
+}
+
+func (q *Queries) GetUserItemWithDetails(ctx context.Context, arg GetUserItemWithDetailsParams) (GetUserItemWithDetailsRow, error) {
+	row := q.db.QueryRow(ctx, getUserItemWithDetails, arg.ID, arg.UserID)
+	var i GetUserItemWithDetailsRow
+	err := row.Scan(
+		&i.UserItem.ID,
+		&i.UserItem.UserID,
+		&i.UserItem.ItemID,
+		&i.UserItem.Status,
+		&i.UserItem.CreatedAt,
+		&i.UserItem.UpdatedAt,
+		&i.ChestItem.ID,
+		&i.ChestItem.ImageUrl,
+		&i.ChestItem.Title,
+		&i.ChestItem.Rarity,
+		&i.ChestItem.Price,
+		&i.ChestItem.CreatedAt,
+		&i.ChestItem.UpdatedAt,
+		&i.ChestItem.Slot,
+		&i.ChestItem.Slug,
+		&i.ChestItem.Effect,
+		&i.ChestItem.EffectValue,
+		&i.ChestItem.EffectDurationSeconds,
+	)
+	return i, err
+}
+
 const getUserItemsByUserId = `-- name: GetUserItemsByUserId :many
 SELECT id, user_id, item_id, status, created_at, updated_at FROM user_items WHERE user_id = $1
 `
@@ -468,13 +968,250 @@ func (q *Queries) GetUserItemsByUserId(ctx context.Context, userID pgtype.UUID)
 	return items, nil
 }
 
+const getUserItemsWithDetails = `-- name: GetUserItemsWithDetails :many
+SELECT user_items.id, user_items.user_id, user_items.item_id, user_items.status, user_items.created_at, user_items.updated_at, chest_items.id, chest_items.image_url, chest_items.title, chest_items.rarity, chest_items.price, chest_items.created_at, chest_items.updated_at, chest_items.slot, chest_items.slug, chest_items.effect, chest_items.effect_value, chest_items.effect_duration_seconds
+FROM user_items
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE user_items.user_id = $1
+  AND ($2::item_type IS NULL OR chest_items.rarity = $2)
+  AND ($3::item_status IS NULL OR user_items.status = $3)
+ORDER BY user_items.created_at DESC
+`
+
+type GetUserItemsWithDetailsParams struct {
+	UserID pgtype.UUID
+	Rarity NullItemType
+	Status NullItemStatus
+}
+
+type GetUserItemsWithDetailsRow struct {
+	UserItem  UserItem
+	ChestItem ChestItem
+}
+
+func (q *Queries) GetUserItemsWithDetails(ctx context.Context, arg GetUserItemsWithDetailsParams) ([]GetUserItemsWithDetailsRow, error) {
+	rows, err := q.db.Query(ctx, getUserItemsWithDetails, arg.UserID, arg.Rarity, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserItemsWithDetailsRow
+	for rows.Next() {
+		var i GetUserItemsWithDetailsRow
+		if err := rows.Scan(
+			&i.UserItem.ID,
+			&i.UserItem.UserID,
+			&i.UserItem.ItemID,
+			&i.UserItem.Status,
+			&i.UserItem.CreatedAt,
+			&i.UserItem.UpdatedAt,
+			&i.ChestItem.ID,
+			&i.ChestItem.ImageUrl,
+			&i.ChestItem.Title,
+			&i.ChestItem.Rarity,
+			&i.ChestItem.Price,
+			&i.ChestItem.CreatedAt,
+			&i.ChestItem.UpdatedAt,
+			&i.ChestItem.Slot,
+			&i.ChestItem.Slug,
+			&i.ChestItem.Effect,
+			&i.ChestItem.EffectValue,
+			&i.ChestItem.EffectDurationSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementChestDropTableVersion = `-- name: IncrementChestDropTableVersion :one
+UPDATE chests
+SET drop_table_version = drop_table_version + 1,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, type, description, price, created_at, updated_at, drop_table_version, slug
+`
+
+func (q *Queries) IncrementChestDropTableVersion(ctx context.Context, id pgtype.UUID) (Chest, error) {
+	row := q.db.QueryRow(ctx, incrementChestDropTableVersion, id)
+	var i Chest
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Description,
+		&i.Price,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DropTableVersion,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const incrementUserChestQuantity = `-- name: IncrementUserChestQuantity :one
+INSERT INTO user_chests (user_id, chest_id, quantity_owned)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, chest_id) DO UPDATE SET
+    quantity_owned = user_chests.quantity_owned + EXCLUDED.quantity_owned,
+    updated_at = NOW()
+RETURNING id, user_id, chest_id, quantity_owned, created_at, updated_at
+`
+
+type IncrementUserChestQuantityParams struct {
+	UserID        pgtype.UUID
+	ChestID       pgtype.UUID
+	QuantityOwned pgtype.Int4
+}
+
+func (q *Queries) IncrementUserChestQuantity(ctx context.Context, arg IncrementUserChestQuantityParams) (UserChest, error) {
+	row := q.db.QueryRow(ctx, incrementUserChestQuantity, arg.UserID, arg.ChestID, arg.QuantityOwned)
+	var i UserChest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChestID,
+		&i.QuantityOwned,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const lockUserChestPity = `-- name: LockUserChestPity :one
+INSERT INTO user_chest_pity (user_id, chest_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, chest_id) DO UPDATE SET user_id = EXCLUDED.user_id
+RETURNING user_id, chest_id, opens_since_top_drop, updated_at
+`
+
+type LockUserChestPityParams struct {
+	UserID  pgtype.UUID
+	ChestID pgtype.UUID
+}
+
+func (q *Queries) LockUserChestPity(ctx context.Context, arg LockUserChestPityParams) (UserChestPity, error) {
+	row := q.db.QueryRow(ctx, lockUserChestPity, arg.UserID, arg.ChestID)
+	var i UserChestPity
+	err := row.Scan(
+		&i.UserID,
+		&i.ChestID,
+		&i.OpensSinceTopDrop,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setUserItemOwner = `-- name: SetUserItemOwner :one
+UPDATE user_items
+SET user_id = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, item_id, status, created_at, updated_at
+`
+
+type SetUserItemOwnerParams struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
+}
+
+// a null user_id holds the item in escrow, out of every inventory
+func (q *Queries) SetUserItemOwner(ctx context.Context, arg SetUserItemOwnerParams) (UserItem, error) {
+	row := q.db.QueryRow(ctx, setUserItemOwner, arg.ID, arg.UserID)
+	var i UserItem
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ItemID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const takeUserChests = `-- name: TakeUserChests :one
+UPDATE user_chests
+SET quantity_owned = quantity_owned - $3,
+    updated_at = NOW()
+WHERE user_id = $1 AND chest_id = $2 AND quantity_owned >= $3
+RETURNING id, user_id, chest_id, quantity_owned, created_at, updated_at
+`
+
+type TakeUserChestsParams struct {
+	UserID   pgtype.UUID
+	ChestID  pgtype.UUID
+	Quantity pgtype.Int4
+}
+
+func (q *Queries) TakeUserChests(ctx context.Context, arg TakeUserChestsParams) (UserChest, error) {
+	row := q.db.QueryRow(ctx, takeUserChests, arg.UserID, arg.ChestID, arg.Quantity)
+	var i UserChest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChestID,
+		&i.QuantityOwned,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const unequipUserItemsInSlot = `-- name: UnequipUserItemsInSlot :many
+UPDATE user_items
+SET status = 'not_equipped',
+    updated_at = NOW()
+FROM chest_items
+WHERE chest_items.id = user_items.item_id
+  AND user_items.user_id = $1
+  AND chest_items.slot = $2
+  AND user_items.status = 'equipped'
+RETURNING user_items.id, user_items.user_id, user_items.item_id, user_items.status, user_items.created_at, user_items.updated_at
+`
+
+type UnequipUserItemsInSlotParams struct {
+	UserID pgtype.UUID
+	Slot   ItemSlot
+}
+
+func (q *Queries) UnequipUserItemsInSlot(ctx context.Context, arg UnequipUserItemsInSlotParams) ([]UserItem, error) {
+	rows, err := q.db.Query(ctx, unequipUserItemsInSlot, arg.UserID, arg.Slot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserItem
+	for rows.Next() {
+		var i UserItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ItemID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateChestById = `-- name: UpdateChestById :one
 UPDATE chests
 SET type = coalesce($1, type),
     description = coalesce($2, description),
     price = coalesce($3, price)
 WHERE id = $4
-RETURNING id, type, description, price, created_at, updated_at
+RETURNING id, type, description, price, created_at, updated_at, drop_table_version, slug
 `
 
 type UpdateChestByIdParams struct {
@@ -499,6 +1236,8 @@ func (q *Queries) UpdateChestById(ctx context.Context, arg UpdateChestByIdParams
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DropTableVersion,
+		&i.Slug,
 	)
 	return i, err
 }
@@ -510,7 +1249,7 @@ SET image_url = coalesce($1, image_url),
     rarity = coalesce($3, rarity),
     price = coalesce($4, price)
 WHERE id = $5
-RETURNING id, image_url, title, rarity, price, created_at, updated_at
+RETURNING id, image_url, title, rarity, price, created_at, updated_at, slot, slug, effect, effect_value, effect_duration_seconds
 `
 
 type UpdateChestItemByIdParams struct {
@@ -538,6 +1277,11 @@ func (q *Queries) UpdateChestItemById(ctx context.Context, arg UpdateChestItemBy
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Slot,
+		&i.Slug,
+		&i.Effect,
+		&i.EffectValue,
+		&i.EffectDurationSeconds,
 	)
 	return i, err
 }
@@ -568,6 +1312,32 @@ func (q *Queries) UpdateDropRate(ctx context.Context, arg UpdateDropRateParams)
 	return i, err
 }
 
+const updateUserChestPity = `-- name: UpdateUserChestPity :one
+UPDATE user_chest_pity
+SET opens_since_top_drop = $3,
+    updated_at = NOW()
+WHERE user_id = $1 AND chest_id = $2
+RETURNING user_id, chest_id, opens_since_top_drop, updated_at
+`
+
+type UpdateUserChestPityParams struct {
+	UserID            pgtype.UUID
+	ChestID           pgtype.UUID
+	OpensSinceTopDrop int32
+}
+
+func (q *Queries) UpdateUserChestPity(ctx context.Context, arg UpdateUserChestPityParams) (UserChestPity, error) {
+	row := q.db.QueryRow(ctx, updateUserChestPity, arg.UserID, arg.ChestID, arg.OpensSinceTopDrop)
+	var i UserChestPity
+	err := row.Scan(
+		&i.UserID,
+		&i.ChestID,
+		&i.OpensSinceTopDrop,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateUserChestQuantity = `-- name: UpdateUserChestQuantity :one
 UPDATE user_chests
 SET quantity_owned = $2
@@ -597,7 +1367,8 @@ func (q *Queries) UpdateUserChestQuantity(ctx context.Context, arg UpdateUserChe
 
 const updateUserItemStatus = `-- name: UpdateUserItemStatus :one
 UPDATE user_items
-SET status = $2
+SET status = $2,
+    updated_at = NOW()
 WHERE id = $1
 RETURNING id, user_id, item_id, status, created_at, updated_at
 `
@@ -620,3 +1391,103 @@ func (q *Queries) UpdateUserItemStatus(ctx context.Context, arg UpdateUserItemSt
 	)
 	return i, err
 }
+
+const upsertChestBySlug = `-- name: UpsertChestBySlug :one
+INSERT INTO chests (slug, type, description, price)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (slug) DO UPDATE SET
+    type = EXCLUDED.type,
+    description = EXCLUDED.description,
+    price = EXCLUDED.price,
+    updated_at = NOW()
+RETURNING id, type, description, price, created_at, updated_at, drop_table_version, slug
+`
+
+type UpsertChestBySlugParams struct {
+	Slug        pgtype.Text
+	Type        ChestType
+	Description string
+	Price       int32
+}
+
+func (q *Queries) UpsertChestBySlug(ctx context.Context, arg UpsertChestBySlugParams) (Chest, error) {
+	row := q.db.QueryRow(ctx, upsertChestBySlug,
+		arg.Slug,
+		arg.Type,
+		arg.Description,
+		arg.Price,
+	)
+	var i Chest
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Description,
+		&i.Price,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DropTableVersion,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const upsertChestItemBySlug = `-- name: UpsertChestItemBySlug :one
+INSERT INTO chest_items (
+    slug, image_url, title, rarity, price, slot, effect, effect_value, effect_duration_seconds
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (slug) DO UPDATE SET
+    image_url = coalesce(EXCLUDED.image_url, chest_items.image_url),
+    title = EXCLUDED.title,
+    rarity = EXCLUDED.rarity,
+    price = EXCLUDED.price,
+    slot = EXCLUDED.slot,
+    effect = EXCLUDED.effect,
+    effect_value = EXCLUDED.effect_value,
+    effect_duration_seconds = EXCLUDED.effect_duration_seconds,
+    updated_at = NOW()
+RETURNING id, image_url, title, rarity, price, created_at, updated_at, slot, slug, effect, effect_value, effect_duration_seconds
+`
+
+type UpsertChestItemBySlugParams struct {
+	Slug                  pgtype.Text
+	ImageUrl              pgtype.Text
+	Title                 string
+	Rarity                ItemType
+	Price                 pgtype.Int4
+	Slot                  ItemSlot
+	Effect                NullItemEffect
+	EffectValue           pgtype.Int4
+	EffectDurationSeconds pgtype.Int4
+}
+
+// items seeded without an image_url keep any art uploaded for them
+func (q *Queries) UpsertChestItemBySlug(ctx context.Context, arg UpsertChestItemBySlugParams) (ChestItem, error) {
+	row := q.db.QueryRow(ctx, upsertChestItemBySlug,
+		arg.Slug,
+		arg.ImageUrl,
+		arg.Title,
+		arg.Rarity,
+		arg.Price,
+		arg.Slot,
+		arg.Effect,
+		arg.EffectValue,
+		arg.EffectDurationSeconds,
+	)
+	var i ChestItem
+	err := row.Scan(
+		&i.ID,
+		&i.ImageUrl,
+		&i.Title,
+		&i.Rarity,
+		&i.Price,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Slot,
+		&i.Slug,
+		&i.Effect,
+		&i.EffectValue,
+		&i.EffectDurationSeconds,
+	)
+	return i, err
+}
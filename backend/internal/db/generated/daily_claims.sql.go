@@ -0,0 +1,79 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: daily_claims.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createDailyClaim = `-- name: CreateDailyClaim :one
+INSERT INTO daily_claims (user_id, claim_date, streak, chest_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, claim_date) DO NOTHING
+RETURNING id, user_id, claim_date, streak, chest_id, created_at
+`
+
+type CreateDailyClaimParams struct {
+	UserID    pgtype.UUID
+	ClaimDate pgtype.Date
+	Streak    int32
+	ChestID   pgtype.UUID
+}
+
+// returns no row when the user has already claimed on claim_date
+func (q *Queries) CreateDailyClaim(ctx context.Context, arg CreateDailyClaimParams) (DailyClaim, error) {
+	row := q.db.QueryRow(ctx, createDailyClaim,
+		arg.UserID,
+		arg.ClaimDate,
+		arg.Streak,
+		arg.ChestID,
+	)
+	var i DailyClaim
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ClaimDate,
+		&i.Streak,
+		&i.ChestID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestDailyClaimByUserId = `-- name: GetLatestDailyClaimByUserId :one
+SELECT id, user_id, claim_date, streak, chest_id, created_at FROM daily_claims
+WHERE user_id = $1
+ORDER BY claim_date DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestDailyClaimByUserId(ctx context.Context, userID pgtype.UUID) (DailyClaim, error) {
+	row := q.db.QueryRow(ctx, getLatestDailyClaimByUserId, userID)
+	var i DailyClaim
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ClaimDate,
+		&i.Streak,
+		&i.ChestID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserLocalDate = `-- name: GetUserLocalDate :one
+SELECT (NOW() AT TIME ZONE timezone)::DATE AS today FROM users WHERE id = $1
+`
+
+// the current date where the user is
+func (q *Queries) GetUserLocalDate(ctx context.Context, id pgtype.UUID) (pgtype.Date, error) {
+	row := q.db.QueryRow(ctx, getUserLocalDate, id)
+	var today pgtype.Date
+	err := row.Scan(&today)
+	return today, err
+}
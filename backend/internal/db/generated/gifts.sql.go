@@ -0,0 +1,244 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: gifts.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createGift = `-- name: CreateGift :one
+INSERT INTO gifts (sender_id, recipient_id, chest_id, quantity, user_item_id, expires_at)
+VALUES ($1, $2, $3, $4, $5, NOW() + $6::INTERVAL)
+RETURNING id, sender_id, recipient_id, chest_id, quantity, user_item_id, status, expires_at, resolved_at, created_at
+`
+
+type CreateGiftParams struct {
+	SenderID    pgtype.UUID
+	RecipientID pgtype.UUID
+	ChestID     pgtype.UUID
+	Quantity    pgtype.Int4
+	UserItemID  pgtype.UUID
+	ExpiresIn   pgtype.Interval
+}
+
+func (q *Queries) CreateGift(ctx context.Context, arg CreateGiftParams) (Gift, error) {
+	row := q.db.QueryRow(ctx, createGift,
+		arg.SenderID,
+		arg.RecipientID,
+		arg.ChestID,
+		arg.Quantity,
+		arg.UserItemID,
+		arg.ExpiresIn,
+	)
+	var i Gift
+	err := row.Scan(
+		&i.ID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.ChestID,
+		&i.Quantity,
+		&i.UserItemID,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.ResolvedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGiftsByRecipientId = `-- name: GetGiftsByRecipientId :many
+SELECT id, sender_id, recipient_id, chest_id, quantity, user_item_id, status, expires_at, resolved_at, created_at FROM gifts
+WHERE recipient_id = $1
+  AND ($2::gift_status IS NULL OR status = $2)
+ORDER BY created_at DESC
+`
+
+type GetGiftsByRecipientIdParams struct {
+	RecipientID pgtype.UUID
+	Status      NullGiftStatus
+}
+
+func (q *Queries) GetGiftsByRecipientId(ctx context.Context, arg GetGiftsByRecipientIdParams) ([]Gift, error) {
+	rows, err := q.db.Query(ctx, getGiftsByRecipientId, arg.RecipientID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Gift
+	for rows.Next() {
+		var i Gift
+		if err := rows.Scan(
+			&i.ID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.ChestID,
+			&i.Quantity,
+			&i.UserItemID,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.ResolvedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGiftsBySenderId = `-- name: GetGiftsBySenderId :many
+SELECT id, sender_id, recipient_id, chest_id, quantity, user_item_id, status, expires_at, resolved_at, created_at FROM gifts
+WHERE sender_id = $1
+  AND ($2::gift_status IS NULL OR status = $2)
+ORDER BY created_at DESC
+`
+
+type GetGiftsBySenderIdParams struct {
+	SenderID pgtype.UUID
+	Status   NullGiftStatus
+}
+
+func (q *Queries) GetGiftsBySenderId(ctx context.Context, arg GetGiftsBySenderIdParams) ([]Gift, error) {
+	rows, err := q.db.Query(ctx, getGiftsBySenderId, arg.SenderID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Gift
+	for rows.Next() {
+		var i Gift
+		if err := rows.Scan(
+			&i.ID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.ChestID,
+			&i.Quantity,
+			&i.UserItemID,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.ResolvedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockExpiredGifts = `-- name: LockExpiredGifts :many
+SELECT id, sender_id, recipient_id, chest_id, quantity, user_item_id, status, expires_at, resolved_at, created_at FROM gifts
+WHERE status = 'pending' AND expires_at <= NOW()
+ORDER BY expires_at
+FOR UPDATE SKIP LOCKED
+`
+
+// pending gifts past their expiry, skipping any a recipient is resolving at the same time
+func (q *Queries) LockExpiredGifts(ctx context.Context) ([]Gift, error) {
+	rows, err := q.db.Query(ctx, lockExpiredGifts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Gift
+	for rows.Next() {
+		var i Gift
+		if err := rows.Scan(
+			&i.ID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.ChestID,
+			&i.Quantity,
+			&i.UserItemID,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.ResolvedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockPendingGift = `-- name: LockPendingGift :one
+SELECT gifts.id, gifts.sender_id, gifts.recipient_id, gifts.chest_id, gifts.quantity, gifts.user_item_id, gifts.status, gifts.expires_at, gifts.resolved_at, gifts.created_at, (gifts.expires_at <= NOW())::BOOLEAN AS expired
+FROM gifts
+WHERE gifts.id = $1 AND gifts.recipient_id = $2 AND gifts.status = 'pending'
+FOR UPDATE
+`
+
+type LockPendingGiftParams struct {
+	ID          pgtype.UUID
+	RecipientID pgtype.UUID
+}
+
+type LockPendingGiftRow struct {
+	Gift    Gift
+	Expired bool
+}
+
+func (q *Queries) LockPendingGift(ctx context.Context, arg LockPendingGiftParams) (LockPendingGiftRow, error) {
+	row := q.db.QueryRow(ctx, lockPendingGift, arg.ID, arg.RecipientID)
+	var i LockPendingGiftRow
+	err := row.Scan(
+		&i.Gift.ID,
+		&i.Gift.SenderID,
+		&i.Gift.RecipientID,
+		&i.Gift.ChestID,
+		&i.Gift.Quantity,
+		&i.Gift.UserItemID,
+		&i.Gift.Status,
+		&i.Gift.ExpiresAt,
+		&i.Gift.ResolvedAt,
+		&i.Gift.CreatedAt,
+		&i.Expired,
+	)
+	return i, err
+}
+
+const resolveGift = `-- name: ResolveGift :one
+UPDATE gifts
+SET status = $2,
+    resolved_at = NOW()
+WHERE id = $1
+RETURNING id, sender_id, recipient_id, chest_id, quantity, user_item_id, status, expires_at, resolved_at, created_at
+`
+
+type ResolveGiftParams struct {
+	ID     pgtype.UUID
+	Status GiftStatus
+}
+
+func (q *Queries) ResolveGift(ctx context.Context, arg ResolveGiftParams) (Gift, error) {
+	row := q.db.QueryRow(ctx, resolveGift, arg.ID, arg.Status)
+	var i Gift
+	err := row.Scan(
+		&i.ID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.ChestID,
+		&i.Quantity,
+		&i.UserItemID,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.ResolvedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -12,18 +12,27 @@ import (
 )
 
 const createGoalCategory = `-- name: CreateGoalCategory :one
-INSERT INTO goal_categories (title, user_id)
-VALUES ($1, $2)
-RETURNING id, title, user_id, created_at, updated_at
+INSERT INTO goal_categories (title, user_id, reset_policy, reset_time, reset_weekday)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, title, user_id, created_at, updated_at, reset_policy, reset_time, reset_weekday, reset_through, current_streak, longest_streak, last_completed_date, streak_milestone
 `
 
 type CreateGoalCategoryParams struct {
-	Title  string
-	UserID pgtype.UUID
+	Title        string
+	UserID       pgtype.UUID
+	ResetPolicy  CategoryResetPolicy
+	ResetTime    pgtype.Time
+	ResetWeekday pgtype.Int2
 }
 
 func (q *Queries) CreateGoalCategory(ctx context.Context, arg CreateGoalCategoryParams) (GoalCategory, error) {
-	row := q.db.QueryRow(ctx, createGoalCategory, arg.Title, arg.UserID)
+	row := q.db.QueryRow(ctx, createGoalCategory,
+		arg.Title,
+		arg.UserID,
+		arg.ResetPolicy,
+		arg.ResetTime,
+		arg.ResetWeekday,
+	)
 	var i GoalCategory
 	err := row.Scan(
 		&i.ID,
@@ -31,6 +40,14 @@ func (q *Queries) CreateGoalCategory(ctx context.Context, arg CreateGoalCategory
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResetPolicy,
+		&i.ResetTime,
+		&i.ResetWeekday,
+		&i.ResetThrough,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
 	)
 	return i, err
 }
@@ -52,8 +69,53 @@ func (q *Queries) DeleteGoalCategoryById(ctx context.Context, arg DeleteGoalCate
 	return result.RowsAffected(), nil
 }
 
+const getDueCategoryResets = `-- name: GetDueCategoryResets :many
+SELECT
+    gc.id, gc.user_id,
+    category_reset_boundary(
+        gc.reset_policy, gc.reset_time, gc.reset_weekday, u.timezone, NOW()
+    )::TIMESTAMPTZ AS boundary
+FROM goal_categories gc
+JOIN users u ON u.id = gc.user_id
+WHERE gc.reset_policy <> 'none'
+    AND category_reset_boundary(
+        gc.reset_policy, gc.reset_time, gc.reset_weekday, u.timezone, NOW()
+    ) > gc.reset_through
+ORDER BY gc.reset_through
+LIMIT $1
+FOR UPDATE OF gc SKIP LOCKED
+`
+
+type GetDueCategoryResetsRow struct {
+	ID       pgtype.UUID
+	UserID   pgtype.UUID
+	Boundary pgtype.Timestamptz
+}
+
+// locks categories whose latest reset boundary in their user's timezone has passed since they
+// were last reset. other instances skip the locked rows rather than resetting them twice.
+func (q *Queries) GetDueCategoryResets(ctx context.Context, limit int32) ([]GetDueCategoryResetsRow, error) {
+	rows, err := q.db.Query(ctx, getDueCategoryResets, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDueCategoryResetsRow
+	for rows.Next() {
+		var i GetDueCategoryResetsRow
+		if err := rows.Scan(&i.ID, &i.UserID, &i.Boundary); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getGoalCategoriesByUserId = `-- name: GetGoalCategoriesByUserId :many
-SELECT id, title, user_id, created_at, updated_at FROM goal_categories WHERE user_id = $1 ORDER BY created_at
+SELECT id, title, user_id, created_at, updated_at, reset_policy, reset_time, reset_weekday, reset_through, current_streak, longest_streak, last_completed_date, streak_milestone FROM goal_categories WHERE user_id = $1 ORDER BY created_at
 `
 
 func (q *Queries) GetGoalCategoriesByUserId(ctx context.Context, userID pgtype.UUID) ([]GoalCategory, error) {
@@ -71,6 +133,14 @@ func (q *Queries) GetGoalCategoriesByUserId(ctx context.Context, userID pgtype.U
 			&i.UserID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ResetPolicy,
+			&i.ResetTime,
+			&i.ResetWeekday,
+			&i.ResetThrough,
+			&i.CurrentStreak,
+			&i.LongestStreak,
+			&i.LastCompletedDate,
+			&i.StreakMilestone,
 		); err != nil {
 			return nil, err
 		}
@@ -85,8 +155,12 @@ func (q *Queries) GetGoalCategoriesByUserId(ctx context.Context, userID pgtype.U
 const getGoalCategoriesWithGoalsByUserId = `-- name: GetGoalCategoriesWithGoalsByUserId :many
 SELECT
     gc.id, gc.title, gc.user_id, gc.created_at, gc.updated_at,
+    gc.reset_policy, gc.reset_time, gc.reset_weekday,
+    gc.current_streak, gc.longest_streak, gc.last_completed_date,
     g.id as goal_id, g.title as goal_title, g.description, g.status,
-    g.created_at as goal_created_at, g.updated_at as goal_updated_at
+    g.created_at as goal_created_at, g.updated_at as goal_updated_at,
+    g.current_streak as goal_current_streak, g.longest_streak as goal_longest_streak,
+    g.last_completed_date as goal_last_completed_date
 FROM goal_categories gc
 LEFT JOIN goals g ON gc.id = g.category_id
 WHERE gc.user_id = $1
@@ -94,17 +168,26 @@ ORDER BY gc.created_at, g.created_at DESC
 `
 
 type GetGoalCategoriesWithGoalsByUserIdRow struct {
-	ID            pgtype.UUID
-	Title         string
-	UserID        pgtype.UUID
-	CreatedAt     pgtype.Timestamp
-	UpdatedAt     pgtype.Timestamp
-	GoalID        pgtype.UUID
-	GoalTitle     pgtype.Text
-	Description   pgtype.Text
-	Status        NullGoalStatus
-	GoalCreatedAt pgtype.Timestamp
-	GoalUpdatedAt pgtype.Timestamp
+	ID                    pgtype.UUID
+	Title                 string
+	UserID                pgtype.UUID
+	CreatedAt             pgtype.Timestamp
+	UpdatedAt             pgtype.Timestamp
+	ResetPolicy           CategoryResetPolicy
+	ResetTime             pgtype.Time
+	ResetWeekday          pgtype.Int2
+	CurrentStreak         int32
+	LongestStreak         int32
+	LastCompletedDate     pgtype.Date
+	GoalID                pgtype.UUID
+	GoalTitle             pgtype.Text
+	Description           pgtype.Text
+	Status                NullGoalStatus
+	GoalCreatedAt         pgtype.Timestamp
+	GoalUpdatedAt         pgtype.Timestamp
+	GoalCurrentStreak     pgtype.Int4
+	GoalLongestStreak     pgtype.Int4
+	GoalLastCompletedDate pgtype.Date
 }
 
 func (q *Queries) GetGoalCategoriesWithGoalsByUserId(ctx context.Context, userID pgtype.UUID) ([]GetGoalCategoriesWithGoalsByUserIdRow, error) {
@@ -122,12 +205,21 @@ func (q *Queries) GetGoalCategoriesWithGoalsByUserId(ctx context.Context, userID
 			&i.UserID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ResetPolicy,
+			&i.ResetTime,
+			&i.ResetWeekday,
+			&i.CurrentStreak,
+			&i.LongestStreak,
+			&i.LastCompletedDate,
 			&i.GoalID,
 			&i.GoalTitle,
 			&i.Description,
 			&i.Status,
 			&i.GoalCreatedAt,
 			&i.GoalUpdatedAt,
+			&i.GoalCurrentStreak,
+			&i.GoalLongestStreak,
+			&i.GoalLastCompletedDate,
 		); err != nil {
 			return nil, err
 		}
@@ -140,7 +232,7 @@ func (q *Queries) GetGoalCategoriesWithGoalsByUserId(ctx context.Context, userID
 }
 
 const getGoalCategoryById = `-- name: GetGoalCategoryById :one
-SELECT id, title, user_id, created_at, updated_at FROM goal_categories WHERE id = $1 AND user_id = $2 LIMIT 1
+SELECT id, title, user_id, created_at, updated_at, reset_policy, reset_time, reset_weekday, reset_through, current_streak, longest_streak, last_completed_date, streak_milestone FROM goal_categories WHERE id = $1 AND user_id = $2 LIMIT 1
 `
 
 type GetGoalCategoryByIdParams struct {
@@ -157,6 +249,14 @@ func (q *Queries) GetGoalCategoryById(ctx context.Context, arg GetGoalCategoryBy
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResetPolicy,
+		&i.ResetTime,
+		&i.ResetWeekday,
+		&i.ResetThrough,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
 	)
 	return i, err
 }
@@ -164,8 +264,12 @@ func (q *Queries) GetGoalCategoryById(ctx context.Context, arg GetGoalCategoryBy
 const getGoalCategoryWithGoalsById = `-- name: GetGoalCategoryWithGoalsById :many
 SELECT
     gc.id, gc.title, gc.user_id, gc.created_at, gc.updated_at,
+    gc.reset_policy, gc.reset_time, gc.reset_weekday,
+    gc.current_streak, gc.longest_streak, gc.last_completed_date,
     g.id as goal_id, g.title as goal_title, g.description, g.status,
-    g.created_at as goal_created_at, g.updated_at as goal_updated_at
+    g.created_at as goal_created_at, g.updated_at as goal_updated_at,
+    g.current_streak as goal_current_streak, g.longest_streak as goal_longest_streak,
+    g.last_completed_date as goal_last_completed_date
 FROM goal_categories gc
 LEFT JOIN goals g ON gc.id = g.category_id
 WHERE gc.id = $1 AND gc.user_id = $2
@@ -178,17 +282,26 @@ type GetGoalCategoryWithGoalsByIdParams struct {
 }
 
 type GetGoalCategoryWithGoalsByIdRow struct {
-	ID            pgtype.UUID
-	Title         string
-	UserID        pgtype.UUID
-	CreatedAt     pgtype.Timestamp
-	UpdatedAt     pgtype.Timestamp
-	GoalID        pgtype.UUID
-	GoalTitle     pgtype.Text
-	Description   pgtype.Text
-	Status        NullGoalStatus
-	GoalCreatedAt pgtype.Timestamp
-	GoalUpdatedAt pgtype.Timestamp
+	ID                    pgtype.UUID
+	Title                 string
+	UserID                pgtype.UUID
+	CreatedAt             pgtype.Timestamp
+	UpdatedAt             pgtype.Timestamp
+	ResetPolicy           CategoryResetPolicy
+	ResetTime             pgtype.Time
+	ResetWeekday          pgtype.Int2
+	CurrentStreak         int32
+	LongestStreak         int32
+	LastCompletedDate     pgtype.Date
+	GoalID                pgtype.UUID
+	GoalTitle             pgtype.Text
+	Description           pgtype.Text
+	Status                NullGoalStatus
+	GoalCreatedAt         pgtype.Timestamp
+	GoalUpdatedAt         pgtype.Timestamp
+	GoalCurrentStreak     pgtype.Int4
+	GoalLongestStreak     pgtype.Int4
+	GoalLastCompletedDate pgtype.Date
 }
 
 func (q *Queries) GetGoalCategoryWithGoalsById(ctx context.Context, arg GetGoalCategoryWithGoalsByIdParams) ([]GetGoalCategoryWithGoalsByIdRow, error) {
@@ -206,12 +319,21 @@ func (q *Queries) GetGoalCategoryWithGoalsById(ctx context.Context, arg GetGoalC
 			&i.UserID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ResetPolicy,
+			&i.ResetTime,
+			&i.ResetWeekday,
+			&i.CurrentStreak,
+			&i.LongestStreak,
+			&i.LastCompletedDate,
 			&i.GoalID,
 			&i.GoalTitle,
 			&i.Description,
 			&i.Status,
 			&i.GoalCreatedAt,
 			&i.GoalUpdatedAt,
+			&i.GoalCurrentStreak,
+			&i.GoalLongestStreak,
+			&i.GoalLastCompletedDate,
 		); err != nil {
 			return nil, err
 		}
@@ -223,11 +345,195 @@ func (q *Queries) GetGoalCategoryWithGoalsById(ctx context.Context, arg GetGoalC
 	return items, nil
 }
 
+const getLapsedCategoryStreaks = `-- name: GetLapsedCategoryStreaks :many
+SELECT gc.id, gc.user_id, (NOW() AT TIME ZONE u.timezone)::DATE AS today
+FROM goal_categories gc
+JOIN users u ON u.id = gc.user_id
+WHERE gc.current_streak > 0
+    AND EXISTS (
+        SELECT 1
+        FROM generate_series(
+            (gc.last_completed_date + 1)::TIMESTAMP,
+            ((NOW() AT TIME ZONE u.timezone)::DATE - 1)::TIMESTAMP,
+            INTERVAL '1 day'
+        ) AS missed(day)
+        WHERE NOT EXISTS (
+            SELECT 1 FROM streak_freeze_days
+            WHERE streak_freeze_days.user_id = gc.user_id
+                AND streak_freeze_days.day = missed.day::DATE
+        )
+    )
+`
+
+type GetLapsedCategoryStreaksRow struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
+	Today  pgtype.Date
+}
+
+// categories with a streak that have missed a day since their last completion that isn't frozen
+func (q *Queries) GetLapsedCategoryStreaks(ctx context.Context) ([]GetLapsedCategoryStreaksRow, error) {
+	rows, err := q.db.Query(ctx, getLapsedCategoryStreaks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLapsedCategoryStreaksRow
+	for rows.Next() {
+		var i GetLapsedCategoryStreaksRow
+		if err := rows.Scan(&i.ID, &i.UserID, &i.Today); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockGoalCategoriesByIds = `-- name: LockGoalCategoriesByIds :many
+SELECT id, title, user_id, created_at, updated_at, reset_policy, reset_time, reset_weekday, reset_through, current_streak, longest_streak, last_completed_date, streak_milestone FROM goal_categories
+WHERE id = ANY($1::UUID[]) AND user_id = $2
+ORDER BY id
+FOR NO KEY UPDATE
+`
+
+type LockGoalCategoriesByIdsParams struct {
+	Ids    []pgtype.UUID
+	UserID pgtype.UUID
+}
+
+// locked in id order, so callers locking several can't deadlock each other, and before the goals
+// in them. resets lock in reset_through order instead and are only kept clear of these locks by
+// skipping locked categories, never waiting on one.
+func (q *Queries) LockGoalCategoriesByIds(ctx context.Context, arg LockGoalCategoriesByIdsParams) ([]GoalCategory, error) {
+	rows, err := q.db.Query(ctx, lockGoalCategoriesByIds, arg.Ids, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GoalCategory
+	for rows.Next() {
+		var i GoalCategory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ResetPolicy,
+			&i.ResetTime,
+			&i.ResetWeekday,
+			&i.ResetThrough,
+			&i.CurrentStreak,
+			&i.LongestStreak,
+			&i.LastCompletedDate,
+			&i.StreakMilestone,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setGoalCategoryReset = `-- name: SetGoalCategoryReset :one
+UPDATE goal_categories
+SET reset_policy = $3,
+    reset_time = $4,
+    reset_weekday = $5,
+    reset_through = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, title, user_id, created_at, updated_at, reset_policy, reset_time, reset_weekday, reset_through, current_streak, longest_streak, last_completed_date, streak_milestone
+`
+
+type SetGoalCategoryResetParams struct {
+	ID           pgtype.UUID
+	UserID       pgtype.UUID
+	ResetPolicy  CategoryResetPolicy
+	ResetTime    pgtype.Time
+	ResetWeekday pgtype.Int2
+}
+
+// moving reset_through to now keeps the new schedule from firing for a boundary already past
+func (q *Queries) SetGoalCategoryReset(ctx context.Context, arg SetGoalCategoryResetParams) (GoalCategory, error) {
+	row := q.db.QueryRow(ctx, setGoalCategoryReset,
+		arg.ID,
+		arg.UserID,
+		arg.ResetPolicy,
+		arg.ResetTime,
+		arg.ResetWeekday,
+	)
+	var i GoalCategory
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ResetPolicy,
+		&i.ResetTime,
+		&i.ResetWeekday,
+		&i.ResetThrough,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
+	)
+	return i, err
+}
+
+const setGoalCategoryResetThrough = `-- name: SetGoalCategoryResetThrough :exec
+UPDATE goal_categories SET reset_through = $2 WHERE id = $1
+`
+
+type SetGoalCategoryResetThroughParams struct {
+	ID           pgtype.UUID
+	ResetThrough pgtype.Timestamptz
+}
+
+func (q *Queries) SetGoalCategoryResetThrough(ctx context.Context, arg SetGoalCategoryResetThroughParams) error {
+	_, err := q.db.Exec(ctx, setGoalCategoryResetThrough, arg.ID, arg.ResetThrough)
+	return err
+}
+
+const setGoalCategoryStreak = `-- name: SetGoalCategoryStreak :exec
+UPDATE goal_categories
+SET current_streak = $2,
+    longest_streak = $3,
+    last_completed_date = $4,
+    streak_milestone = $5
+WHERE id = $1
+`
+
+type SetGoalCategoryStreakParams struct {
+	ID                pgtype.UUID
+	CurrentStreak     int32
+	LongestStreak     int32
+	LastCompletedDate pgtype.Date
+	StreakMilestone   int32
+}
+
+func (q *Queries) SetGoalCategoryStreak(ctx context.Context, arg SetGoalCategoryStreakParams) error {
+	_, err := q.db.Exec(ctx, setGoalCategoryStreak,
+		arg.ID,
+		arg.CurrentStreak,
+		arg.LongestStreak,
+		arg.LastCompletedDate,
+		arg.StreakMilestone,
+	)
+	return err
+}
+
 const updateGoalCategoryById = `-- name: UpdateGoalCategoryById :one
 UPDATE goal_categories
 SET title = coalesce($1, title)
 WHERE id = $2 AND user_id = $3
-RETURNING id, title, user_id, created_at, updated_at
+RETURNING id, title, user_id, created_at, updated_at, reset_policy, reset_time, reset_weekday, reset_through, current_streak, longest_streak, last_completed_date, streak_milestone
 `
 
 type UpdateGoalCategoryByIdParams struct {
@@ -245,6 +551,14 @@ func (q *Queries) UpdateGoalCategoryById(ctx context.Context, arg UpdateGoalCate
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResetPolicy,
+		&i.ResetTime,
+		&i.ResetWeekday,
+		&i.ResetThrough,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
 	)
 	return i, err
 }
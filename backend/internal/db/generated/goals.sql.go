@@ -11,17 +11,77 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const advanceGoalOccurrence = `-- name: AdvanceGoalOccurrence :one
+UPDATE goals
+SET status = 'not_complete',
+    occurrence_date = $1,
+    next_occurrence_date = $2
+WHERE id = $3 AND next_occurrence_date = $4
+RETURNING id, title, description, user_id, category_id, status, created_at, updated_at, recurrence, recurrence_start, occurrence_date, next_occurrence_date, current_streak, longest_streak, last_completed_date, streak_milestone
+`
+
+type AdvanceGoalOccurrenceParams struct {
+	OccurrenceDate     pgtype.Date
+	NextOccurrenceDate pgtype.Date
+	ID                 pgtype.UUID
+	DueDate            pgtype.Date
+}
+
+// reopens the goal for its new occurrence. matching on the next occurrence it was due for makes
+// this a no-op, returning no row, when the goal has already moved on.
+func (q *Queries) AdvanceGoalOccurrence(ctx context.Context, arg AdvanceGoalOccurrenceParams) (Goal, error) {
+	row := q.db.QueryRow(ctx, advanceGoalOccurrence,
+		arg.OccurrenceDate,
+		arg.NextOccurrenceDate,
+		arg.ID,
+		arg.DueDate,
+	)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.UserID,
+		&i.CategoryID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Recurrence,
+		&i.RecurrenceStart,
+		&i.OccurrenceDate,
+		&i.NextOccurrenceDate,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
+	)
+	return i, err
+}
+
 const createGoal = `-- name: CreateGoal :one
-INSERT INTO goals (title, description, user_id, category_id)
-VALUES ($1, $2, $3, $4)
-RETURNING id, title, description, user_id, category_id, status, created_at, updated_at
+INSERT INTO goals (
+    title,
+    description,
+    user_id,
+    category_id,
+    recurrence,
+    recurrence_start,
+    occurrence_date,
+    next_occurrence_date
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, title, description, user_id, category_id, status, created_at, updated_at, recurrence, recurrence_start, occurrence_date, next_occurrence_date, current_streak, longest_streak, last_completed_date, streak_milestone
 `
 
 type CreateGoalParams struct {
-	Title       string
-	Description pgtype.Text
-	UserID      pgtype.UUID
-	CategoryID  pgtype.UUID
+	Title              string
+	Description        pgtype.Text
+	UserID             pgtype.UUID
+	CategoryID         pgtype.UUID
+	Recurrence         pgtype.Text
+	RecurrenceStart    pgtype.Date
+	OccurrenceDate     pgtype.Date
+	NextOccurrenceDate pgtype.Date
 }
 
 func (q *Queries) CreateGoal(ctx context.Context, arg CreateGoalParams) (Goal, error) {
@@ -30,6 +90,10 @@ func (q *Queries) CreateGoal(ctx context.Context, arg CreateGoalParams) (Goal, e
 		arg.Description,
 		arg.UserID,
 		arg.CategoryID,
+		arg.Recurrence,
+		arg.RecurrenceStart,
+		arg.OccurrenceDate,
+		arg.NextOccurrenceDate,
 	)
 	var i Goal
 	err := row.Scan(
@@ -41,6 +105,14 @@ func (q *Queries) CreateGoal(ctx context.Context, arg CreateGoalParams) (Goal, e
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Recurrence,
+		&i.RecurrenceStart,
+		&i.OccurrenceDate,
+		&i.NextOccurrenceDate,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
 	)
 	return i, err
 }
@@ -62,8 +134,61 @@ func (q *Queries) DeleteGoalById(ctx context.Context, arg DeleteGoalByIdParams)
 	return result.RowsAffected(), nil
 }
 
+const getDueRecurringGoals = `-- name: GetDueRecurringGoals :many
+SELECT goals.id, goals.title, goals.description, goals.user_id, goals.category_id, goals.status, goals.created_at, goals.updated_at, goals.recurrence, goals.recurrence_start, goals.occurrence_date, goals.next_occurrence_date, goals.current_streak, goals.longest_streak, goals.last_completed_date, goals.streak_milestone, (NOW() AT TIME ZONE users.timezone)::DATE AS today
+FROM goals
+JOIN users ON users.id = goals.user_id
+WHERE goals.next_occurrence_date <= (NOW() AT TIME ZONE users.timezone)::DATE
+ORDER BY goals.next_occurrence_date
+LIMIT $1
+`
+
+type GetDueRecurringGoalsRow struct {
+	Goal  Goal
+	Today pgtype.Date
+}
+
+// recurring goals whose next occurrence has arrived where their user is
+func (q *Queries) GetDueRecurringGoals(ctx context.Context, limit int32) ([]GetDueRecurringGoalsRow, error) {
+	rows, err := q.db.Query(ctx, getDueRecurringGoals, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDueRecurringGoalsRow
+	for rows.Next() {
+		var i GetDueRecurringGoalsRow
+		if err := rows.Scan(
+			&i.Goal.ID,
+			&i.Goal.Title,
+			&i.Goal.Description,
+			&i.Goal.UserID,
+			&i.Goal.CategoryID,
+			&i.Goal.Status,
+			&i.Goal.CreatedAt,
+			&i.Goal.UpdatedAt,
+			&i.Goal.Recurrence,
+			&i.Goal.RecurrenceStart,
+			&i.Goal.OccurrenceDate,
+			&i.Goal.NextOccurrenceDate,
+			&i.Goal.CurrentStreak,
+			&i.Goal.LongestStreak,
+			&i.Goal.LastCompletedDate,
+			&i.Goal.StreakMilestone,
+			&i.Today,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getGoalById = `-- name: GetGoalById :one
-SELECT id, title, description, user_id, category_id, status, created_at, updated_at FROM goals WHERE id = $1 AND user_id = $2 LIMIT 1
+SELECT id, title, description, user_id, category_id, status, created_at, updated_at, recurrence, recurrence_start, occurrence_date, next_occurrence_date, current_streak, longest_streak, last_completed_date, streak_milestone FROM goals WHERE id = $1 AND user_id = $2 LIMIT 1
 `
 
 type GetGoalByIdParams struct {
@@ -83,12 +208,53 @@ func (q *Queries) GetGoalById(ctx context.Context, arg GetGoalByIdParams) (Goal,
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Recurrence,
+		&i.RecurrenceStart,
+		&i.OccurrenceDate,
+		&i.NextOccurrenceDate,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
+	)
+	return i, err
+}
+
+const getGoalByIdForUpdate = `-- name: GetGoalByIdForUpdate :one
+SELECT id, title, description, user_id, category_id, status, created_at, updated_at, recurrence, recurrence_start, occurrence_date, next_occurrence_date, current_streak, longest_streak, last_completed_date, streak_milestone FROM goals WHERE id = $1 AND user_id = $2 FOR UPDATE
+`
+
+type GetGoalByIdForUpdateParams struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
+}
+
+func (q *Queries) GetGoalByIdForUpdate(ctx context.Context, arg GetGoalByIdForUpdateParams) (Goal, error) {
+	row := q.db.QueryRow(ctx, getGoalByIdForUpdate, arg.ID, arg.UserID)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.UserID,
+		&i.CategoryID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Recurrence,
+		&i.RecurrenceStart,
+		&i.OccurrenceDate,
+		&i.NextOccurrenceDate,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
 	)
 	return i, err
 }
 
 const getGoalsByUserId = `-- name: GetGoalsByUserId :many
-SELECT id, title, description, user_id, category_id, status, created_at, updated_at FROM goals WHERE user_id = $1
+SELECT id, title, description, user_id, category_id, status, created_at, updated_at, recurrence, recurrence_start, occurrence_date, next_occurrence_date, current_streak, longest_streak, last_completed_date, streak_milestone FROM goals WHERE user_id = $1
 `
 
 func (q *Queries) GetGoalsByUserId(ctx context.Context, userID pgtype.UUID) ([]Goal, error) {
@@ -109,6 +275,14 @@ func (q *Queries) GetGoalsByUserId(ctx context.Context, userID pgtype.UUID) ([]G
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Recurrence,
+			&i.RecurrenceStart,
+			&i.OccurrenceDate,
+			&i.NextOccurrenceDate,
+			&i.CurrentStreak,
+			&i.LongestStreak,
+			&i.LastCompletedDate,
+			&i.StreakMilestone,
 		); err != nil {
 			return nil, err
 		}
@@ -120,6 +294,56 @@ func (q *Queries) GetGoalsByUserId(ctx context.Context, userID pgtype.UUID) ([]G
 	return items, nil
 }
 
+const getLapsedGoalStreaks = `-- name: GetLapsedGoalStreaks :many
+SELECT goals.id, goals.user_id, (NOW() AT TIME ZONE users.timezone)::DATE AS today
+FROM goals
+JOIN users ON users.id = goals.user_id
+WHERE goals.recurrence IS NULL
+    AND goals.current_streak > 0
+    AND EXISTS (
+        SELECT 1
+        FROM generate_series(
+            (goals.last_completed_date + 1)::TIMESTAMP,
+            ((NOW() AT TIME ZONE users.timezone)::DATE - 1)::TIMESTAMP,
+            INTERVAL '1 day'
+        ) AS missed(day)
+        WHERE NOT EXISTS (
+            SELECT 1 FROM streak_freeze_days
+            WHERE streak_freeze_days.user_id = goals.user_id
+                AND streak_freeze_days.day = missed.day::DATE
+        )
+    )
+`
+
+type GetLapsedGoalStreaksRow struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
+	Today  pgtype.Date
+}
+
+// goals counted in days, which is every goal with a streak that doesn't recur, that have missed a
+// day since their last completion that isn't frozen. recurring goals break theirs as they move on
+// to a new occurrence.
+func (q *Queries) GetLapsedGoalStreaks(ctx context.Context) ([]GetLapsedGoalStreaksRow, error) {
+	rows, err := q.db.Query(ctx, getLapsedGoalStreaks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLapsedGoalStreaksRow
+	for rows.Next() {
+		var i GetLapsedGoalStreaksRow
+		if err := rows.Scan(&i.ID, &i.UserID, &i.Today); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const resetGoalsByCategory = `-- name: ResetGoalsByCategory :exec
 UPDATE goals
 SET status = 'not_complete'
@@ -136,6 +360,84 @@ func (q *Queries) ResetGoalsByCategory(ctx context.Context, arg ResetGoalsByCate
 	return err
 }
 
+const setGoalRecurrence = `-- name: SetGoalRecurrence :one
+UPDATE goals
+SET recurrence = $3,
+    recurrence_start = $4,
+    occurrence_date = $5,
+    next_occurrence_date = $6
+WHERE id = $1 AND user_id = $2
+RETURNING id, title, description, user_id, category_id, status, created_at, updated_at, recurrence, recurrence_start, occurrence_date, next_occurrence_date, current_streak, longest_streak, last_completed_date, streak_milestone
+`
+
+type SetGoalRecurrenceParams struct {
+	ID                 pgtype.UUID
+	UserID             pgtype.UUID
+	Recurrence         pgtype.Text
+	RecurrenceStart    pgtype.Date
+	OccurrenceDate     pgtype.Date
+	NextOccurrenceDate pgtype.Date
+}
+
+func (q *Queries) SetGoalRecurrence(ctx context.Context, arg SetGoalRecurrenceParams) (Goal, error) {
+	row := q.db.QueryRow(ctx, setGoalRecurrence,
+		arg.ID,
+		arg.UserID,
+		arg.Recurrence,
+		arg.RecurrenceStart,
+		arg.OccurrenceDate,
+		arg.NextOccurrenceDate,
+	)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.UserID,
+		&i.CategoryID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Recurrence,
+		&i.RecurrenceStart,
+		&i.OccurrenceDate,
+		&i.NextOccurrenceDate,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
+	)
+	return i, err
+}
+
+const setGoalStreak = `-- name: SetGoalStreak :exec
+UPDATE goals
+SET current_streak = $2,
+    longest_streak = $3,
+    last_completed_date = $4,
+    streak_milestone = $5
+WHERE id = $1
+`
+
+type SetGoalStreakParams struct {
+	ID                pgtype.UUID
+	CurrentStreak     int32
+	LongestStreak     int32
+	LastCompletedDate pgtype.Date
+	StreakMilestone   int32
+}
+
+func (q *Queries) SetGoalStreak(ctx context.Context, arg SetGoalStreakParams) error {
+	_, err := q.db.Exec(ctx, setGoalStreak,
+		arg.ID,
+		arg.CurrentStreak,
+		arg.LongestStreak,
+		arg.LastCompletedDate,
+		arg.StreakMilestone,
+	)
+	return err
+}
+
 const updateGoalById = `-- name: UpdateGoalById :one
 UPDATE goals
 SET title = coalesce($1, title),
@@ -143,7 +445,7 @@ SET title = coalesce($1, title),
     status = coalesce($3, status),
     category_id = coalesce($4, category_id)
 WHERE id = $5 AND user_id = $6
-RETURNING id, title, description, user_id, category_id, status, created_at, updated_at
+RETURNING id, title, description, user_id, category_id, status, created_at, updated_at, recurrence, recurrence_start, occurrence_date, next_occurrence_date, current_streak, longest_streak, last_completed_date, streak_milestone
 `
 
 type UpdateGoalByIdParams struct {
@@ -174,6 +476,14 @@ func (q *Queries) UpdateGoalById(ctx context.Context, arg UpdateGoalByIdParams)
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Recurrence,
+		&i.RecurrenceStart,
+		&i.OccurrenceDate,
+		&i.NextOccurrenceDate,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
 	)
 	return i, err
 }
@@ -182,7 +492,7 @@ const updateGoalStatus = `-- name: UpdateGoalStatus :one
 UPDATE goals
 SET status = $1
 WHERE id = $2 AND user_id = $3
-RETURNING id, title, description, user_id, category_id, status, created_at, updated_at
+RETURNING id, title, description, user_id, category_id, status, created_at, updated_at, recurrence, recurrence_start, occurrence_date, next_occurrence_date, current_streak, longest_streak, last_completed_date, streak_milestone
 `
 
 type UpdateGoalStatusParams struct {
@@ -203,6 +513,14 @@ func (q *Queries) UpdateGoalStatus(ctx context.Context, arg UpdateGoalStatusPara
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Recurrence,
+		&i.RecurrenceStart,
+		&i.OccurrenceDate,
+		&i.NextOccurrenceDate,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.LastCompletedDate,
+		&i.StreakMilestone,
 	)
 	return i, err
 }
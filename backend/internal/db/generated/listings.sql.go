@@ -0,0 +1,269 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: listings.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const closeListing = `-- name: CloseListing :one
+UPDATE listings
+SET status = $2,
+    buyer_id = $3,
+    fee = $4,
+    closed_at = NOW()
+WHERE id = $1
+RETURNING id, seller_id, user_item_id, price, status, buyer_id, fee, closed_at, created_at
+`
+
+type CloseListingParams struct {
+	ID      int64
+	Status  ListingStatus
+	BuyerID pgtype.UUID
+	Fee     pgtype.Int4
+}
+
+func (q *Queries) CloseListing(ctx context.Context, arg CloseListingParams) (Listing, error) {
+	row := q.db.QueryRow(ctx, closeListing,
+		arg.ID,
+		arg.Status,
+		arg.BuyerID,
+		arg.Fee,
+	)
+	var i Listing
+	err := row.Scan(
+		&i.ID,
+		&i.SellerID,
+		&i.UserItemID,
+		&i.Price,
+		&i.Status,
+		&i.BuyerID,
+		&i.Fee,
+		&i.ClosedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createListing = `-- name: CreateListing :one
+INSERT INTO listings (seller_id, user_item_id, price)
+VALUES ($1, $2, $3)
+RETURNING id, seller_id, user_item_id, price, status, buyer_id, fee, closed_at, created_at
+`
+
+type CreateListingParams struct {
+	SellerID   pgtype.UUID
+	UserItemID pgtype.UUID
+	Price      int32
+}
+
+func (q *Queries) CreateListing(ctx context.Context, arg CreateListingParams) (Listing, error) {
+	row := q.db.QueryRow(ctx, createListing, arg.SellerID, arg.UserItemID, arg.Price)
+	var i Listing
+	err := row.Scan(
+		&i.ID,
+		&i.SellerID,
+		&i.UserItemID,
+		&i.Price,
+		&i.Status,
+		&i.BuyerID,
+		&i.Fee,
+		&i.ClosedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getActiveListings = `-- name: GetActiveListings :many
+SELECT listings.id, listings.seller_id, listings.user_item_id, listings.price, listings.status, listings.buyer_id, listings.fee, listings.closed_at, listings.created_at, chest_items.id, chest_items.image_url, chest_items.title, chest_items.rarity, chest_items.price, chest_items.created_at, chest_items.updated_at, chest_items.slot, chest_items.slug, chest_items.effect, chest_items.effect_value, chest_items.effect_duration_seconds
+FROM listings
+JOIN user_items ON user_items.id = listings.user_item_id
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE listings.status = 'active'
+  AND ($1::item_type IS NULL OR chest_items.rarity = $1)
+  AND (
+      $2::TEXT IS NULL
+      OR chest_items.title ILIKE '%' || $2 || '%'
+  )
+  AND ($3::BIGINT IS NULL OR listings.id < $3)
+ORDER BY listings.id DESC
+LIMIT $4
+`
+
+type GetActiveListingsParams struct {
+	Rarity   NullItemType
+	Title    pgtype.Text
+	Cursor   pgtype.Int8
+	PageSize int32
+}
+
+type GetActiveListingsRow struct {
+	Listing   Listing
+	ChestItem ChestItem
+}
+
+func (q *Queries) GetActiveListings(ctx context.Context, arg GetActiveListingsParams) ([]GetActiveListingsRow, error) {
+	rows, err := q.db.Query(ctx, getActiveListings,
+		arg.Rarity,
+		arg.Title,
+		arg.Cursor,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActiveListingsRow
+	for rows.Next() {
+		var i GetActiveListingsRow
+		if err := rows.Scan(
+			&i.Listing.ID,
+			&i.Listing.SellerID,
+			&i.Listing.UserItemID,
+			&i.Listing.Price,
+			&i.Listing.Status,
+			&i.Listing.BuyerID,
+			&i.Listing.Fee,
+			&i.Listing.ClosedAt,
+			&i.Listing.CreatedAt,
+			&i.ChestItem.ID,
+			&i.ChestItem.ImageUrl,
+			&i.ChestItem.Title,
+			&i.ChestItem.Rarity,
+			&i.ChestItem.Price,
+			&i.ChestItem.CreatedAt,
+			&i.ChestItem.UpdatedAt,
+			&i.ChestItem.Slot,
+			&i.ChestItem.Slug,
+			&i.ChestItem.Effect,
+			&i.ChestItem.EffectValue,
+			&i.ChestItem.EffectDurationSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getListingsBySellerId = `-- name: GetListingsBySellerId :many
+SELECT listings.id, listings.seller_id, listings.user_item_id, listings.price, listings.status, listings.buyer_id, listings.fee, listings.closed_at, listings.created_at, chest_items.id, chest_items.image_url, chest_items.title, chest_items.rarity, chest_items.price, chest_items.created_at, chest_items.updated_at, chest_items.slot, chest_items.slug, chest_items.effect, chest_items.effect_value, chest_items.effect_duration_seconds
+FROM listings
+JOIN user_items ON user_items.id = listings.user_item_id
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE listings.seller_id = $1
+  AND ($2::listing_status IS NULL OR listings.status = $2)
+  AND ($3::BIGINT IS NULL OR listings.id < $3)
+ORDER BY listings.id DESC
+LIMIT $4
+`
+
+type GetListingsBySellerIdParams struct {
+	SellerID pgtype.UUID
+	Status   NullListingStatus
+	Cursor   pgtype.Int8
+	PageSize int32
+}
+
+type GetListingsBySellerIdRow struct {
+	Listing   Listing
+	ChestItem ChestItem
+}
+
+func (q *Queries) GetListingsBySellerId(ctx context.Context, arg GetListingsBySellerIdParams) ([]GetListingsBySellerIdRow, error) {
+	rows, err := q.db.Query(ctx, getListingsBySellerId,
+		arg.SellerID,
+		arg.Status,
+		arg.Cursor,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetListingsBySellerIdRow
+	for rows.Next() {
+		var i GetListingsBySellerIdRow
+		if err := rows.Scan(
+			&i.Listing.ID,
+			&i.Listing.SellerID,
+			&i.Listing.UserItemID,
+			&i.Listing.Price,
+			&i.Listing.Status,
+			&i.Listing.BuyerID,
+			&i.Listing.Fee,
+			&i.Listing.ClosedAt,
+			&i.Listing.CreatedAt,
+			&i.ChestItem.ID,
+			&i.ChestItem.ImageUrl,
+			&i.ChestItem.Title,
+			&i.ChestItem.Rarity,
+			&i.ChestItem.Price,
+			&i.ChestItem.CreatedAt,
+			&i.ChestItem.UpdatedAt,
+			&i.ChestItem.Slot,
+			&i.ChestItem.Slug,
+			&i.ChestItem.Effect,
+			&i.ChestItem.EffectValue,
+			&i.ChestItem.EffectDurationSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockActiveListing = `-- name: LockActiveListing :one
+SELECT listings.id, listings.seller_id, listings.user_item_id, listings.price, listings.status, listings.buyer_id, listings.fee, listings.closed_at, listings.created_at, chest_items.id, chest_items.image_url, chest_items.title, chest_items.rarity, chest_items.price, chest_items.created_at, chest_items.updated_at, chest_items.slot, chest_items.slug, chest_items.effect, chest_items.effect_value, chest_items.effect_duration_seconds
+FROM listings
+JOIN user_items ON user_items.id = listings.user_item_id
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE listings.id = $1 AND listings.status = 'active'
+FOR UPDATE OF listings
+`
+
+type LockActiveListingRow struct {
+	Listing   Listing
+	ChestItem ChestItem
+}
+
+func (q *Queries) LockActiveListing(ctx context.Context, id int64) (LockActiveListingRow, error) {
+	row := q.db.QueryRow(ctx, lockActiveListing, id)
+	var i LockActiveListingRow
+	err := row.Scan(
+		&i.Listing.ID,
+		&i.Listing.SellerID,
+		&i.Listing.UserItemID,
+		&i.Listing.Price,
+		&i.Listing.Status,
+		&i.Listing.BuyerID,
+		&i.Listing.Fee,
+		&i.Listing.ClosedAt,
+		&i.Listing.CreatedAt,
+		&i.ChestItem.ID,
+		&i.ChestItem.ImageUrl,
+		&i.ChestItem.Title,
+		&i.ChestItem.Rarity,
+		&i.ChestItem.Price,
+		&i.ChestItem.CreatedAt,
+		&i.ChestItem.UpdatedAt,
+		&i.ChestItem.Slot,
+		&i.ChestItem.Slug,
+		&i.ChestItem.Effect,
+		&i.ChestItem.EffectValue,
+		&i.ChestItem.EffectDurationSeconds,
+	)
+	return i, err
+}
@@ -11,6 +11,98 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CashTransactionReason string
+
+const (
+	CashTransactionReasonOpeningBalance      CashTransactionReason = "opening_balance"
+	CashTransactionReasonLevelReward         CashTransactionReason = "level_reward"
+	CashTransactionReasonChestPurchase       CashTransactionReason = "chest_purchase"
+	CashTransactionReasonItemSale            CashTransactionReason = "item_sale"
+	CashTransactionReasonAdminGrant          CashTransactionReason = "admin_grant"
+	CashTransactionReasonMarketplacePurchase CashTransactionReason = "marketplace_purchase"
+	CashTransactionReasonMarketplaceSale     CashTransactionReason = "marketplace_sale"
+	CashTransactionReasonCollectionBonus     CashTransactionReason = "collection_bonus"
+	CashTransactionReasonRewardRedemption    CashTransactionReason = "reward_redemption"
+)
+
+func (e *CashTransactionReason) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CashTransactionReason(s)
+	case string:
+		*e = CashTransactionReason(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CashTransactionReason: %T", src)
+	}
+	return nil
+}
+
+type NullCashTransactionReason struct {
+	CashTransactionReason CashTransactionReason
+	Valid                 bool // Valid is true if CashTransactionReason is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCashTransactionReason) Scan(value interface{}) error {
+	if value == nil {
+		ns.CashTransactionReason, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CashTransactionReason.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCashTransactionReason) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CashTransactionReason), nil
+}
+
+type CategoryResetPolicy string
+
+const (
+	CategoryResetPolicyNone   CategoryResetPolicy = "none"
+	CategoryResetPolicyDaily  CategoryResetPolicy = "daily"
+	CategoryResetPolicyWeekly CategoryResetPolicy = "weekly"
+)
+
+func (e *CategoryResetPolicy) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CategoryResetPolicy(s)
+	case string:
+		*e = CategoryResetPolicy(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CategoryResetPolicy: %T", src)
+	}
+	return nil
+}
+
+type NullCategoryResetPolicy struct {
+	CategoryResetPolicy CategoryResetPolicy
+	Valid               bool // Valid is true if CategoryResetPolicy is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCategoryResetPolicy) Scan(value interface{}) error {
+	if value == nil {
+		ns.CategoryResetPolicy, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CategoryResetPolicy.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCategoryResetPolicy) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CategoryResetPolicy), nil
+}
+
 type ChestStatus string
 
 const (
@@ -96,6 +188,50 @@ func (ns NullChestType) Value() (driver.Value, error) {
 	return string(ns.ChestType), nil
 }
 
+type GiftStatus string
+
+const (
+	GiftStatusPending  GiftStatus = "pending"
+	GiftStatusAccepted GiftStatus = "accepted"
+	GiftStatusDeclined GiftStatus = "declined"
+	GiftStatusExpired  GiftStatus = "expired"
+)
+
+func (e *GiftStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GiftStatus(s)
+	case string:
+		*e = GiftStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GiftStatus: %T", src)
+	}
+	return nil
+}
+
+type NullGiftStatus struct {
+	GiftStatus GiftStatus
+	Valid      bool // Valid is true if GiftStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullGiftStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.GiftStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.GiftStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullGiftStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.GiftStatus), nil
+}
+
 type GoalStatus string
 
 const (
@@ -138,6 +274,93 @@ func (ns NullGoalStatus) Value() (driver.Value, error) {
 	return string(ns.GoalStatus), nil
 }
 
+type ItemEffect string
+
+const (
+	ItemEffectXpMultiplier ItemEffect = "xp_multiplier"
+	ItemEffectStreakFreeze ItemEffect = "streak_freeze"
+)
+
+func (e *ItemEffect) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ItemEffect(s)
+	case string:
+		*e = ItemEffect(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ItemEffect: %T", src)
+	}
+	return nil
+}
+
+type NullItemEffect struct {
+	ItemEffect ItemEffect
+	Valid      bool // Valid is true if ItemEffect is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullItemEffect) Scan(value interface{}) error {
+	if value == nil {
+		ns.ItemEffect, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ItemEffect.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullItemEffect) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ItemEffect), nil
+}
+
+type ItemSlot string
+
+const (
+	ItemSlotHead       ItemSlot = "head"
+	ItemSlotBody       ItemSlot = "body"
+	ItemSlotWeapon     ItemSlot = "weapon"
+	ItemSlotAccessory  ItemSlot = "accessory"
+	ItemSlotBackground ItemSlot = "background"
+)
+
+func (e *ItemSlot) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ItemSlot(s)
+	case string:
+		*e = ItemSlot(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ItemSlot: %T", src)
+	}
+	return nil
+}
+
+type NullItemSlot struct {
+	ItemSlot ItemSlot
+	Valid    bool // Valid is true if ItemSlot is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullItemSlot) Scan(value interface{}) error {
+	if value == nil {
+		ns.ItemSlot, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ItemSlot.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullItemSlot) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ItemSlot), nil
+}
+
 type ItemStatus string
 
 const (
@@ -224,23 +447,103 @@ func (ns NullItemType) Value() (driver.Value, error) {
 	return string(ns.ItemType), nil
 }
 
+type ListingStatus string
+
+const (
+	ListingStatusActive    ListingStatus = "active"
+	ListingStatusSold      ListingStatus = "sold"
+	ListingStatusCancelled ListingStatus = "cancelled"
+)
+
+func (e *ListingStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ListingStatus(s)
+	case string:
+		*e = ListingStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ListingStatus: %T", src)
+	}
+	return nil
+}
+
+type NullListingStatus struct {
+	ListingStatus ListingStatus
+	Valid         bool // Valid is true if ListingStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullListingStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ListingStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ListingStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullListingStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ListingStatus), nil
+}
+
+type ActiveEffect struct {
+	ID           int64
+	UserID       pgtype.UUID
+	Effect       ItemEffect
+	Value        int32
+	SourceItemID pgtype.UUID
+	ExpiresAt    pgtype.Timestamp
+	CreatedAt    pgtype.Timestamp
+}
+
+type CashTransaction struct {
+	ID           int64
+	UserID       pgtype.UUID
+	Amount       int32
+	Reason       CashTransactionReason
+	ReferenceID  pgtype.UUID
+	BalanceAfter int32
+	CreatedAt    pgtype.Timestamp
+}
+
 type Chest struct {
-	ID          pgtype.UUID
-	Type        ChestType
-	Description string
-	Price       int32
-	CreatedAt   pgtype.Timestamp
-	UpdatedAt   pgtype.Timestamp
+	ID               pgtype.UUID
+	Type             ChestType
+	Description      string
+	Price            int32
+	CreatedAt        pgtype.Timestamp
+	UpdatedAt        pgtype.Timestamp
+	DropTableVersion int32
+	Slug             pgtype.Text
 }
 
-type ChestItem struct {
+type ChestDropTableHistory struct {
 	ID        pgtype.UUID
-	ImageUrl  pgtype.Text
-	Title     string
-	Rarity    ItemType
-	Price     pgtype.Int4
+	ChestID   pgtype.UUID
+	Version   int32
+	ItemID    pgtype.UUID
+	DropRate  float64
+	CreatedBy pgtype.UUID
 	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
+}
+
+type ChestItem struct {
+	ID                    pgtype.UUID
+	ImageUrl              pgtype.Text
+	Title                 string
+	Rarity                ItemType
+	Price                 pgtype.Int4
+	CreatedAt             pgtype.Timestamp
+	UpdatedAt             pgtype.Timestamp
+	Slot                  ItemSlot
+	Slug                  pgtype.Text
+	Effect                NullItemEffect
+	EffectValue           pgtype.Int4
+	EffectDurationSeconds pgtype.Int4
 }
 
 type ChestItemDropRate struct {
@@ -252,23 +555,137 @@ type ChestItemDropRate struct {
 	UpdatedAt pgtype.Timestamp
 }
 
-type Goal struct {
+type ChestOpening struct {
+	ID               int64
+	UserID           pgtype.UUID
+	ChestID          pgtype.UUID
+	ItemID           pgtype.UUID
+	UserItemID       pgtype.UUID
+	DropTableVersion int32
+	FairnessSeedID   pgtype.UUID
+	Nonce            int32
+	Roll             float64
+	TopDropChance    pgtype.Float8
+	CreatedAt        pgtype.Timestamp
+}
+
+type CollectionReward struct {
+	UserID    pgtype.UUID
+	ChestID   pgtype.UUID
+	Cash      int32
+	CreatedAt pgtype.Timestamp
+}
+
+type Craft struct {
+	ID               int64
+	UserID           pgtype.UUID
+	RecipeID         pgtype.UUID
+	ItemID           pgtype.UUID
+	UserItemID       pgtype.UUID
+	DropTableVersion int32
+	FairnessSeedID   pgtype.UUID
+	Nonce            int32
+	Roll             float64
+	CreatedAt        pgtype.Timestamp
+}
+
+type CraftInput struct {
+	CraftID    int64
+	UserItemID pgtype.UUID
+	ItemID     pgtype.UUID
+}
+
+type CraftingRecipe struct {
+	ID           pgtype.UUID
+	Slug         string
+	ChestID      pgtype.UUID
+	InputRarity  ItemType
+	InputCount   int32
+	OutputRarity ItemType
+	CreatedAt    pgtype.Timestamp
+	UpdatedAt    pgtype.Timestamp
+}
+
+type DailyClaim struct {
+	ID        int64
+	UserID    pgtype.UUID
+	ClaimDate pgtype.Date
+	Streak    int32
+	ChestID   pgtype.UUID
+	CreatedAt pgtype.Timestamp
+}
+
+type FairnessSeed struct {
+	ID             pgtype.UUID
+	UserID         pgtype.UUID
+	ServerSeed     string
+	ServerSeedHash string
+	ClientSeed     string
+	NextNonce      int32
+	RevealedAt     pgtype.Timestamp
+	CreatedAt      pgtype.Timestamp
+}
+
+type Gift struct {
 	ID          pgtype.UUID
-	Title       string
-	Description pgtype.Text
-	UserID      pgtype.UUID
-	CategoryID  pgtype.UUID
-	Status      NullGoalStatus
+	SenderID    pgtype.UUID
+	RecipientID pgtype.UUID
+	ChestID     pgtype.UUID
+	Quantity    pgtype.Int4
+	UserItemID  pgtype.UUID
+	Status      GiftStatus
+	ExpiresAt   pgtype.Timestamp
+	ResolvedAt  pgtype.Timestamp
 	CreatedAt   pgtype.Timestamp
-	UpdatedAt   pgtype.Timestamp
+}
+
+type Goal struct {
+	ID                 pgtype.UUID
+	Title              string
+	Description        pgtype.Text
+	UserID             pgtype.UUID
+	CategoryID         pgtype.UUID
+	Status             NullGoalStatus
+	CreatedAt          pgtype.Timestamp
+	UpdatedAt          pgtype.Timestamp
+	Recurrence         pgtype.Text
+	RecurrenceStart    pgtype.Date
+	OccurrenceDate     pgtype.Date
+	NextOccurrenceDate pgtype.Date
+	CurrentStreak      int32
+	LongestStreak      int32
+	LastCompletedDate  pgtype.Date
+	StreakMilestone    int32
 }
 
 type GoalCategory struct {
-	ID        pgtype.UUID
-	Title     string
-	UserID    pgtype.UUID
-	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
+	ID                pgtype.UUID
+	Title             string
+	UserID            pgtype.UUID
+	CreatedAt         pgtype.Timestamp
+	UpdatedAt         pgtype.Timestamp
+	ResetPolicy       CategoryResetPolicy
+	ResetTime         pgtype.Time
+	ResetWeekday      pgtype.Int2
+	ResetThrough      pgtype.Timestamptz
+	CurrentStreak     int32
+	LongestStreak     int32
+	LastCompletedDate pgtype.Date
+	StreakMilestone   int32
+}
+
+type GoalCompletion struct {
+	ID             int64
+	GoalID         pgtype.UUID
+	UserID         pgtype.UUID
+	OccurrenceDate pgtype.Date
+	CompletedAt    pgtype.Timestamp
+	CategoryID     pgtype.UUID
+	XpGranted      int32
+	UncompletedAt  pgtype.Timestamp
+	PeriodDate     pgtype.Date
+	GoalStreak     int32
+	CategoryStreak int32
 }
 
 type Level struct {
@@ -279,17 +696,59 @@ type Level struct {
 	UpdatedAt  pgtype.Timestamp
 }
 
+type Listing struct {
+	ID         int64
+	SellerID   pgtype.UUID
+	UserItemID pgtype.UUID
+	Price      int32
+	Status     ListingStatus
+	BuyerID    pgtype.UUID
+	Fee        pgtype.Int4
+	ClosedAt   pgtype.Timestamp
+	CreatedAt  pgtype.Timestamp
+}
+
+type Reward struct {
+	ID              pgtype.UUID
+	UserID          pgtype.UUID
+	Title           string
+	Description     string
+	Cost            int32
+	Repeatable      bool
+	CooldownSeconds int32
+	CreatedAt       pgtype.Timestamp
+	UpdatedAt       pgtype.Timestamp
+}
+
+type RewardRedemption struct {
+	ID        int64
+	UserID    pgtype.UUID
+	RewardID  pgtype.UUID
+	Title     string
+	Cost      int32
+	CreatedAt pgtype.Timestamp
+}
+
+type StreakFreezeDay struct {
+	UserID    pgtype.UUID
+	Day       pgtype.Date
+	CreatedAt pgtype.Timestamp
+}
+
 type User struct {
 	ID                 pgtype.UUID
 	Email              string
 	Password           string
 	Xp                 pgtype.Int4
 	LevelID            pgtype.Int4
-	CashAvailable      pgtype.Int4
+	CashAvailable      int32
 	RefreshToken       pgtype.UUID
 	RefreshTokenExpiry pgtype.Timestamp
 	CreatedAt          pgtype.Timestamp
 	UpdatedAt          pgtype.Timestamp
+	IsAdmin            bool
+	Timezone           string
+	HighestLevelID     int32
 }
 
 type UserChest struct {
@@ -301,6 +760,13 @@ type UserChest struct {
 	UpdatedAt     pgtype.Timestamp
 }
 
+type UserChestPity struct {
+	UserID            pgtype.UUID
+	ChestID           pgtype.UUID
+	OpensSinceTopDrop int32
+	UpdatedAt         pgtype.Timestamp
+}
+
 type UserItem struct {
 	ID        pgtype.UUID
 	UserID    pgtype.UUID
@@ -309,3 +775,9 @@ type UserItem struct {
 	CreatedAt pgtype.Timestamp
 	UpdatedAt pgtype.Timestamp
 }
+
+type UserItemDiscovery struct {
+	UserID          pgtype.UUID
+	ItemID          pgtype.UUID
+	FirstObtainedAt pgtype.Timestamp
+}
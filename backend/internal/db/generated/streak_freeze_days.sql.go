@@ -0,0 +1,60 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: streak_freeze_days.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createStreakFreezeDay = `-- name: CreateStreakFreezeDay :exec
+INSERT INTO streak_freeze_days (user_id, day)
+VALUES ($1, $2)
+ON CONFLICT (user_id, day) DO NOTHING
+`
+
+type CreateStreakFreezeDayParams struct {
+	UserID pgtype.UUID
+	Day    pgtype.Date
+}
+
+func (q *Queries) CreateStreakFreezeDay(ctx context.Context, arg CreateStreakFreezeDayParams) error {
+	_, err := q.db.Exec(ctx, createStreakFreezeDay, arg.UserID, arg.Day)
+	return err
+}
+
+const getStreakFreezeDays = `-- name: GetStreakFreezeDays :many
+SELECT day FROM streak_freeze_days
+WHERE user_id = $1 AND day BETWEEN $2::DATE AND $3::DATE
+ORDER BY day
+`
+
+type GetStreakFreezeDaysParams struct {
+	UserID  pgtype.UUID
+	FromDay pgtype.Date
+	ToDay   pgtype.Date
+}
+
+func (q *Queries) GetStreakFreezeDays(ctx context.Context, arg GetStreakFreezeDaysParams) ([]pgtype.Date, error) {
+	rows, err := q.db.Query(ctx, getStreakFreezeDays, arg.UserID, arg.FromDay, arg.ToDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.Date
+	for rows.Next() {
+		var day pgtype.Date
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		items = append(items, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
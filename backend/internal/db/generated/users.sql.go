@@ -12,7 +12,7 @@ import (
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (email, password, refresh_token_expiry, level_id) VALUES ($1, $2, $3, $4) RETURNING id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at
+INSERT INTO users (email, password, refresh_token_expiry, level_id) VALUES ($1, $2, $3, $4) RETURNING id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at, is_admin, timezone, highest_level_id
 `
 
 type CreateUserParams struct {
@@ -41,6 +41,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.RefreshTokenExpiry,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.Timezone,
+		&i.HighestLevelID,
 	)
 	return i, err
 }
@@ -72,7 +75,7 @@ func (q *Queries) GetLevelById(ctx context.Context, id int32) (Level, error) {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at FROM users WHERE email = $1 LIMIT 1
+SELECT id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at, is_admin, timezone, highest_level_id FROM users WHERE email = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -89,12 +92,15 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.RefreshTokenExpiry,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.Timezone,
+		&i.HighestLevelID,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at FROM users WHERE id = $1 LIMIT 1
+SELECT id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at, is_admin, timezone, highest_level_id FROM users WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserById(ctx context.Context, id pgtype.UUID) (User, error) {
@@ -111,16 +117,54 @@ func (q *Queries) GetUserById(ctx context.Context, id pgtype.UUID) (User, error)
 		&i.RefreshTokenExpiry,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.Timezone,
+		&i.HighestLevelID,
 	)
 	return i, err
 }
 
+const getUserByIdForUpdate = `-- name: GetUserByIdForUpdate :one
+SELECT id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at, is_admin, timezone, highest_level_id FROM users WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetUserByIdForUpdate(ctx context.Context, id pgtype.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByIdForUpdate, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Xp,
+		&i.LevelID,
+		&i.CashAvailable,
+		&i.RefreshToken,
+		&i.RefreshTokenExpiry,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.Timezone,
+		&i.HighestLevelID,
+	)
+	return i, err
+}
+
+const lockUserById = `-- name: LockUserById :exec
+SELECT id FROM users WHERE id = $1 FOR UPDATE
+`
+
+// serializes per-user writes, such as equipping items, for the rest of the transaction
+func (q *Queries) LockUserById(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, lockUserById, id)
+	return err
+}
+
 const updateRefreshToken = `-- name: UpdateRefreshToken :one
 UPDATE users
     SET refresh_token = $1,
     refresh_token_expiry = $2
     WHERE id = $3 
-    RETURNING id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at
+    RETURNING id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at, is_admin, timezone, highest_level_id
 `
 
 type UpdateRefreshTokenParams struct {
@@ -143,6 +187,9 @@ func (q *Queries) UpdateRefreshToken(ctx context.Context, arg UpdateRefreshToken
 		&i.RefreshTokenExpiry,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.Timezone,
+		&i.HighestLevelID,
 	)
 	return i, err
 }
@@ -155,9 +202,9 @@ UPDATE users
     refresh_token_expiry = coalesce($4, refresh_token_expiry),
     level_id = coalesce($5, level_id),
     xp = coalesce($6, xp),
-    cash_available = coalesce($7, cash_available)
+    timezone = coalesce($7, timezone)
     WHERE id = $8
-    RETURNING id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at
+    RETURNING id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at, is_admin, timezone, highest_level_id
 `
 
 type UpdateUserByIdParams struct {
@@ -167,7 +214,7 @@ type UpdateUserByIdParams struct {
 	RefreshTokenExpiry pgtype.Timestamp
 	LevelID            pgtype.Int4
 	Xp                 pgtype.Int4
-	CashAvailable      pgtype.Int4
+	Timezone           pgtype.Text
 	ID                 pgtype.UUID
 }
 
@@ -179,7 +226,7 @@ func (q *Queries) UpdateUserById(ctx context.Context, arg UpdateUserByIdParams)
 		arg.RefreshTokenExpiry,
 		arg.LevelID,
 		arg.Xp,
-		arg.CashAvailable,
+		arg.Timezone,
 		arg.ID,
 	)
 	var i User
@@ -194,6 +241,46 @@ func (q *Queries) UpdateUserById(ctx context.Context, arg UpdateUserByIdParams)
 		&i.RefreshTokenExpiry,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.Timezone,
+		&i.HighestLevelID,
+	)
+	return i, err
+}
+
+const updateUserProgress = `-- name: UpdateUserProgress :one
+UPDATE users
+    SET xp = $1,
+    level_id = $2,
+    highest_level_id = GREATEST(highest_level_id, $2),
+    updated_at = NOW()
+    WHERE id = $3
+    RETURNING id, email, password, xp, level_id, cash_available, refresh_token, refresh_token_expiry, created_at, updated_at, is_admin, timezone, highest_level_id
+`
+
+type UpdateUserProgressParams struct {
+	Xp      pgtype.Int4
+	LevelID pgtype.Int4
+	ID      pgtype.UUID
+}
+
+func (q *Queries) UpdateUserProgress(ctx context.Context, arg UpdateUserProgressParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserProgress, arg.Xp, arg.LevelID, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.Xp,
+		&i.LevelID,
+		&i.CashAvailable,
+		&i.RefreshToken,
+		&i.RefreshTokenExpiry,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.Timezone,
+		&i.HighestLevelID,
 	)
 	return i, err
 }
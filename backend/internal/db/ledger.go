@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sqlcdb "goalify/internal/db/generated"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrInsufficientCash is returned when a debit would take a user's cash below zero
+var ErrInsufficientCash = errors.New("insufficient cash")
+
+// RecordCashTransaction is the only way cash_available changes. It applies amount to the user's
+// balance, positive for credits and negative for debits, and appends a cash transaction with the
+// resulting balance. Run it with the queries of the caller's transaction so the balance and the
+// ledger row commit together. A zero amount records nothing and returns the current balance.
+func RecordCashTransaction(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	userID uuid.UUID,
+	amount int,
+	reason sqlcdb.CashTransactionReason,
+	referenceID uuid.UUID,
+) (int, error) {
+	if amount == 0 {
+		balance, err := q.GetUserCash(ctx, UUIDToPgxUUID(userID))
+		return int(balance), err
+	}
+
+	balance, err := q.ApplyUserCashChange(ctx, sqlcdb.ApplyUserCashChangeParams{
+		Amount: int32(amount),
+		ID:     UUIDToPgxUUID(userID),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInsufficientCash
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.CreateCashTransaction(ctx, sqlcdb.CreateCashTransactionParams{
+		UserID:       UUIDToPgxUUID(userID),
+		Amount:       int32(amount),
+		Reason:       reason,
+		ReferenceID:  pgtype.UUID{Bytes: referenceID, Valid: referenceID != uuid.Nil},
+		BalanceAfter: balance,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(balance), nil
+}
@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE UNIQUE INDEX idx_user_chests_user_id_chest_id ON user_chests(user_id, chest_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_chests_user_id_chest_id;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- admins are granted by setting this flag directly in the database
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE chests ADD COLUMN drop_table_version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE chest_item_drop_rates
+    ADD CONSTRAINT chest_item_drop_rates_drop_rate_check CHECK (drop_rate > 0 AND drop_rate <= 1);
+CREATE UNIQUE INDEX idx_drop_rates_chest_id_item_id ON chest_item_drop_rates(chest_id, item_id);
+
+-- every version of a chest's drop table, including the live one, so past openings can be explained
+CREATE TABLE chest_drop_table_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chest_id UUID NOT NULL REFERENCES chests(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    item_id UUID NOT NULL REFERENCES chest_items(id),
+    drop_rate FLOAT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX idx_drop_table_history_chest_id_version ON chest_drop_table_history(chest_id, version);
+
+INSERT INTO chest_drop_table_history (chest_id, version, item_id, drop_rate)
+SELECT chest_id, 1, item_id, drop_rate
+FROM chest_item_drop_rates
+WHERE chest_id IS NOT NULL AND item_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chest_drop_table_history;
+DROP INDEX IF EXISTS idx_drop_rates_chest_id_item_id;
+ALTER TABLE chest_item_drop_rates DROP CONSTRAINT IF EXISTS chest_item_drop_rates_drop_rate_check;
+ALTER TABLE chests DROP COLUMN drop_table_version;
+ALTER TABLE users DROP COLUMN is_admin;
+-- +goose StatementEnd
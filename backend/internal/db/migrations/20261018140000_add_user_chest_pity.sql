@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- opens since the user last received the highest rarity in the chest's drop table
+CREATE TABLE user_chest_pity (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chest_id UUID NOT NULL REFERENCES chests(id) ON DELETE CASCADE,
+    opens_since_top_drop INTEGER NOT NULL DEFAULT 0 CHECK (opens_since_top_drop >= 0),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, chest_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_chest_pity;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE item_slot AS ENUM ('head', 'body', 'weapon', 'accessory', 'background');
+
+ALTER TABLE chest_items ADD COLUMN slot item_slot NOT NULL DEFAULT 'accessory';
+
+CREATE INDEX idx_user_items_user_id_status ON user_items(user_id, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_items_user_id_status;
+ALTER TABLE chest_items DROP COLUMN slot;
+DROP TYPE IF EXISTS item_slot;
+-- +goose StatementEnd
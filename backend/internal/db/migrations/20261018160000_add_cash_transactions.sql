@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE cash_transaction_reason AS ENUM (
+    'opening_balance',
+    'level_reward',
+    'chest_purchase',
+    'item_sale',
+    'admin_grant'
+);
+
+-- append-only record of every change to users.cash_available
+CREATE TABLE cash_transactions (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount <> 0),
+    reason cash_transaction_reason NOT NULL,
+    reference_id UUID,
+    balance_after INTEGER NOT NULL CHECK (balance_after >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_cash_transactions_user_id_id ON cash_transactions(user_id, id DESC);
+
+-- rows may only disappear through the users cascade, which runs one trigger level deeper
+CREATE FUNCTION prevent_cash_transaction_changes() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND pg_trigger_depth() > 1 THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'cash_transactions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER cash_transactions_append_only
+    BEFORE UPDATE OR DELETE ON cash_transactions
+    FOR EACH ROW EXECUTE FUNCTION prevent_cash_transaction_changes();
+
+UPDATE users SET cash_available = 0 WHERE cash_available IS NULL;
+ALTER TABLE users
+    ALTER COLUMN cash_available SET NOT NULL,
+    ADD CONSTRAINT users_cash_available_check CHECK (cash_available >= 0);
+
+INSERT INTO cash_transactions (user_id, amount, reason, balance_after)
+SELECT id, cash_available, 'opening_balance', cash_available
+FROM users
+WHERE cash_available > 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_cash_available_check,
+    ALTER COLUMN cash_available DROP NOT NULL;
+DROP TABLE IF EXISTS cash_transactions;
+DROP FUNCTION IF EXISTS prevent_cash_transaction_changes;
+DROP TYPE IF EXISTS cash_transaction_reason;
+-- +goose StatementEnd
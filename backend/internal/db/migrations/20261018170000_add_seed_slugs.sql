@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- stable natural keys for rows owned by the seeders, NULL for rows created through the API
+ALTER TABLE chests ADD COLUMN slug TEXT UNIQUE;
+ALTER TABLE chest_items ADD COLUMN slug TEXT UNIQUE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chest_items DROP COLUMN slug;
+ALTER TABLE chests DROP COLUMN slug;
+-- +goose StatementEnd
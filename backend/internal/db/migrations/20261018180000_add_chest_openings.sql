@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- provably fair seed pairs. server_seed stays secret until the pair is revealed, only its hash is
+-- shown before then. each user has at most one unrevealed pair, which their openings roll with.
+CREATE TABLE fairness_seeds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    server_seed TEXT NOT NULL,
+    server_seed_hash TEXT NOT NULL,
+    client_seed TEXT NOT NULL,
+    next_nonce INTEGER NOT NULL DEFAULT 0,
+    revealed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX idx_fairness_seeds_active_user_id ON fairness_seeds(user_id)
+    WHERE revealed_at IS NULL;
+
+-- every roll, with what is needed to recompute it once its seed pair is revealed
+CREATE TABLE chest_openings (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chest_id UUID NOT NULL REFERENCES chests(id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES chest_items(id),
+    user_item_id UUID REFERENCES user_items(id) ON DELETE SET NULL,
+    drop_table_version INTEGER NOT NULL,
+    fairness_seed_id UUID NOT NULL REFERENCES fairness_seeds(id) ON DELETE CASCADE,
+    nonce INTEGER NOT NULL,
+    roll DOUBLE PRECISION NOT NULL,
+    top_drop_chance DOUBLE PRECISION,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (fairness_seed_id, nonce)
+);
+CREATE INDEX idx_chest_openings_user_id_id ON chest_openings(user_id, id DESC);
+
+-- openings archive the version they roll with, so concurrent openings may archive it together
+CREATE UNIQUE INDEX idx_drop_table_history_chest_id_version_item_id
+    ON chest_drop_table_history(chest_id, version, item_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_drop_table_history_chest_id_version_item_id;
+DROP TABLE IF EXISTS chest_openings;
+DROP TABLE IF EXISTS fairness_seeds;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE gift_status AS ENUM ('pending', 'accepted', 'declined', 'expired');
+
+-- chests or a single item sent from one user to another. the gifted chests are taken from the
+-- sender, and a gifted item has no owner, until the gift is accepted, declined or expires.
+CREATE TABLE gifts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chest_id UUID REFERENCES chests(id) ON DELETE CASCADE,
+    quantity INTEGER,
+    user_item_id UUID REFERENCES user_items(id) ON DELETE CASCADE,
+    status gift_status NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (sender_id <> recipient_id),
+    CHECK (
+        (chest_id IS NOT NULL AND quantity > 0 AND user_item_id IS NULL)
+        OR (chest_id IS NULL AND quantity IS NULL AND user_item_id IS NOT NULL)
+    )
+);
+CREATE INDEX idx_gifts_recipient_id_status ON gifts(recipient_id, status);
+CREATE INDEX idx_gifts_sender_id_status ON gifts(sender_id, status);
+CREATE INDEX idx_gifts_pending_expires_at ON gifts(expires_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS gifts;
+DROP TYPE IF EXISTS gift_status;
+-- +goose StatementEnd
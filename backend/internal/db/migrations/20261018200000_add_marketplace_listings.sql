@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE cash_transaction_reason ADD VALUE 'marketplace_purchase';
+ALTER TYPE cash_transaction_reason ADD VALUE 'marketplace_sale';
+
+CREATE TYPE listing_status AS ENUM ('active', 'sold', 'cancelled');
+
+-- user items offered for cash. an active listing holds its item without an owner, so it cannot be
+-- equipped, sold or gifted until the listing is bought or cancelled.
+CREATE TABLE listings (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_item_id UUID NOT NULL REFERENCES user_items(id) ON DELETE CASCADE,
+    price INTEGER NOT NULL CHECK (price > 0),
+    status listing_status NOT NULL DEFAULT 'active',
+    buyer_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    fee INTEGER CHECK (fee >= 0 AND fee <= price),
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX idx_listings_active_user_item_id ON listings(user_item_id)
+    WHERE status = 'active';
+CREATE INDEX idx_listings_active_id ON listings(id DESC) WHERE status = 'active';
+CREATE INDEX idx_listings_seller_id_id ON listings(seller_id, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- enum values cannot be dropped, the marketplace reasons stay on cash_transaction_reason
+DROP TABLE IF EXISTS listings;
+DROP TYPE IF EXISTS listing_status;
+-- +goose StatementEnd
@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE cash_transaction_reason ADD VALUE 'collection_bonus';
+
+-- the first time each user came to own each catalog item. rows outlive the user_items that
+-- created them, so selling or gifting an item away keeps it discovered.
+CREATE TABLE user_item_discoveries (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES chest_items(id) ON DELETE CASCADE,
+    first_obtained_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, item_id)
+);
+
+-- every way an item reaches an inventory inserts a user_items row or hands one to a new owner
+CREATE FUNCTION record_item_discovery() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.user_id IS NOT NULL AND NEW.item_id IS NOT NULL THEN
+        INSERT INTO user_item_discoveries (user_id, item_id)
+        VALUES (NEW.user_id, NEW.item_id)
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_items_record_discovery
+    AFTER INSERT OR UPDATE OF user_id ON user_items
+    FOR EACH ROW EXECUTE FUNCTION record_item_discovery();
+
+INSERT INTO user_item_discoveries (user_id, item_id, first_obtained_at)
+SELECT user_id, item_id, MIN(COALESCE(created_at, NOW()))
+FROM user_items
+WHERE user_id IS NOT NULL AND item_id IS NOT NULL
+GROUP BY user_id, item_id;
+
+-- completion bonuses already paid, at most one per user and chest
+CREATE TABLE collection_rewards (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chest_id UUID NOT NULL REFERENCES chests(id) ON DELETE CASCADE,
+    cash INTEGER NOT NULL CHECK (cash >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, chest_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- enum values cannot be dropped, collection_bonus stays on cash_transaction_reason
+DROP TABLE IF EXISTS collection_rewards;
+DROP TRIGGER IF EXISTS user_items_record_discovery ON user_items;
+DROP FUNCTION IF EXISTS record_item_discovery;
+DROP TABLE IF EXISTS user_item_discoveries;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- recipes are owned by the seeder. each turns input_count items of input_rarity into one item of
+-- output_rarity rolled from chest_id's drop table.
+CREATE TABLE crafting_recipes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slug TEXT NOT NULL UNIQUE,
+    chest_id UUID NOT NULL REFERENCES chests(id) ON DELETE CASCADE,
+    input_rarity item_type NOT NULL,
+    input_count INTEGER NOT NULL CHECK (input_count > 0),
+    output_rarity item_type NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- every craft, with what is needed to recompute the roll that picked its output, as for openings
+CREATE TABLE crafts (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id UUID NOT NULL REFERENCES crafting_recipes(id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES chest_items(id),
+    user_item_id UUID REFERENCES user_items(id) ON DELETE SET NULL,
+    drop_table_version INTEGER NOT NULL,
+    fairness_seed_id UUID NOT NULL REFERENCES fairness_seeds(id) ON DELETE CASCADE,
+    nonce INTEGER NOT NULL,
+    roll DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (fairness_seed_id, nonce)
+);
+CREATE INDEX idx_crafts_user_id_id ON crafts(user_id, id DESC);
+
+-- the catalog items a craft consumed. the user_items themselves are deleted.
+CREATE TABLE craft_inputs (
+    craft_id BIGINT NOT NULL REFERENCES crafts(id) ON DELETE CASCADE,
+    user_item_id UUID NOT NULL,
+    item_id UUID NOT NULL REFERENCES chest_items(id),
+    PRIMARY KEY (craft_id, user_item_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS craft_inputs;
+DROP TABLE IF EXISTS crafts;
+DROP TABLE IF EXISTS crafting_recipes;
+-- +goose StatementEnd
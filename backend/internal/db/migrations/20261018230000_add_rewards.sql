@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE cash_transaction_reason ADD VALUE 'reward_redemption';
+
+-- real-life rewards users set for themselves and buy with cash. one-time rewards can be redeemed
+-- once, repeatable ones again after cooldown_seconds.
+CREATE TABLE rewards (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    cost INTEGER NOT NULL CHECK (cost > 0),
+    repeatable BOOLEAN NOT NULL DEFAULT false,
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0 CHECK (cooldown_seconds >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (repeatable OR cooldown_seconds = 0)
+);
+CREATE INDEX idx_rewards_user_id ON rewards(user_id, created_at);
+
+-- every redemption, keeping the title and cost paid so history survives the reward being edited
+-- or deleted
+CREATE TABLE reward_redemptions (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reward_id UUID REFERENCES rewards(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    cost INTEGER NOT NULL CHECK (cost > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_reward_redemptions_user_id_id ON reward_redemptions(user_id, id DESC);
+CREATE INDEX idx_reward_redemptions_reward_id ON reward_redemptions(reward_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- enum values cannot be dropped, reward_redemption stays on cash_transaction_reason
+DROP TABLE IF EXISTS reward_redemptions;
+DROP TABLE IF EXISTS rewards;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE item_effect AS ENUM ('xp_multiplier', 'streak_freeze');
+
+-- consumable items carry an effect that is used up on consumption. effect_value is the xp
+-- multiplier or the number of missed days a freeze covers, and the effect lasts
+-- effect_duration_seconds from when it is consumed.
+ALTER TABLE chest_items
+    ADD COLUMN effect item_effect,
+    ADD COLUMN effect_value INTEGER,
+    ADD COLUMN effect_duration_seconds INTEGER,
+    ADD CONSTRAINT chest_items_effect_check CHECK (
+        (effect IS NULL AND effect_value IS NULL AND effect_duration_seconds IS NULL)
+        OR (effect IS NOT NULL AND effect_value > 0 AND effect_duration_seconds > 0)
+    );
+
+CREATE TABLE active_effects (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    effect item_effect NOT NULL,
+    value INTEGER NOT NULL CHECK (value > 0),
+    source_item_id UUID REFERENCES chest_items(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_active_effects_user_id ON active_effects(user_id, effect, expires_at);
+CREATE INDEX idx_active_effects_expires_at ON active_effects(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS active_effects;
+ALTER TABLE chest_items
+    DROP CONSTRAINT chest_items_effect_check,
+    DROP COLUMN effect,
+    DROP COLUMN effect_value,
+    DROP COLUMN effect_duration_seconds;
+DROP TYPE IF EXISTS item_effect;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- an IANA zone name, deciding where the user's calendar days start
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- one row per daily reward claim. claim_date is the user's local date when they claimed, so the
+-- unique constraint is what rules out a second claim on the same day. streak counts consecutive
+-- claim days up to and including this one.
+CREATE TABLE daily_claims (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    claim_date DATE NOT NULL,
+    streak INTEGER NOT NULL CHECK (streak > 0),
+    chest_id UUID REFERENCES chests(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, claim_date)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS daily_claims;
+ALTER TABLE users DROP COLUMN timezone;
+-- +goose StatementEnd
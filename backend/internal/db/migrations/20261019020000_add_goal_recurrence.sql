@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a recurring goal repeats on the dates its recurrence rule (a canonical RRULE) gives, counted
+-- from recurrence_start. occurrence_date is the occurrence the goal's status applies to, and the
+-- goal reopens once the user's local date reaches next_occurrence_date.
+ALTER TABLE goals
+    ADD COLUMN recurrence TEXT,
+    ADD COLUMN recurrence_start DATE,
+    ADD COLUMN occurrence_date DATE,
+    ADD COLUMN next_occurrence_date DATE,
+    ADD CONSTRAINT goals_recurrence_check CHECK (
+        (recurrence IS NULL AND recurrence_start IS NULL AND occurrence_date IS NULL
+            AND next_occurrence_date IS NULL)
+        OR (recurrence IS NOT NULL AND recurrence_start IS NOT NULL AND occurrence_date IS NOT NULL)
+    );
+CREATE INDEX idx_goals_next_occurrence_date ON goals(next_occurrence_date)
+    WHERE next_occurrence_date IS NOT NULL;
+
+-- one row per completed occurrence of a recurring goal, kept when the goal reopens
+CREATE TABLE goal_completions (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    goal_id UUID NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    occurrence_date DATE NOT NULL,
+    completed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (goal_id, occurrence_date)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS goal_completions;
+ALTER TABLE goals
+    DROP CONSTRAINT goals_recurrence_check,
+    DROP COLUMN recurrence,
+    DROP COLUMN recurrence_start,
+    DROP COLUMN occurrence_date,
+    DROP COLUMN next_occurrence_date;
+-- +goose StatementEnd
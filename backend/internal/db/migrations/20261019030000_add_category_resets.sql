@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE category_reset_policy AS ENUM ('none', 'daily', 'weekly');
+
+-- reset_time is a wall clock time in the user's timezone, and reset_weekday counts from Sunday as
+-- 0. reset_through is the last boundary whose reset has run, or when the schedule was set, so a
+-- new schedule first fires at its next boundary rather than straight away.
+ALTER TABLE goal_categories
+    ADD COLUMN reset_policy category_reset_policy NOT NULL DEFAULT 'none',
+    ADD COLUMN reset_time TIME NOT NULL DEFAULT '00:00',
+    ADD COLUMN reset_weekday SMALLINT CHECK (reset_weekday BETWEEN 0 AND 6),
+    ADD COLUMN reset_through TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD CONSTRAINT goal_categories_reset_weekday_check
+        CHECK ((reset_policy = 'weekly') = (reset_weekday IS NOT NULL));
+
+CREATE INDEX goal_categories_reset_policy_idx ON goal_categories (reset_policy)
+    WHERE reset_policy <> 'none';
+
+-- category_reset_boundary is the latest reset boundary at or before as_of for a schedule, with
+-- the wall clock time read in tz. It is null for the 'none' policy. Comparing instants rather
+-- than wall clock times keeps a change of timezone from skipping or repeating a reset.
+CREATE FUNCTION category_reset_boundary(
+    policy category_reset_policy,
+    reset_time TIME,
+    reset_weekday SMALLINT,
+    tz TEXT,
+    as_of TIMESTAMPTZ
+) RETURNS TIMESTAMPTZ AS $$
+    SELECT (CASE WHEN candidate > local_as_of THEN candidate - step ELSE candidate END)
+        AT TIME ZONE tz
+    FROM (
+        SELECT
+            local_as_of,
+            CASE policy
+                WHEN 'daily' THEN date_trunc('day', local_as_of) + reset_time
+                WHEN 'weekly' THEN date_trunc('day', local_as_of) + reset_time - make_interval(
+                    days => (EXTRACT(DOW FROM local_as_of)::INTEGER - reset_weekday + 7) % 7
+                )
+            END AS candidate,
+            CASE policy WHEN 'weekly' THEN INTERVAL '7 days' ELSE INTERVAL '1 day' END AS step
+        FROM (SELECT as_of AT TIME ZONE tz AS local_as_of) AS local
+    ) AS boundary
+$$ LANGUAGE SQL STABLE;
+
+-- the Daily category every user starts with was meant to reset each day
+UPDATE goal_categories SET reset_policy = 'daily' WHERE title = 'Daily';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS category_reset_boundary;
+DROP INDEX IF EXISTS goal_categories_reset_policy_idx;
+ALTER TABLE goal_categories
+    DROP CONSTRAINT goal_categories_reset_weekday_check,
+    DROP COLUMN reset_through,
+    DROP COLUMN reset_weekday,
+    DROP COLUMN reset_time,
+    DROP COLUMN reset_policy;
+DROP TYPE IF EXISTS category_reset_policy;
+-- +goose StatementEnd
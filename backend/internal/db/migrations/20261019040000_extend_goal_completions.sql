@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- goal_completions now holds every time a goal was completed, not only recurring occurrences.
+-- category_id is the goal's category when it was completed and xp_granted what the completion
+-- earned. un-completing a goal sets uncompleted_at rather than deleting the row, and resets leave
+-- completions alone, so the history stays whole.
+ALTER TABLE goal_completions
+    DROP CONSTRAINT goal_completions_goal_id_occurrence_date_key,
+    ALTER COLUMN occurrence_date DROP NOT NULL,
+    ADD COLUMN category_id UUID REFERENCES goal_categories(id) ON DELETE SET NULL,
+    ADD COLUMN xp_granted INTEGER NOT NULL DEFAULT 0 CHECK (xp_granted >= 0),
+    ADD COLUMN uncompleted_at TIMESTAMP;
+
+UPDATE goal_completions
+SET category_id = goals.category_id
+FROM goals
+WHERE goals.id = goal_completions.goal_id;
+
+CREATE INDEX idx_goal_completions_goal_id ON goal_completions(goal_id, id);
+CREATE INDEX idx_goal_completions_user_id_completed_at
+    ON goal_completions(user_id, completed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_goal_completions_user_id_completed_at;
+DROP INDEX IF EXISTS idx_goal_completions_goal_id;
+-- only standing completions of recurring goals were kept before, one per occurrence
+DELETE FROM goal_completions WHERE occurrence_date IS NULL OR uncompleted_at IS NOT NULL;
+DELETE FROM goal_completions AS later
+USING goal_completions AS earlier
+WHERE later.goal_id = earlier.goal_id
+    AND later.occurrence_date = earlier.occurrence_date
+    AND later.id > earlier.id;
+ALTER TABLE goal_completions
+    DROP COLUMN uncompleted_at,
+    DROP COLUMN xp_granted,
+    DROP COLUMN category_id,
+    ALTER COLUMN occurrence_date SET NOT NULL,
+    ADD CONSTRAINT goal_completions_goal_id_occurrence_date_key UNIQUE (goal_id, occurrence_date);
+-- +goose StatementEnd
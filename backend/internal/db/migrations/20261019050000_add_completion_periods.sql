@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- period_date is the period a completion counts towards: the occurrence for recurring goals and
+-- the user's local date otherwise. only one standing completion of a goal earns XP per period.
+ALTER TABLE goal_completions ADD COLUMN period_date DATE;
+
+UPDATE goal_completions
+SET period_date = COALESCE(
+    goal_completions.occurrence_date,
+    (goal_completions.completed_at AT TIME ZONE 'UTC' AT TIME ZONE users.timezone)::DATE
+)
+FROM users
+WHERE users.id = goal_completions.user_id;
+
+ALTER TABLE goal_completions ALTER COLUMN period_date SET NOT NULL;
+CREATE INDEX idx_goal_completions_goal_id_period_date
+    ON goal_completions(goal_id, period_date);
+
+-- the highest level the user has reached. a level's cash reward is paid only when passing it, so
+-- dropping a level and climbing back pays nothing
+ALTER TABLE users ADD COLUMN highest_level_id INTEGER NOT NULL DEFAULT 1;
+UPDATE users SET highest_level_id = level_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS highest_level_id;
+DROP INDEX IF EXISTS idx_goal_completions_goal_id_period_date;
+ALTER TABLE goal_completions DROP COLUMN IF EXISTS period_date;
+-- +goose StatementEnd
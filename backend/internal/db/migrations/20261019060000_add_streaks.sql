@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a streak counts consecutive periods with a standing completion: occurrences for recurring goals
+-- and days for other goals in daily categories and for daily categories themselves.
+-- last_completed_date is the period that last extended it, and a streak breaks back to 0 once a
+-- period passes without a completion that the user's active streak freeze doesn't cover.
+-- streaks start from nothing, completions made before now don't count towards them.
+ALTER TABLE goals
+    ADD COLUMN current_streak INTEGER NOT NULL DEFAULT 0 CHECK (current_streak >= 0),
+    ADD COLUMN longest_streak INTEGER NOT NULL DEFAULT 0 CHECK (longest_streak >= 0),
+    ADD COLUMN last_completed_date DATE;
+
+ALTER TABLE goal_categories
+    ADD COLUMN current_streak INTEGER NOT NULL DEFAULT 0 CHECK (current_streak >= 0),
+    ADD COLUMN longest_streak INTEGER NOT NULL DEFAULT 0 CHECK (longest_streak >= 0),
+    ADD COLUMN last_completed_date DATE;
+
+-- the goal's and category's streaks as the completion left them, so un-completing it can put
+-- them back
+ALTER TABLE goal_completions
+    ADD COLUMN goal_streak INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN category_streak INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_goals_current_streak ON goals(last_completed_date) WHERE current_streak > 0;
+CREATE INDEX idx_goal_categories_current_streak ON goal_categories(last_completed_date)
+    WHERE current_streak > 0;
+CREATE INDEX idx_goal_completions_category_id_period_date
+    ON goal_completions(category_id, period_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_goal_completions_category_id_period_date;
+DROP INDEX IF EXISTS idx_goal_categories_current_streak;
+DROP INDEX IF EXISTS idx_goals_current_streak;
+ALTER TABLE goal_completions
+    DROP COLUMN IF EXISTS category_streak,
+    DROP COLUMN IF EXISTS goal_streak;
+ALTER TABLE goal_categories
+    DROP COLUMN IF EXISTS last_completed_date,
+    DROP COLUMN IF EXISTS longest_streak,
+    DROP COLUMN IF EXISTS current_streak;
+ALTER TABLE goals
+    DROP COLUMN IF EXISTS last_completed_date,
+    DROP COLUMN IF EXISTS longest_streak,
+    DROP COLUMN IF EXISTS current_streak;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the days a streak freeze has covered for a user. a missed day is frozen once, using up one unit
+-- of an active streak_freeze effect, and then bridges that day in every one of the user's streaks.
+CREATE TABLE streak_freeze_days (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, day)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS streak_freeze_days;
+-- +goose StatementEnd
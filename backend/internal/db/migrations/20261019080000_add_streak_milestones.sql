@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the highest milestone announced for the current streak, so un-completing and completing the
+-- period that reached it again doesn't announce it twice. it starts over with the streak.
+ALTER TABLE goals ADD COLUMN streak_milestone INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE goal_categories ADD COLUMN streak_milestone INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE goal_categories DROP COLUMN IF EXISTS streak_milestone;
+ALTER TABLE goals DROP COLUMN IF EXISTS streak_milestone;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- name: CreateActiveEffect :one
+INSERT INTO active_effects (user_id, effect, value, source_item_id, expires_at)
+VALUES ($1, $2, $3, $4, NOW() + sqlc.arg('duration_seconds')::INTEGER * INTERVAL '1 second')
+RETURNING *;
+
+-- name: GetActiveEffectsByUserId :many
+SELECT * FROM active_effects
+WHERE user_id = $1 AND expires_at > NOW()
+ORDER BY expires_at, id;
+
+-- name: GetXpMultiplierByUserId :one
+-- multipliers don't stack, the strongest active one applies
+SELECT COALESCE(MAX(value), 1)::INTEGER AS multiplier
+FROM active_effects
+WHERE user_id = $1 AND effect = 'xp_multiplier' AND expires_at > NOW();
+
+-- name: LockStreakFreezesByUserId :many
+-- the user's active streak freezes in the order they're used up, soonest to expire first
+SELECT * FROM active_effects
+WHERE user_id = $1 AND effect = 'streak_freeze' AND expires_at > NOW()
+ORDER BY expires_at, id
+FOR UPDATE;
+
+-- name: SetActiveEffectValue :exec
+UPDATE active_effects SET value = $2 WHERE id = $1;
+
+-- name: DeleteActiveEffectById :exec
+DELETE FROM active_effects WHERE id = $1;
+
+-- name: DeleteExpiredActiveEffects :execrows
+DELETE FROM active_effects WHERE expires_at <= NOW();
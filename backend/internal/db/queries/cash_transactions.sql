@@ -0,0 +1,31 @@
+-- name: ApplyUserCashChange :one
+-- credits a positive amount or debits a negative one, unless that would take the balance below zero
+UPDATE users
+    SET cash_available = cash_available + sqlc.arg('amount')::INTEGER,
+    updated_at = NOW()
+    WHERE id = sqlc.arg('id') AND cash_available + sqlc.arg('amount')::INTEGER >= 0
+    RETURNING cash_available;
+
+-- name: GetUserCash :one
+SELECT cash_available FROM users WHERE id = $1;
+
+-- name: CreateCashTransaction :one
+INSERT INTO cash_transactions (user_id, amount, reason, reference_id, balance_after)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetCashTransactionsByUserId :many
+SELECT * FROM cash_transactions
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('cursor')::BIGINT IS NULL OR id < sqlc.narg('cursor')::BIGINT)
+ORDER BY id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: GetCashBalanceMismatches :many
+SELECT users.id AS user_id,
+       users.cash_available,
+       COALESCE(SUM(cash_transactions.amount), 0)::INTEGER AS ledger_balance
+FROM users
+LEFT JOIN cash_transactions ON cash_transactions.user_id = users.id
+GROUP BY users.id, users.cash_available
+HAVING users.cash_available <> COALESCE(SUM(cash_transactions.amount), 0);
@@ -0,0 +1,54 @@
+-- Fairness Seed Operations
+-- name: CreateFairnessSeedIfMissing :exec
+INSERT INTO fairness_seeds (user_id, server_seed, server_seed_hash, client_seed)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) WHERE revealed_at IS NULL DO NOTHING;
+
+-- name: CreateFairnessSeed :one
+INSERT INTO fairness_seeds (user_id, server_seed, server_seed_hash, client_seed)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetActiveFairnessSeed :one
+SELECT * FROM fairness_seeds WHERE user_id = $1 AND revealed_at IS NULL;
+
+-- name: LockActiveFairnessSeed :one
+SELECT * FROM fairness_seeds WHERE user_id = $1 AND revealed_at IS NULL FOR UPDATE;
+
+-- name: IncrementFairnessSeedNonce :one
+UPDATE fairness_seeds SET next_nonce = next_nonce + 1 WHERE id = $1 RETURNING *;
+
+-- name: RevealFairnessSeed :one
+UPDATE fairness_seeds SET revealed_at = NOW() WHERE id = $1 RETURNING *;
+
+-- Chest Opening Operations
+-- name: CreateChestOpening :one
+INSERT INTO chest_openings (
+    user_id,
+    chest_id,
+    item_id,
+    user_item_id,
+    drop_table_version,
+    fairness_seed_id,
+    nonce,
+    roll,
+    top_drop_chance
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetChestOpeningsByUserId :many
+SELECT sqlc.embed(chest_openings), sqlc.embed(fairness_seeds)
+FROM chest_openings
+JOIN fairness_seeds ON fairness_seeds.id = chest_openings.fairness_seed_id
+WHERE chest_openings.user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('cursor')::BIGINT IS NULL OR chest_openings.id < sqlc.narg('cursor')::BIGINT)
+ORDER BY chest_openings.id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: GetDropTableHistoryWithRarityByChestIds :many
+SELECT h.chest_id, h.version, h.item_id, h.drop_rate, ci.rarity
+FROM chest_drop_table_history h
+JOIN chest_items ci ON ci.id = h.item_id
+WHERE h.chest_id = ANY(sqlc.arg('chest_ids')::UUID[])
+ORDER BY h.chest_id, h.version, h.item_id;
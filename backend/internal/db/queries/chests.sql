@@ -7,6 +7,13 @@ RETURNING *;
 -- name: GetChestById :one
 SELECT * FROM chests WHERE id = $1;
 
+-- name: GetChestByIdForShare :one
+-- blocks drop table replacements until the transaction ends, so the rates and version read match
+SELECT * FROM chests WHERE id = $1 FOR SHARE;
+
+-- name: GetChestsBySlugs :many
+SELECT * FROM chests WHERE slug = ANY(sqlc.arg('slugs')::TEXT[]);
+
 -- name: GetAllChests :many
 SELECT * FROM chests ORDER BY price ASC;
 
@@ -21,10 +28,45 @@ RETURNING *;
 -- name: DeleteChestById :exec
 DELETE FROM chests WHERE id = $1;
 
+-- name: UpsertChestBySlug :one
+INSERT INTO chests (slug, type, description, price)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (slug) DO UPDATE SET
+    type = EXCLUDED.type,
+    description = EXCLUDED.description,
+    price = EXCLUDED.price,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: IncrementChestDropTableVersion :one
+UPDATE chests
+SET drop_table_version = drop_table_version + 1,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
 -- Chest Item Operations
 -- name: CreateChestItem :one
-INSERT INTO chest_items (image_url, title, rarity, price)
-VALUES ($1, $2, $3, $4)
+INSERT INTO chest_items (image_url, title, rarity, price, slot)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpsertChestItemBySlug :one
+-- items seeded without an image_url keep any art uploaded for them
+INSERT INTO chest_items (
+    slug, image_url, title, rarity, price, slot, effect, effect_value, effect_duration_seconds
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (slug) DO UPDATE SET
+    image_url = coalesce(EXCLUDED.image_url, chest_items.image_url),
+    title = EXCLUDED.title,
+    rarity = EXCLUDED.rarity,
+    price = EXCLUDED.price,
+    slot = EXCLUDED.slot,
+    effect = EXCLUDED.effect,
+    effect_value = EXCLUDED.effect_value,
+    effect_duration_seconds = EXCLUDED.effect_duration_seconds,
+    updated_at = NOW()
 RETURNING *;
 
 -- name: GetChestItemById :one
@@ -42,6 +84,12 @@ SET image_url = coalesce(sqlc.narg('image_url'), image_url),
 WHERE id = sqlc.arg('id')
 RETURNING *;
 
+-- name: GetChestItemsByIds :many
+SELECT * FROM chest_items WHERE id = ANY(sqlc.arg('ids')::UUID[]);
+
+-- name: GetChestItemsBySlugs :many
+SELECT * FROM chest_items WHERE slug = ANY(sqlc.arg('slugs')::TEXT[]);
+
 -- name: DeleteChestItemById :exec
 DELETE FROM chest_items WHERE id = $1;
 
@@ -54,6 +102,12 @@ RETURNING *;
 -- name: GetDropRatesByChestId :many
 SELECT * FROM chest_item_drop_rates WHERE chest_id = $1;
 
+-- name: GetDropTableByChestId :many
+SELECT dr.*, ci.rarity
+FROM chest_item_drop_rates dr
+JOIN chest_items ci ON ci.id = dr.item_id
+WHERE dr.chest_id = $1;
+
 -- name: GetDropRatesByItemId :many
 SELECT * FROM chest_item_drop_rates WHERE item_id = $1;
 
@@ -66,6 +120,31 @@ RETURNING *;
 -- name: DeleteDropRate :exec
 DELETE FROM chest_item_drop_rates WHERE id = $1;
 
+-- name: DeleteDropRatesByChestId :exec
+DELETE FROM chest_item_drop_rates WHERE chest_id = $1;
+
+-- Drop Table History Operations
+-- name: CreateDropTableHistoryEntry :exec
+INSERT INTO chest_drop_table_history (chest_id, version, item_id, drop_rate, created_by)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ArchiveDropTable :exec
+-- records the chest's live drop table as the given version unless that version is already recorded
+INSERT INTO chest_drop_table_history (chest_id, version, item_id, drop_rate)
+SELECT dr.chest_id, sqlc.arg('version')::INTEGER, dr.item_id, dr.drop_rate
+FROM chest_item_drop_rates dr
+WHERE dr.chest_id = sqlc.arg('chest_id')
+  AND NOT EXISTS (
+    SELECT 1 FROM chest_drop_table_history h
+    WHERE h.chest_id = sqlc.arg('chest_id') AND h.version = sqlc.arg('version')::INTEGER
+  )
+ON CONFLICT (chest_id, version, item_id) DO NOTHING;
+
+-- name: GetDropTableHistoryByChestId :many
+SELECT * FROM chest_drop_table_history
+WHERE chest_id = $1
+ORDER BY version DESC, drop_rate DESC;
+
 -- User Chest Operations
 -- name: CreateUserChest :one
 INSERT INTO user_chests (user_id, chest_id, quantity_owned)
@@ -84,6 +163,28 @@ SET quantity_owned = $2
 WHERE user_id = $1 AND chest_id = $3
 RETURNING *;
 
+-- name: IncrementUserChestQuantity :one
+INSERT INTO user_chests (user_id, chest_id, quantity_owned)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, chest_id) DO UPDATE SET
+    quantity_owned = user_chests.quantity_owned + EXCLUDED.quantity_owned,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DecrementUserChestQuantity :one
+UPDATE user_chests
+SET quantity_owned = quantity_owned - 1,
+    updated_at = NOW()
+WHERE user_id = $1 AND chest_id = $2 AND quantity_owned > 0
+RETURNING *;
+
+-- name: TakeUserChests :one
+UPDATE user_chests
+SET quantity_owned = quantity_owned - sqlc.arg('quantity'),
+    updated_at = NOW()
+WHERE user_id = $1 AND chest_id = $2 AND quantity_owned >= sqlc.arg('quantity')
+RETURNING *;
+
 -- name: DeleteUserChest :exec
 DELETE FROM user_chests WHERE user_id = $1 AND chest_id = $2;
 
@@ -101,9 +202,92 @@ SELECT * FROM user_items WHERE id = $1;
 
 -- name: UpdateUserItemStatus :one
 UPDATE user_items
-SET status = $2
+SET status = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserItemsWithDetails :many
+SELECT sqlc.embed(user_items), sqlc.embed(chest_items)
+FROM user_items
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE user_items.user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('rarity')::item_type IS NULL OR chest_items.rarity = sqlc.narg('rarity'))
+  AND (sqlc.narg('status')::item_status IS NULL OR user_items.status = sqlc.narg('status'))
+ORDER BY user_items.created_at DESC;
+
+-- name: GetUserItemWithDetails :one
+SELECT sqlc.embed(user_items), sqlc.embed(chest_items)
+FROM user_items
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE user_items.id = $1 AND user_items.user_id = $2;
+
+-- name: GetEquippedChestItemsByUserId :many
+SELECT chest_items.*
+FROM user_items
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE user_items.user_id = $1 AND user_items.status = 'equipped'
+ORDER BY chest_items.slot;
+
+-- name: UnequipUserItemsInSlot :many
+UPDATE user_items
+SET status = 'not_equipped',
+    updated_at = NOW()
+FROM chest_items
+WHERE chest_items.id = user_items.item_id
+  AND user_items.user_id = $1
+  AND chest_items.slot = $2
+  AND user_items.status = 'equipped'
+RETURNING user_items.*;
+
+-- name: SetUserItemOwner :one
+-- a null user_id holds the item in escrow, out of every inventory
+UPDATE user_items
+SET user_id = $2,
+    updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
 -- name: DeleteUserItem :exec
-DELETE FROM user_items WHERE id = $1;
\ No newline at end of file
+DELETE FROM user_items WHERE id = $1;
+
+-- name: DeleteUserItemsByIds :exec
+DELETE FROM user_items WHERE id = ANY(sqlc.arg('ids')::UUID[]);
+
+-- name: GetSellableDuplicateUserItems :many
+-- every unequipped copy of an item of the rarity beyond the one the user keeps, which is the
+-- equipped copy if there is one and the oldest otherwise
+WITH ranked AS (
+    SELECT ui.id,
+           ROW_NUMBER() OVER (
+               PARTITION BY ui.item_id
+               ORDER BY ui.status = 'equipped' DESC, ui.created_at, ui.id
+           ) AS copy_number
+    FROM user_items ui
+    JOIN chest_items ci ON ci.id = ui.item_id
+    WHERE ui.user_id = sqlc.arg('user_id') AND ci.rarity = sqlc.arg('rarity')
+)
+SELECT sqlc.embed(user_items), sqlc.embed(chest_items)
+FROM user_items
+JOIN chest_items ON chest_items.id = user_items.item_id
+JOIN ranked ON ranked.id = user_items.id
+WHERE ranked.copy_number > 1
+  AND user_items.status = 'not_equipped'
+  AND chest_items.price IS NOT NULL;
+
+-- Pity Operations
+-- name: GetUserChestPity :one
+SELECT * FROM user_chest_pity WHERE user_id = $1 AND chest_id = $2;
+
+-- name: LockUserChestPity :one
+INSERT INTO user_chest_pity (user_id, chest_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, chest_id) DO UPDATE SET user_id = EXCLUDED.user_id
+RETURNING *;
+
+-- name: UpdateUserChestPity :one
+UPDATE user_chest_pity
+SET opens_since_top_drop = $3,
+    updated_at = NOW()
+WHERE user_id = $1 AND chest_id = $2
+RETURNING *;
@@ -0,0 +1,45 @@
+-- name: GetCollectionEntries :many
+-- every collectible catalog item, consumables aside, with when the user first obtained it and how
+-- many copies they hold now
+SELECT sqlc.embed(chest_items),
+       user_item_discoveries.first_obtained_at,
+       COUNT(user_items.id) AS copies_owned
+FROM chest_items
+LEFT JOIN user_item_discoveries
+    ON user_item_discoveries.item_id = chest_items.id
+   AND user_item_discoveries.user_id = sqlc.arg('user_id')
+LEFT JOIN user_items
+    ON user_items.item_id = chest_items.id
+   AND user_items.user_id = sqlc.arg('user_id')
+WHERE chest_items.effect IS NULL
+GROUP BY chest_items.id, user_item_discoveries.first_obtained_at
+ORDER BY chest_items.rarity DESC, chest_items.title;
+
+-- name: GetChestCollections :many
+-- how many of each chest's current drop table items the user has discovered, and whether its
+-- completion bonus has been paid. consumables drop but aren't collected, so they don't count.
+SELECT chests.id,
+       chests.type,
+       COUNT(chest_item_drop_rates.item_id) AS total,
+       COUNT(user_item_discoveries.item_id) AS discovered,
+       (collection_rewards.chest_id IS NOT NULL)::BOOLEAN AS bonus_claimed
+FROM chests
+JOIN chest_item_drop_rates ON chest_item_drop_rates.chest_id = chests.id
+JOIN chest_items
+    ON chest_items.id = chest_item_drop_rates.item_id
+   AND chest_items.effect IS NULL
+LEFT JOIN user_item_discoveries
+    ON user_item_discoveries.item_id = chest_item_drop_rates.item_id
+   AND user_item_discoveries.user_id = sqlc.arg('user_id')
+LEFT JOIN collection_rewards
+    ON collection_rewards.chest_id = chests.id
+   AND collection_rewards.user_id = sqlc.arg('user_id')
+WHERE sqlc.narg('chest_id')::UUID IS NULL OR chests.id = sqlc.narg('chest_id')
+GROUP BY chests.id, collection_rewards.chest_id
+ORDER BY chests.price, chests.id;
+
+-- name: CreateCollectionReward :one
+INSERT INTO collection_rewards (user_id, chest_id, cash)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, chest_id) DO NOTHING
+RETURNING *;
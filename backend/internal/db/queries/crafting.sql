@@ -0,0 +1,52 @@
+-- Crafting Recipe Operations
+-- name: UpsertCraftingRecipeBySlug :one
+INSERT INTO crafting_recipes (slug, chest_id, input_rarity, input_count, output_rarity)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (slug) DO UPDATE
+SET chest_id = EXCLUDED.chest_id,
+    input_rarity = EXCLUDED.input_rarity,
+    input_count = EXCLUDED.input_count,
+    output_rarity = EXCLUDED.output_rarity,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetCraftingRecipes :many
+SELECT * FROM crafting_recipes ORDER BY input_rarity, chest_id, slug;
+
+-- name: GetCraftingRecipeById :one
+SELECT * FROM crafting_recipes WHERE id = $1;
+
+-- name: GetCraftingOutputsByChestId :many
+-- the chest's drop table items of a recipe's output rarity. consumables only come from openings,
+-- crafts never produce them.
+SELECT dr.*, ci.rarity
+FROM chest_item_drop_rates dr
+JOIN chest_items ci ON ci.id = dr.item_id
+WHERE dr.chest_id = $1 AND ci.rarity = $2 AND ci.effect IS NULL;
+
+-- Craft Operations
+-- name: LockUserItemsWithDetails :many
+SELECT sqlc.embed(user_items), sqlc.embed(chest_items)
+FROM user_items
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE user_items.id = ANY(sqlc.arg('ids')::UUID[])
+  AND user_items.user_id = sqlc.arg('user_id')
+FOR UPDATE OF user_items;
+
+-- name: CreateCraft :one
+INSERT INTO crafts (
+    user_id,
+    recipe_id,
+    item_id,
+    user_item_id,
+    drop_table_version,
+    fairness_seed_id,
+    nonce,
+    roll
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: CreateCraftInput :exec
+INSERT INTO craft_inputs (craft_id, user_item_id, item_id)
+VALUES ($1, $2, $3);
@@ -0,0 +1,16 @@
+-- name: GetUserLocalDate :one
+-- the current date where the user is
+SELECT (NOW() AT TIME ZONE timezone)::DATE AS today FROM users WHERE id = $1;
+
+-- name: GetLatestDailyClaimByUserId :one
+SELECT * FROM daily_claims
+WHERE user_id = $1
+ORDER BY claim_date DESC
+LIMIT 1;
+
+-- name: CreateDailyClaim :one
+-- returns no row when the user has already claimed on claim_date
+INSERT INTO daily_claims (user_id, claim_date, streak, chest_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, claim_date) DO NOTHING
+RETURNING *;
@@ -0,0 +1,36 @@
+-- name: CreateGift :one
+INSERT INTO gifts (sender_id, recipient_id, chest_id, quantity, user_item_id, expires_at)
+VALUES ($1, $2, $3, $4, $5, NOW() + sqlc.arg('expires_in')::INTERVAL)
+RETURNING *;
+
+-- name: LockPendingGift :one
+SELECT sqlc.embed(gifts), (gifts.expires_at <= NOW())::BOOLEAN AS expired
+FROM gifts
+WHERE gifts.id = $1 AND gifts.recipient_id = $2 AND gifts.status = 'pending'
+FOR UPDATE;
+
+-- name: LockExpiredGifts :many
+-- pending gifts past their expiry, skipping any a recipient is resolving at the same time
+SELECT * FROM gifts
+WHERE status = 'pending' AND expires_at <= NOW()
+ORDER BY expires_at
+FOR UPDATE SKIP LOCKED;
+
+-- name: ResolveGift :one
+UPDATE gifts
+SET status = $2,
+    resolved_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetGiftsByRecipientId :many
+SELECT * FROM gifts
+WHERE recipient_id = $1
+  AND (sqlc.narg('status')::gift_status IS NULL OR status = sqlc.narg('status'))
+ORDER BY created_at DESC;
+
+-- name: GetGiftsBySenderId :many
+SELECT * FROM gifts
+WHERE sender_id = $1
+  AND (sqlc.narg('status')::gift_status IS NULL OR status = sqlc.narg('status'))
+ORDER BY created_at DESC;
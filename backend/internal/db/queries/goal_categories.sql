@@ -1,6 +1,6 @@
 -- name: CreateGoalCategory :one
-INSERT INTO goal_categories (title, user_id)
-VALUES ($1, $2)
+INSERT INTO goal_categories (title, user_id, reset_policy, reset_time, reset_weekday)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: GetGoalCategoriesByUserId :many
@@ -15,14 +15,28 @@ SET title = coalesce(sqlc.narg('title'), title)
 WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id')
 RETURNING *;
 
+-- name: SetGoalCategoryReset :one
+-- moving reset_through to now keeps the new schedule from firing for a boundary already past
+UPDATE goal_categories
+SET reset_policy = $3,
+    reset_time = $4,
+    reset_weekday = $5,
+    reset_through = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
 -- name: DeleteGoalCategoryById :execrows
 DELETE FROM goal_categories WHERE id = $1 AND user_id = $2;
 
 -- name: GetGoalCategoriesWithGoalsByUserId :many
 SELECT
     gc.id, gc.title, gc.user_id, gc.created_at, gc.updated_at,
+    gc.reset_policy, gc.reset_time, gc.reset_weekday,
+    gc.current_streak, gc.longest_streak, gc.last_completed_date,
     g.id as goal_id, g.title as goal_title, g.description, g.status,
-    g.created_at as goal_created_at, g.updated_at as goal_updated_at
+    g.created_at as goal_created_at, g.updated_at as goal_updated_at,
+    g.current_streak as goal_current_streak, g.longest_streak as goal_longest_streak,
+    g.last_completed_date as goal_last_completed_date
 FROM goal_categories gc
 LEFT JOIN goals g ON gc.id = g.category_id
 WHERE gc.user_id = $1
@@ -31,9 +45,71 @@ ORDER BY gc.created_at, g.created_at DESC;
 -- name: GetGoalCategoryWithGoalsById :many
 SELECT
     gc.id, gc.title, gc.user_id, gc.created_at, gc.updated_at,
+    gc.reset_policy, gc.reset_time, gc.reset_weekday,
+    gc.current_streak, gc.longest_streak, gc.last_completed_date,
     g.id as goal_id, g.title as goal_title, g.description, g.status,
-    g.created_at as goal_created_at, g.updated_at as goal_updated_at
+    g.created_at as goal_created_at, g.updated_at as goal_updated_at,
+    g.current_streak as goal_current_streak, g.longest_streak as goal_longest_streak,
+    g.last_completed_date as goal_last_completed_date
 FROM goal_categories gc
 LEFT JOIN goals g ON gc.id = g.category_id
 WHERE gc.id = $1 AND gc.user_id = $2
 ORDER BY g.created_at DESC;
+
+-- name: GetDueCategoryResets :many
+-- locks categories whose latest reset boundary in their user's timezone has passed since they
+-- were last reset. other instances skip the locked rows rather than resetting them twice.
+SELECT
+    gc.id, gc.user_id,
+    category_reset_boundary(
+        gc.reset_policy, gc.reset_time, gc.reset_weekday, u.timezone, NOW()
+    )::TIMESTAMPTZ AS boundary
+FROM goal_categories gc
+JOIN users u ON u.id = gc.user_id
+WHERE gc.reset_policy <> 'none'
+    AND category_reset_boundary(
+        gc.reset_policy, gc.reset_time, gc.reset_weekday, u.timezone, NOW()
+    ) > gc.reset_through
+ORDER BY gc.reset_through
+LIMIT $1
+FOR UPDATE OF gc SKIP LOCKED;
+
+-- name: SetGoalCategoryResetThrough :exec
+UPDATE goal_categories SET reset_through = $2 WHERE id = $1;
+
+-- name: LockGoalCategoriesByIds :many
+-- locked in id order, so callers locking several can't deadlock each other, and before the goals
+-- in them. resets lock in reset_through order instead and are only kept clear of these locks by
+-- skipping locked categories, never waiting on one.
+SELECT * FROM goal_categories
+WHERE id = ANY(sqlc.arg('ids')::UUID[]) AND user_id = sqlc.arg('user_id')
+ORDER BY id
+FOR NO KEY UPDATE;
+
+-- name: SetGoalCategoryStreak :exec
+UPDATE goal_categories
+SET current_streak = $2,
+    longest_streak = $3,
+    last_completed_date = $4,
+    streak_milestone = $5
+WHERE id = $1;
+
+-- name: GetLapsedCategoryStreaks :many
+-- categories with a streak that have missed a day since their last completion that isn't frozen
+SELECT gc.id, gc.user_id, (NOW() AT TIME ZONE u.timezone)::DATE AS today
+FROM goal_categories gc
+JOIN users u ON u.id = gc.user_id
+WHERE gc.current_streak > 0
+    AND EXISTS (
+        SELECT 1
+        FROM generate_series(
+            (gc.last_completed_date + 1)::TIMESTAMP,
+            ((NOW() AT TIME ZONE u.timezone)::DATE - 1)::TIMESTAMP,
+            INTERVAL '1 day'
+        ) AS missed(day)
+        WHERE NOT EXISTS (
+            SELECT 1 FROM streak_freeze_days
+            WHERE streak_freeze_days.user_id = gc.user_id
+                AND streak_freeze_days.day = missed.day::DATE
+        )
+    );
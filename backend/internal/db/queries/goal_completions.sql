@@ -0,0 +1,90 @@
+-- name: CreateGoalCompletion :one
+INSERT INTO goal_completions (
+    goal_id,
+    user_id,
+    category_id,
+    occurrence_date,
+    period_date,
+    goal_streak,
+    category_streak
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: UncompleteGoalCompletion :one
+-- marks the goal's latest standing completion as undone, keeping the row
+UPDATE goal_completions
+SET uncompleted_at = NOW()
+WHERE id = (
+    SELECT latest.id FROM goal_completions AS latest
+    WHERE latest.goal_id = $1 AND latest.uncompleted_at IS NULL
+    ORDER BY latest.id DESC
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: ClaimGoalCompletionXp :one
+-- records the XP a standing completion earns, unless it already has some or another standing
+-- completion of the goal holds XP for the same period
+UPDATE goal_completions AS gc
+SET xp_granted = sqlc.arg('xp_granted')
+WHERE gc.id = sqlc.arg('id')
+  AND gc.user_id = sqlc.arg('user_id')
+  AND gc.uncompleted_at IS NULL
+  AND gc.xp_granted = 0
+  AND NOT EXISTS (
+    SELECT 1 FROM goal_completions AS other
+    WHERE other.goal_id = gc.goal_id
+      AND other.period_date = gc.period_date
+      AND other.id <> gc.id
+      AND other.uncompleted_at IS NULL
+      AND other.xp_granted > 0
+  )
+RETURNING *;
+
+-- name: ReverseGoalCompletionXp :one
+-- takes back the XP of an undone completion, returning how much it held
+UPDATE goal_completions AS gc
+SET xp_granted = 0
+FROM (
+    SELECT held.id, held.xp_granted FROM goal_completions AS held
+    WHERE held.id = sqlc.arg('id')
+      AND held.user_id = sqlc.arg('user_id')
+      AND held.uncompleted_at IS NOT NULL
+      AND held.xp_granted > 0
+    FOR UPDATE
+) AS undone
+WHERE gc.id = undone.id
+RETURNING gc.goal_id, undone.xp_granted;
+
+-- name: GetGoalCompletionsByGoalId :many
+SELECT * FROM goal_completions
+WHERE goal_id = $1 AND user_id = $2
+ORDER BY id DESC;
+
+-- name: GetGoalCompletionsByUserId :many
+SELECT * FROM goal_completions
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('from')::TIMESTAMP IS NULL OR completed_at >= sqlc.narg('from')::TIMESTAMP)
+  AND (sqlc.narg('to')::TIMESTAMP IS NULL OR completed_at < sqlc.narg('to')::TIMESTAMP)
+  AND (sqlc.narg('cursor')::BIGINT IS NULL OR id < sqlc.narg('cursor')::BIGINT)
+ORDER BY id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: GetGoalStreakHistory :one
+-- what the goal's standing completions say about its streak: the latest period completed, the
+-- longest streak reached and whether the given period is still completed
+SELECT
+    MAX(period_date)::DATE AS last_completed_date,
+    COALESCE(MAX(goal_streak), 0)::INTEGER AS longest_streak,
+    COALESCE(BOOL_OR(period_date = sqlc.arg('period_date')), false)::BOOLEAN AS period_completed
+FROM goal_completions
+WHERE goal_id = sqlc.arg('goal_id') AND uncompleted_at IS NULL;
+
+-- name: GetCategoryStreakHistory :one
+SELECT
+    MAX(period_date)::DATE AS last_completed_date,
+    COALESCE(MAX(category_streak), 0)::INTEGER AS longest_streak,
+    COALESCE(BOOL_OR(period_date = sqlc.arg('period_date')), false)::BOOLEAN AS period_completed
+FROM goal_completions
+WHERE category_id = sqlc.arg('category_id') AND uncompleted_at IS NULL;
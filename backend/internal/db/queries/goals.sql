@@ -1,6 +1,15 @@
 -- name: CreateGoal :one
-INSERT INTO goals (title, description, user_id, category_id)
-VALUES ($1, $2, $3, $4)
+INSERT INTO goals (
+    title,
+    description,
+    user_id,
+    category_id,
+    recurrence,
+    recurrence_start,
+    occurrence_date,
+    next_occurrence_date
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: UpdateGoalStatus :one
@@ -15,6 +24,9 @@ SELECT * FROM goals WHERE user_id = $1;
 -- name: GetGoalById :one
 SELECT * FROM goals WHERE id = $1 AND user_id = $2 LIMIT 1;
 
+-- name: GetGoalByIdForUpdate :one
+SELECT * FROM goals WHERE id = $1 AND user_id = $2 FOR UPDATE;
+
 -- name: UpdateGoalById :one
 UPDATE goals
 SET title = coalesce(sqlc.narg('title'), title),
@@ -31,3 +43,62 @@ DELETE FROM goals WHERE id = $1 AND user_id = $2;
 UPDATE goals
 SET status = 'not_complete'
 WHERE category_id = $1 AND user_id = $2;
+
+-- name: SetGoalRecurrence :one
+UPDATE goals
+SET recurrence = $3,
+    recurrence_start = $4,
+    occurrence_date = $5,
+    next_occurrence_date = $6
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: AdvanceGoalOccurrence :one
+-- reopens the goal for its new occurrence. matching on the next occurrence it was due for makes
+-- this a no-op, returning no row, when the goal has already moved on.
+UPDATE goals
+SET status = 'not_complete',
+    occurrence_date = sqlc.arg('occurrence_date'),
+    next_occurrence_date = sqlc.arg('next_occurrence_date')
+WHERE id = sqlc.arg('id') AND next_occurrence_date = sqlc.arg('due_date')
+RETURNING *;
+
+-- name: GetDueRecurringGoals :many
+-- recurring goals whose next occurrence has arrived where their user is
+SELECT sqlc.embed(goals), (NOW() AT TIME ZONE users.timezone)::DATE AS today
+FROM goals
+JOIN users ON users.id = goals.user_id
+WHERE goals.next_occurrence_date <= (NOW() AT TIME ZONE users.timezone)::DATE
+ORDER BY goals.next_occurrence_date
+LIMIT $1;
+
+-- name: SetGoalStreak :exec
+UPDATE goals
+SET current_streak = $2,
+    longest_streak = $3,
+    last_completed_date = $4,
+    streak_milestone = $5
+WHERE id = $1;
+
+-- name: GetLapsedGoalStreaks :many
+-- goals counted in days, which is every goal with a streak that doesn't recur, that have missed a
+-- day since their last completion that isn't frozen. recurring goals break theirs as they move on
+-- to a new occurrence.
+SELECT goals.id, goals.user_id, (NOW() AT TIME ZONE users.timezone)::DATE AS today
+FROM goals
+JOIN users ON users.id = goals.user_id
+WHERE goals.recurrence IS NULL
+    AND goals.current_streak > 0
+    AND EXISTS (
+        SELECT 1
+        FROM generate_series(
+            (goals.last_completed_date + 1)::TIMESTAMP,
+            ((NOW() AT TIME ZONE users.timezone)::DATE - 1)::TIMESTAMP,
+            INTERVAL '1 day'
+        ) AS missed(day)
+        WHERE NOT EXISTS (
+            SELECT 1 FROM streak_freeze_days
+            WHERE streak_freeze_days.user_id = goals.user_id
+                AND streak_freeze_days.day = missed.day::DATE
+        )
+    );
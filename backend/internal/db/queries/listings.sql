@@ -0,0 +1,47 @@
+-- name: CreateListing :one
+INSERT INTO listings (seller_id, user_item_id, price)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: LockActiveListing :one
+SELECT sqlc.embed(listings), sqlc.embed(chest_items)
+FROM listings
+JOIN user_items ON user_items.id = listings.user_item_id
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE listings.id = $1 AND listings.status = 'active'
+FOR UPDATE OF listings;
+
+-- name: CloseListing :one
+UPDATE listings
+SET status = $2,
+    buyer_id = $3,
+    fee = $4,
+    closed_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetActiveListings :many
+SELECT sqlc.embed(listings), sqlc.embed(chest_items)
+FROM listings
+JOIN user_items ON user_items.id = listings.user_item_id
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE listings.status = 'active'
+  AND (sqlc.narg('rarity')::item_type IS NULL OR chest_items.rarity = sqlc.narg('rarity'))
+  AND (
+      sqlc.narg('title')::TEXT IS NULL
+      OR chest_items.title ILIKE '%' || sqlc.narg('title') || '%'
+  )
+  AND (sqlc.narg('cursor')::BIGINT IS NULL OR listings.id < sqlc.narg('cursor'))
+ORDER BY listings.id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: GetListingsBySellerId :many
+SELECT sqlc.embed(listings), sqlc.embed(chest_items)
+FROM listings
+JOIN user_items ON user_items.id = listings.user_item_id
+JOIN chest_items ON chest_items.id = user_items.item_id
+WHERE listings.seller_id = sqlc.arg('seller_id')
+  AND (sqlc.narg('status')::listing_status IS NULL OR listings.status = sqlc.narg('status'))
+  AND (sqlc.narg('cursor')::BIGINT IS NULL OR listings.id < sqlc.narg('cursor'))
+ORDER BY listings.id DESC
+LIMIT sqlc.arg('page_size');
@@ -0,0 +1,74 @@
+-- name: CreateReward :one
+INSERT INTO rewards (user_id, title, description, cost, repeatable, cooldown_seconds)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetRewardsByUserId :many
+-- each reward with how often it was redeemed and whether its cooldown is still running
+SELECT sqlc.embed(rewards),
+       stats.redemption_count,
+       stats.last_redeemed_at,
+       COALESCE(
+           stats.last_redeemed_at + rewards.cooldown_seconds * INTERVAL '1 second' > NOW(),
+           false
+       )::BOOLEAN AS cooling_down
+FROM rewards
+CROSS JOIN LATERAL (
+    SELECT COUNT(*)::INTEGER AS redemption_count, MAX(created_at)::TIMESTAMP AS last_redeemed_at
+    FROM reward_redemptions
+    WHERE reward_redemptions.reward_id = rewards.id
+) stats
+WHERE rewards.user_id = $1
+ORDER BY rewards.created_at;
+
+-- name: GetRewardById :one
+SELECT sqlc.embed(rewards),
+       stats.redemption_count,
+       stats.last_redeemed_at,
+       COALESCE(
+           stats.last_redeemed_at + rewards.cooldown_seconds * INTERVAL '1 second' > NOW(),
+           false
+       )::BOOLEAN AS cooling_down
+FROM rewards
+CROSS JOIN LATERAL (
+    SELECT COUNT(*)::INTEGER AS redemption_count, MAX(created_at)::TIMESTAMP AS last_redeemed_at
+    FROM reward_redemptions
+    WHERE reward_redemptions.reward_id = rewards.id
+) stats
+WHERE rewards.id = $1 AND rewards.user_id = $2;
+
+-- name: LockRewardById :one
+-- serializes redemptions of one reward so cooldowns and one-time limits hold under concurrency
+SELECT * FROM rewards WHERE id = $1 AND user_id = $2 FOR UPDATE;
+
+-- name: UpdateRewardById :one
+-- making a reward one-time clears its cooldown
+UPDATE rewards
+SET title = coalesce(sqlc.narg('title'), title),
+    description = coalesce(sqlc.narg('description'), description),
+    cost = coalesce(sqlc.narg('cost'), cost),
+    repeatable = coalesce(sqlc.narg('repeatable'), repeatable),
+    cooldown_seconds = CASE
+        WHEN coalesce(sqlc.narg('repeatable'), repeatable)
+            THEN coalesce(sqlc.narg('cooldown_seconds'), cooldown_seconds)
+        ELSE 0
+    END,
+    updated_at = NOW()
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id')
+RETURNING *;
+
+-- name: DeleteRewardById :execrows
+DELETE FROM rewards WHERE id = $1 AND user_id = $2;
+
+-- name: CreateRewardRedemption :one
+INSERT INTO reward_redemptions (user_id, reward_id, title, cost)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetRewardRedemptionsByUserId :many
+SELECT * FROM reward_redemptions
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('reward_id')::UUID IS NULL OR reward_id = sqlc.narg('reward_id')::UUID)
+  AND (sqlc.narg('cursor')::BIGINT IS NULL OR id < sqlc.narg('cursor')::BIGINT)
+ORDER BY id DESC
+LIMIT sqlc.arg('page_size');
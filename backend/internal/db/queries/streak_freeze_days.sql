@@ -0,0 +1,9 @@
+-- name: GetStreakFreezeDays :many
+SELECT day FROM streak_freeze_days
+WHERE user_id = $1 AND day BETWEEN sqlc.arg('from_day')::DATE AND sqlc.arg('to_day')::DATE
+ORDER BY day;
+
+-- name: CreateStreakFreezeDay :exec
+INSERT INTO streak_freeze_days (user_id, day)
+VALUES ($1, $2)
+ON CONFLICT (user_id, day) DO NOTHING;
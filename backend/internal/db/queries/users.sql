@@ -14,6 +14,13 @@ UPDATE users
 -- name: GetUserById :one
 SELECT * FROM users WHERE id = $1 LIMIT 1;
 
+-- name: GetUserByIdForUpdate :one
+SELECT * FROM users WHERE id = $1 FOR UPDATE;
+
+-- name: LockUserById :exec
+-- serializes per-user writes, such as equipping items, for the rest of the transaction
+SELECT id FROM users WHERE id = $1 FOR UPDATE;
+
 -- name: DeleteUserById :exec
 DELETE FROM users WHERE id = $1;
 
@@ -25,7 +32,16 @@ UPDATE users
     refresh_token_expiry = coalesce(sqlc.narg('refresh_token_expiry'), refresh_token_expiry),
     level_id = coalesce(sqlc.narg('level_id'), level_id),
     xp = coalesce(sqlc.narg('xp'), xp),
-    cash_available = coalesce(sqlc.narg('cash_available'), cash_available)
+    timezone = coalesce(sqlc.narg('timezone'), timezone)
+    WHERE id = sqlc.arg('id')
+    RETURNING *;
+
+-- name: UpdateUserProgress :one
+UPDATE users
+    SET xp = sqlc.arg('xp'),
+    level_id = sqlc.arg('level_id'),
+    highest_level_id = GREATEST(highest_level_id, sqlc.arg('level_id')),
+    updated_at = NOW()
     WHERE id = sqlc.arg('id')
     RETURNING *;
 
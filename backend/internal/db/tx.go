@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	sqlcdb "goalify/internal/db/generated"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// serializationRetries is how many times WithSerializableTx reruns a transaction that lost a
+// serialization conflict before giving up
+const serializationRetries = 3
+
+// pgSerializationFailure is the SQLSTATE of a transaction aborted by a serialization conflict
+const pgSerializationFailure = "40001"
+
+// WithTx runs fn against a transactional variant of the sqlc queries. The transaction is
+// committed when fn returns nil and rolled back otherwise.
+func WithTx(ctx context.Context, pool *pgxpool.Pool, fn func(*sqlcdb.Queries) error) error {
+	return withTxOptions(ctx, pool, pgx.TxOptions{}, fn)
+}
+
+// WithSerializableTx is WithTx at the serializable isolation level. Postgres aborts one of two
+// serializable transactions that conflict, so fn is rerun from the start, up to
+// serializationRetries more times, when the transaction fails with a serialization error. fn must
+// not have effects outside the transaction.
+func WithSerializableTx(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	fn func(*sqlcdb.Queries) error,
+) error {
+	opts := pgx.TxOptions{IsoLevel: pgx.Serializable}
+
+	var err error
+	for range serializationRetries + 1 {
+		err = withTxOptions(ctx, pool, opts, fn)
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != pgSerializationFailure {
+			return err
+		}
+	}
+	return err
+}
+
+func withTxOptions(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	opts pgx.TxOptions,
+	fn func(*sqlcdb.Queries) error,
+) error {
+	tx, err := pool.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	if err := fn(sqlcdb.New(tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"goalify/pkg/options"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,13 +17,77 @@ type Goal struct {
 	ID         uuid.UUID `db:"id"          json:"id"`
 	UserID     uuid.UUID `db:"user_id"     json:"user_id"`
 	CategoryID uuid.UUID `db:"category_id" json:"category_id"`
+	// Recurrence is the RRULE a recurring goal repeats on. OccurrenceDate is the occurrence its
+	// status applies to and NextOccurrenceDate when it reopens, both as YYYY-MM-DD dates where
+	// the user is.
+	Recurrence         options.Option[string] `json:"recurrence"`
+	OccurrenceDate     options.Option[string] `json:"occurrence_date"`
+	NextOccurrenceDate options.Option[string] `json:"next_occurrence_date"`
+	Streak             Streak                 `json:"streak"`
+}
+
+// Streak counts consecutive periods completed: occurrences of a recurring goal, or days for other
+// goals in daily categories and for daily categories. LastCompletedDate is the YYYY-MM-DD period
+// that last extended it, and Current drops to 0 once a period is missed.
+type Streak struct {
+	LastCompletedDate options.Option[string] `json:"last_completed_date"`
+	Current           int                    `json:"current"`
+	Longest           int                    `json:"longest"`
+}
+
+// StreakMilestone is a goal's or category's streak reaching one of the milestone lengths. Kind is
+// "goal" or "category" and ID the goal's or category's.
+type StreakMilestone struct {
+	Kind   string    `json:"kind"`
+	Title  string    `json:"title"`
+	Streak int       `json:"streak"`
+	ID     uuid.UUID `json:"id"`
+}
+
+// GoalCompletion is one time a goal was completed. CategoryID is the goal's category at the time,
+// OccurrenceDate the occurrence a recurring goal was completed for, and UncompletedAt when the
+// goal was un-completed again, if it was. PeriodDate is the period the completion earns XP for,
+// the occurrence or else the user's local date, and XpGranted the XP it holds. GoalStreak and
+// CategoryStreak are the streaks as the completion left them, and Milestones the streak milestones
+// it reached, only set as it's recorded.
+type GoalCompletion struct {
+	CompletedAt    time.Time                 `json:"completed_at"`
+	UncompletedAt  options.Option[time.Time] `json:"uncompleted_at"`
+	OccurrenceDate options.Option[string]    `json:"occurrence_date"`
+	CategoryID     options.Option[uuid.UUID] `json:"category_id"`
+	PeriodDate     string                    `json:"period_date"`
+	Milestones     []*StreakMilestone        `json:"-"`
+	ID             int64                     `json:"id"`
+	XpGranted      int                       `json:"xp_granted"`
+	GoalStreak     int                       `json:"goal_streak"`
+	CategoryStreak int                       `json:"category_streak"`
+	GoalID         uuid.UUID                 `json:"goal_id"`
+	UserID         uuid.UUID                 `json:"user_id"`
 }
 
 type GoalCategory struct {
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-	Title     string    `db:"title"      json:"title"`
-	Goals     []*Goal   `                json:"goals"`
-	ID        uuid.UUID `db:"id"         json:"id"`
-	UserID    uuid.UUID `db:"user_id"    json:"user_id"`
+	CreatedAt time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at" json:"updated_at"`
+	Title     string        `db:"title"      json:"title"`
+	Goals     []*Goal       `                json:"goals"`
+	Reset     ResetSchedule `                json:"reset"`
+	Streak    Streak        `                json:"streak"`
+	ID        uuid.UUID     `db:"id"         json:"id"`
+	UserID    uuid.UUID     `db:"user_id"    json:"user_id"`
+}
+
+// ResetSchedule is when a category's goals go back to not complete. Policy is "none", "daily" or
+// "weekly", Time an HH:MM wall clock time where the user is, and Weekday a lowercase day name
+// that only the weekly policy has.
+type ResetSchedule struct {
+	Weekday options.Option[string] `json:"weekday"`
+	Policy  string                 `json:"policy"`
+	Time    string                 `json:"time"`
+}
+
+// CategoryReset is a scheduled reset of a category's goals, at the boundary it was due for
+type CategoryReset struct {
+	ResetAt    time.Time `json:"reset_at"`
+	CategoryID uuid.UUID `json:"category_id"`
+	UserID     uuid.UUID `json:"user_id"`
 }
@@ -8,27 +8,54 @@ import (
 )
 
 type Chest struct {
-	CreatedAt   time.Time `db:"created_at"  json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"  json:"updated_at"`
-	Type        string    `db:"type"        json:"type"`
-	Description string    `db:"description" json:"description"`
-	Price       int       `db:"price"       json:"price"`
-	ID          uuid.UUID `db:"id"          json:"id"`
+	CreatedAt        time.Time  `db:"created_at"         json:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"         json:"updated_at"`
+	Type             string     `db:"type"               json:"type"`
+	Description      string     `db:"description"        json:"description"`
+	Price            int        `db:"price"              json:"price"`
+	Pity             *PityState `                         json:"pity,omitempty"`
+	DropTableVersion int        `db:"drop_table_version" json:"drop_table_version"`
+	ID               uuid.UUID  `db:"id"                 json:"id"`
 }
 
+// ChestItem is an item in the catalog. Items with an Effect are consumables, used up for the effect
+// rather than equipped.
 type ChestItem struct {
 	CreatedAt time.Time           `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time           `db:"updated_at" json:"updated_at"`
+	Effect    *ItemEffect         `                json:"effect,omitempty"`
 	ImageURL  string              `db:"image_url"  json:"image_url"`
+	Title     string              `db:"title"      json:"title"`
 	Rarity    string              `db:"rarity"     json:"rarity"`
+	Slot      string              `db:"slot"       json:"slot"`
 	Price     options.Option[int] `db:"price"      json:"price"`
 	ID        uuid.UUID           `db:"id"         json:"id"`
 }
 
+// ItemEffect is what consuming an item grants: an xp_multiplier multiplying goal XP by Value, or a
+// streak_freeze covering Value missed days, each used up as it bridges one. Either lasts
+// DurationSeconds once consumed.
+type ItemEffect struct {
+	Type            string `json:"type"`
+	Value           int    `json:"value"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
 type ChestItemDropRate struct {
+	Rarity   string    `db:"rarity"    json:"rarity,omitempty"`
+	ID       uuid.UUID `db:"id"        json:"id"`
 	ItemID   uuid.UUID `db:"item_id"   json:"item_id"`
 	ChestID  uuid.UUID `db:"chest_id"  json:"chest_id"`
-	DropRate float32   `db:"drop_rate" json:"drop_rate"`
+	DropRate float64   `db:"drop_rate" json:"drop_rate"`
+}
+
+// PityState is a user's bad-luck protection progress on a chest. Once OpensSinceTopDrop passes
+// Threshold the odds of Rarity rise with every opening until the drop is guaranteed.
+type PityState struct {
+	Rarity               string `json:"rarity"`
+	OpensSinceTopDrop    int    `json:"opens_since_top_drop"`
+	Threshold            int    `json:"threshold"`
+	OpensUntilGuaranteed int    `json:"opens_until_guaranteed"`
 }
 
 type UserChest struct {
@@ -37,8 +64,228 @@ type UserChest struct {
 	QuantityOwned int       `db:"quantity_owned" json:"quantity_owned"`
 }
 
+// DropTable is one version of a chest's drop table. Replacing a drop table creates a new version
+// and keeps the old ones so past openings can be explained.
+type DropTable struct {
+	CreatedAt time.Time            `json:"created_at"`
+	Rates     []*ChestItemDropRate `json:"rates"`
+	Version   int                  `json:"version"`
+	ChestID   uuid.UUID            `json:"chest_id"`
+	CreatedBy uuid.UUID            `json:"created_by"`
+}
+
+type ChestPurchase struct {
+	UserChest     *UserChest `json:"user_chest"`
+	CashAvailable int        `json:"cash_available"`
+}
+
 type UserItem struct {
-	Status string    `db:"status"  json:"status"`
-	UserID uuid.UUID `db:"user_id" json:"user_id"`
-	ItemID uuid.UUID `db:"item_id" json:"item_id"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	Item      *ChestItem `                json:"item,omitempty"`
+	Status    string     `db:"status"     json:"status"`
+	ID        uuid.UUID  `db:"id"         json:"id"`
+	UserID    uuid.UUID  `db:"user_id"    json:"user_id"`
+	ItemID    uuid.UUID  `db:"item_id"    json:"item_id"`
+}
+
+// ItemSale is the result of selling one or more of a user's items back for cash
+type ItemSale struct {
+	Items         []*UserItem `json:"items"`
+	CashEarned    int         `json:"cash_earned"`
+	CashAvailable int         `json:"cash_available"`
+}
+
+type ChestOpening struct {
+	UserItem          *UserItem      `json:"user_item"`
+	Item              *ChestItem     `json:"item"`
+	Pity              *PityState     `json:"pity,omitempty"`
+	Proof             *FairnessProof `json:"proof"`
+	ID                int64          `json:"id"`
+	ChestID           uuid.UUID      `json:"chest_id"`
+	QuantityRemaining int            `json:"quantity_remaining"`
+}
+
+// FairnessSeed is a user's provably fair seed pair. ServerSeed is only present once the pair has
+// been revealed; until then ServerSeedHash commits to it.
+type FairnessSeed struct {
+	CreatedAt      time.Time                 `json:"created_at"`
+	RevealedAt     options.Option[time.Time] `json:"revealed_at"`
+	ServerSeed     options.Option[string]    `json:"server_seed"`
+	ServerSeedHash string                    `json:"server_seed_hash"`
+	ClientSeed     string                    `json:"client_seed"`
+	NextNonce      int                       `json:"next_nonce"`
+	ID             uuid.UUID                 `json:"id"`
+}
+
+// SeedRotation is the result of rotating a user's seed pair: the pair just revealed, if there was
+// one, and the newly committed pair that future openings roll with
+type SeedRotation struct {
+	Revealed *FairnessSeed `json:"revealed"`
+	Active   *FairnessSeed `json:"active"`
+}
+
+// FairnessProof is what an opening's roll was derived from. Once ServerSeed is revealed, its
+// SHA-256 matches ServerSeedHash and HMAC-SHA256(ServerSeed, "ClientSeed:Nonce") reproduces Roll.
+// TopDropChance is set when pity raised the combined chance of the drop table's top rarity.
+type FairnessProof struct {
+	ServerSeed       options.Option[string]  `json:"server_seed"`
+	TopDropChance    options.Option[float64] `json:"top_drop_chance"`
+	ServerSeedHash   string                  `json:"server_seed_hash"`
+	ClientSeed       string                  `json:"client_seed"`
+	Nonce            int                     `json:"nonce"`
+	Roll             float64                 `json:"roll"`
+	DropTableVersion int                     `json:"drop_table_version"`
+}
+
+// ChestOpeningRecord is one entry in a user's opening history, with the drop table version the
+// roll picked from, ordered by item id as it was rolled against
+type ChestOpeningRecord struct {
+	CreatedAt  time.Time                 `json:"created_at"`
+	UserItemID options.Option[uuid.UUID] `json:"user_item_id"`
+	Proof      *FairnessProof            `json:"proof"`
+	DropTable  []*ChestItemDropRate      `json:"drop_table"`
+	ID         int64                     `json:"id"`
+	ChestID    uuid.UUID                 `json:"chest_id"`
+	ItemID     uuid.UUID                 `json:"item_id"`
+}
+
+// Gift is chests or a single item sent from one user to another. A gift holds either ChestID and
+// Quantity or UserItemID. Until it is resolved, what it holds is out of both users' inventories.
+type Gift struct {
+	CreatedAt   time.Time                 `json:"created_at"`
+	ExpiresAt   time.Time                 `json:"expires_at"`
+	ResolvedAt  options.Option[time.Time] `json:"resolved_at"`
+	ChestID     options.Option[uuid.UUID] `json:"chest_id"`
+	Quantity    options.Option[int]       `json:"quantity"`
+	UserItemID  options.Option[uuid.UUID] `json:"user_item_id"`
+	Status      string                    `json:"status"`
+	ID          uuid.UUID                 `json:"id"`
+	SenderID    uuid.UUID                 `json:"sender_id"`
+	RecipientID uuid.UUID                 `json:"recipient_id"`
+}
+
+// Listing is a user item offered on the marketplace. While it is active the listing holds the
+// item, out of the seller's inventory. Fee is the house's cut of Price once the item has sold.
+type Listing struct {
+	CreatedAt  time.Time                 `json:"created_at"`
+	ClosedAt   options.Option[time.Time] `json:"closed_at"`
+	BuyerID    options.Option[uuid.UUID] `json:"buyer_id"`
+	Fee        options.Option[int]       `json:"fee"`
+	Item       *ChestItem                `json:"item"`
+	Status     string                    `json:"status"`
+	ID         int64                     `json:"id"`
+	Price      int                       `json:"price"`
+	SellerID   uuid.UUID                 `json:"seller_id"`
+	UserItemID uuid.UUID                 `json:"user_item_id"`
+}
+
+type ListingPurchase struct {
+	Listing       *Listing  `json:"listing"`
+	UserItem      *UserItem `json:"user_item"`
+	CashAvailable int       `json:"cash_available"`
+}
+
+// CollectionEntry is one catalog item in a user's collection book. FirstObtainedAt is set once the
+// user has ever owned the item, even if they no longer hold a copy.
+type CollectionEntry struct {
+	FirstObtainedAt options.Option[time.Time] `json:"first_obtained_at"`
+	Item            *ChestItem                `json:"item"`
+	CopiesOwned     int                       `json:"copies_owned"`
+	Discovered      bool                      `json:"discovered"`
+}
+
+// CollectionProgress counts how many of Total catalog items a user has discovered
+type CollectionProgress struct {
+	Discovered int     `json:"discovered"`
+	Total      int     `json:"total"`
+	Percent    float64 `json:"percent"`
+}
+
+// ChestCollection is a user's progress on the items in a chest's current drop table. Completing it
+// pays the collection bonus once.
+type ChestCollection struct {
+	Progress     *CollectionProgress `json:"progress"`
+	ChestType    string              `json:"chest_type"`
+	ChestID      uuid.UUID           `json:"chest_id"`
+	Complete     bool                `json:"complete"`
+	BonusClaimed bool                `json:"bonus_claimed"`
+}
+
+// Collection is a user's collection book: every catalog item, rolled up overall, by rarity and by
+// chest
+type Collection struct {
+	ByRarity        map[string]*CollectionProgress `json:"by_rarity"`
+	Overall         *CollectionProgress            `json:"overall"`
+	Items           []*CollectionEntry             `json:"items"`
+	Chests          []*ChestCollection             `json:"chests"`
+	CompletionBonus int                            `json:"completion_bonus"`
+}
+
+// CollectionBonus is the one-off cash paid for completing a chest's collection
+type CollectionBonus struct {
+	ChestID       uuid.UUID `json:"chest_id"`
+	CashGranted   int       `json:"cash_granted"`
+	CashAvailable int       `json:"cash_available"`
+}
+
+// CraftingRecipe trades InputCount items of InputRarity for one item of OutputRarity, rolled from
+// the OutputRarity items in ChestID's drop table
+type CraftingRecipe struct {
+	Slug         string    `json:"slug"`
+	InputRarity  string    `json:"input_rarity"`
+	OutputRarity string    `json:"output_rarity"`
+	InputCount   int       `json:"input_count"`
+	ID           uuid.UUID `json:"id"`
+	ChestID      uuid.UUID `json:"chest_id"`
+}
+
+// Craft is the result of crafting: the user items consumed, the item created in their place and
+// the proof of the roll that picked it
+type Craft struct {
+	UserItem      *UserItem      `json:"user_item"`
+	Item          *ChestItem     `json:"item"`
+	Proof         *FairnessProof `json:"proof"`
+	ConsumedItems []uuid.UUID    `json:"consumed_user_item_ids"`
+	ID            int64          `json:"id"`
+	RecipeID      uuid.UUID      `json:"recipe_id"`
+}
+
+// Avatar is what a user's character is drawn from: their level and the items they have equipped,
+// at most one per slot
+type Avatar struct {
+	Items  []*ChestItem `json:"items"`
+	Level  int          `json:"level"`
+	UserID uuid.UUID    `json:"user_id"`
+}
+
+// ItemImage is art uploaded for a chest item. Key is where it is kept in blob storage and URL the
+// signed path it is served from, which is also the item's image_url.
+type ItemImage struct {
+	Item        *ChestItem `json:"item"`
+	Key         string     `json:"key"`
+	URL         string     `json:"url"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Width       int        `json:"width"`
+	Height      int        `json:"height"`
+}
+
+// ActiveEffect is an effect a user consumed an item for, in force until ExpiresAt. SourceItemID is
+// the consumed item's catalog entry, empty once that has been deleted.
+type ActiveEffect struct {
+	CreatedAt    time.Time                 `json:"created_at"`
+	ExpiresAt    time.Time                 `json:"expires_at"`
+	SourceItemID options.Option[uuid.UUID] `json:"source_item_id"`
+	Effect       string                    `json:"effect"`
+	ID           int64                     `json:"id"`
+	Value        int                       `json:"value"`
+	UserID       uuid.UUID                 `json:"user_id"`
+}
+
+// ItemConsumption is the result of consuming an item: the user item used up and the effect it
+// started
+type ItemConsumption struct {
+	UserItem *UserItem     `json:"user_item"`
+	Effect   *ActiveEffect `json:"effect"`
 }
@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"goalify/pkg/options"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reward is a real-life treat a user buys from themselves with cash. A one-time reward can be
+// redeemed once; a repeatable one again once CooldownSeconds have passed since its last redemption.
+// AvailableAt is set while a cooldown is running.
+type Reward struct {
+	CreatedAt       time.Time                 `json:"created_at"`
+	UpdatedAt       time.Time                 `json:"updated_at"`
+	LastRedeemedAt  options.Option[time.Time] `json:"last_redeemed_at"`
+	AvailableAt     options.Option[time.Time] `json:"available_at"`
+	Title           string                    `json:"title"`
+	Description     string                    `json:"description"`
+	Cost            int                       `json:"cost"`
+	CooldownSeconds int                       `json:"cooldown_seconds"`
+	RedemptionCount int                       `json:"redemption_count"`
+	Repeatable      bool                      `json:"repeatable"`
+	Redeemable      bool                      `json:"redeemable"`
+	ID              uuid.UUID                 `json:"id"`
+	UserID          uuid.UUID                 `json:"user_id"`
+}
+
+// RewardRedemption is one redemption in a user's history. Title and Cost are as they were when it
+// was redeemed, and RewardID is empty once the reward has been deleted.
+type RewardRedemption struct {
+	CreatedAt time.Time                 `json:"created_at"`
+	RewardID  options.Option[uuid.UUID] `json:"reward_id"`
+	Title     string                    `json:"title"`
+	ID        int64                     `json:"id"`
+	Cost      int                       `json:"cost"`
+	UserID    uuid.UUID                 `json:"user_id"`
+}
+
+// RewardPurchase is the result of redeeming a reward: the redemption, the reward's new state and
+// the user's remaining cash
+type RewardPurchase struct {
+	Redemption    *RewardRedemption `json:"redemption"`
+	Reward        *Reward           `json:"reward"`
+	CashAvailable int               `json:"cash_available"`
+}
+
+// DailyClaim is one claim of the daily reward chest. ClaimDate is the user's local date
+// (YYYY-MM-DD) when they claimed, and Streak counts consecutive claim days up to and including it.
+// Chest is nil once the chest has been deleted, and UserChest is only set on a fresh claim.
+type DailyClaim struct {
+	CreatedAt time.Time  `json:"created_at"`
+	Chest     *Chest     `json:"chest"`
+	UserChest *UserChest `json:"user_chest,omitempty"`
+	ClaimDate string     `json:"claim_date"`
+	ID        int64      `json:"id"`
+	Streak    int        `json:"streak"`
+	UserID    uuid.UUID  `json:"user_id"`
+}
+
+// DailyRewardStatus is where a user stands with the daily reward. Streak is the streak that is
+// still alive, zero once a day has been missed, and NextChestType is the chest the next claim
+// grants.
+type DailyRewardStatus struct {
+	LastClaim     *DailyClaim `json:"last_claim"`
+	Today         string      `json:"today"`
+	NextChestType string      `json:"next_chest_type"`
+	Streak        int         `json:"streak"`
+	ClaimedToday  bool        `json:"claimed_today"`
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"goalify/pkg/options"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,11 +13,13 @@ type User struct {
 	UpdatedAt          time.Time `db:"updated_at"           json:"updated_at"`
 	Email              string    `db:"email"                json:"email"`
 	Password           string    `db:"password"`
+	Timezone           string    `db:"timezone"             json:"timezone"`
 	Xp                 int       `db:"xp"                   json:"xp"`
 	LevelID            int       `db:"level_id"             json:"level_id"`
 	CashAvailable      int       `db:"cash_available"       json:"cash_available"`
 	ID                 uuid.UUID `db:"id"                   json:"id"`
 	RefreshToken       uuid.UUID `db:"refresh_token"        json:"refresh_token"`
+	IsAdmin            bool      `db:"is_admin"             json:"is_admin"`
 }
 
 type UserDTO struct {
@@ -25,11 +28,13 @@ type UserDTO struct {
 	UpdatedAt          time.Time `db:"updated_at"           json:"updated_at"`
 	Email              string    `db:"email"                json:"email"`
 	AccessToken        string    `                          json:"access_token"`
+	Timezone           string    `db:"timezone"             json:"timezone"`
 	Xp                 int       `db:"xp"                   json:"xp"`
 	LevelID            int       `db:"level_id"             json:"level_id"`
 	CashAvailable      int       `db:"cash_available"       json:"cash_available"`
 	ID                 uuid.UUID `db:"id"                   json:"id"`
 	RefreshToken       uuid.UUID `db:"refresh_token"        json:"refresh_token"`
+	IsAdmin            bool      `db:"is_admin"             json:"is_admin"`
 }
 
 func (u *User) ToUserDTO(accessToken string) *UserDTO {
@@ -39,11 +44,13 @@ func (u *User) ToUserDTO(accessToken string) *UserDTO {
 		UpdatedAt:          u.UpdatedAt,
 		Email:              u.Email,
 		AccessToken:        accessToken,
+		Timezone:           u.Timezone,
 		Xp:                 u.Xp,
 		LevelID:            u.LevelID,
 		CashAvailable:      u.CashAvailable,
 		ID:                 u.ID,
 		RefreshToken:       u.RefreshToken,
+		IsAdmin:            u.IsAdmin,
 	}
 }
 
@@ -54,3 +61,28 @@ type Level struct {
 	LevelUpXp  int       `db:"level_up_xp" json:"level_up_xp"`
 	CashReward int       `db:"cash_reward" json:"cash_reward"`
 }
+
+type CashTransaction struct {
+	CreatedAt    time.Time                 `db:"created_at"    json:"created_at"`
+	ReferenceID  options.Option[uuid.UUID] `db:"reference_id"  json:"reference_id"`
+	Reason       string                    `db:"reason"        json:"reason"`
+	ID           int64                     `db:"id"            json:"id"`
+	Amount       int                       `db:"amount"        json:"amount"`
+	BalanceAfter int                       `db:"balance_after" json:"balance_after"`
+	UserID       uuid.UUID                 `db:"user_id"       json:"user_id"`
+}
+
+// CashMismatch is a user whose cash_available differs from the sum of their cash transactions
+type CashMismatch struct {
+	UserID        uuid.UUID `json:"user_id"`
+	CashAvailable int       `json:"cash_available"`
+	LedgerBalance int       `json:"ledger_balance"`
+}
+
+// UserProgress is the user after an XP change, with the level they were on before it and the
+// level cash reward the change paid
+type UserProgress struct {
+	User       *User
+	OldLevelID int
+	CashReward int
+}
@@ -2,12 +2,32 @@ package events
 
 import "goalify/internal/entities"
 
+// GoalUpdatedData carries the goal before and after an update. Completion is the completion the
+// update recorded or marked undone, and is nil when the goal's status didn't change.
 type GoalUpdatedData struct {
-	OldGoal *entities.Goal
-	NewGoal *entities.Goal
+	OldGoal    *entities.Goal
+	NewGoal    *entities.Goal
+	Completion *entities.GoalCompletion
 }
 
 type XpUpdatedData struct {
 	LevelID int `json:"level_id"`
 	Xp      int `json:"xp"`
 }
+
+type CashUpdatedData struct {
+	CashAvailable int `json:"cash_available"`
+	Amount        int `json:"amount"`
+}
+
+type LevelUpData struct {
+	OldLevelID  int `json:"old_level_id"`
+	NewLevelID  int `json:"new_level_id"`
+	CashGranted int `json:"cash_granted"`
+}
+
+type ListingSoldData struct {
+	Listing       *entities.Listing `json:"listing"`
+	Proceeds      int               `json:"proceeds"`
+	CashAvailable int               `json:"cash_available"`
+}
@@ -27,6 +27,21 @@ const (
 	DefaultGoalCreated  string = "default_goal_created"
 	SSEConnected        string = "sse_connected"
 	XPUpdated           string = "xp_updated"
+	ChestPurchased      string = "chest_purchased"
+	ChestOpened         string = "chest_opened"
+	CashUpdated         string = "cash_updated"
+	LevelUp             string = "level_up"
+	GiftSent            string = "gift_sent"
+	GiftAccepted        string = "gift_accepted"
+	GiftDeclined        string = "gift_declined"
+	GiftExpired         string = "gift_expired"
+	ListingSold         string = "listing_sold"
+	ItemCrafted         string = "item_crafted"
+	ItemConsumed        string = "item_consumed"
+	DailyRewardClaimed  string = "daily_reward_claimed"
+	GoalReopened        string = "goal_reopened"
+	CategoryReset       string = "category_reset"
+	StreakMilestone     string = "streak_milestone"
 )
 
 func ParseEventData[T any](event Event) (T, error) {
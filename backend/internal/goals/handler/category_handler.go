@@ -8,6 +8,7 @@ import (
 	"goalify/internal/middleware"
 	"goalify/internal/responses"
 	"goalify/pkg/jsonutil"
+	"goalify/pkg/options"
 	"log/slog"
 	"net/http"
 
@@ -35,7 +36,15 @@ func (h *GoalHandler) HandleCreateGoalCategory(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	category, err := h.goalService.CreateGoalCategory(body.Title, parsedUUID)
+	reset := stores.NoReset
+	if req, ok := body.Reset.GetVal(); ok {
+		if reset, err = req.schedule(); err != nil {
+			responses.SendAPIError(w, r, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+	}
+
+	category, err := h.goalService.CreateGoalCategory(body.Title, parsedUUID, reset)
 	if err != nil {
 		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
 		return
@@ -137,8 +146,16 @@ func (h *GoalHandler) HandleUpdateGoalCategoryByID(w http.ResponseWriter, r *htt
 	params := stores.UpdateGoalCategoryParams{
 		Title: body.Title,
 	}
+	if req, ok := body.Reset.GetVal(); ok {
+		reset, err := req.schedule()
+		if err != nil {
+			responses.SendAPIError(w, r, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		params.Reset = options.Some(reset)
+	}
 
-	if !params.Title.IsPresent() {
+	if !params.Title.IsPresent() && !params.Reset.IsPresent() {
 		noUpdatesError := fmt.Errorf("%w: no fields given to update", responses.ErrBadRequest)
 		responses.SendAPIError(w, r, http.StatusBadRequest, noUpdatesError.Error(), nil)
 		return
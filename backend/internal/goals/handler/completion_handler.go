@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"goalify/pkg/options"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HandleGetGoalCompletions lists every completion of one of the user's goals, newest first. It
+// serves /api/goals/{goalId}/{resource} and only knows the completions resource, because a
+// literal completions segment would conflict with /api/goals/categories/{categoryId} in the mux.
+func (h *GoalHandler) HandleGetGoalCompletions(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetGoalCompletions")
+	if r.PathValue("resource") != "completions" {
+		responses.SendAPIError(w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIdFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	goalID, err := uuid.Parse(r.PathValue("goalId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "invalid goal id", nil)
+		return
+	}
+
+	completions, err := h.goalService.GetGoalCompletions(goalID, parsedUserID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.GoalCompletion]{
+		Object: responses.ObjectList,
+		Data:   completions,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+// HandleGetCompletions lists the user's completions of all their goals, newest first. The from
+// and to query parameters are RFC 3339 times bounding when the goals were completed, from
+// inclusive and to exclusive, and the list is cursor paginated.
+func (h *GoalHandler) HandleGetCompletions(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetCompletions")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIdFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := parseTimeQuery(query, "from")
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	to, err := parseTimeQuery(query, "to")
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if from.IsPresent() && to.IsPresent() && !from.ValueOrZero().Before(to.ValueOrZero()) {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "from must be before to", nil)
+		return
+	}
+
+	cursor := options.None[int64]()
+	if query.Has("cursor") {
+		id, err := strconv.ParseInt(query.Get("cursor"), 10, 64)
+		if err != nil {
+			responses.SendAPIError(w, r, http.StatusBadRequest, "cursor must be an integer", nil)
+			return
+		}
+		cursor = options.Some(id)
+	}
+
+	limit := DefaultCompletionsLimit
+	if query.Has("limit") {
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil || limit < 1 || limit > MaxCompletionsLimit {
+			responses.SendAPIError(
+				w,
+				r,
+				http.StatusBadRequest,
+				fmt.Sprintf("limit must be an integer from 1 to %d", MaxCompletionsLimit),
+				nil,
+			)
+			return
+		}
+	}
+
+	completions, hasMore, err := h.goalService.GetCompletionsByUserID(
+		parsedUserID,
+		from,
+		to,
+		cursor,
+		limit,
+	)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.GoalCompletion]{
+		Object:  responses.ObjectList,
+		Data:    completions,
+		HasMore: &hasMore,
+	}
+	if hasMore {
+		nextPage := strconv.FormatInt(completions[len(completions)-1].ID, 10)
+		res.NextPage = &nextPage
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+// parseTimeQuery reads the named query parameter as an RFC 3339 time, if it was given
+func parseTimeQuery(query url.Values, name string) (options.Option[time.Time], error) {
+	if !query.Has(name) {
+		return options.None[time.Time](), nil
+	}
+	t, err := time.Parse(time.RFC3339, query.Get(name))
+	if err != nil {
+		return options.None[time.Time](), fmt.Errorf(
+			"%s must be an RFC 3339 time, such as 2026-10-13T00:00:00Z",
+			name,
+		)
+	}
+	return options.Some(t), nil
+}
@@ -7,6 +7,7 @@ import (
 	"goalify/internal/responses"
 	"goalify/pkg/jsonutil"
 	"goalify/pkg/options"
+	"goalify/pkg/recurrence"
 	"log/slog"
 	"net/http"
 
@@ -42,11 +43,20 @@ func (h *GoalHandler) HandleCreateGoal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rule := options.None[recurrence.Rule]()
+	if req, ok := body.Recurrence.GetVal(); ok {
+		if rule, err = req.rule(); err != nil {
+			responses.SendAPIError(w, r, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+	}
+
 	goal, err := h.goalService.CreateGoal(
 		body.Title,
 		body.Description,
 		parsedUserID,
 		parsedCategoryID,
+		rule,
 	)
 	if err != nil {
 		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
@@ -98,8 +108,18 @@ func (h *GoalHandler) HandleUpdateGoalByID(w http.ResponseWriter, r *http.Reques
 		params.CategoryID = options.Some(parsedCategoryID)
 	}
 
+	if req, ok := body.Recurrence.GetVal(); ok {
+		params.Recurrence, err = req.rule()
+		if err != nil {
+			responses.SendAPIError(w, r, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		params.ClearRecurrence = !params.Recurrence.IsPresent()
+	}
+
 	if !params.Title.IsPresent() && !params.Description.IsPresent() &&
-		!params.CategoryID.IsPresent() && !params.Status.IsPresent() {
+		!params.CategoryID.IsPresent() && !params.Status.IsPresent() &&
+		!body.Recurrence.IsPresent() {
 		responses.SendAPIError(w, r, http.StatusBadRequest, "no updates provided", nil)
 		return
 	}
@@ -1,9 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"goalify/internal/entities"
 	"goalify/internal/goals/service"
+	"goalify/internal/goals/stores"
 	"goalify/pkg/options"
+	"goalify/pkg/recurrence"
 	"goalify/pkg/stacktrace"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,21 +21,43 @@ type (
 		traceLogger stacktrace.TraceLogger
 	}
 	CreateGoalRequest struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		CategoryID  string `json:"category_id"`
+		Title       string                            `json:"title"`
+		Description string                            `json:"description"`
+		CategoryID  string                            `json:"category_id"`
+		Recurrence  options.Option[RecurrenceRequest] `json:"recurrence"`
+	}
+	// RecurrenceRequest describes when a goal repeats. Type picks which of the other fields apply:
+	// "daily", "weekdays" with Weekdays, "every_n_days" with Interval, "monthly" with Day,
+	// "rrule" with RRule, or "none" to stop a goal repeating.
+	RecurrenceRequest struct {
+		Type     string   `json:"type"`
+		Weekdays []string `json:"weekdays"`
+		RRule    string   `json:"rrule"`
+		Interval int      `json:"interval"`
+		Day      int      `json:"day"`
 	}
 	CreateGoalCategoryRequest struct {
-		Title string `json:"title"`
+		Title string                       `json:"title"`
+		Reset options.Option[ResetRequest] `json:"reset"`
 	}
 	UpdateGoalCategoryRequest struct {
-		Title options.Option[string] `json:"title"`
+		Title options.Option[string]       `json:"title"`
+		Reset options.Option[ResetRequest] `json:"reset"`
+	}
+	// ResetRequest is when a category's goals go back to not complete: Policy "none", "daily" or
+	// "weekly", at Time (HH:MM, midnight if left out) where the user is, and on Weekday for
+	// weekly resets.
+	ResetRequest struct {
+		Policy  string `json:"policy"`
+		Time    string `json:"time"`
+		Weekday string `json:"weekday"`
 	}
 	UpdateGoalRequest struct {
-		Title       options.Option[string] `json:"title"`
-		Description options.Option[string] `json:"description"`
-		CategoryID  options.Option[string] `json:"category_id"`
-		Status      options.Option[string] `json:"status"`
+		Title       options.Option[string]            `json:"title"`
+		Description options.Option[string]            `json:"description"`
+		CategoryID  options.Option[string]            `json:"category_id"`
+		Status      options.Option[string]            `json:"status"`
+		Recurrence  options.Option[RecurrenceRequest] `json:"recurrence"`
 	}
 	DeleteGoalRequest struct {
 		GoalID string `json:"goal_id"`
@@ -43,11 +72,129 @@ func NewGoalHandler(
 }
 
 const (
-	TextMaxLen = 255
+	TextMaxLen              = 255
+	DefaultCompletionsLimit = 20
+	MaxCompletionsLimit     = 100
+)
+
+const (
+	RecurrenceNone       = "none"
+	RecurrenceDaily      = "daily"
+	RecurrenceWeekdays   = "weekdays"
+	RecurrenceEveryNDays = "every_n_days"
+	RecurrenceMonthly    = "monthly"
+	RecurrenceRRule      = "rrule"
 )
 
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// rule builds the recurrence rule the request describes. It returns no rule for "none".
+func (r RecurrenceRequest) rule() (options.Option[recurrence.Rule], error) {
+	var rule recurrence.Rule
+	switch r.Type {
+	case RecurrenceNone:
+		return options.None[recurrence.Rule](), nil
+	case RecurrenceDaily:
+		rule = recurrence.DailyRule()
+	case RecurrenceWeekdays:
+		if len(r.Weekdays) == 0 {
+			return options.None[recurrence.Rule](), errors.New("weekdays are required")
+		}
+		days := make([]time.Weekday, len(r.Weekdays))
+		for i, name := range r.Weekdays {
+			day, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return options.None[recurrence.Rule](), fmt.Errorf("unknown weekday %q", name)
+			}
+			days[i] = day
+		}
+		rule = recurrence.WeekdaysRule(days...)
+	case RecurrenceEveryNDays:
+		rule = recurrence.EveryNDays(r.Interval)
+	case RecurrenceMonthly:
+		rule = recurrence.MonthlyOn(r.Day)
+	case RecurrenceRRule:
+		parsed, err := recurrence.Parse(r.RRule)
+		if err != nil {
+			return options.None[recurrence.Rule](), err
+		}
+		rule = parsed
+	default:
+		return options.None[recurrence.Rule](), fmt.Errorf(
+			"type must be one of %s, %s, %s, %s, %s or %s",
+			RecurrenceNone,
+			RecurrenceDaily,
+			RecurrenceWeekdays,
+			RecurrenceEveryNDays,
+			RecurrenceMonthly,
+			RecurrenceRRule,
+		)
+	}
+
+	if err := rule.Validate(); err != nil {
+		return options.None[recurrence.Rule](), err
+	}
+	return options.Some(rule), nil
+}
+
+func validRecurrence(problems map[string]string, recurrence options.Option[RecurrenceRequest]) {
+	if req, ok := recurrence.GetVal(); ok {
+		if _, err := req.rule(); err != nil {
+			problems["recurrence"] = err.Error()
+		}
+	}
+}
+
+// schedule builds the reset schedule the request describes
+func (r ResetRequest) schedule() (entities.ResetSchedule, error) {
+	reset := entities.ResetSchedule{Policy: r.Policy, Time: r.Time}
+	switch r.Policy {
+	case stores.ResetPolicyNone, stores.ResetPolicyDaily, stores.ResetPolicyWeekly:
+	default:
+		return reset, fmt.Errorf(
+			"policy must be one of %s, %s or %s",
+			stores.ResetPolicyNone,
+			stores.ResetPolicyDaily,
+			stores.ResetPolicyWeekly,
+		)
+	}
+
+	if reset.Time == "" {
+		reset.Time = "00:00"
+	}
+	if _, err := time.Parse(stores.ResetTimeLayout, reset.Time); err != nil {
+		return reset, errors.New("time must be HH:MM")
+	}
+
+	if r.Policy == stores.ResetPolicyWeekly {
+		if _, ok := weekdays[strings.ToLower(r.Weekday)]; !ok {
+			return reset, errors.New("weekly resets need a weekday, such as monday")
+		}
+		reset.Weekday = options.Some(strings.ToLower(r.Weekday))
+	} else if r.Weekday != "" {
+		return reset, errors.New("only weekly resets have a weekday")
+	}
+	return reset, nil
+}
+
+func validReset(problems map[string]string, reset options.Option[ResetRequest]) {
+	if req, ok := reset.GetVal(); ok {
+		if _, err := req.schedule(); err != nil {
+			problems["reset"] = err.Error()
+		}
+	}
+}
+
 func NewGoalCategoryRequest(title string) CreateGoalCategoryRequest {
-	return CreateGoalCategoryRequest{title}
+	return CreateGoalCategoryRequest{Title: title}
 }
 
 func isValidUUID(id string) bool {
@@ -64,6 +211,7 @@ func (r CreateGoalCategoryRequest) Valid() map[string]string {
 		problems["title"] = "title must be less than 255 characters"
 	}
 
+	validReset(problems, r.Reset)
 	return problems
 }
 
@@ -73,6 +221,7 @@ func (r UpdateGoalCategoryRequest) Valid() map[string]string {
 		problems["title"] = "title cannot be empty"
 	}
 
+	validReset(problems, r.Reset)
 	return problems
 }
 
@@ -97,6 +246,11 @@ func (r CreateGoalRequest) Valid() map[string]string {
 		problems["category_id"] = "category id is required"
 	}
 
+	validRecurrence(problems, r.Recurrence)
+	if r.Recurrence.ValueOrZero().Type == RecurrenceNone {
+		problems["recurrence"] = "type none only applies to existing goals"
+	}
+
 	return problems
 }
 
@@ -131,5 +285,7 @@ func (r UpdateGoalRequest) Valid() map[string]string {
 		r.Status.ValueOrZero() != "not_complete" {
 		problems["status"] = "status must be either 'complete' or 'not_complete'"
 	}
+
+	validRecurrence(problems, r.Recurrence)
 	return problems
 }
@@ -3,6 +3,9 @@ package service
 import (
 	"goalify/internal/entities"
 	"goalify/internal/events"
+	"goalify/internal/goals/stores"
+	"goalify/pkg/options"
+	"goalify/pkg/recurrence"
 	"log/slog"
 )
 
@@ -24,7 +27,8 @@ func (gs *goalService) handleUserCreatedEvent(event events.Event) {
 		return
 	}
 
-	_, err = gs.CreateGoalCategory("Daily", user.ID)
+	daily := entities.ResetSchedule{Policy: stores.ResetPolicyDaily, Time: "00:00"}
+	_, err = gs.CreateGoalCategory("Daily", user.ID, daily)
 	if err != nil {
 		slog.Error("service.handleUserCreatedEvent: CreateGoalCategory:", "err", err)
 	}
@@ -45,6 +49,7 @@ func (gs *goalService) handleGoalCategoryCreatedEvent(event events.Event) {
 		"This is an example goal/task!",
 		category.UserID,
 		category.ID,
+		options.None[recurrence.Rule](),
 	)
 	if err != nil {
 		slog.Error("service.handleGoalCategoryCreatedEvent: CreateGoal:", "err", err)
@@ -9,18 +9,32 @@ import (
 	"goalify/internal/events"
 	"goalify/internal/goals/stores"
 	"goalify/internal/responses"
+	"goalify/pkg/options"
+	"goalify/pkg/recurrence"
 	"goalify/pkg/stacktrace"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 var subscribedEvents = []string{events.GoalCategoryCreated, events.UserCreated}
 
+const (
+	// ReopenBatchSize is how many due recurring goals are reopened per store call
+	ReopenBatchSize = 500
+	// ResetBatchSize is how many categories due a scheduled reset are reset per store call
+	ResetBatchSize = 100
+)
+
 type GoalService interface {
 	// goals
-	CreateGoal(title, description string, userID, categoryID uuid.UUID) (*entities.Goal, error)
+	CreateGoal(
+		title, description string,
+		userID, categoryID uuid.UUID,
+		rule options.Option[recurrence.Rule],
+	) (*entities.Goal, error)
 	UpdateGoalStatus(status string, goalID, userID uuid.UUID) (*entities.Goal, error)
 	GetGoalsByUserID(userID uuid.UUID) ([]*entities.Goal, error)
 	GetGoalByID(goalID, userID uuid.UUID) (*entities.Goal, error)
@@ -30,11 +44,20 @@ type GoalService interface {
 		userID uuid.UUID,
 	) (*entities.Goal, error)
 	DeleteGoalByID(goalID, userID uuid.UUID) error
+	ReopenDueGoals() (int, error)
+	GetGoalCompletions(goalID, userID uuid.UUID) ([]*entities.GoalCompletion, error)
+	GetCompletionsByUserID(
+		userID uuid.UUID,
+		from, to options.Option[time.Time],
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.GoalCompletion, bool, error)
 
 	// categories
 	CreateGoalCategory(
 		title string,
 		userID uuid.UUID,
+		reset entities.ResetSchedule,
 	) (*entities.GoalCategory, error)
 	GetGoalCategoriesByUserID(userID uuid.UUID) ([]*entities.GoalCategory, error)
 	GetGoalCategoryByID(categoryID, userID uuid.UUID) (*entities.GoalCategory, error)
@@ -45,6 +68,8 @@ type GoalService interface {
 	) (*entities.GoalCategory, error)
 	DeleteGoalCategoryByID(categoryID, userID uuid.UUID) error
 	ResetGoalsByCategoryID(categoryID, userID uuid.UUID) error
+	ResetDueCategories() (int, error)
+	BreakLapsedStreaks() (int64, error)
 }
 
 type goalService struct {
@@ -75,6 +100,7 @@ func NewGoalService(goalStore stores.GoalStore,
 func (gs *goalService) CreateGoal(
 	title, description string,
 	userID, categoryID uuid.UUID,
+	rule options.Option[recurrence.Rule],
 ) (*entities.Goal, error) {
 	funcStr := gs.traceLogger.GetTrace("service.CreateGoal")
 
@@ -87,7 +113,10 @@ func (gs *goalService) CreateGoal(
 		return nil, responses.ErrInternalServer
 	}
 
-	createdGoal, err := gs.goalStore.CreateGoal(title, description, userID, categoryID)
+	createdGoal, err := gs.goalStore.CreateGoal(title, description, userID, categoryID, rule)
+	if errors.Is(err, stores.ErrRecurrenceNeverOccurs) {
+		return nil, fmt.Errorf("%w: recurrence never occurs", responses.ErrBadRequest)
+	}
 	if err != nil {
 		slog.Error(fmt.Sprintf("%s: store.CreateGoal:", funcStr), "err", err)
 		return nil, fmt.Errorf("%w: error creating goal", responses.ErrInternalServer)
@@ -141,10 +170,14 @@ func (gs *goalService) GetGoalByID(goalID, userID uuid.UUID) (*entities.Goal, er
 func (gs *goalService) CreateGoalCategory(
 	title string,
 	userID uuid.UUID,
+	reset entities.ResetSchedule,
 ) (*entities.GoalCategory, error) {
 	funcStr := gs.traceLogger.GetTrace("service.CreateGoalCategory")
 
-	cat, err := gs.goalCategoryStore.CreateGoalCategory(title, userID)
+	cat, err := gs.goalCategoryStore.CreateGoalCategory(title, userID, reset)
+	if errors.Is(err, stores.ErrInvalidResetSchedule) {
+		return nil, fmt.Errorf("%w: %w", responses.ErrBadRequest, err)
+	}
 	if err != nil {
 		slog.Error(fmt.Sprintf("%s: store.CreateGoalCategory:", funcStr), "err", err)
 		return nil, fmt.Errorf("%w: error creating goal category", responses.ErrInternalServer)
@@ -199,6 +232,10 @@ func (gs *goalService) UpdateGoalCategoryByID(
 		return nil, responses.ErrNotFound
 	}
 
+	if errors.Is(err, stores.ErrInvalidResetSchedule) {
+		return nil, fmt.Errorf("%w: %w", responses.ErrBadRequest, err)
+	}
+
 	if err != nil {
 		slog.Error(fmt.Sprintf("%s: store.UpdateGoalCategoryById:", funcStr), "err", err)
 		return nil, responses.ErrInternalServer
@@ -229,13 +266,15 @@ func (gs *goalService) UpdateGoalByID(
 ) (*entities.Goal, error) {
 	funcStr := gs.traceLogger.GetTrace("service.UpdateGoalById")
 
-	goal, err := gs.goalStore.GetGoalByID(goalID, userID)
+	// a recurring goal that has come due is compared from its reopened state, so completing the
+	// new occurrence counts as a change
+	goal, err := gs.goalStore.RefreshGoalOccurrence(goalID, userID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("%w: goal not found", responses.ErrNotFound)
 	}
 
 	if err != nil {
-		slog.Error(fmt.Sprintf("%s: store.GetGoalById:", funcStr), "err", err)
+		slog.Error(fmt.Sprintf("%s: store.RefreshGoalOccurrence:", funcStr), "err", err)
 		return nil, fmt.Errorf("%w: error getting goal", responses.ErrInternalServer)
 	}
 
@@ -250,10 +289,13 @@ func (gs *goalService) UpdateGoalByID(
 		}
 	}
 
-	updatedGoal, err := gs.goalStore.UpdateGoalByID(goalID, userID, params)
+	updatedGoal, completion, err := gs.goalStore.UpdateGoalByID(goalID, userID, params)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("%w: invalid goal id", responses.ErrNotFound)
 	}
+	if errors.Is(err, stores.ErrRecurrenceNeverOccurs) {
+		return nil, fmt.Errorf("%w: recurrence never occurs", responses.ErrBadRequest)
+	}
 
 	if err != nil {
 		slog.Error(fmt.Sprintf("%s: store.UpdateGoalById:", funcStr), "err", err)
@@ -261,12 +303,21 @@ func (gs *goalService) UpdateGoalByID(
 	}
 
 	eventData := &events.GoalUpdatedData{
-		OldGoal: goal,
-		NewGoal: updatedGoal,
+		OldGoal:    goal,
+		NewGoal:    updatedGoal,
+		Completion: completion,
 	}
 	event := events.NewEventWithUserID(events.GoalUpdated, eventData, userID.String())
 	gs.eventPublisher.Publish(event)
 
+	if completion != nil {
+		for _, milestone := range completion.Milestones {
+			gs.eventPublisher.Publish(
+				events.NewEventWithUserID(events.StreakMilestone, milestone, userID.String()),
+			)
+		}
+	}
+
 	return updatedGoal, nil
 }
 
@@ -284,6 +335,76 @@ func (gs *goalService) DeleteGoalByID(goalID, userID uuid.UUID) error {
 	return nil
 }
 
+// GetGoalCompletions returns every completion of the user's goal, latest first
+func (gs *goalService) GetGoalCompletions(
+	goalID, userID uuid.UUID,
+) ([]*entities.GoalCompletion, error) {
+	funcStr := gs.traceLogger.GetTrace("service.GetGoalCompletions")
+
+	_, err := gs.goalStore.GetGoalByID(goalID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: goal not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetGoalById:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error getting goal", responses.ErrInternalServer)
+	}
+
+	completions, err := gs.goalStore.GetGoalCompletions(goalID, userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetGoalCompletions:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error getting completions", responses.ErrInternalServer)
+	}
+	return completions, nil
+}
+
+// GetCompletionsByUserID returns a page of up to limit of the user's completions, latest first,
+// and whether there are more after it
+func (gs *goalService) GetCompletionsByUserID(
+	userID uuid.UUID,
+	from, to options.Option[time.Time],
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.GoalCompletion, bool, error) {
+	funcStr := gs.traceLogger.GetTrace("service.GetCompletionsByUserID")
+
+	completions, err := gs.goalStore.GetCompletionsByUserID(userID, from, to, cursor, limit+1)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetCompletionsByUserID:", funcStr), "err", err)
+		return nil, false, fmt.Errorf("%w: error getting completions", responses.ErrInternalServer)
+	}
+
+	if len(completions) > limit {
+		return completions[:limit], true, nil
+	}
+	return completions, false, nil
+}
+
+// ReopenDueGoals reopens every recurring goal whose next occurrence has come, in batches of
+// ReopenBatchSize, and tells each goal's user about it. It returns how many goals it reopened.
+func (gs *goalService) ReopenDueGoals() (int, error) {
+	funcStr := gs.traceLogger.GetTrace("service.ReopenDueGoals")
+
+	total := 0
+	for {
+		reopened, err := gs.goalStore.ReopenDueGoals(ReopenBatchSize)
+		for _, goal := range reopened {
+			gs.eventPublisher.Publish(
+				events.NewEventWithUserID(events.GoalReopened, goal, goal.UserID.String()),
+			)
+		}
+		total += len(reopened)
+
+		if err != nil {
+			slog.Error(fmt.Sprintf("%s: store.ReopenDueGoals:", funcStr), "err", err)
+			return total, fmt.Errorf("%w: error reopening goals", responses.ErrInternalServer)
+		}
+		if len(reopened) < ReopenBatchSize {
+			return total, nil
+		}
+	}
+}
+
 func (gs *goalService) ResetGoalsByCategoryID(categoryID, userID uuid.UUID) error {
 	// check ownership first
 	_, err := gs.goalCategoryStore.GetGoalCategoryByID(categoryID, userID)
@@ -303,3 +424,48 @@ func (gs *goalService) ResetGoalsByCategoryID(categoryID, userID uuid.UUID) erro
 	}
 	return nil
 }
+
+// ResetDueCategories resets the goals of every category whose scheduled reset has come, in
+// batches of ResetBatchSize, and tells each category's user about it. It returns how many
+// categories it reset.
+func (gs *goalService) ResetDueCategories() (int, error) {
+	funcStr := gs.traceLogger.GetTrace("service.ResetDueCategories")
+
+	total := 0
+	for {
+		resets, err := gs.goalCategoryStore.ResetDueCategories(ResetBatchSize)
+		if err != nil {
+			slog.Error(fmt.Sprintf("%s: store.ResetDueCategories:", funcStr), "err", err)
+			return total, fmt.Errorf("%w: error resetting categories", responses.ErrInternalServer)
+		}
+
+		for _, reset := range resets {
+			gs.eventPublisher.Publish(
+				events.NewEventWithUserID(events.CategoryReset, reset, reset.UserID.String()),
+			)
+		}
+		total += len(resets)
+
+		if len(resets) < ResetBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// BreakLapsedStreaks breaks the day counted streaks of goals and categories that have missed a day
+// their user's streak freezes can't bridge, returning how many broke
+func (gs *goalService) BreakLapsedStreaks() (int64, error) {
+	funcStr := gs.traceLogger.GetTrace("service.BreakLapsedStreaks")
+
+	goals, err := gs.goalStore.BreakLapsedStreaks()
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.BreakLapsedStreaks:", funcStr), "err", err)
+		return 0, fmt.Errorf("%w: error breaking goal streaks", responses.ErrInternalServer)
+	}
+	categories, err := gs.goalCategoryStore.BreakLapsedStreaks()
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.BreakLapsedStreaks:", funcStr), "err", err)
+		return goals, fmt.Errorf("%w: error breaking category streaks", responses.ErrInternalServer)
+	}
+	return goals + categories, nil
+}
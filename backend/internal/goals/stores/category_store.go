@@ -4,17 +4,40 @@ package stores
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"goalify/internal/entities"
 	"goalify/pkg/options"
+	"strings"
+	"time"
 
 	db "goalify/internal/db"
 	sqlcdb "goalify/internal/db/generated"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+	ResetPolicyNone   = "none"
+	ResetPolicyDaily  = "daily"
+	ResetPolicyWeekly = "weekly"
+)
+
+// ResetTimeLayout is how a reset schedule's wall clock time is written
+const ResetTimeLayout = "15:04"
+
+// ErrInvalidResetSchedule is returned for a reset schedule the database can't store
+var ErrInvalidResetSchedule = errors.New("invalid reset schedule")
+
+// NoReset is the schedule of a category that only resets when asked to
+var NoReset = entities.ResetSchedule{Policy: ResetPolicyNone, Time: "00:00"}
+
 type UpdateGoalCategoryParams struct {
 	Title options.Option[string]
+	// Reset replaces the category's reset schedule, which first fires at its next boundary
+	Reset options.Option[entities.ResetSchedule]
 }
 
 type (
@@ -22,6 +45,7 @@ type (
 		CreateGoalCategory(
 			title string,
 			userID uuid.UUID,
+			reset entities.ResetSchedule,
 		) (*entities.GoalCategory, error)
 		GetGoalCategoriesByUserID(userID uuid.UUID) ([]*entities.GoalCategory, error)
 		GetGoalCategoryByID(categoryID, userID uuid.UUID) (*entities.GoalCategory, error)
@@ -30,12 +54,76 @@ type (
 			params UpdateGoalCategoryParams,
 		) (*entities.GoalCategory, error)
 		DeleteGoalCategoryByID(categoryID, userID uuid.UUID) error
+		ResetDueCategories(limit int) ([]*entities.CategoryReset, error)
+		BreakLapsedStreaks() (int64, error)
 	}
 	goalCategoryStore struct {
+		pool    *pgxpool.Pool
 		queries *sqlcdb.Queries
 	}
 )
 
+// resetScheduleParams holds a reset schedule in the columns' types
+type resetScheduleParams struct {
+	policy  sqlcdb.CategoryResetPolicy
+	time    pgtype.Time
+	weekday pgtype.Int2
+}
+
+func resetScheduleToPgx(reset entities.ResetSchedule) (resetScheduleParams, error) {
+	params := resetScheduleParams{policy: sqlcdb.CategoryResetPolicy(reset.Policy)}
+	switch reset.Policy {
+	case ResetPolicyNone, ResetPolicyDaily, ResetPolicyWeekly:
+	default:
+		return params, fmt.Errorf("%w: unknown policy %q", ErrInvalidResetSchedule, reset.Policy)
+	}
+	if (reset.Policy == ResetPolicyWeekly) != reset.Weekday.IsPresent() {
+		return params, fmt.Errorf("%w: only weekly resets have a weekday", ErrInvalidResetSchedule)
+	}
+
+	t, err := time.Parse(ResetTimeLayout, reset.Time)
+	if err != nil {
+		return params, fmt.Errorf("%w: time must be HH:MM", ErrInvalidResetSchedule)
+	}
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	params.time = pgtype.Time{Microseconds: sinceMidnight.Microseconds(), Valid: true}
+
+	if name, ok := reset.Weekday.GetVal(); ok {
+		day, ok := weekdayNumber(name)
+		if !ok {
+			return params, fmt.Errorf("%w: unknown weekday %q", ErrInvalidResetSchedule, name)
+		}
+		params.weekday = pgtype.Int2{Int16: int16(day), Valid: true}
+	}
+	return params, nil
+}
+
+func pgxResetScheduleToEntity(
+	policy sqlcdb.CategoryResetPolicy,
+	resetTime pgtype.Time,
+	weekday pgtype.Int2,
+) entities.ResetSchedule {
+	sinceMidnight := time.Duration(resetTime.Microseconds) * time.Microsecond
+	reset := entities.ResetSchedule{
+		Policy: string(policy),
+		Time:   time.Time{}.Add(sinceMidnight).Format(ResetTimeLayout),
+	}
+	if weekday.Valid {
+		reset.Weekday = options.Some(strings.ToLower(time.Weekday(weekday.Int16).String()))
+	}
+	return reset
+}
+
+// weekdayNumber reads a day name in any case, counting from Sunday as 0 like postgres does
+func weekdayNumber(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
 // Helper function to convert sqlc GoalCategory to entity GoalCategory
 func pgxGoalCategoryToEntity(gc sqlcdb.GoalCategory) *entities.GoalCategory {
 	return &entities.GoalCategory{
@@ -45,6 +133,8 @@ func pgxGoalCategoryToEntity(gc sqlcdb.GoalCategory) *entities.GoalCategory {
 		CreatedAt: gc.CreatedAt.Time,
 		UpdatedAt: gc.UpdatedAt.Time,
 		Goals:     []*entities.Goal{}, // Initialize empty slice
+		Reset:     pgxResetScheduleToEntity(gc.ResetPolicy, gc.ResetTime, gc.ResetWeekday),
+		Streak:    pgxStreakToEntity(gc.CurrentStreak, gc.LongestStreak, gc.LastCompletedDate),
 	}
 }
 
@@ -67,6 +157,16 @@ func mapGoalCategoryWithGoalsRows(
 				CreatedAt: row.CreatedAt.Time,
 				UpdatedAt: row.UpdatedAt.Time,
 				Goals:     []*entities.Goal{},
+				Reset: pgxResetScheduleToEntity(
+					row.ResetPolicy,
+					row.ResetTime,
+					row.ResetWeekday,
+				),
+				Streak: pgxStreakToEntity(
+					row.CurrentStreak,
+					row.LongestStreak,
+					row.LastCompletedDate,
+				),
 			}
 			categoryMap[categoryID] = gc
 			categorySlice = append(categorySlice, gc)
@@ -83,6 +183,11 @@ func mapGoalCategoryWithGoalsRows(
 				UserID:      uuid.UUID(row.UserID.Bytes),
 				CreatedAt:   row.GoalCreatedAt.Time,
 				UpdatedAt:   row.GoalUpdatedAt.Time,
+				Streak: pgxStreakToEntity(
+					row.GoalCurrentStreak.Int32,
+					row.GoalLongestStreak.Int32,
+					row.GoalLastCompletedDate,
+				),
 			}
 			categoryMap[categoryID].Goals = append(categoryMap[categoryID].Goals, goal)
 		}
@@ -107,6 +212,16 @@ func mapGoalCategoryWithGoalsSingleRow(
 		CreatedAt: firstRow.CreatedAt.Time,
 		UpdatedAt: firstRow.UpdatedAt.Time,
 		Goals:     []*entities.Goal{},
+		Reset: pgxResetScheduleToEntity(
+			firstRow.ResetPolicy,
+			firstRow.ResetTime,
+			firstRow.ResetWeekday,
+		),
+		Streak: pgxStreakToEntity(
+			firstRow.CurrentStreak,
+			firstRow.LongestStreak,
+			firstRow.LastCompletedDate,
+		),
 	}
 
 	for _, row := range rows {
@@ -120,6 +235,11 @@ func mapGoalCategoryWithGoalsSingleRow(
 				UserID:      uuid.UUID(row.UserID.Bytes),
 				CreatedAt:   row.GoalCreatedAt.Time,
 				UpdatedAt:   row.GoalUpdatedAt.Time,
+				Streak: pgxStreakToEntity(
+					row.GoalCurrentStreak.Int32,
+					row.GoalLongestStreak.Int32,
+					row.GoalLastCompletedDate,
+				),
 			}
 			gc.Goals = append(gc.Goals, goal)
 		}
@@ -128,8 +248,9 @@ func mapGoalCategoryWithGoalsSingleRow(
 	return gc, nil
 }
 
-func NewGoalCategoryStore(queries *sqlcdb.Queries) GoalCategoryStore {
+func NewGoalCategoryStore(pool *pgxpool.Pool, queries *sqlcdb.Queries) GoalCategoryStore {
 	return &goalCategoryStore{
+		pool:    pool,
 		queries: queries,
 	}
 }
@@ -137,10 +258,19 @@ func NewGoalCategoryStore(queries *sqlcdb.Queries) GoalCategoryStore {
 func (s *goalCategoryStore) CreateGoalCategory(
 	title string,
 	userID uuid.UUID,
+	reset entities.ResetSchedule,
 ) (*entities.GoalCategory, error) {
+	schedule, err := resetScheduleToPgx(reset)
+	if err != nil {
+		return nil, err
+	}
+
 	params := sqlcdb.CreateGoalCategoryParams{
-		Title:  title,
-		UserID: db.UUIDToPgxUUID(userID),
+		Title:        title,
+		UserID:       db.UUIDToPgxUUID(userID),
+		ResetPolicy:  schedule.policy,
+		ResetTime:    schedule.time,
+		ResetWeekday: schedule.weekday,
 	}
 
 	gc, err := s.queries.CreateGoalCategory(context.Background(), params)
@@ -192,7 +322,32 @@ func (s *goalCategoryStore) UpdateGoalCategoryByID(
 
 	sqlcParams.Title = db.OptionStringToPgxText(params.Title)
 
-	gc, err := s.queries.UpdateGoalCategoryById(context.Background(), sqlcParams)
+	var gc sqlcdb.GoalCategory
+	err := db.WithTx(context.Background(), s.pool, func(q *sqlcdb.Queries) error {
+		ctx := context.Background()
+		var err error
+		gc, err = q.UpdateGoalCategoryById(ctx, sqlcParams)
+		if err != nil {
+			return err
+		}
+
+		reset, ok := params.Reset.GetVal()
+		if !ok {
+			return nil
+		}
+		schedule, err := resetScheduleToPgx(reset)
+		if err != nil {
+			return err
+		}
+		gc, err = q.SetGoalCategoryReset(ctx, sqlcdb.SetGoalCategoryResetParams{
+			ID:           sqlcParams.ID,
+			UserID:       sqlcParams.UserID,
+			ResetPolicy:  schedule.policy,
+			ResetTime:    schedule.time,
+			ResetWeekday: schedule.weekday,
+		})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -216,3 +371,80 @@ func (s *goalCategoryStore) DeleteGoalCategoryByID(categoryID, userID uuid.UUID)
 	}
 	return nil
 }
+
+// ResetDueCategories resets the goals of up to limit categories whose scheduled reset has come,
+// in one transaction. A category that missed several boundaries, say while the app was down, is
+// reset once for the latest of them.
+func (s *goalCategoryStore) ResetDueCategories(limit int) ([]*entities.CategoryReset, error) {
+	var resets []*entities.CategoryReset
+	err := db.WithTx(context.Background(), s.pool, func(q *sqlcdb.Queries) error {
+		ctx := context.Background()
+		rows, err := q.GetDueCategoryResets(ctx, int32(limit))
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			err := q.ResetGoalsByCategory(ctx, sqlcdb.ResetGoalsByCategoryParams{
+				CategoryID: row.ID,
+				UserID:     row.UserID,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = q.SetGoalCategoryResetThrough(ctx, sqlcdb.SetGoalCategoryResetThroughParams{
+				ID:           row.ID,
+				ResetThrough: row.Boundary,
+			})
+			if err != nil {
+				return err
+			}
+
+			resets = append(resets, &entities.CategoryReset{
+				CategoryID: uuid.UUID(row.ID.Bytes),
+				UserID:     uuid.UUID(row.UserID.Bytes),
+				ResetAt:    row.Boundary.Time,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resets, nil
+}
+
+// BreakLapsedStreaks breaks the streaks of categories that have missed a day their user's streak
+// freezes can't bridge, returning how many broke. A category that fails doesn't hold up the rest;
+// its error is joined into the one returned.
+func (s *goalCategoryStore) BreakLapsedStreaks() (int64, error) {
+	ctx := context.Background()
+	rows, err := s.queries.GetLapsedCategoryStreaks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var broken int64
+	var errs []error
+	for _, row := range rows {
+		err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+			locked, err := q.LockGoalCategoriesByIds(ctx, sqlcdb.LockGoalCategoriesByIdsParams{
+				Ids:    []pgtype.UUID{row.ID},
+				UserID: row.UserID,
+			})
+			if err != nil || len(locked) == 0 {
+				return err
+			}
+			broke, err := checkCategoryStreak(ctx, q, locked[0], row.Today.Time)
+			if broke {
+				broken++
+			}
+			return err
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return broken, errors.Join(errs...)
+}
@@ -3,43 +3,70 @@ package stores
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"goalify/internal/entities"
 	"goalify/pkg/options"
+	"goalify/pkg/recurrence"
+	"time"
 
 	db "goalify/internal/db"
 	sqlcdb "goalify/internal/db/generated"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrRecurrenceNeverOccurs is returned for a recurrence rule with no occurrence from today on
+var ErrRecurrenceNeverOccurs = errors.New("recurrence never occurs")
+
 type UpdateGoalParams struct {
 	Title       options.Option[string]
 	Description options.Option[string]
 	Status      options.Option[string]
 	CategoryID  options.Option[uuid.UUID]
+	// Recurrence starts the goal repeating, or over on a new rule, from today. ClearRecurrence
+	// stops it repeating.
+	Recurrence      options.Option[recurrence.Rule]
+	ClearRecurrence bool
 }
 
 type GoalStore interface {
-	CreateGoal(title, description string, userID, categoryID uuid.UUID) (*entities.Goal, error)
+	CreateGoal(
+		title, description string,
+		userID, categoryID uuid.UUID,
+		rule options.Option[recurrence.Rule],
+	) (*entities.Goal, error)
 	UpdateGoalStatus(goalID, userID uuid.UUID, status string) (*entities.Goal, error)
 	GetGoalsByUserID(userID uuid.UUID) ([]*entities.Goal, error)
 	GetGoalByID(goalID, userID uuid.UUID) (*entities.Goal, error)
 	UpdateGoalByID(
 		goalID, userID uuid.UUID,
 		params UpdateGoalParams,
-	) (*entities.Goal, error)
+	) (*entities.Goal, *entities.GoalCompletion, error)
 	DeleteGoalByID(goalID, userID uuid.UUID) error
 	ResetGoalsByCategoryID(categoryID, userID uuid.UUID) error
+
+	RefreshGoalOccurrence(goalID, userID uuid.UUID) (*entities.Goal, error)
+	ReopenDueGoals(limit int) ([]*entities.Goal, error)
+	BreakLapsedStreaks() (int64, error)
+	GetGoalCompletions(goalID, userID uuid.UUID) ([]*entities.GoalCompletion, error)
+	GetCompletionsByUserID(
+		userID uuid.UUID,
+		from, to options.Option[time.Time],
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.GoalCompletion, error)
 }
 
 type goalStore struct {
+	pool    *pgxpool.Pool
 	queries *sqlcdb.Queries
 }
 
 // Helper function to convert sqlc Goal to entity Goal
 func pgxGoalToEntity(g sqlcdb.Goal) *entities.Goal {
-	return &entities.Goal{
+	goal := &entities.Goal{
 		ID:          uuid.UUID(g.ID.Bytes),
 		Title:       g.Title,
 		Description: g.Description.String,
@@ -48,19 +75,125 @@ func pgxGoalToEntity(g sqlcdb.Goal) *entities.Goal {
 		Status:      string(g.Status.GoalStatus),
 		CreatedAt:   g.CreatedAt.Time,
 		UpdatedAt:   g.UpdatedAt.Time,
+
+		OccurrenceDate:     db.PgxDateToOptionString(g.OccurrenceDate),
+		NextOccurrenceDate: db.PgxDateToOptionString(g.NextOccurrenceDate),
+		Streak: pgxStreakToEntity(
+			g.CurrentStreak,
+			g.LongestStreak,
+			g.LastCompletedDate,
+		),
+	}
+	if g.Recurrence.Valid {
+		goal.Recurrence = options.Some(g.Recurrence.String)
+	}
+	return goal
+}
+
+func pgxGoalCompletionToEntity(gc sqlcdb.GoalCompletion) *entities.GoalCompletion {
+	completion := &entities.GoalCompletion{
+		ID:             gc.ID,
+		GoalID:         uuid.UUID(gc.GoalID.Bytes),
+		UserID:         uuid.UUID(gc.UserID.Bytes),
+		OccurrenceDate: db.PgxDateToOptionString(gc.OccurrenceDate),
+		PeriodDate:     gc.PeriodDate.Time.Format(time.DateOnly),
+		XpGranted:      int(gc.XpGranted),
+		GoalStreak:     int(gc.GoalStreak),
+		CategoryStreak: int(gc.CategoryStreak),
+		CompletedAt:    gc.CompletedAt.Time,
+	}
+	if gc.CategoryID.Valid {
+		completion.CategoryID = options.Some(uuid.UUID(gc.CategoryID.Bytes))
 	}
+	if gc.UncompletedAt.Valid {
+		completion.UncompletedAt = options.Some(gc.UncompletedAt.Time)
+	}
+	return completion
 }
 
-func NewGoalStore(queries *sqlcdb.Queries) GoalStore {
+func NewGoalStore(pool *pgxpool.Pool, queries *sqlcdb.Queries) GoalStore {
 	return &goalStore{
+		pool:    pool,
 		queries: queries,
 	}
 }
 
+// goalSchedule is where a recurring goal stands on a given day
+type goalSchedule struct {
+	occurrence pgtype.Date
+	next       pgtype.Date
+}
+
+// scheduleOn finds the occurrence a goal repeating on rule from start is on as of today: the
+// latest one so far, or the first one while it is still to come
+func scheduleOn(rule recurrence.Rule, start, today time.Time) (goalSchedule, error) {
+	occurrence, ok := rule.Latest(start, today)
+	if !ok {
+		occurrence, ok = rule.Next(start, start.AddDate(0, 0, -1))
+	}
+	if !ok {
+		return goalSchedule{}, ErrRecurrenceNeverOccurs
+	}
+
+	schedule := goalSchedule{occurrence: db.TimeToPgxDate(occurrence)}
+	if next, ok := rule.Next(start, occurrence); ok {
+		schedule.next = db.TimeToPgxDate(next)
+	}
+	return schedule, nil
+}
+
+// isDue reports whether the goal's next occurrence has arrived by today
+func isDue(goal sqlcdb.Goal, today pgtype.Date) bool {
+	return goal.NextOccurrenceDate.Valid && !goal.NextOccurrenceDate.Time.After(today.Time)
+}
+
+// advanceOccurrence moves a due goal on to its latest occurrence and reopens it, leaving the
+// completion of the occurrence it leaves behind in place and breaking the goal's streak when it
+// skipped occurrences the user's streak freezes can't bridge. Goals that aren't due come back as
+// they are. It returns sql.ErrNoRows when the goal has already been moved on by someone else. Run
+// it in a transaction, so a freeze is only used up when the goal moves.
+func advanceOccurrence(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	goal sqlcdb.Goal,
+	today pgtype.Date,
+) (sqlcdb.Goal, error) {
+	if !isDue(goal, today) {
+		return goal, nil
+	}
+
+	rule, err := recurrence.Parse(goal.Recurrence.String)
+	if err != nil {
+		return sqlcdb.Goal{}, err
+	}
+	schedule, err := scheduleOn(rule, goal.RecurrenceStart.Time, today.Time)
+	if err != nil {
+		return sqlcdb.Goal{}, err
+	}
+
+	// the goal is moved, and so locked, before its streak is checked, the same order a
+	// completion locks the goal and then the user's streak freezes in
+	advanced, err := q.AdvanceGoalOccurrence(ctx, sqlcdb.AdvanceGoalOccurrenceParams{
+		ID:                 goal.ID,
+		DueDate:            goal.NextOccurrenceDate,
+		OccurrenceDate:     schedule.occurrence,
+		NextOccurrenceDate: schedule.next,
+	})
+	if err != nil {
+		return sqlcdb.Goal{}, err
+	}
+	if _, err := checkGoalStreak(ctx, q, &advanced, schedule.occurrence.Time); err != nil {
+		return sqlcdb.Goal{}, err
+	}
+	return advanced, nil
+}
+
 func (s *goalStore) CreateGoal(
 	title, description string,
 	userID, categoryID uuid.UUID,
+	rule options.Option[recurrence.Rule],
 ) (*entities.Goal, error) {
+	ctx := context.Background()
 	params := sqlcdb.CreateGoalParams{
 		Title:       title,
 		Description: pgtype.Text{String: description, Valid: true},
@@ -68,7 +201,22 @@ func (s *goalStore) CreateGoal(
 		CategoryID:  pgtype.UUID{Bytes: categoryID, Valid: true},
 	}
 
-	goal, err := s.queries.CreateGoal(context.Background(), params)
+	if rule, ok := rule.GetVal(); ok {
+		today, err := s.queries.GetUserLocalDate(ctx, params.UserID)
+		if err != nil {
+			return nil, err
+		}
+		schedule, err := scheduleOn(rule, today.Time, today.Time)
+		if err != nil {
+			return nil, err
+		}
+		params.Recurrence = db.StringToPgxText(rule.String())
+		params.RecurrenceStart = today
+		params.OccurrenceDate = schedule.occurrence
+		params.NextOccurrenceDate = schedule.next
+	}
+
+	goal, err := s.queries.CreateGoal(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -126,33 +274,155 @@ func (s *goalStore) GetGoalByID(goalID, userID uuid.UUID) (*entities.Goal, error
 	return pgxGoalToEntity(goal), nil
 }
 
+// UpdateGoalByID applies the updates to the goal. A recurring goal that has come due is moved on
+// to its current occurrence first, so a status change lands on the right occurrence. Completing
+// the goal records a completion and un-completing it marks its latest completion undone; that
+// completion is returned alongside the goal, and is nil when the status didn't change.
 func (s *goalStore) UpdateGoalByID(
 	goalID uuid.UUID,
 	userID uuid.UUID,
 	params UpdateGoalParams,
-) (*entities.Goal, error) {
-	sqlcParams := sqlcdb.UpdateGoalByIdParams{
-		ID:     db.UUIDToPgxUUID(goalID),
-		UserID: db.UUIDToPgxUUID(userID),
-	}
+) (*entities.Goal, *entities.GoalCompletion, error) {
+	ctx := context.Background()
+	var updated sqlcdb.Goal
+	var completion *entities.GoalCompletion
 
-	sqlcParams.Title = db.OptionStringToPgxText(params.Title)
-	sqlcParams.Description = db.OptionStringToPgxText(params.Description)
-	sqlcParams.CategoryID = db.OptionUUIDToPgxUUID(params.CategoryID)
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		pgGoalID, pgUserID := db.UUIDToPgxUUID(goalID), db.UUIDToPgxUUID(userID)
+		// a status change moves category streaks, so the categories are locked first, before
+		// the goal, the same order resets lock them in
+		categories := make(map[pgtype.UUID]sqlcdb.GoalCategory)
+		if params.Status.IsPresent() {
+			current, err := q.GetGoalById(ctx, sqlcdb.GetGoalByIdParams{
+				ID:     pgGoalID,
+				UserID: pgUserID,
+			})
+			if err != nil {
+				return err
+			}
+			ids := []pgtype.UUID{current.CategoryID}
+			if categoryID, ok := params.CategoryID.GetVal(); ok {
+				ids = append(ids, db.UUIDToPgxUUID(categoryID))
+			}
+			if err := lockCategories(ctx, q, pgUserID, categories, ids...); err != nil {
+				return err
+			}
+		}
 
-	if params.Status.IsPresent() {
-		sqlcParams.Status = sqlcdb.NullGoalStatus{
-			GoalStatus: sqlcdb.GoalStatus(params.Status.ValueOrZero()),
-			Valid:      true,
+		goal, err := q.GetGoalByIdForUpdate(ctx, sqlcdb.GetGoalByIdForUpdateParams{
+			ID:     pgGoalID,
+			UserID: pgUserID,
+		})
+		if err != nil {
+			return err
+		}
+		today, err := q.GetUserLocalDate(ctx, pgUserID)
+		if err != nil {
+			return err
 		}
-	}
 
-	goal, err := s.queries.UpdateGoalById(context.Background(), sqlcParams)
+		recurrenceParams := sqlcdb.SetGoalRecurrenceParams{ID: pgGoalID, UserID: pgUserID}
+		if rule, ok := params.Recurrence.GetVal(); ok {
+			schedule, err := scheduleOn(rule, today.Time, today.Time)
+			if err != nil {
+				return err
+			}
+			recurrenceParams.Recurrence = db.StringToPgxText(rule.String())
+			recurrenceParams.RecurrenceStart = today
+			recurrenceParams.OccurrenceDate = schedule.occurrence
+			recurrenceParams.NextOccurrenceDate = schedule.next
+		}
+		if params.Recurrence.IsPresent() || params.ClearRecurrence {
+			goal, err = q.SetGoalRecurrence(ctx, recurrenceParams)
+		} else {
+			goal, err = advanceOccurrence(ctx, q, goal, today)
+		}
+		if err != nil {
+			return err
+		}
+
+		sqlcParams := sqlcdb.UpdateGoalByIdParams{
+			ID:          pgGoalID,
+			UserID:      pgUserID,
+			Title:       db.OptionStringToPgxText(params.Title),
+			Description: db.OptionStringToPgxText(params.Description),
+			CategoryID:  db.OptionUUIDToPgxUUID(params.CategoryID),
+		}
+		if params.Status.IsPresent() {
+			sqlcParams.Status = sqlcdb.NullGoalStatus{
+				GoalStatus: sqlcdb.GoalStatus(params.Status.ValueOrZero()),
+				Valid:      true,
+			}
+		}
+
+		updated, err = q.UpdateGoalById(ctx, sqlcParams)
+		if err != nil {
+			return err
+		}
+
+		if updated.Status == goal.Status {
+			return nil
+		}
+		if updated.Status.GoalStatus == sqlcdb.GoalStatusComplete {
+			// a recurring goal's period is its occurrence, any other goal's the user's day
+			period := today
+			if updated.OccurrenceDate.Valid {
+				period = updated.OccurrenceDate
+			}
+			category, err := lockCategory(ctx, q, pgUserID, categories, updated.CategoryID)
+			if err != nil {
+				return err
+			}
+			goalStreak, categoryStreak, milestones, err := recordStreaks(
+				ctx,
+				q,
+				&updated,
+				category,
+				period.Time,
+			)
+			if err != nil {
+				return err
+			}
+			row, err := q.CreateGoalCompletion(ctx, sqlcdb.CreateGoalCompletionParams{
+				GoalID:         pgGoalID,
+				UserID:         pgUserID,
+				CategoryID:     updated.CategoryID,
+				OccurrenceDate: updated.OccurrenceDate,
+				PeriodDate:     period,
+				GoalStreak:     int32(goalStreak),
+				CategoryStreak: int32(categoryStreak),
+			})
+			if err != nil {
+				return err
+			}
+			completion = pgxGoalCompletionToEntity(row)
+			completion.Milestones = milestones
+			return nil
+		}
+
+		row, err := q.UncompleteGoalCompletion(ctx, pgGoalID)
+		// goals completed before completions were recorded have none to undo
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		category, err := lockCategory(ctx, q, pgUserID, categories, row.CategoryID)
+		if err != nil {
+			return err
+		}
+		if err := revertStreaks(ctx, q, &updated, category, row); err != nil {
+			return err
+		}
+		completion = pgxGoalCompletionToEntity(row)
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return pgxGoalToEntity(goal), nil
+	return pgxGoalToEntity(updated), completion, nil
 }
 
 func (s *goalStore) DeleteGoalByID(goalID, userID uuid.UUID) error {
@@ -178,3 +448,160 @@ func (s *goalStore) ResetGoalsByCategoryID(categoryID, userID uuid.UUID) error {
 			UserID:     db.UUIDToPgxUUID(userID),
 		})
 }
+
+// RefreshGoalOccurrence returns the goal after moving it on to its current occurrence if it has
+// come due
+func (s *goalStore) RefreshGoalOccurrence(goalID, userID uuid.UUID) (*entities.Goal, error) {
+	ctx := context.Background()
+	var refreshed sqlcdb.Goal
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		pgUserID := db.UUIDToPgxUUID(userID)
+		goal, err := q.GetGoalByIdForUpdate(ctx, sqlcdb.GetGoalByIdForUpdateParams{
+			ID:     db.UUIDToPgxUUID(goalID),
+			UserID: pgUserID,
+		})
+		if err != nil {
+			return err
+		}
+		today, err := q.GetUserLocalDate(ctx, pgUserID)
+		if err != nil {
+			return err
+		}
+
+		refreshed, err = advanceOccurrence(ctx, q, goal, today)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pgxGoalToEntity(refreshed), nil
+}
+
+// ReopenDueGoals moves up to limit recurring goals that have come due on to their current
+// occurrence, returning the goals it moved. Each goal moves with a single conditional update, so
+// instances running this at once never move a goal twice. A goal that fails doesn't hold up the
+// rest; its error is joined into the one returned.
+func (s *goalStore) ReopenDueGoals(limit int) ([]*entities.Goal, error) {
+	ctx := context.Background()
+	rows, err := s.queries.GetDueRecurringGoals(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var reopened []*entities.Goal
+	var errs []error
+	for _, row := range rows {
+		var goal sqlcdb.Goal
+		err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+			var err error
+			goal, err = advanceOccurrence(ctx, q, row.Goal, row.Today)
+			return err
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		reopened = append(reopened, pgxGoalToEntity(goal))
+	}
+	return reopened, errors.Join(errs...)
+}
+
+// BreakLapsedStreaks breaks the streaks of goals counted in days that have missed a day their
+// user's streak freezes can't bridge, returning how many broke. Recurring goals break theirs as
+// they reopen. A goal that fails doesn't hold up the rest; its error is joined into the one
+// returned.
+func (s *goalStore) BreakLapsedStreaks() (int64, error) {
+	ctx := context.Background()
+	rows, err := s.queries.GetLapsedGoalStreaks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var broken int64
+	var errs []error
+	for _, row := range rows {
+		err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+			goal, err := q.GetGoalByIdForUpdate(ctx, sqlcdb.GetGoalByIdForUpdateParams{
+				ID:     row.ID,
+				UserID: row.UserID,
+			})
+			// a goal deleted or made recurring since has nothing to break here
+			if errors.Is(err, sql.ErrNoRows) || goal.Recurrence.Valid {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			broke, err := checkGoalStreak(ctx, q, &goal, row.Today.Time)
+			if broke {
+				broken++
+			}
+			return err
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return broken, errors.Join(errs...)
+}
+
+// GetGoalCompletions returns every completion of the user's goal, latest first
+func (s *goalStore) GetGoalCompletions(
+	goalID, userID uuid.UUID,
+) ([]*entities.GoalCompletion, error) {
+	rows, err := s.queries.GetGoalCompletionsByGoalId(
+		context.Background(),
+		sqlcdb.GetGoalCompletionsByGoalIdParams{
+			GoalID: db.UUIDToPgxUUID(goalID),
+			UserID: db.UUIDToPgxUUID(userID),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	completions := make([]*entities.GoalCompletion, len(rows))
+	for i, row := range rows {
+		completions[i] = pgxGoalCompletionToEntity(row)
+	}
+	return completions, nil
+}
+
+// GetCompletionsByUserID returns a page of the user's completions of any goal, latest first,
+// completed from from up to but not including to
+func (s *goalStore) GetCompletionsByUserID(
+	userID uuid.UUID,
+	from, to options.Option[time.Time],
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.GoalCompletion, error) {
+	params := sqlcdb.GetGoalCompletionsByUserIdParams{
+		UserID:   db.UUIDToPgxUUID(userID),
+		PageSize: int32(limit),
+	}
+	if t, ok := from.GetVal(); ok {
+		params.From = pgtype.Timestamp{Time: t.UTC(), Valid: true}
+	}
+	if t, ok := to.GetVal(); ok {
+		params.To = pgtype.Timestamp{Time: t.UTC(), Valid: true}
+	}
+	if id, ok := cursor.GetVal(); ok {
+		params.Cursor = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	rows, err := s.queries.GetGoalCompletionsByUserId(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	completions := make([]*entities.GoalCompletion, len(rows))
+	for i, row := range rows {
+		completions[i] = pgxGoalCompletionToEntity(row)
+	}
+	return completions, nil
+}
@@ -7,12 +7,18 @@ import (
 	"goalify/internal/entities"
 	"goalify/internal/testsetup"
 	"goalify/pkg/options"
+	"goalify/pkg/recurrence"
 	"log"
 	"os"
 	"slices"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 
 	sqlcdb "goalify/internal/db/generated"
@@ -22,10 +28,13 @@ import (
 
 const password = "Password123!"
 
+var noRecurrence = options.None[recurrence.Rule]()
+
 var (
 	userStore   us.UserStore
 	gStore      GoalStore
 	gcStore     GoalCategoryStore
+	pool        *pgxpool.Pool
 	pgContainer *postgres.PostgresContainer
 )
 
@@ -48,9 +57,10 @@ func setup(ctx context.Context) {
 	}
 
 	queries := sqlcdb.New(pgxPool)
-	userStore = us.NewUserStore(queries)
-	gStore = NewGoalStore(queries)
-	gcStore = NewGoalCategoryStore(queries)
+	userStore = us.NewUserStore(pgxPool, queries)
+	pool = pgxPool
+	gStore = NewGoalStore(pgxPool, queries)
+	gcStore = NewGoalCategoryStore(pgxPool, queries)
 }
 
 func TestMain(m *testing.M) {
@@ -71,7 +81,7 @@ func TestCreateCategory(t *testing.T) {
 	t.Parallel()
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
-	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID)
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
 	assert.NoError(t, err)
 	assert.Equal(t, t.Name(), category.Title)
 	assert.Equal(t, user.ID, category.UserID)
@@ -82,10 +92,10 @@ func TestGetGoalCategoriesByUser(t *testing.T) {
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
 
-	category1, _ := gcStore.CreateGoalCategory(t.Name()+"1", user.ID)
-	category2, _ := gcStore.CreateGoalCategory(t.Name()+"2", user.ID)
-	category3, _ := gcStore.CreateGoalCategory(t.Name()+"3", user.ID)
-	category4, _ := gcStore.CreateGoalCategory(t.Name()+"4", user.ID)
+	category1, _ := gcStore.CreateGoalCategory(t.Name()+"1", user.ID, NoReset)
+	category2, _ := gcStore.CreateGoalCategory(t.Name()+"2", user.ID, NoReset)
+	category3, _ := gcStore.CreateGoalCategory(t.Name()+"3", user.ID, NoReset)
+	category4, _ := gcStore.CreateGoalCategory(t.Name()+"4", user.ID, NoReset)
 
 	ids := []string{
 		category1.ID.String(),
@@ -111,7 +121,7 @@ func TestGetGoalCategoryById(t *testing.T) {
 	t.Parallel()
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
-	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID)
+	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
 
 	foundCategory, err := gcStore.GetGoalCategoryByID(category.ID, user.ID)
 	assert.NoError(t, err)
@@ -124,7 +134,7 @@ func TestUpdateGoalCategoryById(t *testing.T) {
 	t.Parallel()
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
-	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID)
+	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
 
 	params := UpdateGoalCategoryParams{
 		Title: options.Some("new title"),
@@ -138,7 +148,7 @@ func TestDeleteGoalCategoryById(t *testing.T) {
 	t.Parallel()
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
-	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID)
+	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
 
 	_, err = gcStore.GetGoalCategoryByID(category.ID, user.ID)
 	assert.NoError(t, err)
@@ -154,10 +164,10 @@ func TestCreateGoal(t *testing.T) {
 	t.Parallel()
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
-	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID)
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
 	assert.NoError(t, err)
 
-	goal, err := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID)
+	goal, err := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID, noRecurrence)
 	assert.NoError(t, err)
 	assert.Equal(t, t.Name(), goal.Title)
 	assert.Equal(t, category.ID, goal.CategoryID)
@@ -169,8 +179,8 @@ func TestGetGoalById(t *testing.T) {
 	t.Parallel()
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
-	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID)
-	goal, _ := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID)
+	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
+	goal, _ := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID, noRecurrence)
 
 	foundGoal, err := gStore.GetGoalByID(goal.ID, user.ID)
 	assert.NoError(t, err)
@@ -185,14 +195,14 @@ func TestUpdateGoalById(t *testing.T) {
 	t.Parallel()
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
-	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID)
-	goal, _ := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID)
+	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
+	goal, _ := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID, noRecurrence)
 
 	params := UpdateGoalParams{
 		Title:       options.Some("new title"),
 		Description: options.Some("new desc"),
 	}
-	updated, err := gStore.UpdateGoalByID(goal.ID, user.ID, params)
+	updated, _, err := gStore.UpdateGoalByID(goal.ID, user.ID, params)
 	assert.NoError(t, err)
 	assert.Equal(t, "new title", updated.Title)
 	assert.Equal(t, "new desc", updated.Description)
@@ -202,8 +212,8 @@ func TestDeleteGoalById(t *testing.T) {
 	t.Parallel()
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
-	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID)
-	goal, _ := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID)
+	category, _ := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
+	goal, _ := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID, noRecurrence)
 
 	_, err = gStore.GetGoalByID(goal.ID, user.ID)
 	assert.NoError(t, err)
@@ -221,7 +231,7 @@ func TestResetGoalsByCategoryID(t *testing.T) {
 	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
 	assert.NoError(t, err)
 
-	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID)
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
 	assert.NoError(t, err)
 
 	// Create multiple goals for this category
@@ -234,6 +244,7 @@ func TestResetGoalsByCategoryID(t *testing.T) {
 			"desc",
 			user.ID,
 			category.ID,
+			noRecurrence,
 		)
 		assert.NoError(t, err)
 		goals[i] = goal
@@ -242,7 +253,7 @@ func TestResetGoalsByCategoryID(t *testing.T) {
 	// Mark all goals as complete
 	for i, goal := range goals {
 		var updatedGoal *entities.Goal
-		updatedGoal, err = gStore.UpdateGoalByID(
+		updatedGoal, _, err = gStore.UpdateGoalByID(
 			goal.ID,
 			user.ID,
 			UpdateGoalParams{Status: options.Some("complete")},
@@ -269,7 +280,7 @@ func TestResetGoalsByCategoryID(t *testing.T) {
 	// Mark all goals complete again
 	for i, goal := range goals {
 		var updatedGoal *entities.Goal
-		updatedGoal, err = gStore.UpdateGoalByID(
+		updatedGoal, _, err = gStore.UpdateGoalByID(
 			goal.ID,
 			user.ID,
 			UpdateGoalParams{Status: options.Some("complete")},
@@ -295,3 +306,615 @@ func TestResetGoalsByCategoryID(t *testing.T) {
 			"Goal %s should still be complete (unauthorized reset attempt)", goal.ID)
 	}
 }
+
+// moveBackADay makes a recurring goal look a day older, as if its current occurrence were
+// yesterday's and the next one today
+func moveBackADay(t *testing.T, goalID uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := pool.Exec(ctx, `
+		UPDATE goals
+		SET recurrence_start = recurrence_start - 1,
+			occurrence_date = occurrence_date - 1,
+			next_occurrence_date = occurrence_date
+		WHERE id = $1`, goalID)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		UPDATE goals SET last_completed_date = last_completed_date - 1 WHERE id = $1`, goalID)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		UPDATE goal_completions
+		SET occurrence_date = occurrence_date - 1,
+			period_date = period_date - 1
+		WHERE goal_id = $1`,
+		goalID)
+	require.NoError(t, err)
+}
+
+func TestRecurringGoalReopens(t *testing.T) {
+	t.Parallel()
+	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
+	require.NoError(t, err)
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
+	require.NoError(t, err)
+
+	goal, err := gStore.CreateGoal(
+		t.Name(),
+		"desc",
+		user.ID,
+		category.ID,
+		options.Some(recurrence.DailyRule()),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY", goal.Recurrence.ValueOrZero())
+	today := goal.OccurrenceDate.ValueOrZero()
+
+	complete := UpdateGoalParams{Status: options.Some("complete")}
+	goal, _, err = gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, "complete", goal.Status)
+
+	moveBackADay(t, goal.ID)
+	reopened, err := gStore.ReopenDueGoals(1000)
+	require.NoError(t, err)
+	idx := slices.IndexFunc(reopened, func(g *entities.Goal) bool { return g.ID == goal.ID })
+	require.NotEqual(t, -1, idx)
+	assert.Equal(t, "not_complete", reopened[idx].Status)
+	assert.Equal(t, today, reopened[idx].OccurrenceDate.ValueOrZero())
+
+	// a second pass finds nothing left to do for it
+	reopened, err = gStore.ReopenDueGoals(1000)
+	require.NoError(t, err)
+	assert.False(t, slices.ContainsFunc(reopened, func(g *entities.Goal) bool {
+		return g.ID == goal.ID
+	}))
+
+	_, _, err = gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	completions, err := gStore.GetGoalCompletions(goal.ID, user.ID)
+	require.NoError(t, err)
+	require.Len(t, completions, 2)
+	assert.Equal(t, today, completions[0].OccurrenceDate.ValueOrZero())
+
+	// un-completing only takes back the current occurrence, and keeps it in the history
+	_, undone, err := gStore.UpdateGoalByID(
+		goal.ID,
+		user.ID,
+		UpdateGoalParams{Status: options.Some("not_complete")},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, undone)
+	assert.Equal(t, completions[0].ID, undone.ID)
+	completions, err = gStore.GetGoalCompletions(goal.ID, user.ID)
+	require.NoError(t, err)
+	require.Len(t, completions, 2)
+	assert.True(t, completions[0].UncompletedAt.IsPresent())
+	assert.False(t, completions[1].UncompletedAt.IsPresent())
+	assert.NotEqual(t, today, completions[1].OccurrenceDate.ValueOrZero())
+}
+
+func TestUpdateDueRecurringGoal(t *testing.T) {
+	t.Parallel()
+	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
+	require.NoError(t, err)
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
+	require.NoError(t, err)
+	goal, err := gStore.CreateGoal(
+		t.Name(),
+		"desc",
+		user.ID,
+		category.ID,
+		options.Some(recurrence.DailyRule()),
+	)
+	require.NoError(t, err)
+
+	complete := UpdateGoalParams{Status: options.Some("complete")}
+	_, _, err = gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	moveBackADay(t, goal.ID)
+
+	// before any reopen pass, the goal is still marked done for yesterday
+	refreshed, err := gStore.RefreshGoalOccurrence(goal.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "not_complete", refreshed.Status)
+	assert.Equal(t, goal.OccurrenceDate, refreshed.OccurrenceDate)
+
+	updated, _, err := gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, "complete", updated.Status)
+	completions, err := gStore.GetGoalCompletions(goal.ID, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, completions, 2)
+}
+
+func TestChangeGoalRecurrence(t *testing.T) {
+	t.Parallel()
+	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
+	require.NoError(t, err)
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
+	require.NoError(t, err)
+	goal, err := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID, noRecurrence)
+	require.NoError(t, err)
+	assert.False(t, goal.Recurrence.IsPresent())
+
+	updated, _, err := gStore.UpdateGoalByID(goal.ID, user.ID, UpdateGoalParams{
+		Recurrence: options.Some(recurrence.EveryNDays(2)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;INTERVAL=2", updated.Recurrence.ValueOrZero())
+	occurrence, err := time.Parse(time.DateOnly, updated.OccurrenceDate.ValueOrZero())
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		occurrence.AddDate(0, 0, 2).Format(time.DateOnly),
+		updated.NextOccurrenceDate.ValueOrZero(),
+	)
+
+	updated, _, err = gStore.UpdateGoalByID(goal.ID, user.ID, UpdateGoalParams{
+		ClearRecurrence: true,
+	})
+	require.NoError(t, err)
+	assert.False(t, updated.Recurrence.IsPresent())
+	assert.False(t, updated.OccurrenceDate.IsPresent())
+	assert.False(t, updated.NextOccurrenceDate.IsPresent())
+}
+
+func TestCategoryResetBoundary(t *testing.T) {
+	// 2026-10-14 is a Wednesday
+	asOf := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	on := func(day time.Weekday) pgtype.Int2 { return pgtype.Int2{Int16: int16(day), Valid: true} }
+	tests := []struct {
+		name     string
+		policy   string
+		time     string
+		weekday  pgtype.Int2
+		tz       string
+		expected time.Time
+	}{
+		{
+			"daily",
+			"daily",
+			"00:00",
+			pgtype.Int2{},
+			"UTC",
+			time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"daily later on",
+			"daily",
+			"18:00",
+			pgtype.Int2{},
+			"UTC",
+			time.Date(2026, 10, 13, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			"daily behind utc",
+			"daily",
+			"00:00",
+			pgtype.Int2{},
+			"America/New_York",
+			time.Date(2026, 10, 14, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			"daily ahead of utc",
+			"daily",
+			"00:00",
+			pgtype.Int2{},
+			"Pacific/Kiritimati",
+			time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			"weekly",
+			"weekly",
+			"09:00",
+			on(time.Monday),
+			"UTC",
+			time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			"weekly later today",
+			"weekly",
+			"13:00",
+			on(time.Wednesday),
+			"UTC",
+			time.Date(2026, 10, 7, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			"weekly right now",
+			"weekly",
+			"12:00",
+			on(time.Wednesday),
+			"UTC",
+			asOf,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var boundary time.Time
+			err := pool.QueryRow(
+				context.Background(),
+				`SELECT category_reset_boundary($1::category_reset_policy, $2::TIME, $3, $4, $5)`,
+				tt.policy, tt.time, tt.weekday, tt.tz, asOf,
+			).Scan(&boundary)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, boundary.UTC())
+		})
+	}
+}
+
+func TestResetDueCategories(t *testing.T) {
+	t.Parallel()
+	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
+	require.NoError(t, err)
+	daily := entities.ResetSchedule{Policy: ResetPolicyDaily, Time: "00:00"}
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, daily)
+	require.NoError(t, err)
+	assert.Equal(t, daily, category.Reset)
+	goal, err := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID, noRecurrence)
+	require.NoError(t, err)
+	_, _, err = gStore.UpdateGoalByID(
+		goal.ID,
+		user.ID,
+		UpdateGoalParams{Status: options.Some("complete")},
+	)
+	require.NoError(t, err)
+
+	isCategory := func(r *entities.CategoryReset) bool { return r.CategoryID == category.ID }
+
+	// a new schedule waits for its next boundary
+	resets, err := gcStore.ResetDueCategories(1000)
+	require.NoError(t, err)
+	assert.False(t, slices.ContainsFunc(resets, isCategory))
+
+	_, err = pool.Exec(context.Background(), `
+		UPDATE goal_categories SET reset_through = reset_through - INTERVAL '1 day'
+		WHERE id = $1`, category.ID)
+	require.NoError(t, err)
+
+	resets, err = gcStore.ResetDueCategories(1000)
+	require.NoError(t, err)
+	idx := slices.IndexFunc(resets, isCategory)
+	require.NotEqual(t, -1, idx)
+	assert.Equal(t, user.ID, resets[idx].UserID)
+	assert.False(t, resets[idx].ResetAt.After(time.Now()))
+	fetched, err := gStore.GetGoalByID(goal.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "not_complete", fetched.Status)
+
+	// the boundary is only reset once
+	resets, err = gcStore.ResetDueCategories(1000)
+	require.NoError(t, err)
+	assert.False(t, slices.ContainsFunc(resets, isCategory))
+}
+
+func TestUpdateCategoryResetSchedule(t *testing.T) {
+	t.Parallel()
+	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
+	require.NoError(t, err)
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
+	require.NoError(t, err)
+	assert.Equal(t, NoReset, category.Reset)
+
+	weekly := entities.ResetSchedule{
+		Policy:  ResetPolicyWeekly,
+		Time:    "18:30",
+		Weekday: options.Some("sunday"),
+	}
+	updated, err := gcStore.UpdateGoalCategoryByID(
+		category.ID,
+		user.ID,
+		UpdateGoalCategoryParams{Reset: options.Some(weekly)},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, category.Title, updated.Title)
+	assert.Equal(t, weekly, updated.Reset)
+
+	fetched, err := gcStore.GetGoalCategoryByID(category.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, weekly, fetched.Reset)
+
+	noWeekday := entities.ResetSchedule{Policy: ResetPolicyWeekly, Time: "18:30"}
+	_, err = gcStore.UpdateGoalCategoryByID(
+		category.ID,
+		user.ID,
+		UpdateGoalCategoryParams{Reset: options.Some(noWeekday)},
+	)
+	assert.ErrorIs(t, err, ErrInvalidResetSchedule)
+}
+
+func TestGoalCompletionHistory(t *testing.T) {
+	t.Parallel()
+	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
+	require.NoError(t, err)
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
+	require.NoError(t, err)
+	other, err := gcStore.CreateGoalCategory(t.Name()+"2", user.ID, NoReset)
+	require.NoError(t, err)
+	goal, err := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID, noRecurrence)
+	require.NoError(t, err)
+
+	complete := UpdateGoalParams{Status: options.Some("complete")}
+	_, first, err := gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, goal.ID, first.GoalID)
+	assert.Equal(t, options.Some(category.ID), first.CategoryID)
+	assert.False(t, first.OccurrenceDate.IsPresent())
+
+	// an update that leaves the status alone records nothing
+	_, completion, err := gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Nil(t, completion)
+
+	// a reset leaves the completion standing
+	require.NoError(t, gStore.ResetGoalsByCategoryID(category.ID, user.ID))
+	_, second, err := gStore.UpdateGoalByID(goal.ID, user.ID, UpdateGoalParams{
+		Status:     options.Some("complete"),
+		CategoryID: options.Some(other.ID),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	assert.Equal(t, options.Some(other.ID), second.CategoryID)
+
+	completions, err := gStore.GetGoalCompletions(goal.ID, user.ID)
+	require.NoError(t, err)
+	require.Len(t, completions, 2)
+	assert.Equal(t, second.ID, completions[0].ID)
+	assert.Equal(t, first.ID, completions[1].ID)
+	assert.False(t, completions[1].UncompletedAt.IsPresent())
+
+	noTime := options.None[time.Time]()
+	page, err := gStore.GetCompletionsByUserID(user.ID, noTime, noTime, options.None[int64](), 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, second.ID, page[0].ID)
+	page, err = gStore.GetCompletionsByUserID(
+		user.ID,
+		noTime,
+		noTime,
+		options.Some(page[0].ID),
+		10,
+	)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, first.ID, page[0].ID)
+
+	// to is exclusive
+	page, err = gStore.GetCompletionsByUserID(
+		user.ID,
+		options.Some(first.CompletedAt),
+		options.Some(second.CompletedAt),
+		options.None[int64](),
+		10,
+	)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, first.ID, page[0].ID)
+}
+
+func TestGoalStreaks(t *testing.T) {
+	t.Parallel()
+	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
+	require.NoError(t, err)
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, NoReset)
+	require.NoError(t, err)
+	goal, err := gStore.CreateGoal(
+		t.Name(),
+		"desc",
+		user.ID,
+		category.ID,
+		options.Some(recurrence.DailyRule()),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, entities.Streak{}, goal.Streak)
+	today := goal.OccurrenceDate.ValueOrZero()
+
+	complete := UpdateGoalParams{Status: options.Some("complete")}
+	updated, completion, err := gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, entities.Streak{
+		LastCompletedDate: options.Some(today),
+		Current:           1,
+		Longest:           1,
+	}, updated.Streak)
+	assert.Equal(t, 1, completion.GoalStreak)
+	assert.Equal(t, 0, completion.CategoryStreak)
+	assert.Empty(t, completion.Milestones)
+
+	moveBackADay(t, goal.ID)
+	updated, _, err = gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Streak.Current)
+	assert.Equal(t, 2, updated.Streak.Longest)
+
+	// un-completing takes today back off the streak
+	updated, _, err = gStore.UpdateGoalByID(
+		goal.ID,
+		user.ID,
+		UpdateGoalParams{Status: options.Some("not_complete")},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Streak.Current)
+	assert.Equal(t, 1, updated.Streak.Longest)
+	assert.NotEqual(t, today, updated.Streak.LastCompletedDate.ValueOrZero())
+
+	updated, _, err = gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Streak.Current)
+	assert.Equal(t, today, updated.Streak.LastCompletedDate.ValueOrZero())
+
+	// moving on past a missed occurrence breaks the streak but keeps the longest
+	moveBackADay(t, goal.ID)
+	moveBackADay(t, goal.ID)
+	refreshed, err := gStore.RefreshGoalOccurrence(goal.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, today, refreshed.OccurrenceDate.ValueOrZero())
+	assert.Equal(t, 0, refreshed.Streak.Current)
+	assert.Equal(t, 2, refreshed.Streak.Longest)
+}
+
+func TestCategoryStreaks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
+	require.NoError(t, err)
+	daily := entities.ResetSchedule{Policy: ResetPolicyDaily, Time: "00:00"}
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, daily)
+	require.NoError(t, err)
+	first, err := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID, noRecurrence)
+	require.NoError(t, err)
+	second, err := gStore.CreateGoal(t.Name()+"2", "desc", user.ID, category.ID, noRecurrence)
+	require.NoError(t, err)
+
+	complete := UpdateGoalParams{Status: options.Some("complete")}
+	uncomplete := UpdateGoalParams{Status: options.Some("not_complete")}
+	_, completion, err := gStore.UpdateGoalByID(first.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, 1, completion.GoalStreak)
+	assert.Equal(t, 1, completion.CategoryStreak)
+	_, completion, err = gStore.UpdateGoalByID(second.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, 1, completion.CategoryStreak)
+
+	// the category's day still stands while another of its goals is complete
+	updated, _, err := gStore.UpdateGoalByID(first.ID, user.ID, uncomplete)
+	require.NoError(t, err)
+	assert.Equal(t, 0, updated.Streak.Current)
+	assert.False(t, updated.Streak.LastCompletedDate.IsPresent())
+	fetched, err := gcStore.GetGoalCategoryByID(category.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetched.Streak.Current)
+	today := fetched.Streak.LastCompletedDate.ValueOrZero()
+
+	// completing the day after six in a row reaches the first milestone for both
+	_, err = pool.Exec(ctx, `
+		UPDATE goals SET current_streak = 6, longest_streak = 6, last_completed_date = $2::DATE - 1
+		WHERE id = $1`, first.ID, today)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		UPDATE goal_categories
+		SET current_streak = 6, longest_streak = 6, last_completed_date = $2::DATE - 1
+		WHERE id = $1`, category.ID, today)
+	require.NoError(t, err)
+	updated, completion, err = gStore.UpdateGoalByID(first.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, 7, updated.Streak.Current)
+	assert.Equal(t, []*entities.StreakMilestone{
+		{Kind: StreakKindGoal, Title: first.Title, Streak: 7, ID: first.ID},
+		{Kind: StreakKindCategory, Title: category.Title, Streak: 7, ID: category.ID},
+	}, completion.Milestones)
+
+	// taking the day back and completing it again reaches the milestone without announcing it twice
+	_, err = pool.Exec(ctx, `
+		INSERT INTO goal_completions
+			(goal_id, user_id, category_id, period_date, goal_streak, category_streak)
+		VALUES ($1, $2, $3, $4::DATE - 1, 6, 6)`, first.ID, user.ID, category.ID, today)
+	require.NoError(t, err)
+	for _, id := range []uuid.UUID{first.ID, second.ID} {
+		_, _, err = gStore.UpdateGoalByID(id, user.ID, uncomplete)
+		require.NoError(t, err)
+	}
+	fetched, err = gcStore.GetGoalCategoryByID(category.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 6, fetched.Streak.Current)
+	updated, completion, err = gStore.UpdateGoalByID(first.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, 7, updated.Streak.Current)
+	assert.Equal(t, 7, completion.CategoryStreak)
+	assert.Empty(t, completion.Milestones)
+}
+
+// shiftStreaks moves the goal's and category's streaks and the user's frozen days back by days,
+// as if that many days had passed since
+func shiftStreaks(t *testing.T, userID, goalID, categoryID uuid.UUID, days int) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := pool.Exec(ctx, `
+		UPDATE goals SET last_completed_date = last_completed_date - $2::INTEGER WHERE id = $1`,
+		goalID, days)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		UPDATE goal_categories SET last_completed_date = last_completed_date - $2::INTEGER
+		WHERE id = $1`, categoryID, days)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		UPDATE streak_freeze_days SET day = day - $2::INTEGER WHERE user_id = $1`,
+		userID, days)
+	require.NoError(t, err)
+}
+
+func TestStreakFreezeIsUsedUp(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	user, err := userStore.CreateUser(t.Name()+"@mail.com", password)
+	require.NoError(t, err)
+	daily := entities.ResetSchedule{Policy: ResetPolicyDaily, Time: "00:00"}
+	category, err := gcStore.CreateGoalCategory(t.Name(), user.ID, daily)
+	require.NoError(t, err)
+	goal, err := gStore.CreateGoal(t.Name(), "desc", user.ID, category.ID, noRecurrence)
+	require.NoError(t, err)
+
+	complete := UpdateGoalParams{Status: options.Some("complete")}
+	uncomplete := UpdateGoalParams{Status: options.Some("not_complete")}
+	updated, _, err := gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	today := updated.Streak.LastCompletedDate.ValueOrZero()
+	_, _, err = gStore.UpdateGoalByID(goal.ID, user.ID, uncomplete)
+	require.NoError(t, err)
+
+	// three days in a row up to the day before yesterday
+	_, err = pool.Exec(ctx, `
+		UPDATE goals SET current_streak = 3, longest_streak = 3, last_completed_date = $2::DATE - 2
+		WHERE id = $1`, goal.ID, today)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		UPDATE goal_categories
+		SET current_streak = 3, longest_streak = 3, last_completed_date = $2::DATE - 2
+		WHERE id = $1`, category.ID, today)
+	require.NoError(t, err)
+
+	streaks := func() (int, int) {
+		t.Helper()
+		fetchedGoal, err := gStore.GetGoalByID(goal.ID, user.ID)
+		require.NoError(t, err)
+		fetchedCategory, err := gcStore.GetGoalCategoryByID(category.ID, user.ID)
+		require.NoError(t, err)
+		return fetchedGoal.Streak.Current, fetchedCategory.Streak.Current
+	}
+	breakLapsed := func() {
+		t.Helper()
+		_, err := gStore.BreakLapsedStreaks()
+		require.NoError(t, err)
+		_, err = gcStore.BreakLapsedStreaks()
+		require.NoError(t, err)
+	}
+
+	// the first missed day freezes once for both streaks, using the freeze up
+	_, err = pool.Exec(ctx, `
+		INSERT INTO active_effects (user_id, effect, value, expires_at)
+		VALUES ($1, 'streak_freeze', 1, NOW() + INTERVAL '30 days')`, user.ID)
+	require.NoError(t, err)
+	breakLapsed()
+	goalStreak, categoryStreak := streaks()
+	assert.Equal(t, 3, goalStreak)
+	assert.Equal(t, 3, categoryStreak)
+	var freezesLeft int
+	err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM active_effects WHERE user_id = $1`, user.ID).
+		Scan(&freezesLeft)
+	require.NoError(t, err)
+	assert.Equal(t, 0, freezesLeft)
+
+	// completing after the frozen day carries both streaks on
+	updated, completion, err := gStore.UpdateGoalByID(goal.ID, user.ID, complete)
+	require.NoError(t, err)
+	assert.Equal(t, 4, updated.Streak.Current)
+	assert.Equal(t, today, updated.Streak.LastCompletedDate.ValueOrZero())
+	assert.Equal(t, 4, completion.CategoryStreak)
+
+	// a second missed day later on has no freeze left to cover it
+	shiftStreaks(t, user.ID, goal.ID, category.ID, 2)
+	breakLapsed()
+	goalStreak, categoryStreak = streaks()
+	assert.Equal(t, 0, goalStreak)
+	assert.Equal(t, 0, categoryStreak)
+}
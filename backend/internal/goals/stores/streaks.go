@@ -0,0 +1,480 @@
+package stores
+
+import (
+	"context"
+	"goalify/internal/db"
+	"goalify/internal/entities"
+	"goalify/pkg/recurrence"
+	"slices"
+	"time"
+
+	sqlcdb "goalify/internal/db/generated"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	StreakKindGoal     = "goal"
+	StreakKindCategory = "category"
+)
+
+// StreakMilestones are the streak lengths worth celebrating
+var StreakMilestones = []int{7, 30, 100}
+
+// maxStreakGap bounds the missed periods a streak freeze can bridge in one go. Longer gaps
+// always break the streak.
+const maxStreakGap = 366
+
+// streak is a goal's or category's streak as stored. milestone is the highest milestone
+// announced since the streak started.
+type streak struct {
+	last      pgtype.Date
+	current   int
+	longest   int
+	milestone int
+}
+
+func goalStreak(goal sqlcdb.Goal) streak {
+	return streak{
+		last:      goal.LastCompletedDate,
+		current:   int(goal.CurrentStreak),
+		longest:   int(goal.LongestStreak),
+		milestone: int(goal.StreakMilestone),
+	}
+}
+
+func categoryStreak(category sqlcdb.GoalCategory) streak {
+	return streak{
+		last:      category.LastCompletedDate,
+		current:   int(category.CurrentStreak),
+		longest:   int(category.LongestStreak),
+		milestone: int(category.StreakMilestone),
+	}
+}
+
+func pgxStreakToEntity(current, longest int32, last pgtype.Date) entities.Streak {
+	return entities.Streak{
+		LastCompletedDate: db.PgxDateToOptionString(last),
+		Current:           int(current),
+		Longest:           int(longest),
+	}
+}
+
+// running reports whether the streak is going and was last extended before period
+func (s streak) running(period time.Time) bool {
+	return s.current > 0 && s.last.Valid && s.last.Time.Before(period)
+}
+
+// extend counts period towards the streak. Completing a period already counted changes nothing,
+// and a streak that missed periods since its last starts over unless they were bridged.
+func (s streak) extend(period time.Time, bridged bool) streak {
+	if s.last.Valid && !period.After(s.last.Time) {
+		return s
+	}
+
+	extended := streak{last: db.TimeToPgxDate(period), current: 1, longest: s.longest}
+	if s.running(period) && bridged {
+		extended.current = s.current + 1
+		extended.milestone = s.milestone
+	}
+	extended.longest = max(extended.longest, extended.current)
+	return extended
+}
+
+// broken is the streak after missing a period
+func (s streak) broken() streak {
+	return streak{last: s.last, longest: s.longest}
+}
+
+// announce reports whether the streak is at a milestone length it hasn't announced yet, marking
+// it announced. Taking a period back off the streak and completing it again reaches the same
+// length without announcing it twice.
+func (s *streak) announce() bool {
+	if s.current <= s.milestone || !slices.Contains(StreakMilestones, s.current) {
+		return false
+	}
+	s.milestone = s.current
+	return true
+}
+
+// missedDays lists the days strictly between last and day. ok is false when there are more than
+// maxStreakGap of them.
+func missedDays(last, day time.Time) (missed []time.Time, ok bool) {
+	for next := last.AddDate(0, 0, 1); next.Before(day); next = next.AddDate(0, 0, 1) {
+		if len(missed) == maxStreakGap {
+			return nil, false
+		}
+		missed = append(missed, next)
+	}
+	return missed, true
+}
+
+// missedOccurrences lists the rule's occurrences strictly between last and until. ok is false
+// when there are more than maxStreakGap of them.
+func missedOccurrences(
+	rule recurrence.Rule,
+	start, last, until time.Time,
+) (missed []time.Time, ok bool) {
+	for day := last; ; {
+		next, found := rule.Next(start, day)
+		if !found || !next.Before(until) {
+			return missed, true
+		}
+		if len(missed) == maxStreakGap {
+			return nil, false
+		}
+		missed = append(missed, next)
+		day = next
+	}
+}
+
+// lockCategory returns the user's category with the given id, locking it unless it's among the
+// categories already locked. It is nil for goals without a category.
+func lockCategory(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	userID pgtype.UUID,
+	locked map[pgtype.UUID]sqlcdb.GoalCategory,
+	id pgtype.UUID,
+) (*sqlcdb.GoalCategory, error) {
+	if !id.Valid {
+		return nil, nil
+	}
+	if category, ok := locked[id]; ok {
+		return &category, nil
+	}
+	if err := lockCategories(ctx, q, userID, locked, id); err != nil {
+		return nil, err
+	}
+	if category, ok := locked[id]; ok {
+		return &category, nil
+	}
+	return nil, nil
+}
+
+// lockCategories locks the user's categories with the given ids, adding them to locked
+func lockCategories(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	userID pgtype.UUID,
+	locked map[pgtype.UUID]sqlcdb.GoalCategory,
+	ids ...pgtype.UUID,
+) error {
+	rows, err := q.LockGoalCategoriesByIds(ctx, sqlcdb.LockGoalCategoriesByIdsParams{
+		Ids:    ids,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		locked[row.ID] = row
+	}
+	return nil
+}
+
+func sameDate(a, b pgtype.Date) bool {
+	return a.Valid && b.Valid && a.Time.Equal(b.Time)
+}
+
+// bridgeGap reports whether every missed day is frozen for the user, freezing the ones that
+// aren't when the user's active streak freezes cover them all, a unit of freeze per day. Nothing
+// is used up when they don't. A frozen day bridges that day in all of the user's streaks.
+func bridgeGap(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	userID pgtype.UUID,
+	missed []time.Time,
+) (bool, error) {
+	if len(missed) == 0 {
+		return true, nil
+	}
+
+	// the freezes are locked before the frozen days are read, so transactions bridging the same
+	// day take turns and only the first uses up a freeze for it
+	freezes, err := q.LockStreakFreezesByUserId(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	frozenDays, err := q.GetStreakFreezeDays(ctx, sqlcdb.GetStreakFreezeDaysParams{
+		UserID:  userID,
+		FromDay: db.TimeToPgxDate(missed[0]),
+		ToDay:   db.TimeToPgxDate(missed[len(missed)-1]),
+	})
+	if err != nil {
+		return false, err
+	}
+	var unfrozen []time.Time
+	for _, day := range missed {
+		if !slices.ContainsFunc(frozenDays, func(d pgtype.Date) bool { return d.Time.Equal(day) }) {
+			unfrozen = append(unfrozen, day)
+		}
+	}
+	if len(unfrozen) == 0 {
+		return true, nil
+	}
+
+	available := 0
+	for _, freeze := range freezes {
+		available += int(freeze.Value)
+	}
+	if available < len(unfrozen) {
+		return false, nil
+	}
+
+	for _, day := range unfrozen {
+		err := q.CreateStreakFreezeDay(ctx, sqlcdb.CreateStreakFreezeDayParams{
+			UserID: userID,
+			Day:    db.TimeToPgxDate(day),
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	needed := len(unfrozen)
+	for _, freeze := range freezes {
+		if needed == 0 {
+			break
+		}
+		used := min(needed, int(freeze.Value))
+		needed -= used
+		if used == int(freeze.Value) {
+			err = q.DeleteActiveEffectById(ctx, freeze.ID)
+		} else {
+			err = q.SetActiveEffectValue(ctx, sqlcdb.SetActiveEffectValueParams{
+				ID:    freeze.ID,
+				Value: freeze.Value - int32(used),
+			})
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// bridgeGoalGap reports whether the goal's streak carries on to period, bridging the periods it
+// missed since its last completion: occurrences for recurring goals and days for others
+func bridgeGoalGap(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	goal sqlcdb.Goal,
+	period time.Time,
+) (bool, error) {
+	last := goal.LastCompletedDate.Time
+	var missed []time.Time
+	var ok bool
+	if goal.Recurrence.Valid {
+		rule, err := recurrence.Parse(goal.Recurrence.String)
+		if err != nil {
+			return false, err
+		}
+		missed, ok = missedOccurrences(rule, goal.RecurrenceStart.Time, last, period)
+	} else {
+		missed, ok = missedDays(last, period)
+	}
+	if !ok {
+		return false, nil
+	}
+	return bridgeGap(ctx, q, goal.UserID, missed)
+}
+
+// bridgeCategoryGap reports whether the category's streak carries on to day, bridging the days
+// it missed since its last completion
+func bridgeCategoryGap(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	category sqlcdb.GoalCategory,
+	day time.Time,
+) (bool, error) {
+	missed, ok := missedDays(category.LastCompletedDate.Time, day)
+	if !ok {
+		return false, nil
+	}
+	return bridgeGap(ctx, q, category.UserID, missed)
+}
+
+// checkGoalStreak breaks the goal's streak, in place, when it missed a period before period that
+// can't be bridged, reporting whether it broke
+func checkGoalStreak(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	goal *sqlcdb.Goal,
+	period time.Time,
+) (bool, error) {
+	before := goalStreak(*goal)
+	if !before.running(period) {
+		return false, nil
+	}
+	bridged, err := bridgeGoalGap(ctx, q, *goal, period)
+	if err != nil || bridged {
+		return false, err
+	}
+	return true, setGoalStreak(ctx, q, goal, before.broken())
+}
+
+// checkCategoryStreak breaks the category's streak when it missed a day before day that can't be
+// bridged, reporting whether it broke
+func checkCategoryStreak(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	category sqlcdb.GoalCategory,
+	day time.Time,
+) (bool, error) {
+	before := categoryStreak(category)
+	if !before.running(day) {
+		return false, nil
+	}
+	bridged, err := bridgeCategoryGap(ctx, q, category, day)
+	if err != nil || bridged {
+		return false, err
+	}
+	return true, setCategoryStreak(ctx, q, category.ID, before.broken())
+}
+
+// recordStreaks counts the completed period towards the goal's streak when it recurs or sits in
+// a daily category, and towards the category's streak when it's daily, in the caller's
+// transaction. Missed periods are bridged with the user's streak freezes where they can be. goal
+// is updated in place, and the streaks left and milestones reached returned.
+func recordStreaks(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	goal *sqlcdb.Goal,
+	category *sqlcdb.GoalCategory,
+	period time.Time,
+) (goalStreakAfter, categoryStreakAfter int, milestones []*entities.StreakMilestone, err error) {
+	daily := category != nil && category.ResetPolicy == sqlcdb.CategoryResetPolicyDaily
+
+	goalBefore := goalStreak(*goal)
+	goalAfter := goalBefore
+	if goal.Recurrence.Valid || daily {
+		bridged := false
+		if goalBefore.running(period) {
+			if bridged, err = bridgeGoalGap(ctx, q, *goal, period); err != nil {
+				return 0, 0, nil, err
+			}
+		}
+		goalAfter = goalBefore.extend(period, bridged)
+		announced := goalAfter.announce()
+		if err := setGoalStreak(ctx, q, goal, goalAfter); err != nil {
+			return 0, 0, nil, err
+		}
+		if announced {
+			milestones = append(milestones, &entities.StreakMilestone{
+				Kind:   StreakKindGoal,
+				Title:  goal.Title,
+				Streak: goalAfter.current,
+				ID:     uuid.UUID(goal.ID.Bytes),
+			})
+		}
+	}
+
+	var categoryAfter streak
+	if daily {
+		categoryBefore := categoryStreak(*category)
+		bridged := false
+		if categoryBefore.running(period) {
+			if bridged, err = bridgeCategoryGap(ctx, q, *category, period); err != nil {
+				return 0, 0, nil, err
+			}
+		}
+		categoryAfter = categoryBefore.extend(period, bridged)
+		announced := categoryAfter.announce()
+		if err := setCategoryStreak(ctx, q, category.ID, categoryAfter); err != nil {
+			return 0, 0, nil, err
+		}
+		if announced {
+			milestones = append(milestones, &entities.StreakMilestone{
+				Kind:   StreakKindCategory,
+				Title:  category.Title,
+				Streak: categoryAfter.current,
+				ID:     uuid.UUID(category.ID.Bytes),
+			})
+		}
+	} else if category != nil {
+		categoryAfter = categoryStreak(*category)
+	}
+
+	return goalAfter.current, categoryAfter.current, milestones, nil
+}
+
+// revertStreaks takes an undone completion's period back off the goal's streak and, when given,
+// its category's, unless another standing completion still completes the period. The streaks
+// drop back to what they were before the completion extended them.
+func revertStreaks(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	goal *sqlcdb.Goal,
+	category *sqlcdb.GoalCategory,
+	undone sqlcdb.GoalCompletion,
+) error {
+	if sameDate(goal.LastCompletedDate, undone.PeriodDate) {
+		history, err := q.GetGoalStreakHistory(ctx, sqlcdb.GetGoalStreakHistoryParams{
+			PeriodDate: undone.PeriodDate,
+			GoalID:     goal.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if !history.PeriodCompleted {
+			reverted := streak{
+				last:      history.LastCompletedDate,
+				current:   max(int(undone.GoalStreak)-1, 0),
+				longest:   int(history.LongestStreak),
+				milestone: int(goal.StreakMilestone),
+			}
+			if err := setGoalStreak(ctx, q, goal, reverted); err != nil {
+				return err
+			}
+		}
+	}
+
+	if category == nil || !sameDate(category.LastCompletedDate, undone.PeriodDate) {
+		return nil
+	}
+	history, err := q.GetCategoryStreakHistory(ctx, sqlcdb.GetCategoryStreakHistoryParams{
+		PeriodDate: undone.PeriodDate,
+		CategoryID: category.ID,
+	})
+	if err != nil || history.PeriodCompleted {
+		return err
+	}
+	return setCategoryStreak(ctx, q, category.ID, streak{
+		last:      history.LastCompletedDate,
+		current:   max(int(undone.CategoryStreak)-1, 0),
+		longest:   int(history.LongestStreak),
+		milestone: int(category.StreakMilestone),
+	})
+}
+
+func setGoalStreak(ctx context.Context, q *sqlcdb.Queries, goal *sqlcdb.Goal, s streak) error {
+	err := q.SetGoalStreak(ctx, sqlcdb.SetGoalStreakParams{
+		ID:                goal.ID,
+		CurrentStreak:     int32(s.current),
+		LongestStreak:     int32(s.longest),
+		LastCompletedDate: s.last,
+		StreakMilestone:   int32(s.milestone),
+	})
+	if err != nil {
+		return err
+	}
+	goal.CurrentStreak, goal.LongestStreak = int32(s.current), int32(s.longest)
+	goal.LastCompletedDate, goal.StreakMilestone = s.last, int32(s.milestone)
+	return nil
+}
+
+func setCategoryStreak(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	categoryID pgtype.UUID,
+	s streak,
+) error {
+	return q.SetGoalCategoryStreak(ctx, sqlcdb.SetGoalCategoryStreakParams{
+		ID:                categoryID,
+		CurrentStreak:     int32(s.current),
+		LongestStreak:     int32(s.longest),
+		LastCompletedDate: s.last,
+		StreakMilestone:   int32(s.milestone),
+	})
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/loot/service"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"goalify/pkg/jsonutil"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (h *LootHandler) HandleReplaceDropTable(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleReplaceDropTable")
+	body, problems, err := jsonutil.DecodeValid[ReplaceDropTableRequest](r)
+	if err != nil {
+		responses.HandleDecodeError(w, r, problems, err)
+		return
+	}
+
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	chestID, err := uuid.Parse(r.PathValue("chestId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid chest id", nil)
+		return
+	}
+
+	// item ids were checked by ReplaceDropTableRequest.Valid
+	rates := make([]*entities.ChestItemDropRate, len(body.Rates))
+	for i, rate := range body.Rates {
+		rates[i] = &entities.ChestItemDropRate{
+			ItemID:   uuid.MustParse(rate.ItemID),
+			ChestID:  chestID,
+			DropRate: rate.DropRate,
+		}
+	}
+
+	dropTable, err := h.lootService.ReplaceDropTable(chestID, parsedUserID, rates)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.DropTable]{
+		Object: responses.ObjectDropTable,
+		Data:   dropTable,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleGetDropTableHistory(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetDropTableHistory")
+	chestID, err := uuid.Parse(r.PathValue("chestId"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid chest id", nil)
+		return
+	}
+
+	history, err := h.lootService.GetDropTableHistory(chestID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.DropTable]{
+		Object: responses.ObjectList,
+		Data:   history,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+// HandleUploadItemImage replaces an item's art with the image in the request body. The body is the
+// raw PNG or JPEG, whose type is sniffed from its contents.
+func (h *LootHandler) HandleUploadItemImage(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleUploadItemImage")
+	itemID, err := uuid.Parse(r.PathValue("itemId"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid item id", nil)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, service.MaxItemImageBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		responses.SendAPIError(
+			w,
+			r,
+			http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("request too large: image is larger than %d bytes", tooLarge.Limit),
+			nil,
+		)
+		return
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: io.ReadAll:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: unreadable body", nil)
+		return
+	}
+
+	itemImage, err := h.lootService.UploadItemImage(itemID, data)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.ItemImage]{
+		Object: responses.ObjectItemImage,
+		Data:   itemImage,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
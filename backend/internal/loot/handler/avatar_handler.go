@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/avatar"
+	"goalify/internal/responses"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// HandleGetAvatar serves the user's avatar as an SVG or PNG. Responses carry an ETag derived from
+// the user's level, equipped items and whether their art loaded, so clients revalidate instead
+// of downloading it again.
+func (h *LootHandler) HandleGetAvatar(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetAvatar")
+
+	userID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid user id", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	req := GetAvatarRequest{Format: avatar.FormatSVG, Size: avatar.DefaultSize}
+	if query.Has("format") {
+		req.Format = query.Get("format")
+	}
+	if query.Has("size") {
+		// a malformed size fails validation as 0
+		req.Size, _ = strconv.Atoi(query.Get("size"))
+	}
+	if problems := req.Valid(); len(problems) > 0 {
+		responses.SendAPIError(
+			w,
+			r,
+			http.StatusBadRequest,
+			"bad request: invalid avatar options",
+			problems,
+		)
+		return
+	}
+
+	userAvatar, err := h.lootService.GetAvatar(userID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	etag := h.avatarRenderer.ETag(userAvatar, req.Format, req.Size)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if responses.ETagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, err := h.avatarRenderer.Render(userAvatar, req.Format, req.Size)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: avatarRenderer.Render:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", avatar.ContentType(req.Format))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		slog.Error(fmt.Sprintf("%s: w.Write:", funcStr), "err", err)
+	}
+}
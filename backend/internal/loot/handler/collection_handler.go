@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (h *LootHandler) HandleGetCollection(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetCollection")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	collection, err := h.lootService.GetCollection(parsedUserID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Collection]{
+		Object: responses.ObjectCollection,
+		Data:   collection,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleClaimCollectionBonus(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleClaimCollectionBonus")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	chestID, err := uuid.Parse(r.PathValue("chestId"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid chest id", nil)
+		return
+	}
+
+	bonus, err := h.lootService.ClaimCollectionBonus(parsedUserID, chestID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.CollectionBonus]{
+		Object: responses.ObjectCollectionBonus,
+		Data:   bonus,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
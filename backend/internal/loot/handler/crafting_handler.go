@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"goalify/pkg/jsonutil"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (h *LootHandler) HandleGetCraftingRecipes(w http.ResponseWriter, r *http.Request) {
+	recipes, err := h.lootService.GetCraftingRecipes()
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.CraftingRecipe]{
+		Object: responses.ObjectList,
+		Data:   recipes,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleCraft(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleCraft")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	recipeID, err := uuid.Parse(r.PathValue("recipeId"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid recipe id", nil)
+		return
+	}
+
+	decoded, problems, err := jsonutil.DecodeValid[CraftRequest](r)
+	if err != nil {
+		responses.HandleDecodeError(w, r, problems, err)
+		return
+	}
+
+	// ids were validated by CraftRequest.Valid
+	userItemIDs := make([]uuid.UUID, len(decoded.UserItemIDs))
+	for i, id := range decoded.UserItemIDs {
+		userItemIDs[i] = uuid.MustParse(id)
+	}
+
+	craft, err := h.lootService.Craft(parsedUserID, recipeID, userItemIDs)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Craft]{
+		Object: responses.ObjectCraft,
+		Data:   craft,
+	}
+	responses.SendResponse(w, r, http.StatusCreated, res)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (h *LootHandler) HandleConsumeItem(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleConsumeItem")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	userItemID, err := uuid.Parse(r.PathValue("userItemId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid item id", nil)
+		return
+	}
+
+	consumption, err := h.lootService.ConsumeItem(parsedUserID, userItemID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.ItemConsumption]{
+		Object: responses.ObjectItemConsumption,
+		Data:   consumption,
+	}
+	responses.SendResponse(w, r, http.StatusCreated, res)
+}
+
+func (h *LootHandler) HandleGetActiveEffects(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetActiveEffects")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	effects, err := h.lootService.GetActiveEffects(parsedUserID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.ActiveEffect]{
+		Object: responses.ObjectList,
+		Data:   effects,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
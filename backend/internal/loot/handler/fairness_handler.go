@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"goalify/pkg/jsonutil"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+func (h *LootHandler) HandleGetFairnessSeed(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetFairnessSeed")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	seed, err := h.lootService.GetFairnessSeed(parsedUserID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.FairnessSeed]{
+		Object: responses.ObjectFairnessSeed,
+		Data:   seed,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleRotateFairnessSeed(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleRotateFairnessSeed")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	decoded, problems, err := jsonutil.DecodeValid[RotateSeedRequest](r)
+	if err != nil {
+		responses.HandleDecodeError(w, r, problems, err)
+		return
+	}
+
+	rotation, err := h.lootService.RotateFairnessSeed(parsedUserID, decoded.ClientSeed)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.SeedRotation]{
+		Object: responses.ObjectSeedRotation,
+		Data:   rotation,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleGetChestOpenings(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetChestOpenings")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	cursor, limit, ok := parsePage(w, r, DefaultOpeningsLimit, MaxOpeningsLimit)
+	if !ok {
+		return
+	}
+
+	openings, hasMore, err := h.lootService.GetChestOpenings(parsedUserID, cursor, limit)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.ChestOpeningRecord]{
+		Object:  responses.ObjectList,
+		Data:    openings,
+		HasMore: &hasMore,
+	}
+	if hasMore {
+		nextPage := strconv.FormatInt(openings[len(openings)-1].ID, 10)
+		res.NextPage = &nextPage
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/loot/stores"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"goalify/pkg/jsonutil"
+	"goalify/pkg/options"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (h *LootHandler) HandleSendGift(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleSendGift")
+	body, problems, err := jsonutil.DecodeValid[SendGiftRequest](r)
+	if err != nil {
+		responses.HandleDecodeError(w, r, problems, err)
+		return
+	}
+
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	// ids were validated by SendGiftRequest.Valid
+	recipient := stores.GiftRecipient{Email: body.RecipientEmail}
+	if body.RecipientID != "" {
+		recipient.ID = options.Some(uuid.MustParse(body.RecipientID))
+	}
+	contents := stores.GiftContents{Quantity: body.Quantity}
+	if body.ChestID != "" {
+		contents.ChestID = options.Some(uuid.MustParse(body.ChestID))
+	}
+	if body.UserItemID != "" {
+		contents.UserItemID = options.Some(uuid.MustParse(body.UserItemID))
+	}
+
+	gift, err := h.lootService.SendGift(parsedUserID, recipient, contents)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Gift]{
+		Object: responses.ObjectGift,
+		Data:   gift,
+	}
+	responses.SendResponse(w, r, http.StatusCreated, res)
+}
+
+func (h *LootHandler) HandleGetGifts(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetGifts")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	req := GetGiftsRequest{Direction: "received"}
+	if query.Has("direction") {
+		req.Direction = query.Get("direction")
+	}
+	if query.Has("status") {
+		req.Status = options.Some(query.Get("status"))
+	}
+	if problems := req.Valid(); len(problems) > 0 {
+		responses.SendAPIError(
+			w,
+			r,
+			http.StatusBadRequest,
+			"bad request: invalid gift filters",
+			problems,
+		)
+		return
+	}
+
+	gifts, err := h.lootService.GetGifts(
+		parsedUserID,
+		stores.GiftFilter{Status: req.Status, Sent: req.Direction == "sent"},
+	)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.Gift]{
+		Object: responses.ObjectList,
+		Data:   gifts,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleAcceptGift(w http.ResponseWriter, r *http.Request) {
+	h.handleResolveGift(w, r, "handler.HandleAcceptGift", h.lootService.AcceptGift)
+}
+
+func (h *LootHandler) HandleDeclineGift(w http.ResponseWriter, r *http.Request) {
+	h.handleResolveGift(w, r, "handler.HandleDeclineGift", h.lootService.DeclineGift)
+}
+
+// handleResolveGift resolves the gift in the path on behalf of its recipient with resolve
+func (h *LootHandler) handleResolveGift(
+	w http.ResponseWriter,
+	r *http.Request,
+	trace string,
+	resolve func(userID, giftID uuid.UUID) (*entities.Gift, error),
+) {
+	funcStr := h.traceLogger.GetTrace(trace)
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	giftID, err := uuid.Parse(r.PathValue("giftId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid gift id", nil)
+		return
+	}
+
+	gift, err := resolve(parsedUserID, giftID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Gift]{
+		Object: responses.ObjectGift,
+		Data:   gift,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,177 @@
+// Package handler is the API request/response handling for chests, items and drop rates
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (h *LootHandler) HandleGetChests(w http.ResponseWriter, r *http.Request) {
+	chests, err := h.lootService.GetChests()
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.Chest]{
+		Object: responses.ObjectList,
+		Data:   chests,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleGetChestByID(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetChestByID")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	chestID, err := uuid.Parse(r.PathValue("chestId"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid chest id", nil)
+		return
+	}
+
+	chest, err := h.lootService.GetChestForUser(parsedUserID, chestID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Chest]{
+		Object: responses.ObjectChest,
+		Data:   chest,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleGetChestItems(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetChestItems")
+	chestID, err := uuid.Parse(r.PathValue("chestId"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid chest id", nil)
+		return
+	}
+
+	items, err := h.lootService.GetChestItemsByChestID(chestID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.ChestItem]{
+		Object: responses.ObjectList,
+		Data:   items,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleGetChestDropRates(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetChestDropRates")
+	chestID, err := uuid.Parse(r.PathValue("chestId"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid chest id", nil)
+		return
+	}
+
+	rates, err := h.lootService.GetDropRatesByChestID(chestID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.ChestItemDropRate]{
+		Object: responses.ObjectList,
+		Data:   rates,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandlePurchaseChest(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandlePurchaseChest")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	chestID, err := uuid.Parse(r.PathValue("chestId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid chest id", nil)
+		return
+	}
+
+	purchase, err := h.lootService.PurchaseChest(parsedUserID, chestID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.ChestPurchase]{
+		Object: responses.ObjectChestPurchase,
+		Data:   purchase,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleOpenChest(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleOpenChest")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	chestID, err := uuid.Parse(r.PathValue("chestId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid chest id", nil)
+		return
+	}
+
+	opening, err := h.lootService.OpenChest(parsedUserID, chestID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.ChestOpening]{
+		Object: responses.ObjectChestOpening,
+		Data:   opening,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/loot/stores"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"goalify/pkg/jsonutil"
+	"goalify/pkg/options"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (h *LootHandler) HandleGetUserItems(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetUserItems")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	req := GetUserItemsRequest{}
+	if query.Has("rarity") {
+		req.Rarity = options.Some(query.Get("rarity"))
+	}
+	if query.Has("status") {
+		req.Status = options.Some(query.Get("status"))
+	}
+	if problems := req.Valid(); len(problems) > 0 {
+		responses.SendAPIError(
+			w,
+			r,
+			http.StatusBadRequest,
+			"bad request: invalid item filters",
+			problems,
+		)
+		return
+	}
+
+	items, err := h.lootService.GetUserItems(
+		parsedUserID,
+		stores.UserItemFilter{Rarity: req.Rarity, Status: req.Status},
+	)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.UserItem]{
+		Object: responses.ObjectList,
+		Data:   items,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleEquipItem(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleEquipItem")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	userItemID, err := uuid.Parse(r.PathValue("userItemId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid item id", nil)
+		return
+	}
+
+	item, err := h.lootService.EquipItem(parsedUserID, userItemID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.UserItem]{
+		Object: responses.ObjectUserItem,
+		Data:   item,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleUnequipItem(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleUnequipItem")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	userItemID, err := uuid.Parse(r.PathValue("userItemId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid item id", nil)
+		return
+	}
+
+	item, err := h.lootService.UnequipItem(parsedUserID, userItemID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.UserItem]{
+		Object: responses.ObjectUserItem,
+		Data:   item,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleSellItem(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleSellItem")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	userItemID, err := uuid.Parse(r.PathValue("userItemId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid item id", nil)
+		return
+	}
+
+	sale, err := h.lootService.SellItem(parsedUserID, userItemID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.ItemSale]{
+		Object: responses.ObjectItemSale,
+		Data:   sale,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleSellDuplicateItems(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleSellDuplicateItems")
+	body, problems, err := jsonutil.DecodeValid[SellDuplicatesRequest](r)
+	if err != nil {
+		responses.HandleDecodeError(w, r, problems, err)
+		return
+	}
+
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	sale, err := h.lootService.SellDuplicateItems(parsedUserID, body.Rarity)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.ItemSale]{
+		Object: responses.ObjectItemSale,
+		Data:   sale,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/loot/stores"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"goalify/pkg/jsonutil"
+	"goalify/pkg/options"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+func (h *LootHandler) HandleGetListings(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := GetListingsRequest{}
+	if query.Has("rarity") {
+		req.Rarity = options.Some(query.Get("rarity"))
+	}
+	if query.Get("title") != "" {
+		req.Title = options.Some(query.Get("title"))
+	}
+	if problems := req.Valid(); len(problems) > 0 {
+		responses.SendAPIError(
+			w,
+			r,
+			http.StatusBadRequest,
+			"bad request: invalid listing filters",
+			problems,
+		)
+		return
+	}
+
+	cursor, limit, ok := parsePage(w, r, DefaultListingsLimit, MaxListingsLimit)
+	if !ok {
+		return
+	}
+
+	listings, hasMore, err := h.lootService.GetListings(
+		stores.ListingFilter{Rarity: req.Rarity, Title: req.Title},
+		cursor,
+		limit,
+	)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	sendListings(w, r, listings, hasMore)
+}
+
+func (h *LootHandler) HandleGetUserListings(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetUserListings")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	req := GetUserListingsRequest{}
+	if r.URL.Query().Has("status") {
+		req.Status = options.Some(r.URL.Query().Get("status"))
+	}
+	if problems := req.Valid(); len(problems) > 0 {
+		responses.SendAPIError(
+			w,
+			r,
+			http.StatusBadRequest,
+			"bad request: invalid listing filters",
+			problems,
+		)
+		return
+	}
+
+	cursor, limit, ok := parsePage(w, r, DefaultListingsLimit, MaxListingsLimit)
+	if !ok {
+		return
+	}
+
+	listings, hasMore, err := h.lootService.GetUserListings(parsedUserID, req.Status, cursor, limit)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	sendListings(w, r, listings, hasMore)
+}
+
+// sendListings responds with a page of listings, pointing the next page at the last one
+func sendListings(
+	w http.ResponseWriter,
+	r *http.Request,
+	listings []*entities.Listing,
+	hasMore bool,
+) {
+	res := responses.ServerResponse[[]*entities.Listing]{
+		Object:  responses.ObjectList,
+		Data:    listings,
+		HasMore: &hasMore,
+	}
+	if hasMore {
+		nextPage := strconv.FormatInt(listings[len(listings)-1].ID, 10)
+		res.NextPage = &nextPage
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleCreateListing(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleCreateListing")
+	body, problems, err := jsonutil.DecodeValid[CreateListingRequest](r)
+	if err != nil {
+		responses.HandleDecodeError(w, r, problems, err)
+		return
+	}
+
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	// validated by CreateListingRequest.Valid
+	userItemID := uuid.MustParse(body.UserItemID)
+	listing, err := h.lootService.CreateListing(parsedUserID, userItemID, body.Price)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Listing]{
+		Object: responses.ObjectListing,
+		Data:   listing,
+	}
+	responses.SendResponse(w, r, http.StatusCreated, res)
+}
+
+func (h *LootHandler) HandleCancelListing(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleCancelListing")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	listingID, err := strconv.ParseInt(r.PathValue("listingId"), 10, 64)
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid listing id", nil)
+		return
+	}
+
+	listing, err := h.lootService.CancelListing(parsedUserID, listingID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Listing]{
+		Object: responses.ObjectListing,
+		Data:   listing,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *LootHandler) HandleBuyListing(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleBuyListing")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	listingID, err := strconv.ParseInt(r.PathValue("listingId"), 10, 64)
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid listing id", nil)
+		return
+	}
+
+	purchase, err := h.lootService.BuyListing(parsedUserID, listingID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.ListingPurchase]{
+		Object: responses.ObjectListingPurchase,
+		Data:   purchase,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
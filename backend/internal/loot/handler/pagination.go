@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/responses"
+	"goalify/pkg/options"
+	"net/http"
+	"strconv"
+)
+
+// parsePage reads the cursor and limit query parameters of a cursor paginated list. It sends a
+// 400 and returns false when either is malformed.
+func parsePage(
+	w http.ResponseWriter,
+	r *http.Request,
+	defaultLimit, maxLimit int,
+) (options.Option[int64], int, bool) {
+	query := r.URL.Query()
+	cursor := options.None[int64]()
+	if query.Has("cursor") {
+		id, err := strconv.ParseInt(query.Get("cursor"), 10, 64)
+		if err != nil {
+			responses.SendAPIError(w, r, http.StatusBadRequest, "cursor must be an integer", nil)
+			return cursor, 0, false
+		}
+		cursor = options.Some(id)
+	}
+
+	limit := defaultLimit
+	if query.Has("limit") {
+		var err error
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil || limit < 1 || limit > maxLimit {
+			responses.SendAPIError(
+				w,
+				r,
+				http.StatusBadRequest,
+				fmt.Sprintf("limit must be an integer from 1 to %d", maxLimit),
+				nil,
+			)
+			return cursor, 0, false
+		}
+	}
+
+	return cursor, limit, true
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/avatar"
+	"goalify/internal/loot/service"
+	"goalify/pkg/options"
+	"goalify/pkg/stacktrace"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type (
+	LootHandler struct {
+		lootService    service.LootService
+		avatarRenderer *avatar.Renderer
+		traceLogger    stacktrace.TraceLogger
+	}
+	DropRateRequest struct {
+		ItemID   string  `json:"item_id"`
+		DropRate float64 `json:"drop_rate"`
+	}
+	ReplaceDropTableRequest struct {
+		Rates []DropRateRequest `json:"rates"`
+	}
+	SellDuplicatesRequest struct {
+		Rarity string `json:"rarity"`
+	}
+	GetUserItemsRequest struct {
+		Rarity options.Option[string]
+		Status options.Option[string]
+	}
+	RotateSeedRequest struct {
+		ClientSeed string `json:"client_seed"`
+	}
+	// SendGiftRequest addresses the recipient by recipient_id or recipient_email, and gifts either
+	// quantity of chest_id or the item user_item_id
+	SendGiftRequest struct {
+		RecipientID    string `json:"recipient_id"`
+		RecipientEmail string `json:"recipient_email"`
+		ChestID        string `json:"chest_id"`
+		UserItemID     string `json:"user_item_id"`
+		Quantity       int    `json:"quantity"`
+	}
+	GetGiftsRequest struct {
+		Status    options.Option[string]
+		Direction string
+	}
+	CreateListingRequest struct {
+		UserItemID string `json:"user_item_id"`
+		Price      int    `json:"price"`
+	}
+	GetListingsRequest struct {
+		Rarity options.Option[string]
+		Title  options.Option[string]
+	}
+	GetUserListingsRequest struct {
+		Status options.Option[string]
+	}
+	CraftRequest struct {
+		UserItemIDs []string `json:"user_item_ids"`
+	}
+	GetAvatarRequest struct {
+		Format string
+		Size   int
+	}
+)
+
+const (
+	DefaultOpeningsLimit = 20
+	MaxOpeningsLimit     = 100
+	DefaultListingsLimit = 20
+	MaxListingsLimit     = 100
+	maxTitleFilterLength = 100
+	maxCraftInputs       = 100
+)
+
+var (
+	itemRarities    = []string{"common", "rare", "epic", "legendary"}
+	itemStatuses    = []string{"equipped", "not_equipped"}
+	giftStatuses    = []string{"pending", "accepted", "declined", "expired"}
+	listingStatuses = []string{"active", "sold", "cancelled"}
+)
+
+func NewLootHandler(
+	lootService service.LootService,
+	avatarRenderer *avatar.Renderer,
+	traceLogger stacktrace.TraceLogger,
+) *LootHandler {
+	return &LootHandler{lootService, avatarRenderer, traceLogger}
+}
+
+func (r ReplaceDropTableRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if len(r.Rates) == 0 {
+		problems["rates"] = "rates are required"
+	}
+	for i, rate := range r.Rates {
+		if _, err := uuid.Parse(rate.ItemID); err != nil {
+			problems[fmt.Sprintf("rates[%d].item_id", i)] = "invalid item id"
+		}
+	}
+
+	return problems
+}
+
+func (r GetUserItemsRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if r.Rarity.IsPresent() && !slices.Contains(itemRarities, r.Rarity.ValueOrZero()) {
+		problems["rarity"] = "rarity must be one of 'common', 'rare', 'epic' or 'legendary'"
+	}
+	if r.Status.IsPresent() && !slices.Contains(itemStatuses, r.Status.ValueOrZero()) {
+		problems["status"] = "status must be either 'equipped' or 'not_equipped'"
+	}
+
+	return problems
+}
+
+func (r SellDuplicatesRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if r.Rarity == "" {
+		problems["rarity"] = "rarity is required"
+	} else if !slices.Contains(itemRarities, r.Rarity) {
+		problems["rarity"] = "rarity must be one of 'common', 'rare', 'epic' or 'legendary'"
+	}
+
+	return problems
+}
+
+func (r RotateSeedRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	// an empty client seed is replaced with a random one
+	if len(r.ClientSeed) > service.MaxClientSeedLength {
+		problems["client_seed"] = fmt.Sprintf(
+			"client seed must be at most %d characters",
+			service.MaxClientSeedLength,
+		)
+	}
+
+	return problems
+}
+
+func (r SendGiftRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if (r.RecipientID == "") == (r.RecipientEmail == "") {
+		problems["recipient"] = "exactly one of recipient_id or recipient_email is required"
+	} else if r.RecipientID != "" {
+		if _, err := uuid.Parse(r.RecipientID); err != nil {
+			problems["recipient_id"] = "invalid recipient id"
+		}
+	}
+
+	if (r.ChestID == "") == (r.UserItemID == "") {
+		problems["gift"] = "exactly one of chest_id or user_item_id is required"
+	}
+	if r.ChestID != "" {
+		if _, err := uuid.Parse(r.ChestID); err != nil {
+			problems["chest_id"] = "invalid chest id"
+		}
+		if r.Quantity < 1 {
+			problems["quantity"] = "quantity must be at least 1"
+		}
+	}
+	if r.UserItemID != "" {
+		if _, err := uuid.Parse(r.UserItemID); err != nil {
+			problems["user_item_id"] = "invalid item id"
+		}
+		if r.Quantity != 0 {
+			problems["quantity"] = "quantity only applies to chest gifts"
+		}
+	}
+
+	return problems
+}
+
+func (r GetGiftsRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if r.Direction != "received" && r.Direction != "sent" {
+		problems["direction"] = "direction must be either 'received' or 'sent'"
+	}
+	if r.Status.IsPresent() && !slices.Contains(giftStatuses, r.Status.ValueOrZero()) {
+		problems["status"] = "status must be one of 'pending', 'accepted', 'declined' or 'expired'"
+	}
+
+	return problems
+}
+
+func (r CreateListingRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if _, err := uuid.Parse(r.UserItemID); err != nil {
+		problems["user_item_id"] = "invalid item id"
+	}
+	if r.Price < 1 || r.Price > service.MaxListingPrice {
+		problems["price"] = fmt.Sprintf("price must be from 1 to %d", service.MaxListingPrice)
+	}
+
+	return problems
+}
+
+func (r GetListingsRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if r.Rarity.IsPresent() && !slices.Contains(itemRarities, r.Rarity.ValueOrZero()) {
+		problems["rarity"] = "rarity must be one of 'common', 'rare', 'epic' or 'legendary'"
+	}
+	if len(r.Title.ValueOrZero()) > maxTitleFilterLength {
+		problems["title"] = fmt.Sprintf("title must be at most %d characters", maxTitleFilterLength)
+	}
+
+	return problems
+}
+
+func (r GetUserListingsRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if r.Status.IsPresent() && !slices.Contains(listingStatuses, r.Status.ValueOrZero()) {
+		problems["status"] = "status must be one of 'active', 'sold' or 'cancelled'"
+	}
+
+	return problems
+}
+
+func (r CraftRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if len(r.UserItemIDs) == 0 {
+		problems["user_item_ids"] = "user item ids are required"
+	}
+	if len(r.UserItemIDs) > maxCraftInputs {
+		problems["user_item_ids"] = fmt.Sprintf("at most %d items can be crafted", maxCraftInputs)
+	}
+	seen := make(map[string]bool, len(r.UserItemIDs))
+	for i, id := range r.UserItemIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			problems[fmt.Sprintf("user_item_ids[%d]", i)] = "invalid item id"
+		}
+		if seen[id] {
+			problems[fmt.Sprintf("user_item_ids[%d]", i)] = "item is listed more than once"
+		}
+		seen[id] = true
+	}
+
+	return problems
+}
+
+func (r GetAvatarRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if !slices.Contains(avatar.Formats, r.Format) {
+		problems["format"] = "format must be either 'svg' or 'png'"
+	}
+	if r.Size < avatar.MinSize || r.Size > avatar.MaxSize {
+		problems["size"] = fmt.Sprintf(
+			"size must be an integer from %d to %d",
+			avatar.MinSize,
+			avatar.MaxSize,
+		)
+	}
+
+	return problems
+}
@@ -0,0 +1,44 @@
+package service
+
+import "goalify/internal/entities"
+
+// collectionPercent returns discovered as a percentage of total, rounded to two decimal places.
+// An empty set counts as complete.
+func collectionPercent(discovered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(discovered*10000/total) / 100
+}
+
+// rollUpCollection fills in the overall, per rarity and per chest completion of a collection from
+// its items and chest counts
+func rollUpCollection(collection *entities.Collection) {
+	collection.Overall = &entities.CollectionProgress{}
+	collection.ByRarity = make(map[string]*entities.CollectionProgress)
+
+	for _, entry := range collection.Items {
+		progress, ok := collection.ByRarity[entry.Item.Rarity]
+		if !ok {
+			progress = &entities.CollectionProgress{}
+			collection.ByRarity[entry.Item.Rarity] = progress
+		}
+		progress.Total++
+		collection.Overall.Total++
+		if entry.Discovered {
+			progress.Discovered++
+			collection.Overall.Discovered++
+		}
+	}
+
+	collection.Overall.Percent = collectionPercent(
+		collection.Overall.Discovered,
+		collection.Overall.Total,
+	)
+	for _, progress := range collection.ByRarity {
+		progress.Percent = collectionPercent(progress.Discovered, progress.Total)
+	}
+	for _, chest := range collection.Chests {
+		chest.Progress.Percent = collectionPercent(chest.Progress.Discovered, chest.Progress.Total)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"goalify/internal/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCollectionEntry(rarity string, discovered bool) *entities.CollectionEntry {
+	return &entities.CollectionEntry{
+		Item:       &entities.ChestItem{Rarity: rarity},
+		Discovered: discovered,
+	}
+}
+
+func TestCollectionPercent(t *testing.T) {
+	assert.Equal(t, 0.0, collectionPercent(0, 4))
+	assert.Equal(t, 50.0, collectionPercent(2, 4))
+	assert.Equal(t, 33.33, collectionPercent(1, 3))
+	assert.Equal(t, 66.66, collectionPercent(2, 3))
+	assert.Equal(t, 100.0, collectionPercent(3, 3))
+	assert.Equal(t, 100.0, collectionPercent(0, 0))
+}
+
+func TestRollUpCollection(t *testing.T) {
+	collection := &entities.Collection{
+		Items: []*entities.CollectionEntry{
+			newCollectionEntry("common", true),
+			newCollectionEntry("common", false),
+			newCollectionEntry("rare", true),
+			newCollectionEntry("legendary", false),
+		},
+		Chests: []*entities.ChestCollection{
+			{Progress: &entities.CollectionProgress{Discovered: 1, Total: 4}},
+			{Progress: &entities.CollectionProgress{Discovered: 2, Total: 2}},
+		},
+	}
+
+	rollUpCollection(collection)
+
+	expected := &entities.CollectionProgress{Discovered: 2, Total: 4, Percent: 50}
+	assert.Equal(t, expected, collection.Overall)
+	require.Len(t, collection.ByRarity, 3)
+	assert.Equal(t, 50.0, collection.ByRarity["common"].Percent)
+	assert.Equal(t, 100.0, collection.ByRarity["rare"].Percent)
+	assert.Equal(t, 0.0, collection.ByRarity["legendary"].Percent)
+	assert.Equal(t, 25.0, collection.Chests[0].Progress.Percent)
+	assert.Equal(t, 100.0, collection.Chests[1].Progress.Percent)
+}
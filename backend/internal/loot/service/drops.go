@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"goalify/internal/entities"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// ErrEmptyDropTable is returned when a chest has no items that can drop
+var ErrEmptyDropTable = errors.New("chest has no items to drop")
+
+// DropRateSumTolerance is how far a drop table's rates may sum from 1 before it is rejected. It
+// absorbs float error from rates such as 0.7, 0.2 and 0.1 without letting real mistakes through.
+const DropRateSumTolerance = 1e-6
+
+// validateDropTable checks that a replacement drop table is non-empty, lists each item once, has
+// only positive rates and sums to 1 within DropRateSumTolerance.
+func validateDropTable(rates []*entities.ChestItemDropRate) error {
+	if len(rates) == 0 {
+		return errors.New("drop table must contain at least one item")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(rates))
+	total := 0.0
+	for _, rate := range rates {
+		if seen[rate.ItemID] {
+			return fmt.Errorf("item %s is listed more than once", rate.ItemID)
+		}
+		seen[rate.ItemID] = true
+
+		if rate.DropRate <= 0 || rate.DropRate > 1 {
+			return fmt.Errorf(
+				"drop rate for item %s must be greater than 0 and at most 1",
+				rate.ItemID,
+			)
+		}
+		total += rate.DropRate
+	}
+
+	if math.Abs(total-1) > DropRateSumTolerance {
+		return fmt.Errorf("drop rates must sum to 1, got %g", total)
+	}
+	return nil
+}
+
+// pickWeighted selects an item from the drop table using a roll in [0, 1). Each item's chance is
+// its drop rate divided by the table's total, so every row carries its relative weight.
+func pickWeighted(rates []*entities.ChestItemDropRate, roll float64) (uuid.UUID, error) {
+	total := 0.0
+	for _, rate := range rates {
+		if rate.DropRate > 0 {
+			total += rate.DropRate
+		}
+	}
+	if total == 0 {
+		return uuid.Nil, ErrEmptyDropTable
+	}
+
+	target := roll * total
+	cumulative := 0.0
+	var last uuid.UUID
+	for _, rate := range rates {
+		if rate.DropRate <= 0 {
+			continue
+		}
+		cumulative += rate.DropRate
+		last = rate.ItemID
+		if target < cumulative {
+			return rate.ItemID, nil
+		}
+	}
+
+	// floating point error can leave target a hair above the final bucket
+	return last, nil
+}
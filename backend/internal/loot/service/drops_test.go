@@ -0,0 +1,119 @@
+package service
+
+import (
+	"goalify/internal/entities"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDropTable(rates ...float64) []*entities.ChestItemDropRate {
+	table := make([]*entities.ChestItemDropRate, len(rates))
+	for i, rate := range rates {
+		table[i] = &entities.ChestItemDropRate{ItemID: uuid.New(), DropRate: rate}
+	}
+	return table
+}
+
+func TestPickWeightedBoundaries(t *testing.T) {
+	table := newDropTable(0.5, 0.25, 0.25)
+
+	tests := []struct {
+		name string
+		roll float64
+		want uuid.UUID
+	}{
+		{name: "lowest roll", roll: 0, want: table[0].ItemID},
+		{name: "just below first bucket edge", roll: 0.4999, want: table[0].ItemID},
+		{name: "first bucket edge", roll: 0.5, want: table[1].ItemID},
+		{name: "last bucket", roll: 0.8, want: table[2].ItemID},
+		{name: "highest roll", roll: 0.999999, want: table[2].ItemID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickWeighted(table, tt.roll)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPickWeightedEmptyTable(t *testing.T) {
+	_, err := pickWeighted(nil, 0.5)
+	assert.ErrorIs(t, err, ErrEmptyDropTable)
+
+	_, err = pickWeighted(newDropTable(0, 0), 0.5)
+	assert.ErrorIs(t, err, ErrEmptyDropTable)
+}
+
+func TestPickWeightedDistribution(t *testing.T) {
+	table := newDropTable(0.6, 0.3, 0.09, 0.01)
+	rng := rand.New(rand.NewPCG(42, 1024))
+
+	const rolls = 200_000
+	counts := make(map[uuid.UUID]int)
+	for range rolls {
+		itemID, err := pickWeighted(table, rng.Float64())
+		require.NoError(t, err)
+		counts[itemID]++
+	}
+
+	for _, rate := range table {
+		observed := float64(counts[rate.ItemID]) / rolls
+		assert.InDelta(t, rate.DropRate, observed, 0.005,
+			"item with drop rate %.2f dropped %.4f of the time", rate.DropRate, observed)
+	}
+}
+
+func TestPickWeightedIsDeterministicForSeed(t *testing.T) {
+	table := newDropTable(0.5, 0.25, 0.25)
+
+	pickAll := func() []uuid.UUID {
+		rng := rand.New(rand.NewPCG(7, 7))
+		picks := make([]uuid.UUID, 50)
+		for i := range picks {
+			itemID, err := pickWeighted(table, rng.Float64())
+			require.NoError(t, err)
+			picks[i] = itemID
+		}
+		return picks
+	}
+
+	assert.Equal(t, pickAll(), pickAll())
+}
+
+func TestValidateDropTable(t *testing.T) {
+	duplicated := newDropTable(0.5, 0.5)
+	duplicated[1].ItemID = duplicated[0].ItemID
+
+	tests := []struct {
+		name    string
+		rates   []*entities.ChestItemDropRate
+		wantErr bool
+	}{
+		{name: "valid table", rates: newDropTable(0.5, 0.25, 0.25)},
+		{name: "float error within tolerance", rates: newDropTable(0.7, 0.2, 0.1)},
+		{name: "single item", rates: newDropTable(1)},
+		{name: "empty table", rates: newDropTable(), wantErr: true},
+		{name: "sum below one", rates: newDropTable(0.5, 0.4), wantErr: true},
+		{name: "sum above one", rates: newDropTable(0.5, 0.6), wantErr: true},
+		{name: "zero rate", rates: newDropTable(1, 0), wantErr: true},
+		{name: "negative rate", rates: newDropTable(1.5, -0.5), wantErr: true},
+		{name: "duplicate item", rates: duplicated, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDropTable(tt.rates)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/loot/stores"
+	"io"
+	"slices"
+)
+
+// Openings are provably fair. Before a roll, the user is shown the SHA-256 of a secret server
+// seed. Each opening rolls with HMAC-SHA256(server seed, "client seed:nonce"), where the nonce
+// counts up from 0 for the seed pair. The first 8 bytes of the digest, as a big-endian integer
+// shifted down to 53 bits, over 2^53 give a roll in [0, 1). The roll picks from the drop table
+// ordered by item id, after pity reweighting when the opening recorded a top drop chance. Rotating
+// the pair reveals the server seed, so every opening rolled with it can be recomputed. Crafts draw
+// the next nonce from the same pair and roll against only the recipe's output rarity items,
+// leaving out consumables.
+
+const (
+	serverSeedBytes = 32
+	clientSeedBytes = 8
+	// MaxClientSeedLength bounds the client seeds users may choose
+	MaxClientSeedLength = 64
+)
+
+// newSeed returns n bytes read from entropy, hex encoded
+func newSeed(entropy io.Reader, n int) (string, error) {
+	seed := make([]byte, n)
+	if _, err := io.ReadFull(entropy, seed); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(seed), nil
+}
+
+// newSeedCommitment generates a server seed and its hash from the service's entropy source,
+// paired with clientSeed or a random client seed when it is empty. Sources other than
+// crypto/rand.Reader are not safe for concurrent use, so reads are serialized.
+func (ls *lootService) newSeedCommitment(clientSeed string) (stores.SeedCommitment, error) {
+	ls.entropyMu.Lock()
+	defer ls.entropyMu.Unlock()
+
+	serverSeed, err := newSeed(ls.entropy, serverSeedBytes)
+	if err != nil {
+		return stores.SeedCommitment{}, err
+	}
+	if clientSeed == "" {
+		clientSeed, err = newSeed(ls.entropy, clientSeedBytes)
+		if err != nil {
+			return stores.SeedCommitment{}, err
+		}
+	}
+
+	return stores.SeedCommitment{
+		ServerSeed:     serverSeed,
+		ServerSeedHash: hashServerSeed(serverSeed),
+		ClientSeed:     clientSeed,
+	}, nil
+}
+
+// hashServerSeed returns the hex encoded SHA-256 commitment shown before a server seed is revealed
+func hashServerSeed(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// fairRoll derives the roll in [0, 1) for the nonce-th opening with a seed pair
+func fairRoll(serverSeed, clientSeed string, nonce int) float64 {
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	fmt.Fprintf(mac, "%s:%d", clientSeed, nonce)
+	sum := mac.Sum(nil)
+	return float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+}
+
+// rollOrder returns a copy of the drop table sorted by item id, the order rolls are made against,
+// so the result does not depend on the order rows come back from the database
+func rollOrder(rates []*entities.ChestItemDropRate) []*entities.ChestItemDropRate {
+	sorted := slices.Clone(rates)
+	slices.SortFunc(sorted, func(a, b *entities.ChestItemDropRate) int {
+		return bytes.Compare(a.ItemID[:], b.ItemID[:])
+	})
+	return sorted
+}
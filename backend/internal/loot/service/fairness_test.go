@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashServerSeed(t *testing.T) {
+	assert.Equal(
+		t,
+		"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		hashServerSeed("abc"),
+	)
+}
+
+func TestNewSeedCommitment(t *testing.T) {
+	ls := &lootService{entropy: rand.Reader}
+	commitment, err := ls.newSeedCommitment("")
+	require.NoError(t, err)
+	assert.Len(t, commitment.ServerSeed, serverSeedBytes*2)
+	assert.Len(t, commitment.ClientSeed, clientSeedBytes*2)
+	assert.Equal(t, hashServerSeed(commitment.ServerSeed), commitment.ServerSeedHash)
+
+	chosen, err := ls.newSeedCommitment("lucky")
+	require.NoError(t, err)
+	assert.Equal(t, "lucky", chosen.ClientSeed)
+	assert.NotEqual(t, commitment.ServerSeed, chosen.ServerSeed)
+}
+
+func TestNewSeedCommitmentInjectedEntropy(t *testing.T) {
+	// a fixed entropy source gives fixed seeds, so rolls made with them can be checked exactly
+	entropy := bytes.Repeat([]byte{0xab}, serverSeedBytes+clientSeedBytes)
+	ls := &lootService{entropy: bytes.NewReader(entropy)}
+	commitment, err := ls.newSeedCommitment("")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("ab", serverSeedBytes), commitment.ServerSeed)
+	assert.Equal(t, strings.Repeat("ab", clientSeedBytes), commitment.ClientSeed)
+	assert.Equal(t, hashServerSeed(commitment.ServerSeed), commitment.ServerSeedHash)
+
+	// running out of entropy fails rather than committing a short seed
+	_, err = ls.newSeedCommitment("lucky")
+	assert.Error(t, err)
+}
+
+func TestFairRoll(t *testing.T) {
+	// recomputed the way the fairness doc describes, as a user verifying an opening would
+	mac := hmac.New(sha256.New, []byte("server"))
+	fmt.Fprint(mac, "client:3")
+	sum := mac.Sum(nil)
+	want := float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+
+	assert.Equal(t, want, fairRoll("server", "client", 3))
+	assert.Equal(t, fairRoll("server", "client", 3), fairRoll("server", "client", 3))
+	assert.NotEqual(t, fairRoll("server", "client", 3), fairRoll("server", "client", 4))
+	assert.NotEqual(t, fairRoll("server", "client", 3), fairRoll("server", "other", 3))
+}
+
+func TestFairRollDistribution(t *testing.T) {
+	const n = 20000
+	sum := 0.0
+	for nonce := range n {
+		roll := fairRoll("server", "client", nonce)
+		require.GreaterOrEqual(t, roll, 0.0)
+		require.Less(t, roll, 1.0)
+		sum += roll
+	}
+	assert.InDelta(t, 0.5, sum/n, 0.01)
+}
+
+func TestRollOrder(t *testing.T) {
+	table := newDropTable(0.2, 0.3, 0.5)
+	table[0].ItemID = uuid.MustParse("cccccccc-0000-0000-0000-000000000000")
+	table[1].ItemID = uuid.MustParse("aaaaaaaa-0000-0000-0000-000000000000")
+	table[2].ItemID = uuid.MustParse("bbbbbbbb-0000-0000-0000-000000000000")
+
+	sorted := rollOrder(table)
+	assert.Equal(t, table[1].ItemID, sorted[0].ItemID)
+	assert.Equal(t, table[2].ItemID, sorted[1].ItemID)
+	assert.Equal(t, table[0].ItemID, sorted[2].ItemID)
+	// the input table is not modified
+	assert.Equal(t, 0.2, table[0].DropRate)
+}
+
+func TestBoostedTopChanceReplaysPity(t *testing.T) {
+	cfg := PityConfig{SoftThreshold: 10, HardThreshold: 20}
+	table := newRarityTable([]string{"legendary", "common"}, 0.1, 0.9)
+	table[0].ItemID = uuid.MustParse("bbbbbbbb-0000-0000-0000-000000000000")
+	table[1].ItemID = uuid.MustParse("aaaaaaaa-0000-0000-0000-000000000000")
+
+	assert.False(t, cfg.boostedTopChance(table, 0).IsPresent())
+
+	// the recorded chance alone reproduces the pity reweighting, so each roll picks the common
+	// item, first in roll order, below 1 - chance and the legendary from there
+	chance, ok := cfg.boostedTopChance(table, 14).GetVal()
+	require.True(t, ok)
+	assert.InDelta(t, 0.1+0.9*0.5, chance, 1e-9)
+	replayed := reweightTop(rollOrder(table), chance)
+	for nonce := range 100 {
+		roll := fairRoll("server", "client", nonce)
+		want := table[1].ItemID
+		if roll >= 1-chance {
+			want = table[0].ItemID
+		}
+		itemID, err := pickWeighted(replayed, roll)
+		require.NoError(t, err)
+		assert.Equal(t, want, itemID, roll)
+	}
+}
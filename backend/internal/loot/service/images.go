@@ -0,0 +1,95 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	// registers PNG and JPEG with image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+const (
+	// MaxItemImageBytes is the largest item image that can be uploaded
+	MaxItemImageBytes = 2 << 20
+	// item images are square, with sides from MinItemImageSide to MaxItemImageSide pixels
+	MinItemImageSide = 64
+	MaxItemImageSide = 1024
+)
+
+// itemImageExtensions maps the image types accepted for items to their key extensions
+var itemImageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+}
+
+// errInvalidItemImage is wrapped by every reason an upload is rejected as item art
+var errInvalidItemImage = errors.New("invalid item image")
+
+// itemImage is what inspectItemImage learns about an upload
+type itemImage struct {
+	key         string
+	contentType string
+	width       int
+	height      int
+}
+
+// inspectItemImage checks that data is usable as item art: a PNG or JPEG, judged by its contents
+// rather than any declared type, within the size and dimension limits. The returned key is named
+// after the image's hash, so identical uploads share a blob and a blob never changes.
+func inspectItemImage(data []byte) (*itemImage, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: image is empty", errInvalidItemImage)
+	}
+	if len(data) > MaxItemImageBytes {
+		return nil, fmt.Errorf(
+			"%w: image is larger than %d bytes",
+			errInvalidItemImage,
+			MaxItemImageBytes,
+		)
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := itemImageExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: %s is not a png or jpeg image",
+			errInvalidItemImage,
+			contentType,
+		)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: image could not be decoded", errInvalidItemImage)
+	}
+	if config.Width != config.Height {
+		return nil, fmt.Errorf(
+			"%w: image must be square, got %dx%d",
+			errInvalidItemImage,
+			config.Width,
+			config.Height,
+		)
+	}
+	if config.Width < MinItemImageSide || config.Width > MaxItemImageSide {
+		return nil, fmt.Errorf(
+			"%w: image sides must be from %d to %d pixels, got %d",
+			errInvalidItemImage,
+			MinItemImageSide,
+			MaxItemImageSide,
+			config.Width,
+		)
+	}
+
+	sum := sha256.Sum256(data)
+	return &itemImage{
+		key:         "items/" + hex.EncodeToString(sum[:]) + ext,
+		contentType: contentType,
+		width:       config.Width,
+		height:      config.Height,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	require.NoError(t, png.Encode(&b, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return b.Bytes()
+}
+
+func TestInspectItemImage(t *testing.T) {
+	data := encodePNG(t, 128, 128)
+	img, err := inspectItemImage(data)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", img.contentType)
+	assert.Equal(t, 128, img.width)
+	assert.Equal(t, 128, img.height)
+	assert.True(t, strings.HasPrefix(img.key, "items/"))
+	assert.True(t, strings.HasSuffix(img.key, ".png"))
+
+	again, err := inspectItemImage(encodePNG(t, 128, 128))
+	require.NoError(t, err)
+	assert.Equal(t, img.key, again.key)
+
+	var b bytes.Buffer
+	require.NoError(t, jpeg.Encode(&b, image.NewRGBA(image.Rect(0, 0, 64, 64)), nil))
+	img, err = inspectItemImage(b.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", img.contentType)
+	assert.True(t, strings.HasSuffix(img.key, ".jpg"))
+}
+
+func TestInspectItemImageRejects(t *testing.T) {
+	// a PNG signature followed by garbage sniffs as a PNG but does not decode
+	corrupt := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), bytes.Repeat([]byte{0}, 32)...)
+
+	tests := map[string][]byte{
+		"empty":      nil,
+		"too large":  make([]byte, MaxItemImageBytes+1),
+		"not image":  []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"),
+		"corrupt":    corrupt,
+		"not square": encodePNG(t, 128, 64),
+		"too small":  encodePNG(t, MinItemImageSide-1, MinItemImageSide-1),
+		"too big":    encodePNG(t, MaxItemImageSide+1, MaxItemImageSide+1),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := inspectItemImage(data)
+			assert.ErrorIs(t, err, errInvalidItemImage)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package service
+
+// MaxListingPrice bounds the cash price a marketplace listing may ask
+const MaxListingPrice = 1_000_000
+
+// marketplaceFee returns the house's cut of a sale at price, feePercent percent rounded down so
+// the seller never receives less than the advertised share
+func marketplaceFee(price, feePercent int) int {
+	return price * feePercent / 100
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarketplaceFee(t *testing.T) {
+	tests := []struct {
+		name       string
+		price      int
+		feePercent int
+		expected   int
+	}{
+		{name: "no fee", price: 250, feePercent: 0, expected: 0},
+		{name: "exact percentage", price: 200, feePercent: 5, expected: 10},
+		{name: "rounds down", price: 99, feePercent: 5, expected: 4},
+		{name: "small sale", price: 1, feePercent: 10, expected: 0},
+		{name: "house keeps everything", price: 80, feePercent: 100, expected: 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, marketplaceFee(tt.price, tt.feePercent))
+		})
+	}
+}
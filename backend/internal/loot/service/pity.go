@@ -0,0 +1,132 @@
+package service
+
+import (
+	"goalify/internal/entities"
+	"goalify/pkg/options"
+
+	"github.com/google/uuid"
+)
+
+// PityConfig controls bad-luck protection for the highest rarity in a chest's drop table. After
+// SoftThreshold openings without a top rarity drop, each opening raises its odds linearly until the
+// HardThreshold-th opening, which is guaranteed to land one. A HardThreshold of 0 turns pity off.
+type PityConfig struct {
+	SoftThreshold int
+	HardThreshold int
+}
+
+// rarityRanks mirrors the order of the item_type enum, lowest to highest
+var rarityRanks = map[string]int{"common": 0, "rare": 1, "epic": 2, "legendary": 3}
+
+func (c PityConfig) enabled() bool {
+	return c.HardThreshold > 0
+}
+
+// topRarity returns the highest rarity present in the drop table
+func topRarity(rates []*entities.ChestItemDropRate) string {
+	top := ""
+	for _, rate := range rates {
+		if top == "" || rarityRanks[rate.Rarity] > rarityRanks[top] {
+			top = rate.Rarity
+		}
+	}
+	return top
+}
+
+func rarityOf(rates []*entities.ChestItemDropRate, itemID uuid.UUID) string {
+	for _, rate := range rates {
+		if rate.ItemID == itemID {
+			return rate.Rarity
+		}
+	}
+	return ""
+}
+
+// rarityTotals sums the positive drop rates of the whole table and of the rarity's items
+func rarityTotals(rates []*entities.ChestItemDropRate, rarity string) (total, rarityTotal float64) {
+	for _, rate := range rates {
+		if rate.DropRate <= 0 {
+			continue
+		}
+		total += rate.DropRate
+		if rate.Rarity == rarity {
+			rarityTotal += rate.DropRate
+		}
+	}
+	return total, rarityTotal
+}
+
+// topDropChance returns the chance the next opening lands the top rarity, given its base chance in
+// the drop table and the openings since the user last received it
+func (c PityConfig) topDropChance(base float64, opensSinceTopDrop int) float64 {
+	opening := opensSinceTopDrop + 1
+	if !c.enabled() || opening <= c.SoftThreshold {
+		return base
+	}
+	if opening >= c.HardThreshold {
+		return 1
+	}
+
+	progress := float64(opening-c.SoftThreshold) / float64(c.HardThreshold-c.SoftThreshold)
+	return base + (1-base)*progress
+}
+
+// boostedTopChance returns the top rarity's combined chance after pity for a user with
+// opensSinceTopDrop, or None when the drop table's own odds apply
+func (c PityConfig) boostedTopChance(
+	rates []*entities.ChestItemDropRate,
+	opensSinceTopDrop int,
+) options.Option[float64] {
+	top := topRarity(rates)
+	total, topTotal := rarityTotals(rates, top)
+	if topTotal == 0 || topTotal == total {
+		return options.None[float64]()
+	}
+
+	base := topTotal / total
+	chance := c.topDropChance(base, opensSinceTopDrop)
+	if chance == base {
+		return options.None[float64]()
+	}
+	return options.Some(chance)
+}
+
+// reweightTop returns a copy of the drop table where the top rarity's items share chance between
+// them and every other item shares the rest, each keeping its relative odds within its group
+func reweightTop(
+	rates []*entities.ChestItemDropRate,
+	chance float64,
+) []*entities.ChestItemDropRate {
+	top := topRarity(rates)
+	total, topTotal := rarityTotals(rates, top)
+	if topTotal == 0 || topTotal == total {
+		return rates
+	}
+
+	topScale := chance / topTotal
+	otherScale := (1 - chance) / (total - topTotal)
+	boosted := make([]*entities.ChestItemDropRate, len(rates))
+	for i, rate := range rates {
+		adjusted := *rate
+		if rate.Rarity == top {
+			adjusted.DropRate *= topScale
+		} else {
+			adjusted.DropRate *= otherScale
+		}
+		boosted[i] = &adjusted
+	}
+	return boosted
+}
+
+// state describes the user's pity progress towards rarity, or nil when pity is turned off
+func (c PityConfig) state(rarity string, opensSinceTopDrop int) *entities.PityState {
+	if !c.enabled() || rarity == "" {
+		return nil
+	}
+	return &entities.PityState{
+		Rarity:               rarity,
+		OpensSinceTopDrop:    opensSinceTopDrop,
+		Threshold:            c.SoftThreshold,
+		OpensUntilGuaranteed: max(c.HardThreshold-opensSinceTopDrop, 1),
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"goalify/internal/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRarityTable(rarities []string, rates ...float64) []*entities.ChestItemDropRate {
+	table := newDropTable(rates...)
+	for i, rate := range table {
+		rate.Rarity = rarities[i]
+	}
+	return table
+}
+
+func topShare(rates []*entities.ChestItemDropRate, rarity string) float64 {
+	total, top := 0.0, 0.0
+	for _, rate := range rates {
+		total += rate.DropRate
+		if rate.Rarity == rarity {
+			top += rate.DropRate
+		}
+	}
+	return top / total
+}
+
+func TestTopRarity(t *testing.T) {
+	table := newRarityTable([]string{"rare", "legendary", "common"}, 0.3, 0.1, 0.6)
+	assert.Equal(t, "legendary", topRarity(table))
+
+	table = newRarityTable([]string{"common", "epic", "rare"}, 0.5, 0.2, 0.3)
+	assert.Equal(t, "epic", topRarity(table))
+
+	assert.Equal(t, "", topRarity(nil))
+}
+
+func TestTopDropChanceRamp(t *testing.T) {
+	cfg := PityConfig{SoftThreshold: 10, HardThreshold: 20}
+	base := 0.05
+
+	tests := []struct {
+		name              string
+		opensSinceTopDrop int
+		want              float64
+	}{
+		{name: "fresh counter", opensSinceTopDrop: 0, want: base},
+		{name: "at soft threshold", opensSinceTopDrop: 9, want: base},
+		{name: "first boosted opening", opensSinceTopDrop: 10, want: base + (1-base)*0.1},
+		{name: "half way", opensSinceTopDrop: 14, want: base + (1-base)*0.5},
+		{name: "guaranteed", opensSinceTopDrop: 19, want: 1},
+		{name: "past guarantee", opensSinceTopDrop: 40, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, cfg.topDropChance(base, tt.opensSinceTopDrop), 1e-9)
+		})
+	}
+
+	disabled := PityConfig{}
+	assert.Equal(t, base, disabled.topDropChance(base, 100))
+}
+
+func TestReweightTop(t *testing.T) {
+	cfg := PityConfig{SoftThreshold: 10, HardThreshold: 20}
+	table := newRarityTable(
+		[]string{"common", "rare", "legendary", "legendary"},
+		0.6, 0.3, 0.06, 0.04,
+	)
+	ordered := rollOrder(table)
+
+	// below the threshold the table's own odds apply
+	assert.False(t, cfg.boostedTopChance(ordered, 5).IsPresent())
+
+	chance, ok := cfg.boostedTopChance(ordered, 14).GetVal()
+	require.True(t, ok)
+	assert.InDelta(t, 0.1+0.9*0.5, chance, 1e-9)
+	boosted := reweightTop(ordered, chance)
+	total := 0.0
+	for _, rate := range boosted {
+		total += rate.DropRate
+	}
+	assert.InDelta(t, 1, total, 1e-9)
+	assert.InDelta(t, chance, topShare(boosted, "legendary"), 1e-9)
+	// relative odds within each group are kept
+	for i, rate := range boosted {
+		for j, other := range boosted {
+			if (rate.Rarity == "legendary") == (other.Rarity == "legendary") {
+				want := ordered[i].DropRate / ordered[j].DropRate
+				assert.InDelta(t, want, rate.DropRate/other.DropRate, 1e-9)
+			}
+		}
+	}
+	// the input table is not modified
+	assert.Equal(t, 0.6, table[0].DropRate)
+
+	// the guaranteed opening lands the top rarity whatever the seed pair rolls
+	chance, ok = cfg.boostedTopChance(ordered, 19).GetVal()
+	require.True(t, ok)
+	assert.Equal(t, 1.0, chance)
+	guaranteed := reweightTop(ordered, chance)
+	for nonce := range 1000 {
+		itemID, err := pickWeighted(guaranteed, fairRoll("server", "client", nonce))
+		require.NoError(t, err)
+		assert.Equal(t, "legendary", rarityOf(table, itemID))
+	}
+}
+
+func TestReweightTopSingleRarity(t *testing.T) {
+	cfg := PityConfig{SoftThreshold: 1, HardThreshold: 2}
+	table := newRarityTable([]string{"common", "common"}, 0.5, 0.5)
+	assert.False(t, cfg.boostedTopChance(table, 5).IsPresent())
+	assert.Equal(t, table, reweightTop(table, 1))
+}
+
+func TestPityState(t *testing.T) {
+	cfg := PityConfig{SoftThreshold: 30, HardThreshold: 50}
+
+	state := cfg.state("legendary", 0)
+	require.NotNil(t, state)
+	assert.Equal(t, 50, state.OpensUntilGuaranteed)
+	assert.Equal(t, 30, state.Threshold)
+
+	state = cfg.state("legendary", 49)
+	assert.Equal(t, 1, state.OpensUntilGuaranteed)
+
+	assert.Nil(t, PityConfig{}.state("legendary", 3))
+	assert.Nil(t, cfg.state("", 3))
+}
@@ -0,0 +1,961 @@
+// Package service is the business logic layer for the loot domain
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/events"
+	"goalify/internal/loot/stores"
+	"goalify/internal/responses"
+	"goalify/internal/storage"
+	"goalify/pkg/options"
+	"goalify/pkg/stacktrace"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LootService interface {
+	GetChests() ([]*entities.Chest, error)
+	GetChestByID(chestID uuid.UUID) (*entities.Chest, error)
+	GetChestForUser(userID, chestID uuid.UUID) (*entities.Chest, error)
+	GetChestItemsByChestID(chestID uuid.UUID) ([]*entities.ChestItem, error)
+	GetDropRatesByChestID(chestID uuid.UUID) ([]*entities.ChestItemDropRate, error)
+
+	ReplaceDropTable(
+		chestID, adminID uuid.UUID,
+		rates []*entities.ChestItemDropRate,
+	) (*entities.DropTable, error)
+	GetDropTableHistory(chestID uuid.UUID) ([]*entities.DropTable, error)
+
+	PurchaseChest(userID, chestID uuid.UUID) (*entities.ChestPurchase, error)
+	OpenChest(userID, chestID uuid.UUID) (*entities.ChestOpening, error)
+	GetChestOpenings(
+		userID uuid.UUID,
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.ChestOpeningRecord, bool, error)
+	GetFairnessSeed(userID uuid.UUID) (*entities.FairnessSeed, error)
+	RotateFairnessSeed(userID uuid.UUID, clientSeed string) (*entities.SeedRotation, error)
+
+	GetUserItems(userID uuid.UUID, filter stores.UserItemFilter) ([]*entities.UserItem, error)
+	EquipItem(userID, userItemID uuid.UUID) (*entities.UserItem, error)
+	UnequipItem(userID, userItemID uuid.UUID) (*entities.UserItem, error)
+	SellItem(userID, userItemID uuid.UUID) (*entities.ItemSale, error)
+	SellDuplicateItems(userID uuid.UUID, rarity string) (*entities.ItemSale, error)
+
+	SendGift(
+		senderID uuid.UUID,
+		recipient stores.GiftRecipient,
+		contents stores.GiftContents,
+	) (*entities.Gift, error)
+	AcceptGift(userID, giftID uuid.UUID) (*entities.Gift, error)
+	DeclineGift(userID, giftID uuid.UUID) (*entities.Gift, error)
+	ExpireGifts() ([]*entities.Gift, error)
+	GetGifts(userID uuid.UUID, filter stores.GiftFilter) ([]*entities.Gift, error)
+
+	CreateListing(sellerID, userItemID uuid.UUID, price int) (*entities.Listing, error)
+	CancelListing(sellerID uuid.UUID, listingID int64) (*entities.Listing, error)
+	BuyListing(buyerID uuid.UUID, listingID int64) (*entities.ListingPurchase, error)
+	GetListings(
+		filter stores.ListingFilter,
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.Listing, bool, error)
+	GetUserListings(
+		sellerID uuid.UUID,
+		status options.Option[string],
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.Listing, bool, error)
+
+	GetCollection(userID uuid.UUID) (*entities.Collection, error)
+	ClaimCollectionBonus(userID, chestID uuid.UUID) (*entities.CollectionBonus, error)
+
+	GetAvatar(userID uuid.UUID) (*entities.Avatar, error)
+	UploadItemImage(itemID uuid.UUID, data []byte) (*entities.ItemImage, error)
+
+	GetCraftingRecipes() ([]*entities.CraftingRecipe, error)
+	Craft(userID, recipeID uuid.UUID, userItemIDs []uuid.UUID) (*entities.Craft, error)
+
+	ConsumeItem(userID, userItemID uuid.UUID) (*entities.ItemConsumption, error)
+	GetActiveEffects(userID uuid.UUID) ([]*entities.ActiveEffect, error)
+	DeleteExpiredEffects() (int, error)
+}
+
+// Config holds the loot economy's tunable settings
+type Config struct {
+	Pity PityConfig
+	// how long a gift stays pending before it returns to its sender
+	GiftExpiry time.Duration
+	// percentage of each marketplace sale kept by the house
+	MarketplaceFeePercent int
+	// cash paid, once per chest, for discovering every item in its drop table
+	CollectionBonus int
+}
+
+type lootService struct {
+	lootStore      stores.LootStore
+	traceLogger    stacktrace.TraceLogger
+	eventPublisher events.EventPublisher
+	// entropy supplies fairness server seeds, crypto/rand.Reader outside of tests
+	entropy   io.Reader
+	entropyMu sync.Mutex
+	// item art is kept in blobs and linked through signer's URLs
+	blobs  storage.BlobStore
+	signer *storage.URLSigner
+	config Config
+}
+
+func NewLootService(
+	lootStore stores.LootStore,
+	traceLogger stacktrace.TraceLogger,
+	ep events.EventPublisher,
+	entropy io.Reader,
+	blobs storage.BlobStore,
+	signer *storage.URLSigner,
+	config Config,
+) LootService {
+	return &lootService{
+		lootStore:      lootStore,
+		traceLogger:    traceLogger,
+		eventPublisher: ep,
+		entropy:        entropy,
+		blobs:          blobs,
+		signer:         signer,
+		config:         config,
+	}
+}
+
+func (ls *lootService) GetChests() ([]*entities.Chest, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetChests")
+
+	chests, err := ls.lootStore.GetAllChests()
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetAllChests:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching chests", responses.ErrInternalServer)
+	}
+	return chests, nil
+}
+
+func (ls *lootService) GetChestByID(chestID uuid.UUID) (*entities.Chest, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetChestByID")
+
+	chest, err := ls.lootStore.GetChestByID(chestID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: chest not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetChestByID:", funcStr), "err", err)
+		return nil, responses.ErrInternalServer
+	}
+	return chest, nil
+}
+
+// GetChestForUser returns the chest along with the user's pity progress on it
+func (ls *lootService) GetChestForUser(userID, chestID uuid.UUID) (*entities.Chest, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetChestForUser")
+
+	chest, err := ls.GetChestByID(chestID)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := ls.lootStore.GetDropRatesByChestID(chestID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetDropRatesByChestID:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching drop rates", responses.ErrInternalServer)
+	}
+
+	opensSinceTopDrop, err := ls.lootStore.GetOpensSinceTopDrop(userID, chestID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetOpensSinceTopDrop:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching pity state", responses.ErrInternalServer)
+	}
+
+	chest.Pity = ls.config.Pity.state(topRarity(rates), opensSinceTopDrop)
+	return chest, nil
+}
+
+func (ls *lootService) GetDropRatesByChestID(
+	chestID uuid.UUID,
+) ([]*entities.ChestItemDropRate, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetDropRatesByChestID")
+
+	// surface a 404 for unknown chests rather than an empty drop table
+	if _, err := ls.GetChestByID(chestID); err != nil {
+		return nil, err
+	}
+
+	rates, err := ls.lootStore.GetDropRatesByChestID(chestID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetDropRatesByChestID:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching drop rates", responses.ErrInternalServer)
+	}
+	return rates, nil
+}
+
+func (ls *lootService) GetChestItemsByChestID(chestID uuid.UUID) ([]*entities.ChestItem, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetChestItemsByChestID")
+
+	rates, err := ls.GetDropRatesByChestID(chestID)
+	if err != nil {
+		return nil, err
+	}
+
+	inChest := make(map[uuid.UUID]bool, len(rates))
+	for _, rate := range rates {
+		inChest[rate.ItemID] = true
+	}
+
+	items, err := ls.lootStore.GetAllChestItems()
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetAllChestItems:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching chest items", responses.ErrInternalServer)
+	}
+
+	chestItems := make([]*entities.ChestItem, 0, len(rates))
+	for _, item := range items {
+		if inChest[item.ID] {
+			chestItems = append(chestItems, item)
+		}
+	}
+	return chestItems, nil
+}
+
+func (ls *lootService) ReplaceDropTable(
+	chestID, adminID uuid.UUID,
+	rates []*entities.ChestItemDropRate,
+) (*entities.DropTable, error) {
+	funcStr := ls.traceLogger.GetTrace("service.ReplaceDropTable")
+
+	if err := validateDropTable(rates); err != nil {
+		return nil, fmt.Errorf("%w: %w", responses.ErrBadRequest, err)
+	}
+
+	dropTable, err := ls.lootStore.ReplaceDropTable(chestID, adminID, rates)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: chest not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrUnknownChestItem) {
+		return nil, fmt.Errorf(
+			"%w: drop table references an item that does not exist",
+			responses.ErrBadRequest,
+		)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.ReplaceDropTable:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error replacing drop table", responses.ErrInternalServer)
+	}
+	return dropTable, nil
+}
+
+func (ls *lootService) GetDropTableHistory(chestID uuid.UUID) ([]*entities.DropTable, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetDropTableHistory")
+
+	if _, err := ls.GetChestByID(chestID); err != nil {
+		return nil, err
+	}
+
+	history, err := ls.lootStore.GetDropTableHistory(chestID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetDropTableHistory:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching drop table history", responses.ErrInternalServer)
+	}
+	return history, nil
+}
+
+func (ls *lootService) PurchaseChest(userID, chestID uuid.UUID) (*entities.ChestPurchase, error) {
+	funcStr := ls.traceLogger.GetTrace("service.PurchaseChest")
+
+	purchase, err := ls.lootStore.PurchaseChest(userID, chestID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: chest not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrInsufficientCash) {
+		return nil, fmt.Errorf("%w: not enough cash to purchase chest", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.PurchaseChest:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error purchasing chest", responses.ErrInternalServer)
+	}
+
+	ls.eventPublisher.Publish(
+		events.NewEventWithUserID(events.ChestPurchased, purchase, userID.String()),
+	)
+	return purchase, nil
+}
+
+func (ls *lootService) OpenChest(userID, chestID uuid.UUID) (*entities.ChestOpening, error) {
+	funcStr := ls.traceLogger.GetTrace("service.OpenChest")
+
+	// the first opening commits the user's seed pair
+	if _, err := ls.GetFairnessSeed(userID); err != nil {
+		return nil, err
+	}
+
+	var top string
+	opening, err := ls.lootStore.OpenChest(
+		userID,
+		chestID,
+		func(
+			rates []*entities.ChestItemDropRate,
+			opensSinceTopDrop int,
+			seed stores.FairnessInput,
+		) (*stores.Draw, error) {
+			rates = rollOrder(rates)
+			top = topRarity(rates)
+			draw := &stores.Draw{
+				TopDropChance: ls.config.Pity.boostedTopChance(rates, opensSinceTopDrop),
+				Roll:          fairRoll(seed.ServerSeed, seed.ClientSeed, seed.Nonce),
+			}
+			if chance, ok := draw.TopDropChance.GetVal(); ok {
+				rates = reweightTop(rates, chance)
+			}
+
+			itemID, err := pickWeighted(rates, draw.Roll)
+			if err != nil {
+				return nil, err
+			}
+			draw.ItemID = itemID
+			draw.TopDrop = rarityOf(rates, itemID) == top
+			return draw, nil
+		},
+	)
+	if errors.Is(err, stores.ErrNoChestsOwned) {
+		return nil, fmt.Errorf("%w: no chests of this type to open", responses.ErrBadRequest)
+	}
+	if errors.Is(err, ErrEmptyDropTable) {
+		return nil, fmt.Errorf("%w: chest has no items to drop", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.OpenChest:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error opening chest", responses.ErrInternalServer)
+	}
+	opening.Pity = ls.config.Pity.state(top, opening.Pity.OpensSinceTopDrop)
+
+	ls.eventPublisher.Publish(
+		events.NewEventWithUserID(events.ChestOpened, opening, userID.String()),
+	)
+	return opening, nil
+}
+
+// GetChestOpenings returns a page of at most limit of the user's openings, newest first, and
+// whether older openings remain after it
+func (ls *lootService) GetChestOpenings(
+	userID uuid.UUID,
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.ChestOpeningRecord, bool, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetChestOpenings")
+
+	openings, err := ls.lootStore.GetChestOpenings(userID, cursor, limit+1)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetChestOpenings:", funcStr), "err", err)
+		return nil, false, fmt.Errorf("%w: error fetching openings", responses.ErrInternalServer)
+	}
+
+	if len(openings) > limit {
+		return openings[:limit], true, nil
+	}
+	return openings, false, nil
+}
+
+// GetFairnessSeed returns the user's active seed pair, committing a new one if they have none
+func (ls *lootService) GetFairnessSeed(userID uuid.UUID) (*entities.FairnessSeed, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetFairnessSeed")
+
+	commitment, err := ls.newSeedCommitment("")
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: newSeedCommitment:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error generating seed", responses.ErrInternalServer)
+	}
+
+	seed, err := ls.lootStore.CommitFairnessSeed(userID, commitment)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.CommitFairnessSeed:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching seed", responses.ErrInternalServer)
+	}
+	return seed, nil
+}
+
+// RotateFairnessSeed reveals the user's active seed pair and commits a new one with clientSeed,
+// or a random client seed when it is empty
+func (ls *lootService) RotateFairnessSeed(
+	userID uuid.UUID,
+	clientSeed string,
+) (*entities.SeedRotation, error) {
+	funcStr := ls.traceLogger.GetTrace("service.RotateFairnessSeed")
+
+	commitment, err := ls.newSeedCommitment(clientSeed)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: newSeedCommitment:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error generating seed", responses.ErrInternalServer)
+	}
+
+	rotation, err := ls.lootStore.RotateFairnessSeed(userID, commitment)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.RotateFairnessSeed:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error rotating seed", responses.ErrInternalServer)
+	}
+	return rotation, nil
+}
+
+func (ls *lootService) GetUserItems(
+	userID uuid.UUID,
+	filter stores.UserItemFilter,
+) ([]*entities.UserItem, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetUserItems")
+
+	items, err := ls.lootStore.GetUserItems(userID, filter)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetUserItems:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching items", responses.ErrInternalServer)
+	}
+	return items, nil
+}
+
+func (ls *lootService) EquipItem(userID, userItemID uuid.UUID) (*entities.UserItem, error) {
+	funcStr := ls.traceLogger.GetTrace("service.EquipItem")
+
+	item, err := ls.lootStore.EquipUserItem(userID, userItemID)
+	if errors.Is(err, stores.ErrItemNotOwned) {
+		return nil, fmt.Errorf("%w: item not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrItemConsumable) {
+		return nil, fmt.Errorf("%w: consumables cannot be equipped", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.EquipUserItem:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error equipping item", responses.ErrInternalServer)
+	}
+	return item, nil
+}
+
+func (ls *lootService) UnequipItem(userID, userItemID uuid.UUID) (*entities.UserItem, error) {
+	funcStr := ls.traceLogger.GetTrace("service.UnequipItem")
+
+	item, err := ls.lootStore.UnequipUserItem(userID, userItemID)
+	if errors.Is(err, stores.ErrItemNotOwned) {
+		return nil, fmt.Errorf("%w: item not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.UnequipUserItem:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error unequipping item", responses.ErrInternalServer)
+	}
+	return item, nil
+}
+
+func (ls *lootService) SellItem(userID, userItemID uuid.UUID) (*entities.ItemSale, error) {
+	funcStr := ls.traceLogger.GetTrace("service.SellItem")
+
+	sale, err := ls.lootStore.SellUserItem(userID, userItemID)
+	if errors.Is(err, stores.ErrItemNotOwned) {
+		return nil, fmt.Errorf("%w: item not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrItemEquipped) {
+		return nil, fmt.Errorf("%w: unequip the item before selling it", responses.ErrBadRequest)
+	}
+	if errors.Is(err, stores.ErrItemNotSellable) {
+		return nil, fmt.Errorf("%w: item cannot be sold", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.SellUserItem:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error selling item", responses.ErrInternalServer)
+	}
+
+	ls.publishCashUpdated(userID, sale)
+	return sale, nil
+}
+
+func (ls *lootService) SellDuplicateItems(
+	userID uuid.UUID,
+	rarity string,
+) (*entities.ItemSale, error) {
+	funcStr := ls.traceLogger.GetTrace("service.SellDuplicateItems")
+
+	sale, err := ls.lootStore.SellDuplicateUserItems(userID, rarity)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.SellDuplicateUserItems:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error selling items", responses.ErrInternalServer)
+	}
+
+	if sale.CashEarned > 0 {
+		ls.publishCashUpdated(userID, sale)
+	}
+	return sale, nil
+}
+
+func (ls *lootService) publishCashUpdated(userID uuid.UUID, sale *entities.ItemSale) {
+	eventData := &events.CashUpdatedData{
+		CashAvailable: sale.CashAvailable,
+		Amount:        sale.CashEarned,
+	}
+	ls.eventPublisher.Publish(
+		events.NewEventWithUserID(events.CashUpdated, eventData, userID.String()),
+	)
+}
+
+func (ls *lootService) SendGift(
+	senderID uuid.UUID,
+	recipient stores.GiftRecipient,
+	contents stores.GiftContents,
+) (*entities.Gift, error) {
+	funcStr := ls.traceLogger.GetTrace("service.SendGift")
+
+	gift, err := ls.lootStore.CreateGift(senderID, recipient, contents, ls.config.GiftExpiry)
+	if errors.Is(err, stores.ErrRecipientNotFound) {
+		return nil, fmt.Errorf("%w: recipient not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrGiftToSelf) {
+		return nil, fmt.Errorf("%w: cannot send a gift to yourself", responses.ErrBadRequest)
+	}
+	if errors.Is(err, stores.ErrNoChestsOwned) {
+		return nil, fmt.Errorf("%w: not enough chests owned", responses.ErrBadRequest)
+	}
+	if errors.Is(err, stores.ErrItemNotOwned) {
+		return nil, fmt.Errorf("%w: item not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrItemEquipped) {
+		return nil, fmt.Errorf("%w: unequip the item before gifting it", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.CreateGift:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error sending gift", responses.ErrInternalServer)
+	}
+
+	ls.publishGift(events.GiftSent, gift)
+	return gift, nil
+}
+
+func (ls *lootService) AcceptGift(userID, giftID uuid.UUID) (*entities.Gift, error) {
+	funcStr := ls.traceLogger.GetTrace("service.AcceptGift")
+
+	gift, err := ls.lootStore.AcceptGift(userID, giftID)
+	if errors.Is(err, stores.ErrGiftNotFound) {
+		return nil, fmt.Errorf("%w: gift not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrGiftExpired) {
+		return nil, fmt.Errorf("%w: gift has expired", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.AcceptGift:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error accepting gift", responses.ErrInternalServer)
+	}
+
+	ls.publishGift(events.GiftAccepted, gift)
+	return gift, nil
+}
+
+func (ls *lootService) DeclineGift(userID, giftID uuid.UUID) (*entities.Gift, error) {
+	funcStr := ls.traceLogger.GetTrace("service.DeclineGift")
+
+	gift, err := ls.lootStore.DeclineGift(userID, giftID)
+	if errors.Is(err, stores.ErrGiftNotFound) {
+		return nil, fmt.Errorf("%w: gift not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.DeclineGift:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error declining gift", responses.ErrInternalServer)
+	}
+
+	ls.publishGift(events.GiftDeclined, gift)
+	return gift, nil
+}
+
+// ExpireGifts returns every unclaimed gift past its expiry to its sender
+func (ls *lootService) ExpireGifts() ([]*entities.Gift, error) {
+	funcStr := ls.traceLogger.GetTrace("service.ExpireGifts")
+
+	gifts, err := ls.lootStore.ExpireGifts()
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.ExpireGifts:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error expiring gifts", responses.ErrInternalServer)
+	}
+
+	for _, gift := range gifts {
+		ls.publishGift(events.GiftExpired, gift)
+	}
+	return gifts, nil
+}
+
+func (ls *lootService) GetGifts(
+	userID uuid.UUID,
+	filter stores.GiftFilter,
+) ([]*entities.Gift, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetGifts")
+
+	gifts, err := ls.lootStore.GetGifts(userID, filter)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetGifts:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching gifts", responses.ErrInternalServer)
+	}
+	return gifts, nil
+}
+
+// publishGift notifies both the sender and the recipient of a change to a gift
+func (ls *lootService) publishGift(eventType string, gift *entities.Gift) {
+	for _, userID := range []uuid.UUID{gift.SenderID, gift.RecipientID} {
+		ls.eventPublisher.Publish(events.NewEventWithUserID(eventType, gift, userID.String()))
+	}
+}
+
+func (ls *lootService) CreateListing(
+	sellerID, userItemID uuid.UUID,
+	price int,
+) (*entities.Listing, error) {
+	funcStr := ls.traceLogger.GetTrace("service.CreateListing")
+
+	listing, err := ls.lootStore.CreateListing(sellerID, userItemID, price)
+	if errors.Is(err, stores.ErrItemNotOwned) {
+		return nil, fmt.Errorf("%w: item not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrItemEquipped) {
+		return nil, fmt.Errorf("%w: unequip the item before listing it", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.CreateListing:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error creating listing", responses.ErrInternalServer)
+	}
+	return listing, nil
+}
+
+func (ls *lootService) CancelListing(
+	sellerID uuid.UUID,
+	listingID int64,
+) (*entities.Listing, error) {
+	funcStr := ls.traceLogger.GetTrace("service.CancelListing")
+
+	listing, err := ls.lootStore.CancelListing(sellerID, listingID)
+	if errors.Is(err, stores.ErrListingNotFound) {
+		return nil, fmt.Errorf("%w: listing not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.CancelListing:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error cancelling listing", responses.ErrInternalServer)
+	}
+	return listing, nil
+}
+
+func (ls *lootService) BuyListing(
+	buyerID uuid.UUID,
+	listingID int64,
+) (*entities.ListingPurchase, error) {
+	funcStr := ls.traceLogger.GetTrace("service.BuyListing")
+
+	sale, err := ls.lootStore.BuyListing(buyerID, listingID, func(price int) int {
+		return marketplaceFee(price, ls.config.MarketplaceFeePercent)
+	})
+	if errors.Is(err, stores.ErrListingNotFound) {
+		return nil, fmt.Errorf("%w: listing not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrOwnListing) {
+		return nil, fmt.Errorf("%w: cannot buy your own listing", responses.ErrBadRequest)
+	}
+	if errors.Is(err, stores.ErrInsufficientCash) {
+		return nil, fmt.Errorf("%w: insufficient cash", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.BuyListing:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error buying listing", responses.ErrInternalServer)
+	}
+
+	purchase := sale.Purchase
+	sellerID := purchase.Listing.SellerID.String()
+	ls.eventPublisher.Publish(events.NewEventWithUserID(
+		events.CashUpdated,
+		&events.CashUpdatedData{
+			CashAvailable: purchase.CashAvailable,
+			Amount:        -purchase.Listing.Price,
+		},
+		buyerID.String(),
+	))
+	ls.eventPublisher.Publish(events.NewEventWithUserID(
+		events.ListingSold,
+		&events.ListingSoldData{
+			Listing:       purchase.Listing,
+			Proceeds:      sale.Proceeds,
+			CashAvailable: sale.SellerCashAvailable,
+		},
+		sellerID,
+	))
+	if sale.Proceeds > 0 {
+		ls.eventPublisher.Publish(events.NewEventWithUserID(
+			events.CashUpdated,
+			&events.CashUpdatedData{
+				CashAvailable: sale.SellerCashAvailable,
+				Amount:        sale.Proceeds,
+			},
+			sellerID,
+		))
+	}
+	return purchase, nil
+}
+
+func (ls *lootService) GetListings(
+	filter stores.ListingFilter,
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.Listing, bool, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetListings")
+
+	listings, err := ls.lootStore.GetListings(filter, cursor, limit+1)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetListings:", funcStr), "err", err)
+		return nil, false, fmt.Errorf("%w: error fetching listings", responses.ErrInternalServer)
+	}
+
+	if len(listings) > limit {
+		return listings[:limit], true, nil
+	}
+	return listings, false, nil
+}
+
+func (ls *lootService) GetUserListings(
+	sellerID uuid.UUID,
+	status options.Option[string],
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.Listing, bool, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetUserListings")
+
+	listings, err := ls.lootStore.GetUserListings(sellerID, status, cursor, limit+1)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetUserListings:", funcStr), "err", err)
+		return nil, false, fmt.Errorf("%w: error fetching listings", responses.ErrInternalServer)
+	}
+
+	if len(listings) > limit {
+		return listings[:limit], true, nil
+	}
+	return listings, false, nil
+}
+
+func (ls *lootService) GetCollection(userID uuid.UUID) (*entities.Collection, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetCollection")
+
+	collection, err := ls.lootStore.GetCollection(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetCollection:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error getting collection", responses.ErrInternalServer)
+	}
+
+	rollUpCollection(collection)
+	collection.CompletionBonus = ls.config.CollectionBonus
+	return collection, nil
+}
+
+func (ls *lootService) ClaimCollectionBonus(
+	userID, chestID uuid.UUID,
+) (*entities.CollectionBonus, error) {
+	funcStr := ls.traceLogger.GetTrace("service.ClaimCollectionBonus")
+
+	bonus, err := ls.lootStore.ClaimCollectionBonus(userID, chestID, ls.config.CollectionBonus)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: chest not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrCollectionIncomplete) {
+		return nil, fmt.Errorf("%w: chest collection is not complete", responses.ErrBadRequest)
+	}
+	if errors.Is(err, stores.ErrBonusClaimed) {
+		return nil, fmt.Errorf("%w: collection bonus already claimed", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.ClaimCollectionBonus:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error claiming collection bonus", responses.ErrInternalServer)
+	}
+
+	if bonus.CashGranted > 0 {
+		ls.eventPublisher.Publish(events.NewEventWithUserID(
+			events.CashUpdated,
+			&events.CashUpdatedData{
+				CashAvailable: bonus.CashAvailable,
+				Amount:        bonus.CashGranted,
+			},
+			userID.String(),
+		))
+	}
+	return bonus, nil
+}
+
+func (ls *lootService) GetCraftingRecipes() ([]*entities.CraftingRecipe, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetCraftingRecipes")
+
+	recipes, err := ls.lootStore.GetCraftingRecipes()
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetCraftingRecipes:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching recipes", responses.ErrInternalServer)
+	}
+	return recipes, nil
+}
+
+func (ls *lootService) Craft(
+	userID, recipeID uuid.UUID,
+	userItemIDs []uuid.UUID,
+) (*entities.Craft, error) {
+	funcStr := ls.traceLogger.GetTrace("service.Craft")
+
+	// the first roll commits the user's seed pair
+	if _, err := ls.GetFairnessSeed(userID); err != nil {
+		return nil, err
+	}
+
+	craft, err := ls.lootStore.Craft(
+		userID,
+		recipeID,
+		userItemIDs,
+		func(rates []*entities.ChestItemDropRate, seed stores.FairnessInput) (*stores.Draw, error) {
+			draw := &stores.Draw{
+				TopDropChance: options.None[float64](),
+				Roll:          fairRoll(seed.ServerSeed, seed.ClientSeed, seed.Nonce),
+			}
+			itemID, err := pickWeighted(rollOrder(rates), draw.Roll)
+			if err != nil {
+				return nil, err
+			}
+			draw.ItemID = itemID
+			return draw, nil
+		},
+	)
+	if errors.Is(err, stores.ErrRecipeNotFound) {
+		return nil, fmt.Errorf("%w: recipe not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrCraftInputs) {
+		return nil, fmt.Errorf("%w: items do not match the recipe inputs", responses.ErrBadRequest)
+	}
+	if errors.Is(err, stores.ErrItemNotOwned) {
+		return nil, fmt.Errorf("%w: item not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrItemEquipped) {
+		return nil, fmt.Errorf("%w: unequip items before crafting", responses.ErrBadRequest)
+	}
+	if errors.Is(err, ErrEmptyDropTable) {
+		return nil, fmt.Errorf("%w: recipe has no items to craft", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.Craft:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error crafting item", responses.ErrInternalServer)
+	}
+
+	ls.eventPublisher.Publish(
+		events.NewEventWithUserID(events.ItemCrafted, craft, userID.String()),
+	)
+	return craft, nil
+}
+
+func (ls *lootService) GetAvatar(userID uuid.UUID) (*entities.Avatar, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetAvatar")
+
+	avatar, err := ls.lootStore.GetAvatar(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: user not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetAvatar:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching avatar", responses.ErrInternalServer)
+	}
+	return avatar, nil
+}
+
+// UploadItemImage stores data as the item's art and points its image_url at it
+func (ls *lootService) UploadItemImage(
+	itemID uuid.UUID,
+	data []byte,
+) (*entities.ItemImage, error) {
+	funcStr := ls.traceLogger.GetTrace("service.UploadItemImage")
+
+	img, err := inspectItemImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", responses.ErrBadRequest, err)
+	}
+
+	// checked first so images are not stored for items that do not exist
+	_, err = ls.lootStore.GetChestItemByID(itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: item not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetChestItemByID:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching item", responses.ErrInternalServer)
+	}
+
+	err = ls.blobs.Put(context.Background(), img.key, bytes.NewReader(data), img.contentType)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: blobs.Put:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error storing image", responses.ErrInternalServer)
+	}
+
+	url := ls.signer.URL(img.key)
+	item, err := ls.lootStore.SetChestItemImage(itemID, url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: item not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.SetChestItemImage:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error updating item", responses.ErrInternalServer)
+	}
+
+	return &entities.ItemImage{
+		Item:        item,
+		Key:         img.key,
+		URL:         url,
+		ContentType: img.contentType,
+		Size:        int64(len(data)),
+		Width:       img.width,
+		Height:      img.height,
+	}, nil
+}
+
+// ConsumeItem uses up a consumable from the user's inventory and starts its effect
+func (ls *lootService) ConsumeItem(
+	userID, userItemID uuid.UUID,
+) (*entities.ItemConsumption, error) {
+	funcStr := ls.traceLogger.GetTrace("service.ConsumeItem")
+
+	consumption, err := ls.lootStore.ConsumeUserItem(userID, userItemID)
+	if errors.Is(err, stores.ErrItemNotOwned) {
+		return nil, fmt.Errorf("%w: item not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrItemNotConsumable) {
+		return nil, fmt.Errorf("%w: item is not consumable", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.ConsumeUserItem:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error consuming item", responses.ErrInternalServer)
+	}
+
+	ls.eventPublisher.Publish(
+		events.NewEventWithUserID(events.ItemConsumed, consumption, userID.String()),
+	)
+	return consumption, nil
+}
+
+func (ls *lootService) GetActiveEffects(userID uuid.UUID) ([]*entities.ActiveEffect, error) {
+	funcStr := ls.traceLogger.GetTrace("service.GetActiveEffects")
+
+	effects, err := ls.lootStore.GetActiveEffects(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetActiveEffects:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching effects", responses.ErrInternalServer)
+	}
+	return effects, nil
+}
+
+// DeleteExpiredEffects clears out every effect that has run its course
+func (ls *lootService) DeleteExpiredEffects() (int, error) {
+	funcStr := ls.traceLogger.GetTrace("service.DeleteExpiredEffects")
+
+	deleted, err := ls.lootStore.DeleteExpiredEffects()
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.DeleteExpiredEffects:", funcStr), "err", err)
+		return 0, fmt.Errorf("%w: error deleting expired effects", responses.ErrInternalServer)
+	}
+	return deleted, nil
+}
@@ -2,41 +2,421 @@
 package stores
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"errors"
 	"goalify/internal/entities"
+	"goalify/pkg/options"
+	"slices"
+	"strings"
+	"time"
 
+	db "goalify/internal/db"
 	sqlcdb "goalify/internal/db/generated"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	// ErrInsufficientCash is returned when a user cannot afford a purchase
+	ErrInsufficientCash = errors.New("insufficient cash")
+	// ErrNoChestsOwned is returned when a user opens a chest they do not own
+	ErrNoChestsOwned = errors.New("no chests owned")
+	// ErrItemNotOwned is returned when a user acts on an item that is not in their inventory
+	ErrItemNotOwned = errors.New("item not owned")
+	// ErrItemEquipped is returned when selling an item the user has equipped
+	ErrItemEquipped = errors.New("item equipped")
+	// ErrItemNotSellable is returned when selling an item that has no price
+	ErrItemNotSellable = errors.New("item not sellable")
+	// ErrUnknownChestItem is returned when a drop table references an item that does not exist
+	ErrUnknownChestItem = errors.New("unknown chest item")
+	// ErrNoFairnessSeed is returned when opening a chest before the user has a committed seed pair
+	ErrNoFairnessSeed = errors.New("no fairness seed")
+	// ErrRecipientNotFound is returned when a gift's recipient does not exist
+	ErrRecipientNotFound = errors.New("recipient not found")
+	// ErrGiftToSelf is returned when a user sends a gift to themselves
+	ErrGiftToSelf = errors.New("gift to self")
+	// ErrGiftNotFound is returned when a gift is not pending for the user resolving it
+	ErrGiftNotFound = errors.New("gift not found")
+	// ErrGiftExpired is returned when accepting a gift past its expiry
+	ErrGiftExpired = errors.New("gift expired")
+	// ErrListingNotFound is returned when a listing is not active, or not the user's to cancel
+	ErrListingNotFound = errors.New("listing not found")
+	// ErrOwnListing is returned when a user buys their own listing
+	ErrOwnListing = errors.New("own listing")
+	// ErrCollectionIncomplete is returned when claiming the bonus of an unfinished chest collection
+	ErrCollectionIncomplete = errors.New("collection incomplete")
+	// ErrBonusClaimed is returned when a chest's collection bonus has already been paid
+	ErrBonusClaimed = errors.New("bonus already claimed")
+	// ErrRecipeNotFound is returned when a crafting recipe does not exist
+	ErrRecipeNotFound = errors.New("recipe not found")
+	// ErrCraftInputs is returned when the items offered to a recipe are not its input count of its
+	// input rarity
+	ErrCraftInputs = errors.New("inputs do not match recipe")
+	// ErrItemNotConsumable is returned when consuming an item that has no effect
+	ErrItemNotConsumable = errors.New("item not consumable")
+	// ErrItemConsumable is returned when equipping a consumable, which can only be used up
+	ErrItemConsumable = errors.New("item consumable")
+)
+
+// PickItemFunc chooses the item awarded from a chest's drop table. opensSinceTopDrop is the user's
+// pity counter for the chest and seed is the committed seed pair and nonce the roll must use.
+type PickItemFunc func(
+	rates []*entities.ChestItemDropRate,
+	opensSinceTopDrop int,
+	seed FairnessInput,
+) (*Draw, error)
+
+// CraftPickFunc chooses a craft's output from the recipe's candidates, the items of its output
+// rarity in its chest's drop table, using the user's committed seed pair and nonce
+type CraftPickFunc func(rates []*entities.ChestItemDropRate, seed FairnessInput) (*Draw, error)
+
+// FairnessInput is what a provably fair roll is derived from
+type FairnessInput struct {
+	ServerSeed string
+	ClientSeed string
+	Nonce      int
+}
+
+// Draw is the outcome of a pick. TopDrop reports whether it resets the pity counter, and Roll and
+// TopDropChance are recorded with the opening so it can be verified later.
+type Draw struct {
+	TopDropChance options.Option[float64]
+	Roll          float64
+	ItemID        uuid.UUID
+	TopDrop       bool
+}
+
+// SeedCommitment is a freshly generated seed pair to commit for a user
+type SeedCommitment struct {
+	ServerSeed     string
+	ServerSeedHash string
+	ClientSeed     string
+}
+
+// UserItemFilter narrows a user's inventory. Unset fields match every item.
+type UserItemFilter struct {
+	Rarity options.Option[string]
+	Status options.Option[string]
+}
+
+// GiftRecipient identifies a gift's recipient by id or, when ID is unset, by email
+type GiftRecipient struct {
+	ID    options.Option[uuid.UUID]
+	Email string
+}
+
+// GiftContents is what a gift transfers: Quantity of the chest ChestID, or the item UserItemID
+type GiftContents struct {
+	ChestID    options.Option[uuid.UUID]
+	UserItemID options.Option[uuid.UUID]
+	Quantity   int
+}
+
+// GiftFilter narrows a user's gifts to those they sent or received, optionally in one status
+type GiftFilter struct {
+	Status options.Option[string]
+	Sent   bool
+}
+
+// FeeFunc returns the house's cut of a marketplace sale at price
+type FeeFunc func(price int) int
+
+// ListingFilter narrows the active marketplace listings. Unset fields match every listing, and
+// Title matches any item title containing it, ignoring case.
+type ListingFilter struct {
+	Rarity options.Option[string]
+	Title  options.Option[string]
+}
+
+// ListingSale is a bought listing: the buyer's purchase and the seller's resulting cash
+type ListingSale struct {
+	Purchase            *entities.ListingPurchase
+	Proceeds            int
+	SellerCashAvailable int
+}
+
 type LootStore interface {
 	CreateChest(chestType, description string, price int) (*entities.Chest, error)
 	GetChestByID(chestID uuid.UUID) (*entities.Chest, error)
 	GetAllChests() ([]*entities.Chest, error)
 	UpdateChestByID(chestID uuid.UUID, updates map[string]any) (*entities.Chest, error)
 	DeleteChestByID(chestID uuid.UUID) error
+
+	GetAllChestItems() ([]*entities.ChestItem, error)
+	GetChestItemByID(itemID uuid.UUID) (*entities.ChestItem, error)
+	SetChestItemImage(itemID uuid.UUID, imageURL string) (*entities.ChestItem, error)
+	GetDropRatesByChestID(chestID uuid.UUID) ([]*entities.ChestItemDropRate, error)
+	ReplaceDropTable(
+		chestID, adminID uuid.UUID,
+		rates []*entities.ChestItemDropRate,
+	) (*entities.DropTable, error)
+	GetDropTableHistory(chestID uuid.UUID) ([]*entities.DropTable, error)
+
+	PurchaseChest(userID, chestID uuid.UUID) (*entities.ChestPurchase, error)
+	OpenChest(userID, chestID uuid.UUID, pick PickItemFunc) (*entities.ChestOpening, error)
+	GetOpensSinceTopDrop(userID, chestID uuid.UUID) (int, error)
+	GetChestOpenings(
+		userID uuid.UUID,
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.ChestOpeningRecord, error)
+
+	CommitFairnessSeed(userID uuid.UUID, seed SeedCommitment) (*entities.FairnessSeed, error)
+	RotateFairnessSeed(userID uuid.UUID, seed SeedCommitment) (*entities.SeedRotation, error)
+
+	GetUserItems(userID uuid.UUID, filter UserItemFilter) ([]*entities.UserItem, error)
+	EquipUserItem(userID, userItemID uuid.UUID) (*entities.UserItem, error)
+	UnequipUserItem(userID, userItemID uuid.UUID) (*entities.UserItem, error)
+	SellUserItem(userID, userItemID uuid.UUID) (*entities.ItemSale, error)
+	SellDuplicateUserItems(userID uuid.UUID, rarity string) (*entities.ItemSale, error)
+
+	CreateGift(
+		senderID uuid.UUID,
+		recipient GiftRecipient,
+		contents GiftContents,
+		expiresIn time.Duration,
+	) (*entities.Gift, error)
+	AcceptGift(recipientID, giftID uuid.UUID) (*entities.Gift, error)
+	DeclineGift(recipientID, giftID uuid.UUID) (*entities.Gift, error)
+	ExpireGifts() ([]*entities.Gift, error)
+	GetGifts(userID uuid.UUID, filter GiftFilter) ([]*entities.Gift, error)
+
+	CreateListing(sellerID, userItemID uuid.UUID, price int) (*entities.Listing, error)
+	CancelListing(sellerID uuid.UUID, listingID int64) (*entities.Listing, error)
+	BuyListing(buyerID uuid.UUID, listingID int64, fee FeeFunc) (*ListingSale, error)
+	GetListings(
+		filter ListingFilter,
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.Listing, error)
+	GetUserListings(
+		sellerID uuid.UUID,
+		status options.Option[string],
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.Listing, error)
+
+	GetCollection(userID uuid.UUID) (*entities.Collection, error)
+	ClaimCollectionBonus(userID, chestID uuid.UUID, cash int) (*entities.CollectionBonus, error)
+
+	GetAvatar(userID uuid.UUID) (*entities.Avatar, error)
+
+	GetCraftingRecipes() ([]*entities.CraftingRecipe, error)
+	Craft(
+		userID, recipeID uuid.UUID,
+		userItemIDs []uuid.UUID,
+		pick CraftPickFunc,
+	) (*entities.Craft, error)
+
+	ConsumeUserItem(userID, userItemID uuid.UUID) (*entities.ItemConsumption, error)
+	GetActiveEffects(userID uuid.UUID) ([]*entities.ActiveEffect, error)
+	DeleteExpiredEffects() (int, error)
 }
 
 type lootStore struct {
+	pool    *pgxpool.Pool
 	queries *sqlcdb.Queries
 }
 
 // Helper function to convert sqlc Chest to entity Chest
 func pgxChestToEntity(c sqlcdb.Chest) *entities.Chest {
 	return &entities.Chest{
-		ID:          uuid.UUID(c.ID.Bytes),
-		Type:        string(c.Type),
-		Description: c.Description,
-		Price:       int(c.Price),
-		CreatedAt:   c.CreatedAt.Time,
-		UpdatedAt:   c.UpdatedAt.Time,
+		ID:               uuid.UUID(c.ID.Bytes),
+		Type:             string(c.Type),
+		Description:      c.Description,
+		Price:            int(c.Price),
+		DropTableVersion: int(c.DropTableVersion),
+		CreatedAt:        c.CreatedAt.Time,
+		UpdatedAt:        c.UpdatedAt.Time,
+	}
+}
+
+func pgxChestItemToEntity(ci sqlcdb.ChestItem) *entities.ChestItem {
+	item := &entities.ChestItem{
+		ID:        uuid.UUID(ci.ID.Bytes),
+		ImageURL:  ci.ImageUrl.String,
+		Title:     ci.Title,
+		Rarity:    string(ci.Rarity),
+		Slot:      string(ci.Slot),
+		CreatedAt: ci.CreatedAt.Time,
+		UpdatedAt: ci.UpdatedAt.Time,
+	}
+	if ci.Price.Valid {
+		item.Price = options.Some(int(ci.Price.Int32))
+	}
+	if ci.Effect.Valid {
+		item.Effect = &entities.ItemEffect{
+			Type:            string(ci.Effect.ItemEffect),
+			Value:           int(ci.EffectValue.Int32),
+			DurationSeconds: int(ci.EffectDurationSeconds.Int32),
+		}
+	}
+	return item
+}
+
+func pgxActiveEffectToEntity(ae sqlcdb.ActiveEffect) *entities.ActiveEffect {
+	effect := &entities.ActiveEffect{
+		ID:        ae.ID,
+		UserID:    uuid.UUID(ae.UserID.Bytes),
+		Effect:    string(ae.Effect),
+		Value:     int(ae.Value),
+		ExpiresAt: ae.ExpiresAt.Time,
+		CreatedAt: ae.CreatedAt.Time,
+	}
+	if ae.SourceItemID.Valid {
+		effect.SourceItemID = options.Some(uuid.UUID(ae.SourceItemID.Bytes))
+	}
+	return effect
+}
+
+func pgxDropRateToEntity(dr sqlcdb.ChestItemDropRate) *entities.ChestItemDropRate {
+	return &entities.ChestItemDropRate{
+		ID:       uuid.UUID(dr.ID.Bytes),
+		ItemID:   uuid.UUID(dr.ItemID.Bytes),
+		ChestID:  uuid.UUID(dr.ChestID.Bytes),
+		DropRate: dr.DropRate,
+	}
+}
+
+func pgxDropTableRowToEntity(dr sqlcdb.GetDropTableByChestIdRow) *entities.ChestItemDropRate {
+	return &entities.ChestItemDropRate{
+		ID:       uuid.UUID(dr.ID.Bytes),
+		ItemID:   uuid.UUID(dr.ItemID.Bytes),
+		ChestID:  uuid.UUID(dr.ChestID.Bytes),
+		DropRate: dr.DropRate,
+		Rarity:   string(dr.Rarity),
+	}
+}
+
+func pgxFairnessSeedToEntity(fs sqlcdb.FairnessSeed) *entities.FairnessSeed {
+	seed := &entities.FairnessSeed{
+		CreatedAt:      fs.CreatedAt.Time,
+		RevealedAt:     options.None[time.Time](),
+		ServerSeed:     options.None[string](),
+		ServerSeedHash: fs.ServerSeedHash,
+		ClientSeed:     fs.ClientSeed,
+		NextNonce:      int(fs.NextNonce),
+		ID:             uuid.UUID(fs.ID.Bytes),
+	}
+	// the server seed is secret until the pair is revealed
+	if fs.RevealedAt.Valid {
+		seed.RevealedAt = options.Some(fs.RevealedAt.Time)
+		seed.ServerSeed = options.Some(fs.ServerSeed)
+	}
+	return seed
+}
+
+func pgxChestOpeningToEntity(
+	co sqlcdb.ChestOpening,
+	fs sqlcdb.FairnessSeed,
+) *entities.ChestOpeningRecord {
+	record := &entities.ChestOpeningRecord{
+		CreatedAt:  co.CreatedAt.Time,
+		UserItemID: options.None[uuid.UUID](),
+		Proof: &entities.FairnessProof{
+			ServerSeed:       pgxFairnessSeedToEntity(fs).ServerSeed,
+			TopDropChance:    options.None[float64](),
+			ServerSeedHash:   fs.ServerSeedHash,
+			ClientSeed:       fs.ClientSeed,
+			Nonce:            int(co.Nonce),
+			Roll:             co.Roll,
+			DropTableVersion: int(co.DropTableVersion),
+		},
+		ID:      co.ID,
+		ChestID: uuid.UUID(co.ChestID.Bytes),
+		ItemID:  uuid.UUID(co.ItemID.Bytes),
+	}
+	if co.UserItemID.Valid {
+		record.UserItemID = options.Some(uuid.UUID(co.UserItemID.Bytes))
+	}
+	if co.TopDropChance.Valid {
+		record.Proof.TopDropChance = options.Some(co.TopDropChance.Float64)
+	}
+	return record
+}
+
+func pgxUserChestToEntity(uc sqlcdb.UserChest) *entities.UserChest {
+	return &entities.UserChest{
+		UserID:        uuid.UUID(uc.UserID.Bytes),
+		ChestID:       uuid.UUID(uc.ChestID.Bytes),
+		QuantityOwned: int(uc.QuantityOwned.Int32),
+	}
+}
+
+func pgxUserItemToEntity(ui sqlcdb.UserItem) *entities.UserItem {
+	return &entities.UserItem{
+		ID:        uuid.UUID(ui.ID.Bytes),
+		UserID:    uuid.UUID(ui.UserID.Bytes),
+		ItemID:    uuid.UUID(ui.ItemID.Bytes),
+		Status:    string(ui.Status.ItemStatus),
+		CreatedAt: ui.CreatedAt.Time,
+		UpdatedAt: ui.UpdatedAt.Time,
 	}
 }
 
-func NewChestStore(queries *sqlcdb.Queries) LootStore {
-	return &lootStore{queries: queries}
+func pgxUserItemWithDetailsToEntity(ui sqlcdb.UserItem, ci sqlcdb.ChestItem) *entities.UserItem {
+	userItem := pgxUserItemToEntity(ui)
+	userItem.Item = pgxChestItemToEntity(ci)
+	return userItem
+}
+
+func pgxGiftToEntity(g sqlcdb.Gift) *entities.Gift {
+	gift := &entities.Gift{
+		CreatedAt:   g.CreatedAt.Time,
+		ExpiresAt:   g.ExpiresAt.Time,
+		ResolvedAt:  options.None[time.Time](),
+		ChestID:     options.None[uuid.UUID](),
+		Quantity:    options.None[int](),
+		UserItemID:  options.None[uuid.UUID](),
+		Status:      string(g.Status),
+		ID:          uuid.UUID(g.ID.Bytes),
+		SenderID:    uuid.UUID(g.SenderID.Bytes),
+		RecipientID: uuid.UUID(g.RecipientID.Bytes),
+	}
+	if g.ResolvedAt.Valid {
+		gift.ResolvedAt = options.Some(g.ResolvedAt.Time)
+	}
+	if g.ChestID.Valid {
+		gift.ChestID = options.Some(uuid.UUID(g.ChestID.Bytes))
+		gift.Quantity = options.Some(int(g.Quantity.Int32))
+	}
+	if g.UserItemID.Valid {
+		gift.UserItemID = options.Some(uuid.UUID(g.UserItemID.Bytes))
+	}
+	return gift
+}
+
+func pgxListingToEntity(l sqlcdb.Listing, ci sqlcdb.ChestItem) *entities.Listing {
+	listing := &entities.Listing{
+		CreatedAt:  l.CreatedAt.Time,
+		ClosedAt:   options.None[time.Time](),
+		BuyerID:    options.None[uuid.UUID](),
+		Fee:        options.None[int](),
+		Item:       pgxChestItemToEntity(ci),
+		Status:     string(l.Status),
+		ID:         l.ID,
+		Price:      int(l.Price),
+		SellerID:   uuid.UUID(l.SellerID.Bytes),
+		UserItemID: uuid.UUID(l.UserItemID.Bytes),
+	}
+	if l.ClosedAt.Valid {
+		listing.ClosedAt = options.Some(l.ClosedAt.Time)
+	}
+	if l.BuyerID.Valid {
+		listing.BuyerID = options.Some(uuid.UUID(l.BuyerID.Bytes))
+	}
+	if l.Fee.Valid {
+		listing.Fee = options.Some(int(l.Fee.Int32))
+	}
+	return listing
+}
+
+func NewChestStore(pool *pgxpool.Pool, queries *sqlcdb.Queries) LootStore {
+	return &lootStore{pool: pool, queries: queries}
 }
 
 func (s *lootStore) CreateChest(chestType, description string, price int) (*entities.Chest, error) {
@@ -116,3 +496,1661 @@ func (s *lootStore) UpdateChestByID(
 func (s *lootStore) DeleteChestByID(chestID uuid.UUID) error {
 	return s.queries.DeleteChestById(context.Background(), pgtype.UUID{Bytes: chestID, Valid: true})
 }
+
+func (s *lootStore) GetAllChestItems() ([]*entities.ChestItem, error) {
+	items, err := s.queries.GetAllChestItems(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*entities.ChestItem, len(items))
+	for i, item := range items {
+		result[i] = pgxChestItemToEntity(item)
+	}
+
+	return result, nil
+}
+
+func (s *lootStore) GetChestItemByID(itemID uuid.UUID) (*entities.ChestItem, error) {
+	item, err := s.queries.GetChestItemById(context.Background(), db.UUIDToPgxUUID(itemID))
+	if err != nil {
+		return nil, err
+	}
+	return pgxChestItemToEntity(item), nil
+}
+
+// SetChestItemImage points the item's image_url at new art, returning sql.ErrNoRows when the item
+// does not exist
+func (s *lootStore) SetChestItemImage(
+	itemID uuid.UUID,
+	imageURL string,
+) (*entities.ChestItem, error) {
+	params := sqlcdb.UpdateChestItemByIdParams{
+		ID:       db.UUIDToPgxUUID(itemID),
+		ImageUrl: pgtype.Text{String: imageURL, Valid: true},
+	}
+	item, err := s.queries.UpdateChestItemById(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+	return pgxChestItemToEntity(item), nil
+}
+
+func (s *lootStore) GetDropRatesByChestID(
+	chestID uuid.UUID,
+) ([]*entities.ChestItemDropRate, error) {
+	rates, err := s.queries.GetDropTableByChestId(
+		context.Background(),
+		pgtype.UUID{Bytes: chestID, Valid: true},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*entities.ChestItemDropRate, len(rates))
+	for i, r := range rates {
+		result[i] = pgxDropTableRowToEntity(r)
+	}
+
+	return result, nil
+}
+
+// ReplaceDropTable swaps a chest's whole drop table for rates and records it as the chest's next
+// drop table version. Bumping the version locks the chest row, so concurrent replacements and
+// openings see either the old table or the new one, never a mix.
+func (s *lootStore) ReplaceDropTable(
+	chestID, adminID uuid.UUID,
+	rates []*entities.ChestItemDropRate,
+) (*entities.DropTable, error) {
+	ctx := context.Background()
+	dropTable := &entities.DropTable{ChestID: chestID, CreatedBy: adminID}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		itemIDs := make([]pgtype.UUID, len(rates))
+		for i, rate := range rates {
+			itemIDs[i] = db.UUIDToPgxUUID(rate.ItemID)
+		}
+		items, err := q.GetChestItemsByIds(ctx, itemIDs)
+		if err != nil {
+			return err
+		}
+		if len(items) != len(rates) {
+			return ErrUnknownChestItem
+		}
+
+		chest, err := q.IncrementChestDropTableVersion(ctx, db.UUIDToPgxUUID(chestID))
+		if err != nil {
+			return err
+		}
+
+		// tables written outside this method, e.g. by seeders, have no history yet
+		err = q.ArchiveDropTable(ctx, sqlcdb.ArchiveDropTableParams{
+			ChestID: chest.ID,
+			Version: chest.DropTableVersion - 1,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteDropRatesByChestId(ctx, chest.ID); err != nil {
+			return err
+		}
+
+		dropTable.Rates = make([]*entities.ChestItemDropRate, len(rates))
+		for i, rate := range rates {
+			created, err := q.CreateChestItemDropRate(ctx, sqlcdb.CreateChestItemDropRateParams{
+				ItemID:   db.UUIDToPgxUUID(rate.ItemID),
+				ChestID:  chest.ID,
+				DropRate: rate.DropRate,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = q.CreateDropTableHistoryEntry(ctx, sqlcdb.CreateDropTableHistoryEntryParams{
+				ChestID:   chest.ID,
+				Version:   chest.DropTableVersion,
+				ItemID:    created.ItemID,
+				DropRate:  created.DropRate,
+				CreatedBy: db.UUIDToPgxUUID(adminID),
+			})
+			if err != nil {
+				return err
+			}
+			dropTable.Rates[i] = pgxDropRateToEntity(created)
+		}
+
+		dropTable.Version = int(chest.DropTableVersion)
+		dropTable.CreatedAt = chest.UpdatedAt.Time
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dropTable, nil
+}
+
+// GetDropTableHistory returns every recorded version of a chest's drop table, newest first
+func (s *lootStore) GetDropTableHistory(chestID uuid.UUID) ([]*entities.DropTable, error) {
+	entries, err := s.queries.GetDropTableHistoryByChestId(
+		context.Background(),
+		db.UUIDToPgxUUID(chestID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*entities.DropTable, 0)
+	var current *entities.DropTable
+	for _, e := range entries {
+		if current == nil || current.Version != int(e.Version) {
+			current = &entities.DropTable{
+				ChestID:   chestID,
+				Version:   int(e.Version),
+				CreatedAt: e.CreatedAt.Time,
+				Rates:     make([]*entities.ChestItemDropRate, 0),
+			}
+			if e.CreatedBy.Valid {
+				current.CreatedBy = uuid.UUID(e.CreatedBy.Bytes)
+			}
+			history = append(history, current)
+		}
+		current.Rates = append(current.Rates, &entities.ChestItemDropRate{
+			ID:       uuid.UUID(e.ID.Bytes),
+			ItemID:   uuid.UUID(e.ItemID.Bytes),
+			ChestID:  chestID,
+			DropRate: e.DropRate,
+		})
+	}
+
+	return history, nil
+}
+
+// PurchaseChest takes the chest's price from the user's cash and adds one chest to their
+// inventory in a single transaction. The debit only applies while the balance covers the price,
+// so concurrent purchases can never push cash_available below zero.
+func (s *lootStore) PurchaseChest(userID, chestID uuid.UUID) (*entities.ChestPurchase, error) {
+	ctx := context.Background()
+	purchase := &entities.ChestPurchase{}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		chest, err := q.GetChestById(ctx, db.UUIDToPgxUUID(chestID))
+		if err != nil {
+			return err
+		}
+
+		balance, err := db.RecordCashTransaction(
+			ctx,
+			q,
+			userID,
+			-int(chest.Price),
+			sqlcdb.CashTransactionReasonChestPurchase,
+			chestID,
+		)
+		if errors.Is(err, db.ErrInsufficientCash) {
+			return ErrInsufficientCash
+		}
+		if err != nil {
+			return err
+		}
+
+		userChest, err := q.IncrementUserChestQuantity(ctx, sqlcdb.IncrementUserChestQuantityParams{
+			UserID:        db.UUIDToPgxUUID(userID),
+			ChestID:       db.UUIDToPgxUUID(chestID),
+			QuantityOwned: pgtype.Int4{Int32: 1, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		purchase.UserChest = pgxUserChestToEntity(userChest)
+		purchase.CashAvailable = balance
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return purchase, nil
+}
+
+// OpenChest consumes one of the user's chests, rolls an item from the chest's drop table using
+// pick and the user's committed seed pair, adds it to the user's items, advances or resets their
+// pity counter and records the opening, all in a single transaction. The returned opening carries
+// the updated counter in Pity.OpensSinceTopDrop.
+func (s *lootStore) OpenChest(
+	userID, chestID uuid.UUID,
+	pick PickItemFunc,
+) (*entities.ChestOpening, error) {
+	ctx := context.Background()
+	opening := &entities.ChestOpening{ChestID: chestID}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		userChest, err := q.DecrementUserChestQuantity(
+			ctx,
+			sqlcdb.DecrementUserChestQuantityParams{
+				UserID:  db.UUIDToPgxUUID(userID),
+				ChestID: db.UUIDToPgxUUID(chestID),
+			},
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoChestsOwned
+		}
+		if err != nil {
+			return err
+		}
+
+		chest, err := q.GetChestByIdForShare(ctx, db.UUIDToPgxUUID(chestID))
+		if err != nil {
+			return err
+		}
+		// the opening references its version, so make sure the history holds it
+		err = q.ArchiveDropTable(ctx, sqlcdb.ArchiveDropTableParams{
+			ChestID: chest.ID,
+			Version: chest.DropTableVersion,
+		})
+		if err != nil {
+			return err
+		}
+
+		rates, err := q.GetDropTableByChestId(ctx, chest.ID)
+		if err != nil {
+			return err
+		}
+		dropTable := make([]*entities.ChestItemDropRate, len(rates))
+		for i, r := range rates {
+			dropTable[i] = pgxDropTableRowToEntity(r)
+		}
+
+		seed, err := q.LockActiveFairnessSeed(ctx, db.UUIDToPgxUUID(userID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoFairnessSeed
+		}
+		if err != nil {
+			return err
+		}
+
+		pity, err := q.LockUserChestPity(ctx, sqlcdb.LockUserChestPityParams{
+			UserID:  db.UUIDToPgxUUID(userID),
+			ChestID: db.UUIDToPgxUUID(chestID),
+		})
+		if err != nil {
+			return err
+		}
+
+		draw, err := pick(dropTable, int(pity.OpensSinceTopDrop), FairnessInput{
+			ServerSeed: seed.ServerSeed,
+			ClientSeed: seed.ClientSeed,
+			Nonce:      int(seed.NextNonce),
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.IncrementFairnessSeedNonce(ctx, seed.ID); err != nil {
+			return err
+		}
+
+		opensSinceTopDrop := pity.OpensSinceTopDrop + 1
+		if draw.TopDrop {
+			opensSinceTopDrop = 0
+		}
+		pity, err = q.UpdateUserChestPity(ctx, sqlcdb.UpdateUserChestPityParams{
+			UserID:            pity.UserID,
+			ChestID:           pity.ChestID,
+			OpensSinceTopDrop: opensSinceTopDrop,
+		})
+		if err != nil {
+			return err
+		}
+
+		userItem, err := q.CreateUserItem(ctx, sqlcdb.CreateUserItemParams{
+			UserID: db.UUIDToPgxUUID(userID),
+			ItemID: db.UUIDToPgxUUID(draw.ItemID),
+			Status: sqlcdb.NullItemStatus{
+				ItemStatus: sqlcdb.ItemStatusNotEquipped,
+				Valid:      true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		item, err := q.GetChestItemById(ctx, userItem.ItemID)
+		if err != nil {
+			return err
+		}
+
+		params := sqlcdb.CreateChestOpeningParams{
+			UserID:           db.UUIDToPgxUUID(userID),
+			ChestID:          chest.ID,
+			ItemID:           item.ID,
+			UserItemID:       userItem.ID,
+			DropTableVersion: chest.DropTableVersion,
+			FairnessSeedID:   seed.ID,
+			Nonce:            seed.NextNonce,
+			Roll:             draw.Roll,
+		}
+		if chance, ok := draw.TopDropChance.GetVal(); ok {
+			params.TopDropChance = pgtype.Float8{Float64: chance, Valid: true}
+		}
+		record, err := q.CreateChestOpening(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		opening.ID = record.ID
+		opening.UserItem = pgxUserItemToEntity(userItem)
+		opening.Item = pgxChestItemToEntity(item)
+		opening.QuantityRemaining = int(userChest.QuantityOwned.Int32)
+		opening.Pity = &entities.PityState{OpensSinceTopDrop: int(pity.OpensSinceTopDrop)}
+		opening.Proof = pgxChestOpeningToEntity(record, seed).Proof
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return opening, nil
+}
+
+// GetOpensSinceTopDrop returns the user's pity counter for a chest, which is 0 until they first
+// open it
+func (s *lootStore) GetOpensSinceTopDrop(userID, chestID uuid.UUID) (int, error) {
+	pity, err := s.queries.GetUserChestPity(context.Background(), sqlcdb.GetUserChestPityParams{
+		UserID:  db.UUIDToPgxUUID(userID),
+		ChestID: db.UUIDToPgxUUID(chestID),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(pity.OpensSinceTopDrop), nil
+}
+
+// GetChestOpenings returns up to limit of the user's openings, newest first, that are older than
+// the cursor opening id when one is given. Each carries the drop table version it rolled with.
+func (s *lootStore) GetChestOpenings(
+	userID uuid.UUID,
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.ChestOpeningRecord, error) {
+	ctx := context.Background()
+	params := sqlcdb.GetChestOpeningsByUserIdParams{
+		UserID:   db.UUIDToPgxUUID(userID),
+		PageSize: int32(limit),
+	}
+	if id, ok := cursor.GetVal(); ok {
+		params.Cursor = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	rows, err := s.queries.GetChestOpeningsByUserId(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	chestIDs := make([]pgtype.UUID, 0, len(rows))
+	for _, row := range rows {
+		chestIDs = append(chestIDs, row.ChestOpening.ChestID)
+	}
+	history, err := s.queries.GetDropTableHistoryWithRarityByChestIds(ctx, chestIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type chestVersion struct {
+		chestID uuid.UUID
+		version int32
+	}
+	dropTables := make(map[chestVersion][]*entities.ChestItemDropRate)
+	for _, entry := range history {
+		key := chestVersion{uuid.UUID(entry.ChestID.Bytes), entry.Version}
+		dropTables[key] = append(dropTables[key], &entities.ChestItemDropRate{
+			Rarity:   string(entry.Rarity),
+			ItemID:   uuid.UUID(entry.ItemID.Bytes),
+			ChestID:  uuid.UUID(entry.ChestID.Bytes),
+			DropRate: entry.DropRate,
+		})
+	}
+
+	openings := make([]*entities.ChestOpeningRecord, len(rows))
+	for i, row := range rows {
+		openings[i] = pgxChestOpeningToEntity(row.ChestOpening, row.FairnessSeed)
+		openings[i].DropTable = dropTables[chestVersion{
+			openings[i].ChestID,
+			row.ChestOpening.DropTableVersion,
+		}]
+	}
+	return openings, nil
+}
+
+// CommitFairnessSeed commits seed as the user's active pair unless they already have one, and
+// returns whichever pair is active
+func (s *lootStore) CommitFairnessSeed(
+	userID uuid.UUID,
+	seed SeedCommitment,
+) (*entities.FairnessSeed, error) {
+	ctx := context.Background()
+	err := s.queries.CreateFairnessSeedIfMissing(ctx, sqlcdb.CreateFairnessSeedIfMissingParams{
+		UserID:         db.UUIDToPgxUUID(userID),
+		ServerSeed:     seed.ServerSeed,
+		ServerSeedHash: seed.ServerSeedHash,
+		ClientSeed:     seed.ClientSeed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := s.queries.GetActiveFairnessSeed(ctx, db.UUIDToPgxUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+	return pgxFairnessSeedToEntity(active), nil
+}
+
+// RotateFairnessSeed reveals the user's active pair, if any, and commits seed in its place in a
+// single transaction. Openings in flight hold the active pair's lock, so none roll with a
+// revealed seed.
+func (s *lootStore) RotateFairnessSeed(
+	userID uuid.UUID,
+	seed SeedCommitment,
+) (*entities.SeedRotation, error) {
+	ctx := context.Background()
+	rotation := &entities.SeedRotation{}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		current, err := q.LockActiveFairnessSeed(ctx, db.UUIDToPgxUUID(userID))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err == nil {
+			revealed, err := q.RevealFairnessSeed(ctx, current.ID)
+			if err != nil {
+				return err
+			}
+			rotation.Revealed = pgxFairnessSeedToEntity(revealed)
+		}
+
+		active, err := q.CreateFairnessSeed(ctx, sqlcdb.CreateFairnessSeedParams{
+			UserID:         db.UUIDToPgxUUID(userID),
+			ServerSeed:     seed.ServerSeed,
+			ServerSeedHash: seed.ServerSeedHash,
+			ClientSeed:     seed.ClientSeed,
+		})
+		if err != nil {
+			return err
+		}
+		rotation.Active = pgxFairnessSeedToEntity(active)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rotation, nil
+}
+
+func (s *lootStore) GetUserItems(
+	userID uuid.UUID,
+	filter UserItemFilter,
+) ([]*entities.UserItem, error) {
+	params := sqlcdb.GetUserItemsWithDetailsParams{UserID: db.UUIDToPgxUUID(userID)}
+	if rarity, ok := filter.Rarity.GetVal(); ok {
+		params.Rarity = sqlcdb.NullItemType{ItemType: sqlcdb.ItemType(rarity), Valid: true}
+	}
+	if status, ok := filter.Status.GetVal(); ok {
+		params.Status = sqlcdb.NullItemStatus{ItemStatus: sqlcdb.ItemStatus(status), Valid: true}
+	}
+
+	rows, err := s.queries.GetUserItemsWithDetails(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*entities.UserItem, len(rows))
+	for i, row := range rows {
+		result[i] = pgxUserItemWithDetailsToEntity(row.UserItem, row.ChestItem)
+	}
+	return result, nil
+}
+
+// EquipUserItem equips one of the user's items and unequips whatever they had in the same slot.
+// The user row is locked for the transaction, so concurrent equips cannot fill a slot twice.
+func (s *lootStore) EquipUserItem(userID, userItemID uuid.UUID) (*entities.UserItem, error) {
+	ctx := context.Background()
+	var equipped *entities.UserItem
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		if err := q.LockUserById(ctx, db.UUIDToPgxUUID(userID)); err != nil {
+			return err
+		}
+
+		row, err := q.GetUserItemWithDetails(ctx, sqlcdb.GetUserItemWithDetailsParams{
+			ID:     db.UUIDToPgxUUID(userItemID),
+			UserID: db.UUIDToPgxUUID(userID),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrItemNotOwned
+		}
+		if err != nil {
+			return err
+		}
+		if row.ChestItem.Effect.Valid {
+			return ErrItemConsumable
+		}
+
+		_, err = q.UnequipUserItemsInSlot(ctx, sqlcdb.UnequipUserItemsInSlotParams{
+			UserID: row.UserItem.UserID,
+			Slot:   row.ChestItem.Slot,
+		})
+		if err != nil {
+			return err
+		}
+
+		userItem, err := q.UpdateUserItemStatus(ctx, sqlcdb.UpdateUserItemStatusParams{
+			ID:     row.UserItem.ID,
+			Status: sqlcdb.NullItemStatus{ItemStatus: sqlcdb.ItemStatusEquipped, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		equipped = pgxUserItemWithDetailsToEntity(userItem, row.ChestItem)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return equipped, nil
+}
+
+func (s *lootStore) UnequipUserItem(userID, userItemID uuid.UUID) (*entities.UserItem, error) {
+	ctx := context.Background()
+	var unequipped *entities.UserItem
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		if err := q.LockUserById(ctx, db.UUIDToPgxUUID(userID)); err != nil {
+			return err
+		}
+
+		row, err := q.GetUserItemWithDetails(ctx, sqlcdb.GetUserItemWithDetailsParams{
+			ID:     db.UUIDToPgxUUID(userItemID),
+			UserID: db.UUIDToPgxUUID(userID),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrItemNotOwned
+		}
+		if err != nil {
+			return err
+		}
+
+		userItem, err := q.UpdateUserItemStatus(ctx, sqlcdb.UpdateUserItemStatusParams{
+			ID:     row.UserItem.ID,
+			Status: sqlcdb.NullItemStatus{ItemStatus: sqlcdb.ItemStatusNotEquipped, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		unequipped = pgxUserItemWithDetailsToEntity(userItem, row.ChestItem)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return unequipped, nil
+}
+
+// SellUserItem removes an unequipped item from the user's inventory and credits them its price in
+// a single transaction
+func (s *lootStore) SellUserItem(userID, userItemID uuid.UUID) (*entities.ItemSale, error) {
+	ctx := context.Background()
+	sale := &entities.ItemSale{}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		if err := q.LockUserById(ctx, db.UUIDToPgxUUID(userID)); err != nil {
+			return err
+		}
+
+		row, err := q.GetUserItemWithDetails(ctx, sqlcdb.GetUserItemWithDetailsParams{
+			ID:     db.UUIDToPgxUUID(userItemID),
+			UserID: db.UUIDToPgxUUID(userID),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrItemNotOwned
+		}
+		if err != nil {
+			return err
+		}
+		if row.UserItem.Status.ItemStatus == sqlcdb.ItemStatusEquipped {
+			return ErrItemEquipped
+		}
+		if !row.ChestItem.Price.Valid {
+			return ErrItemNotSellable
+		}
+
+		if err := q.DeleteUserItem(ctx, row.UserItem.ID); err != nil {
+			return err
+		}
+		balance, err := db.RecordCashTransaction(
+			ctx,
+			q,
+			userID,
+			int(row.ChestItem.Price.Int32),
+			sqlcdb.CashTransactionReasonItemSale,
+			uuid.UUID(row.UserItem.ID.Bytes),
+		)
+		if err != nil {
+			return err
+		}
+
+		sale.Items = []*entities.UserItem{
+			pgxUserItemWithDetailsToEntity(row.UserItem, row.ChestItem),
+		}
+		sale.CashEarned = int(row.ChestItem.Price.Int32)
+		sale.CashAvailable = balance
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sale, nil
+}
+
+// SellDuplicateUserItems sells every unequipped duplicate of the rarity in the user's inventory,
+// keeping one copy of each item, and credits the combined price in a single transaction
+func (s *lootStore) SellDuplicateUserItems(
+	userID uuid.UUID,
+	rarity string,
+) (*entities.ItemSale, error) {
+	ctx := context.Background()
+	sale := &entities.ItemSale{Items: make([]*entities.UserItem, 0)}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		if err := q.LockUserById(ctx, db.UUIDToPgxUUID(userID)); err != nil {
+			return err
+		}
+
+		rows, err := q.GetSellableDuplicateUserItems(
+			ctx,
+			sqlcdb.GetSellableDuplicateUserItemsParams{
+				UserID: db.UUIDToPgxUUID(userID),
+				Rarity: sqlcdb.ItemType(rarity),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]pgtype.UUID, len(rows))
+		total := int32(0)
+		for i, row := range rows {
+			ids[i] = row.UserItem.ID
+			total += row.ChestItem.Price.Int32
+			sale.Items = append(
+				sale.Items,
+				pgxUserItemWithDetailsToEntity(row.UserItem, row.ChestItem),
+			)
+		}
+
+		if err := q.DeleteUserItemsByIds(ctx, ids); err != nil {
+			return err
+		}
+
+		// one ledger row per sold item, so each credit references the item it came from
+		balance, err := q.GetUserCash(ctx, db.UUIDToPgxUUID(userID))
+		if err != nil {
+			return err
+		}
+		cashAvailable := int(balance)
+		for _, row := range rows {
+			cashAvailable, err = db.RecordCashTransaction(
+				ctx,
+				q,
+				userID,
+				int(row.ChestItem.Price.Int32),
+				sqlcdb.CashTransactionReasonItemSale,
+				uuid.UUID(row.UserItem.ID.Bytes),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		sale.CashEarned = int(total)
+		sale.CashAvailable = cashAvailable
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sale, nil
+}
+
+// CreateGift takes what the gift holds out of the sender's inventory and records it as pending for
+// the recipient until expiresIn has passed, in a single transaction. Gifted chests are removed
+// from the sender's quantity and a gifted item is held without an owner, so neither can be
+// opened, sold or gifted again while the gift is pending.
+func (s *lootStore) CreateGift(
+	senderID uuid.UUID,
+	recipient GiftRecipient,
+	contents GiftContents,
+	expiresIn time.Duration,
+) (*entities.Gift, error) {
+	ctx := context.Background()
+	var gift *entities.Gift
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		recipientID, err := resolveGiftRecipient(ctx, q, recipient)
+		if err != nil {
+			return err
+		}
+		if recipientID == senderID {
+			return ErrGiftToSelf
+		}
+
+		params := sqlcdb.CreateGiftParams{
+			SenderID:    db.UUIDToPgxUUID(senderID),
+			RecipientID: db.UUIDToPgxUUID(recipientID),
+			ExpiresIn:   pgtype.Interval{Microseconds: expiresIn.Microseconds(), Valid: true},
+		}
+
+		if chestID, ok := contents.ChestID.GetVal(); ok {
+			_, err := q.TakeUserChests(ctx, sqlcdb.TakeUserChestsParams{
+				UserID:   db.UUIDToPgxUUID(senderID),
+				ChestID:  db.UUIDToPgxUUID(chestID),
+				Quantity: pgtype.Int4{Int32: int32(contents.Quantity), Valid: true},
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNoChestsOwned
+			}
+			if err != nil {
+				return err
+			}
+			params.ChestID = db.UUIDToPgxUUID(chestID)
+			params.Quantity = pgtype.Int4{Int32: int32(contents.Quantity), Valid: true}
+		}
+
+		if userItemID, ok := contents.UserItemID.GetVal(); ok {
+			if err := q.LockUserById(ctx, db.UUIDToPgxUUID(senderID)); err != nil {
+				return err
+			}
+			row, err := q.GetUserItemWithDetails(ctx, sqlcdb.GetUserItemWithDetailsParams{
+				ID:     db.UUIDToPgxUUID(userItemID),
+				UserID: db.UUIDToPgxUUID(senderID),
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrItemNotOwned
+			}
+			if err != nil {
+				return err
+			}
+			if row.UserItem.Status.ItemStatus == sqlcdb.ItemStatusEquipped {
+				return ErrItemEquipped
+			}
+
+			_, err = q.SetUserItemOwner(ctx, sqlcdb.SetUserItemOwnerParams{
+				ID: row.UserItem.ID,
+			})
+			if err != nil {
+				return err
+			}
+			params.UserItemID = row.UserItem.ID
+		}
+
+		created, err := q.CreateGift(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		gift = pgxGiftToEntity(created)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return gift, nil
+}
+
+// resolveGiftRecipient returns the id of the user the gift is addressed to
+func resolveGiftRecipient(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	recipient GiftRecipient,
+) (uuid.UUID, error) {
+	var user sqlcdb.User
+	var err error
+	if id, ok := recipient.ID.GetVal(); ok {
+		user, err = q.GetUserById(ctx, db.UUIDToPgxUUID(id))
+	} else {
+		user, err = q.GetUserByEmail(ctx, recipient.Email)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, ErrRecipientNotFound
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return uuid.UUID(user.ID.Bytes), nil
+}
+
+// AcceptGift moves a pending gift's chests or item into the recipient's inventory and marks it
+// accepted in a single transaction. A gift past its expiry can no longer be accepted and is left
+// for ExpireGifts to return.
+func (s *lootStore) AcceptGift(recipientID, giftID uuid.UUID) (*entities.Gift, error) {
+	return s.resolveGift(recipientID, giftID, sqlcdb.GiftStatusAccepted)
+}
+
+// DeclineGift returns a pending gift's chests or item to the sender and marks it declined in a
+// single transaction
+func (s *lootStore) DeclineGift(recipientID, giftID uuid.UUID) (*entities.Gift, error) {
+	return s.resolveGift(recipientID, giftID, sqlcdb.GiftStatusDeclined)
+}
+
+func (s *lootStore) resolveGift(
+	recipientID, giftID uuid.UUID,
+	status sqlcdb.GiftStatus,
+) (*entities.Gift, error) {
+	ctx := context.Background()
+	var gift *entities.Gift
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		row, err := q.LockPendingGift(ctx, sqlcdb.LockPendingGiftParams{
+			ID:          db.UUIDToPgxUUID(giftID),
+			RecipientID: db.UUIDToPgxUUID(recipientID),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGiftNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		owner := row.Gift.SenderID
+		if status == sqlcdb.GiftStatusAccepted {
+			if row.Expired {
+				return ErrGiftExpired
+			}
+			owner = row.Gift.RecipientID
+		}
+
+		resolved, err := settleGift(ctx, q, row.Gift, owner, status)
+		if err != nil {
+			return err
+		}
+
+		gift = pgxGiftToEntity(resolved)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return gift, nil
+}
+
+// ExpireGifts returns every pending gift past its expiry to its sender and marks it expired in a
+// single transaction. Gifts a recipient is resolving concurrently are skipped and left to them.
+func (s *lootStore) ExpireGifts() ([]*entities.Gift, error) {
+	ctx := context.Background()
+	expired := make([]*entities.Gift, 0)
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		gifts, err := q.LockExpiredGifts(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, gift := range gifts {
+			resolved, err := settleGift(ctx, q, gift, gift.SenderID, sqlcdb.GiftStatusExpired)
+			if err != nil {
+				return err
+			}
+			expired = append(expired, pgxGiftToEntity(resolved))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return expired, nil
+}
+
+// settleGift gives a locked gift's chests or item to owner and records the gift's final status
+func settleGift(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	gift sqlcdb.Gift,
+	owner pgtype.UUID,
+	status sqlcdb.GiftStatus,
+) (sqlcdb.Gift, error) {
+	if gift.ChestID.Valid {
+		_, err := q.IncrementUserChestQuantity(ctx, sqlcdb.IncrementUserChestQuantityParams{
+			UserID:        owner,
+			ChestID:       gift.ChestID,
+			QuantityOwned: gift.Quantity,
+		})
+		if err != nil {
+			return sqlcdb.Gift{}, err
+		}
+	}
+	if gift.UserItemID.Valid {
+		_, err := q.SetUserItemOwner(ctx, sqlcdb.SetUserItemOwnerParams{
+			ID:     gift.UserItemID,
+			UserID: owner,
+		})
+		if err != nil {
+			return sqlcdb.Gift{}, err
+		}
+	}
+
+	return q.ResolveGift(ctx, sqlcdb.ResolveGiftParams{ID: gift.ID, Status: status})
+}
+
+// GetGifts returns the gifts the user received, or sent when filter.Sent is set, newest first
+func (s *lootStore) GetGifts(userID uuid.UUID, filter GiftFilter) ([]*entities.Gift, error) {
+	ctx := context.Background()
+	status := sqlcdb.NullGiftStatus{}
+	if value, ok := filter.Status.GetVal(); ok {
+		status = sqlcdb.NullGiftStatus{GiftStatus: sqlcdb.GiftStatus(value), Valid: true}
+	}
+
+	var rows []sqlcdb.Gift
+	var err error
+	if filter.Sent {
+		rows, err = s.queries.GetGiftsBySenderId(ctx, sqlcdb.GetGiftsBySenderIdParams{
+			SenderID: db.UUIDToPgxUUID(userID),
+			Status:   status,
+		})
+	} else {
+		rows, err = s.queries.GetGiftsByRecipientId(ctx, sqlcdb.GetGiftsByRecipientIdParams{
+			RecipientID: db.UUIDToPgxUUID(userID),
+			Status:      status,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gifts := make([]*entities.Gift, len(rows))
+	for i, row := range rows {
+		gifts[i] = pgxGiftToEntity(row)
+	}
+	return gifts, nil
+}
+
+// CreateListing puts one of the seller's unequipped items on the marketplace at price. The item
+// is held by the listing until it is bought or cancelled, so it cannot be equipped, sold or
+// gifted in the meantime.
+func (s *lootStore) CreateListing(
+	sellerID, userItemID uuid.UUID,
+	price int,
+) (*entities.Listing, error) {
+	ctx := context.Background()
+	var listing *entities.Listing
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		if err := q.LockUserById(ctx, db.UUIDToPgxUUID(sellerID)); err != nil {
+			return err
+		}
+
+		row, err := q.GetUserItemWithDetails(ctx, sqlcdb.GetUserItemWithDetailsParams{
+			ID:     db.UUIDToPgxUUID(userItemID),
+			UserID: db.UUIDToPgxUUID(sellerID),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrItemNotOwned
+		}
+		if err != nil {
+			return err
+		}
+		if row.UserItem.Status.ItemStatus == sqlcdb.ItemStatusEquipped {
+			return ErrItemEquipped
+		}
+
+		_, err = q.SetUserItemOwner(ctx, sqlcdb.SetUserItemOwnerParams{ID: row.UserItem.ID})
+		if err != nil {
+			return err
+		}
+
+		created, err := q.CreateListing(ctx, sqlcdb.CreateListingParams{
+			SellerID:   db.UUIDToPgxUUID(sellerID),
+			UserItemID: row.UserItem.ID,
+			Price:      int32(price),
+		})
+		if err != nil {
+			return err
+		}
+
+		listing = pgxListingToEntity(created, row.ChestItem)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return listing, nil
+}
+
+// CancelListing takes the seller's active listing off the marketplace and returns its item to
+// their inventory
+func (s *lootStore) CancelListing(sellerID uuid.UUID, listingID int64) (*entities.Listing, error) {
+	ctx := context.Background()
+	var listing *entities.Listing
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		row, err := q.LockActiveListing(ctx, listingID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrListingNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if uuid.UUID(row.Listing.SellerID.Bytes) != sellerID {
+			return ErrListingNotFound
+		}
+
+		_, err = q.SetUserItemOwner(ctx, sqlcdb.SetUserItemOwnerParams{
+			ID:     row.Listing.UserItemID,
+			UserID: row.Listing.SellerID,
+		})
+		if err != nil {
+			return err
+		}
+
+		closed, err := q.CloseListing(ctx, sqlcdb.CloseListingParams{
+			ID:     row.Listing.ID,
+			Status: sqlcdb.ListingStatusCancelled,
+		})
+		if err != nil {
+			return err
+		}
+
+		listing = pgxListingToEntity(closed, row.ChestItem)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return listing, nil
+}
+
+// lockUsers locks the users' rows in id order, so transactions locking the same users can't
+// deadlock whatever order they name them in
+func lockUsers(ctx context.Context, q *sqlcdb.Queries, userIDs ...uuid.UUID) error {
+	sorted := slices.Clone(userIDs)
+	slices.SortFunc(sorted, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	for _, id := range sorted {
+		if err := q.LockUserById(ctx, db.UUIDToPgxUUID(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuyListing moves an active listing's item to the buyer, debits the buyer the price and credits
+// the seller the price less fee(price), in a single serializable transaction. Both sides of the
+// trade are recorded in the cash ledger against the user item. Buyer and seller are locked in id
+// order, so crossing purchases between the same two users wait on each other instead of
+// deadlocking.
+func (s *lootStore) BuyListing(
+	buyerID uuid.UUID,
+	listingID int64,
+	fee FeeFunc,
+) (*ListingSale, error) {
+	ctx := context.Background()
+	var sale *ListingSale
+
+	err := db.WithSerializableTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		row, err := q.LockActiveListing(ctx, listingID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrListingNotFound
+		}
+		if err != nil {
+			return err
+		}
+		sellerID := uuid.UUID(row.Listing.SellerID.Bytes)
+		if sellerID == buyerID {
+			return ErrOwnListing
+		}
+		if err := lockUsers(ctx, q, buyerID, sellerID); err != nil {
+			return err
+		}
+
+		price := int(row.Listing.Price)
+		userItemID := uuid.UUID(row.Listing.UserItemID.Bytes)
+		buyerBalance, err := db.RecordCashTransaction(
+			ctx,
+			q,
+			buyerID,
+			-price,
+			sqlcdb.CashTransactionReasonMarketplacePurchase,
+			userItemID,
+		)
+		if errors.Is(err, db.ErrInsufficientCash) {
+			return ErrInsufficientCash
+		}
+		if err != nil {
+			return err
+		}
+
+		houseFee := fee(price)
+		sellerBalance, err := db.RecordCashTransaction(
+			ctx,
+			q,
+			sellerID,
+			price-houseFee,
+			sqlcdb.CashTransactionReasonMarketplaceSale,
+			userItemID,
+		)
+		if err != nil {
+			return err
+		}
+
+		userItem, err := q.SetUserItemOwner(ctx, sqlcdb.SetUserItemOwnerParams{
+			ID:     row.Listing.UserItemID,
+			UserID: db.UUIDToPgxUUID(buyerID),
+		})
+		if err != nil {
+			return err
+		}
+
+		closed, err := q.CloseListing(ctx, sqlcdb.CloseListingParams{
+			ID:      row.Listing.ID,
+			Status:  sqlcdb.ListingStatusSold,
+			BuyerID: db.UUIDToPgxUUID(buyerID),
+			Fee:     pgtype.Int4{Int32: int32(houseFee), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		sale = &ListingSale{
+			Purchase: &entities.ListingPurchase{
+				Listing:       pgxListingToEntity(closed, row.ChestItem),
+				UserItem:      pgxUserItemWithDetailsToEntity(userItem, row.ChestItem),
+				CashAvailable: buyerBalance,
+			},
+			Proceeds:            price - houseFee,
+			SellerCashAvailable: sellerBalance,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sale, nil
+}
+
+// titleLikeEscaper escapes LIKE wildcards so a title filter matches literally
+var titleLikeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// GetListings returns up to limit active listings matching filter, newest first, that are older
+// than the cursor listing id when one is given
+func (s *lootStore) GetListings(
+	filter ListingFilter,
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.Listing, error) {
+	params := sqlcdb.GetActiveListingsParams{PageSize: int32(limit)}
+	if rarity, ok := filter.Rarity.GetVal(); ok {
+		params.Rarity = sqlcdb.NullItemType{ItemType: sqlcdb.ItemType(rarity), Valid: true}
+	}
+	if title, ok := filter.Title.GetVal(); ok {
+		params.Title = pgtype.Text{String: titleLikeEscaper.Replace(title), Valid: true}
+	}
+	if id, ok := cursor.GetVal(); ok {
+		params.Cursor = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	rows, err := s.queries.GetActiveListings(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	listings := make([]*entities.Listing, len(rows))
+	for i, row := range rows {
+		listings[i] = pgxListingToEntity(row.Listing, row.ChestItem)
+	}
+	return listings, nil
+}
+
+// GetUserListings returns up to limit of the seller's listings, optionally in one status, newest
+// first, that are older than the cursor listing id when one is given
+func (s *lootStore) GetUserListings(
+	sellerID uuid.UUID,
+	status options.Option[string],
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.Listing, error) {
+	params := sqlcdb.GetListingsBySellerIdParams{
+		SellerID: db.UUIDToPgxUUID(sellerID),
+		PageSize: int32(limit),
+	}
+	if value, ok := status.GetVal(); ok {
+		params.Status = sqlcdb.NullListingStatus{
+			ListingStatus: sqlcdb.ListingStatus(value),
+			Valid:         true,
+		}
+	}
+	if id, ok := cursor.GetVal(); ok {
+		params.Cursor = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	rows, err := s.queries.GetListingsBySellerId(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	listings := make([]*entities.Listing, len(rows))
+	for i, row := range rows {
+		listings[i] = pgxListingToEntity(row.Listing, row.ChestItem)
+	}
+	return listings, nil
+}
+
+// GetCollection returns every catalog item with the user's discovery of it, and their progress on
+// each chest's current drop table. The rollups are left for the caller.
+func (s *lootStore) GetCollection(userID uuid.UUID) (*entities.Collection, error) {
+	ctx := context.Background()
+
+	entryRows, err := s.queries.GetCollectionEntries(ctx, db.UUIDToPgxUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+	chestRows, err := s.queries.GetChestCollections(ctx, sqlcdb.GetChestCollectionsParams{
+		UserID: db.UUIDToPgxUUID(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &entities.Collection{
+		Items:  make([]*entities.CollectionEntry, len(entryRows)),
+		Chests: make([]*entities.ChestCollection, len(chestRows)),
+	}
+	for i, row := range entryRows {
+		entry := &entities.CollectionEntry{
+			FirstObtainedAt: options.None[time.Time](),
+			Item:            pgxChestItemToEntity(row.ChestItem),
+			CopiesOwned:     int(row.CopiesOwned),
+			Discovered:      row.FirstObtainedAt.Valid,
+		}
+		if row.FirstObtainedAt.Valid {
+			entry.FirstObtainedAt = options.Some(row.FirstObtainedAt.Time)
+		}
+		collection.Items[i] = entry
+	}
+	for i, row := range chestRows {
+		collection.Chests[i] = pgxChestCollectionToEntity(row)
+	}
+
+	return collection, nil
+}
+
+func pgxChestCollectionToEntity(row sqlcdb.GetChestCollectionsRow) *entities.ChestCollection {
+	return &entities.ChestCollection{
+		Progress: &entities.CollectionProgress{
+			Discovered: int(row.Discovered),
+			Total:      int(row.Total),
+		},
+		ChestType:    string(row.Type),
+		ChestID:      uuid.UUID(row.ID.Bytes),
+		Complete:     row.Discovered == row.Total,
+		BonusClaimed: row.BonusClaimed,
+	}
+}
+
+// ClaimCollectionBonus pays cash for having discovered every item in the chest's current drop
+// table. The payout is recorded per user and chest, so it is made at most once even if the drop
+// table later grows.
+func (s *lootStore) ClaimCollectionBonus(
+	userID, chestID uuid.UUID,
+	cash int,
+) (*entities.CollectionBonus, error) {
+	ctx := context.Background()
+	bonus := &entities.CollectionBonus{ChestID: chestID, CashGranted: cash}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		rows, err := q.GetChestCollections(ctx, sqlcdb.GetChestCollectionsParams{
+			UserID:  db.UUIDToPgxUUID(userID),
+			ChestID: db.UUIDToPgxUUID(chestID),
+		})
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return sql.ErrNoRows
+		}
+		if rows[0].Discovered < rows[0].Total {
+			return ErrCollectionIncomplete
+		}
+
+		_, err = q.CreateCollectionReward(ctx, sqlcdb.CreateCollectionRewardParams{
+			UserID:  db.UUIDToPgxUUID(userID),
+			ChestID: db.UUIDToPgxUUID(chestID),
+			Cash:    int32(cash),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBonusClaimed
+		}
+		if err != nil {
+			return err
+		}
+
+		bonus.CashAvailable, err = db.RecordCashTransaction(
+			ctx,
+			q,
+			userID,
+			cash,
+			sqlcdb.CashTransactionReasonCollectionBonus,
+			chestID,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bonus, nil
+}
+
+func pgxCraftingRecipeToEntity(cr sqlcdb.CraftingRecipe) *entities.CraftingRecipe {
+	return &entities.CraftingRecipe{
+		Slug:         cr.Slug,
+		InputRarity:  string(cr.InputRarity),
+		OutputRarity: string(cr.OutputRarity),
+		InputCount:   int(cr.InputCount),
+		ID:           uuid.UUID(cr.ID.Bytes),
+		ChestID:      uuid.UUID(cr.ChestID.Bytes),
+	}
+}
+
+func (s *lootStore) GetCraftingRecipes() ([]*entities.CraftingRecipe, error) {
+	recipes, err := s.queries.GetCraftingRecipes(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*entities.CraftingRecipe, len(recipes))
+	for i, r := range recipes {
+		result[i] = pgxCraftingRecipeToEntity(r)
+	}
+	return result, nil
+}
+
+// Craft consumes the user's items, which must be exactly the recipe's input count of its input
+// rarity, rolls the output from the recipe's candidates, the chest's non-consumable items of its
+// output rarity, using pick and the user's committed seed pair, adds it to the user's items and
+// records the craft, all in a single transaction. Equipped items are refused, and items held by a
+// gift or listing are not in the user's inventory.
+func (s *lootStore) Craft(
+	userID, recipeID uuid.UUID,
+	userItemIDs []uuid.UUID,
+	pick CraftPickFunc,
+) (*entities.Craft, error) {
+	ctx := context.Background()
+	craft := &entities.Craft{RecipeID: recipeID, ConsumedItems: userItemIDs}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		recipe, err := q.GetCraftingRecipeById(ctx, db.UUIDToPgxUUID(recipeID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRecipeNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if len(userItemIDs) != int(recipe.InputCount) {
+			return ErrCraftInputs
+		}
+
+		if err := q.LockUserById(ctx, db.UUIDToPgxUUID(userID)); err != nil {
+			return err
+		}
+
+		ids := make([]pgtype.UUID, len(userItemIDs))
+		for i, id := range userItemIDs {
+			ids[i] = db.UUIDToPgxUUID(id)
+		}
+		inputs, err := q.LockUserItemsWithDetails(ctx, sqlcdb.LockUserItemsWithDetailsParams{
+			Ids:    ids,
+			UserID: db.UUIDToPgxUUID(userID),
+		})
+		if err != nil {
+			return err
+		}
+		if len(inputs) != len(userItemIDs) {
+			return ErrItemNotOwned
+		}
+		for _, input := range inputs {
+			if input.UserItem.Status.ItemStatus == sqlcdb.ItemStatusEquipped {
+				return ErrItemEquipped
+			}
+			if input.ChestItem.Rarity != recipe.InputRarity {
+				return ErrCraftInputs
+			}
+		}
+
+		chest, err := q.GetChestByIdForShare(ctx, recipe.ChestID)
+		if err != nil {
+			return err
+		}
+		// the craft references its version, so make sure the history holds it
+		err = q.ArchiveDropTable(ctx, sqlcdb.ArchiveDropTableParams{
+			ChestID: chest.ID,
+			Version: chest.DropTableVersion,
+		})
+		if err != nil {
+			return err
+		}
+
+		rates, err := q.GetCraftingOutputsByChestId(ctx, sqlcdb.GetCraftingOutputsByChestIdParams{
+			ChestID: chest.ID,
+			Rarity:  recipe.OutputRarity,
+		})
+		if err != nil {
+			return err
+		}
+		candidates := make([]*entities.ChestItemDropRate, 0, len(rates))
+		for _, r := range rates {
+			row := sqlcdb.GetDropTableByChestIdRow(r)
+			candidates = append(candidates, pgxDropTableRowToEntity(row))
+		}
+
+		seed, err := q.LockActiveFairnessSeed(ctx, db.UUIDToPgxUUID(userID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoFairnessSeed
+		}
+		if err != nil {
+			return err
+		}
+
+		draw, err := pick(candidates, FairnessInput{
+			ServerSeed: seed.ServerSeed,
+			ClientSeed: seed.ClientSeed,
+			Nonce:      int(seed.NextNonce),
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.IncrementFairnessSeedNonce(ctx, seed.ID); err != nil {
+			return err
+		}
+		if err := q.DeleteUserItemsByIds(ctx, ids); err != nil {
+			return err
+		}
+
+		userItem, err := q.CreateUserItem(ctx, sqlcdb.CreateUserItemParams{
+			UserID: db.UUIDToPgxUUID(userID),
+			ItemID: db.UUIDToPgxUUID(draw.ItemID),
+			Status: sqlcdb.NullItemStatus{
+				ItemStatus: sqlcdb.ItemStatusNotEquipped,
+				Valid:      true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		item, err := q.GetChestItemById(ctx, userItem.ItemID)
+		if err != nil {
+			return err
+		}
+
+		record, err := q.CreateCraft(ctx, sqlcdb.CreateCraftParams{
+			UserID:           db.UUIDToPgxUUID(userID),
+			RecipeID:         recipe.ID,
+			ItemID:           item.ID,
+			UserItemID:       userItem.ID,
+			DropTableVersion: chest.DropTableVersion,
+			FairnessSeedID:   seed.ID,
+			Nonce:            seed.NextNonce,
+			Roll:             draw.Roll,
+		})
+		if err != nil {
+			return err
+		}
+		for _, input := range inputs {
+			err := q.CreateCraftInput(ctx, sqlcdb.CreateCraftInputParams{
+				CraftID:    record.ID,
+				UserItemID: input.UserItem.ID,
+				ItemID:     input.ChestItem.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		craft.ID = record.ID
+		craft.UserItem = pgxUserItemToEntity(userItem)
+		craft.Item = pgxChestItemToEntity(item)
+		craft.Proof = &entities.FairnessProof{
+			ServerSeed:       options.None[string](),
+			TopDropChance:    options.None[float64](),
+			ServerSeedHash:   seed.ServerSeedHash,
+			ClientSeed:       seed.ClientSeed,
+			Nonce:            int(record.Nonce),
+			Roll:             record.Roll,
+			DropTableVersion: int(record.DropTableVersion),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return craft, nil
+}
+
+// GetAvatar returns the user's level and equipped items, or sql.ErrNoRows when the user does not
+// exist
+func (s *lootStore) GetAvatar(userID uuid.UUID) (*entities.Avatar, error) {
+	ctx := context.Background()
+
+	user, err := s.queries.GetUserById(ctx, db.UUIDToPgxUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.queries.GetEquippedChestItemsByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	avatar := &entities.Avatar{
+		Items:  make([]*entities.ChestItem, len(items)),
+		Level:  int(user.LevelID.Int32),
+		UserID: userID,
+	}
+	for i, item := range items {
+		avatar.Items[i] = pgxChestItemToEntity(item)
+	}
+	return avatar, nil
+}
+
+// ConsumeUserItem uses up an unequipped consumable from the user's inventory and starts its effect
+// in a single transaction
+func (s *lootStore) ConsumeUserItem(
+	userID, userItemID uuid.UUID,
+) (*entities.ItemConsumption, error) {
+	ctx := context.Background()
+	consumption := &entities.ItemConsumption{}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		if err := q.LockUserById(ctx, db.UUIDToPgxUUID(userID)); err != nil {
+			return err
+		}
+
+		row, err := q.GetUserItemWithDetails(ctx, sqlcdb.GetUserItemWithDetailsParams{
+			ID:     db.UUIDToPgxUUID(userItemID),
+			UserID: db.UUIDToPgxUUID(userID),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrItemNotOwned
+		}
+		if err != nil {
+			return err
+		}
+		if !row.ChestItem.Effect.Valid {
+			return ErrItemNotConsumable
+		}
+
+		if err := q.DeleteUserItem(ctx, row.UserItem.ID); err != nil {
+			return err
+		}
+		effect, err := q.CreateActiveEffect(ctx, sqlcdb.CreateActiveEffectParams{
+			UserID:          row.UserItem.UserID,
+			Effect:          row.ChestItem.Effect.ItemEffect,
+			Value:           row.ChestItem.EffectValue.Int32,
+			SourceItemID:    row.ChestItem.ID,
+			DurationSeconds: row.ChestItem.EffectDurationSeconds.Int32,
+		})
+		if err != nil {
+			return err
+		}
+
+		consumption.UserItem = pgxUserItemWithDetailsToEntity(row.UserItem, row.ChestItem)
+		consumption.Effect = pgxActiveEffectToEntity(effect)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return consumption, nil
+}
+
+// GetActiveEffects returns the user's effects that have not expired, soonest to expire first
+func (s *lootStore) GetActiveEffects(userID uuid.UUID) ([]*entities.ActiveEffect, error) {
+	rows, err := s.queries.GetActiveEffectsByUserId(context.Background(), db.UUIDToPgxUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	effects := make([]*entities.ActiveEffect, len(rows))
+	for i, row := range rows {
+		effects[i] = pgxActiveEffectToEntity(row)
+	}
+	return effects, nil
+}
+
+// DeleteExpiredEffects removes every expired effect and returns how many there were
+func (s *lootStore) DeleteExpiredEffects() (int, error) {
+	rows, err := s.queries.DeleteExpiredActiveEffects(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return int(rows), nil
+}
@@ -2,13 +2,21 @@ package stores
 
 import (
 	"context"
+	"database/sql"
 	"goalify/internal/db"
+	"goalify/internal/entities"
 	"goalify/internal/testsetup"
+	"goalify/pkg/options"
 	"log"
 	"os"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 
 	sqlcdb "goalify/internal/db/generated"
@@ -16,6 +24,7 @@ import (
 
 var (
 	cStore      LootStore
+	queries     *sqlcdb.Queries
 	pgContainer *postgres.PostgresContainer
 )
 
@@ -37,8 +46,8 @@ func setup(ctx context.Context) {
 		panic(err)
 	}
 
-	queries := sqlcdb.New(pgxPool)
-	cStore = NewChestStore(queries)
+	queries = sqlcdb.New(pgxPool)
+	cStore = NewChestStore(pgxPool, queries)
 }
 
 func TestMain(m *testing.M) {
@@ -63,3 +72,1012 @@ func TestCreateChest(t *testing.T) {
 	assert.NotNil(t, chest)
 	assert.Equal(t, 100, chest.Price)
 }
+
+func createTestItem(t *testing.T, title, rarity string) *entities.ChestItem {
+	t.Helper()
+	return createTestSlotItem(t, title, rarity, "accessory")
+}
+
+func createTestSlotItem(t *testing.T, title, rarity, slot string) *entities.ChestItem {
+	t.Helper()
+	item, err := queries.CreateChestItem(context.Background(), sqlcdb.CreateChestItemParams{
+		Title:  title,
+		Rarity: sqlcdb.ItemType(rarity),
+		Slot:   sqlcdb.ItemSlot(slot),
+	})
+	assert.NoError(t, err)
+	return pgxChestItemToEntity(item)
+}
+
+func createTestUserItem(t *testing.T, userID, itemID uuid.UUID) *entities.UserItem {
+	t.Helper()
+	userItem, err := queries.CreateUserItem(context.Background(), sqlcdb.CreateUserItemParams{
+		UserID: db.UUIDToPgxUUID(userID),
+		ItemID: db.UUIDToPgxUUID(itemID),
+		Status: sqlcdb.NullItemStatus{ItemStatus: sqlcdb.ItemStatusNotEquipped, Valid: true},
+	})
+	require.NoError(t, err)
+	return pgxUserItemToEntity(userItem)
+}
+
+func createTestDropRate(t *testing.T, chestID, itemID uuid.UUID, rate float64) {
+	t.Helper()
+	_, err := queries.CreateChestItemDropRate(
+		context.Background(),
+		sqlcdb.CreateChestItemDropRateParams{
+			ChestID:  db.UUIDToPgxUUID(chestID),
+			ItemID:   db.UUIDToPgxUUID(itemID),
+			DropRate: rate,
+		},
+	)
+	assert.NoError(t, err)
+}
+
+func TestGetDropRatesByChestID(t *testing.T) {
+	t.Parallel()
+
+	chest, err := cStore.CreateChest("silver", t.Name(), 200)
+	assert.NoError(t, err)
+	common := createTestItem(t, t.Name()+" common", "common")
+	rare := createTestItem(t, t.Name()+" rare", "rare")
+	createTestDropRate(t, chest.ID, common.ID, 0.75)
+	createTestDropRate(t, chest.ID, rare.ID, 0.25)
+
+	rates, err := cStore.GetDropRatesByChestID(chest.ID)
+	assert.NoError(t, err)
+	assert.Len(t, rates, 2)
+	for _, rate := range rates {
+		assert.Equal(t, chest.ID, rate.ChestID)
+	}
+}
+
+func TestGetAllChestItems(t *testing.T) {
+	t.Parallel()
+
+	item := createTestItem(t, t.Name(), "epic")
+
+	items, err := cStore.GetAllChestItems()
+	assert.NoError(t, err)
+	found := false
+	for _, i := range items {
+		if i.ID == item.ID {
+			found = true
+			assert.Equal(t, "epic", i.Rarity)
+			assert.False(t, i.Price.IsPresent())
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestSetChestItemImage(t *testing.T) {
+	t.Parallel()
+
+	item := createTestItem(t, t.Name(), "rare")
+
+	updated, err := cStore.SetChestItemImage(item.ID, "/api/blobs/items/a.png?signature=x")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/blobs/items/a.png?signature=x", updated.ImageURL)
+	assert.Equal(t, item.Title, updated.Title)
+
+	fetched, err := cStore.GetChestItemByID(item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated.ImageURL, fetched.ImageURL)
+
+	_, err = cStore.SetChestItemImage(uuid.New(), "items/b.png")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = cStore.GetChestItemByID(uuid.New())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func createTestUser(t *testing.T, cash int32) uuid.UUID {
+	t.Helper()
+	return createTestUserWithEmail(t, t.Name()+"@mail.com", cash)
+}
+
+func createTestUserWithEmail(t *testing.T, email string, cash int32) uuid.UUID {
+	t.Helper()
+	user, err := queries.CreateUser(context.Background(), sqlcdb.CreateUserParams{
+		Email:              email,
+		Password:           "Password123!",
+		RefreshTokenExpiry: pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+		LevelID:            pgtype.Int4{Int32: 1, Valid: true},
+	})
+	assert.NoError(t, err)
+	_, err = db.RecordCashTransaction(
+		context.Background(),
+		queries,
+		uuid.UUID(user.ID.Bytes),
+		int(cash),
+		sqlcdb.CashTransactionReasonAdminGrant,
+		uuid.Nil,
+	)
+	assert.NoError(t, err)
+	return uuid.UUID(user.ID.Bytes)
+}
+
+func TestPurchaseChest(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 250)
+	chest, err := cStore.CreateChest("bronze", t.Name(), 100)
+	assert.NoError(t, err)
+
+	purchase, err := cStore.PurchaseChest(userID, chest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 150, purchase.CashAvailable)
+	assert.Equal(t, 1, purchase.UserChest.QuantityOwned)
+
+	purchase, err = cStore.PurchaseChest(userID, chest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, purchase.CashAvailable)
+	assert.Equal(t, 2, purchase.UserChest.QuantityOwned)
+
+	_, err = cStore.PurchaseChest(userID, chest.ID)
+	assert.ErrorIs(t, err, ErrInsufficientCash)
+}
+
+func TestOpenChest(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 100)
+	chest, err := cStore.CreateChest("bronze", t.Name(), 100)
+	assert.NoError(t, err)
+	item := createTestItem(t, t.Name(), "common")
+	createTestDropRate(t, chest.ID, item.ID, 1)
+
+	pickOnly := func(
+		rates []*entities.ChestItemDropRate,
+		_ int,
+		_ FairnessInput,
+	) (*Draw, error) {
+		assert.Len(t, rates, 1)
+		return &Draw{ItemID: rates[0].ItemID, Roll: 0.5}, nil
+	}
+
+	_, err = cStore.OpenChest(userID, chest.ID, pickOnly)
+	assert.ErrorIs(t, err, ErrNoChestsOwned)
+
+	_, err = cStore.PurchaseChest(userID, chest.ID)
+	assert.NoError(t, err)
+	_, err = cStore.OpenChest(userID, chest.ID, pickOnly)
+	assert.ErrorIs(t, err, ErrNoFairnessSeed)
+
+	commitTestSeed(t, userID)
+
+	opening, err := cStore.OpenChest(userID, chest.ID, pickOnly)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, opening.QuantityRemaining)
+	assert.Equal(t, item.ID, opening.Item.ID)
+	assert.Equal(t, userID, opening.UserItem.UserID)
+	assert.Equal(t, "not_equipped", opening.UserItem.Status)
+	assert.Equal(t, 1, opening.Pity.OpensSinceTopDrop)
+}
+
+func TestOpenChestPityCounter(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 300)
+	chest, err := cStore.CreateChest("gold", t.Name(), 100)
+	require.NoError(t, err)
+	item := createTestItem(t, t.Name(), "legendary")
+	createTestDropRate(t, chest.ID, item.ID, 1)
+
+	count, err := cStore.GetOpensSinceTopDrop(userID, chest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	commitTestSeed(t, userID)
+
+	var seen []int
+	open := func(topDrop bool) {
+		_, err := cStore.PurchaseChest(userID, chest.ID)
+		require.NoError(t, err)
+		_, err = cStore.OpenChest(
+			userID,
+			chest.ID,
+			func(
+				rates []*entities.ChestItemDropRate,
+				pity int,
+				_ FairnessInput,
+			) (*Draw, error) {
+				seen = append(seen, pity)
+				return &Draw{ItemID: rates[0].ItemID, TopDrop: topDrop}, nil
+			},
+		)
+		require.NoError(t, err)
+	}
+
+	open(false)
+	open(false)
+	count, err = cStore.GetOpensSinceTopDrop(userID, chest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// a top drop resets the counter
+	open(true)
+	count, err = cStore.GetOpensSinceTopDrop(userID, chest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, []int{0, 1, 2}, seen)
+}
+
+func commitTestSeed(t *testing.T, userID uuid.UUID) *entities.FairnessSeed {
+	t.Helper()
+	seed, err := cStore.CommitFairnessSeed(userID, SeedCommitment{
+		ServerSeed:     t.Name() + "-server",
+		ServerSeedHash: t.Name() + "-hash",
+		ClientSeed:     t.Name() + "-client",
+	})
+	require.NoError(t, err)
+	return seed
+}
+
+func TestOpenChestRecordsOpening(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 200)
+	chest, err := cStore.CreateChest("silver", t.Name(), 100)
+	require.NoError(t, err)
+	item := createTestItem(t, t.Name(), "rare")
+	createTestDropRate(t, chest.ID, item.ID, 1)
+	seed := commitTestSeed(t, userID)
+
+	var nonces []int
+	pick := func(
+		rates []*entities.ChestItemDropRate,
+		_ int,
+		input FairnessInput,
+	) (*Draw, error) {
+		assert.Equal(t, t.Name()+"-server", input.ServerSeed)
+		assert.Equal(t, t.Name()+"-client", input.ClientSeed)
+		nonces = append(nonces, input.Nonce)
+		return &Draw{
+			ItemID:        rates[0].ItemID,
+			Roll:          0.25,
+			TopDropChance: options.Some(0.75),
+		}, nil
+	}
+	for range 2 {
+		_, err = cStore.PurchaseChest(userID, chest.ID)
+		require.NoError(t, err)
+		opening, err := cStore.OpenChest(userID, chest.ID, pick)
+		require.NoError(t, err)
+		assert.Equal(t, seed.ServerSeedHash, opening.Proof.ServerSeedHash)
+		assert.False(t, opening.Proof.ServerSeed.IsPresent())
+	}
+	assert.Equal(t, []int{0, 1}, nonces)
+
+	openings, err := cStore.GetChestOpenings(userID, options.None[int64](), 10)
+	require.NoError(t, err)
+	require.Len(t, openings, 2)
+	latest := openings[0]
+	assert.Equal(t, 1, latest.Proof.Nonce)
+	assert.Equal(t, 0.25, latest.Proof.Roll)
+	assert.Equal(t, options.Some(0.75), latest.Proof.TopDropChance)
+	assert.Equal(t, chest.DropTableVersion, latest.Proof.DropTableVersion)
+	assert.Equal(t, item.ID, latest.ItemID)
+	// the version rolled with is archived even though the table was never replaced
+	require.Len(t, latest.DropTable, 1)
+	assert.Equal(t, item.ID, latest.DropTable[0].ItemID)
+	assert.Equal(t, "rare", latest.DropTable[0].Rarity)
+
+	page, err := cStore.GetChestOpenings(userID, options.Some(latest.ID), 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, 0, page[0].Proof.Nonce)
+}
+
+func TestRotateFairnessSeed(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	first := commitTestSeed(t, userID)
+	assert.False(t, first.ServerSeed.IsPresent())
+
+	// committing again keeps the active pair
+	again := commitTestSeed(t, userID)
+	assert.Equal(t, first.ID, again.ID)
+
+	rotation, err := cStore.RotateFairnessSeed(userID, SeedCommitment{
+		ServerSeed:     "next-server",
+		ServerSeedHash: "next-hash",
+		ClientSeed:     "next-client",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, rotation.Revealed)
+	assert.Equal(t, first.ID, rotation.Revealed.ID)
+	assert.Equal(t, options.Some(t.Name()+"-server"), rotation.Revealed.ServerSeed)
+	assert.True(t, rotation.Revealed.RevealedAt.IsPresent())
+	assert.Equal(t, "next-hash", rotation.Active.ServerSeedHash)
+	assert.False(t, rotation.Active.ServerSeed.IsPresent())
+	assert.Equal(t, 0, rotation.Active.NextNonce)
+}
+
+func TestReplaceDropTable(t *testing.T) {
+	t.Parallel()
+
+	chest, err := cStore.CreateChest("gold", t.Name(), 300)
+	require.NoError(t, err)
+	oldItem := createTestItem(t, t.Name()+" old", "common")
+	createTestDropRate(t, chest.ID, oldItem.ID, 1)
+	common := createTestItem(t, t.Name()+" common", "common")
+	rare := createTestItem(t, t.Name()+" rare", "rare")
+	adminID := createTestUser(t, 0)
+
+	dropTable, err := cStore.ReplaceDropTable(chest.ID, adminID, []*entities.ChestItemDropRate{
+		{ItemID: common.ID, DropRate: 0.9},
+		{ItemID: rare.ID, DropRate: 0.1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, dropTable.Version)
+	assert.Len(t, dropTable.Rates, 2)
+
+	rates, err := cStore.GetDropRatesByChestID(chest.ID)
+	require.NoError(t, err)
+	assert.Len(t, rates, 2)
+
+	history, err := cStore.GetDropTableHistory(chest.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 2, history[0].Version)
+	assert.Equal(t, adminID, history[0].CreatedBy)
+	assert.Len(t, history[0].Rates, 2)
+	assert.Equal(t, 1, history[1].Version)
+	require.Len(t, history[1].Rates, 1)
+	assert.Equal(t, oldItem.ID, history[1].Rates[0].ItemID)
+
+	// unknown items roll back the whole replacement
+	_, err = cStore.ReplaceDropTable(chest.ID, adminID, []*entities.ChestItemDropRate{
+		{ItemID: uuid.New(), DropRate: 1},
+	})
+	assert.ErrorIs(t, err, ErrUnknownChestItem)
+	updated, err := cStore.GetChestByID(chest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.DropTableVersion)
+}
+
+func TestGetUserItems(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	common := createTestItem(t, t.Name()+" common", "common")
+	epic := createTestItem(t, t.Name()+" epic", "epic")
+	createTestUserItem(t, userID, common.ID)
+	epicItem := createTestUserItem(t, userID, epic.ID)
+	_, err := cStore.EquipUserItem(userID, epicItem.ID)
+	require.NoError(t, err)
+
+	items, err := cStore.GetUserItems(userID, UserItemFilter{})
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+	for _, item := range items {
+		require.NotNil(t, item.Item)
+		assert.Equal(t, item.ItemID, item.Item.ID)
+	}
+
+	items, err = cStore.GetUserItems(userID, UserItemFilter{Rarity: options.Some("epic")})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, epicItem.ID, items[0].ID)
+
+	items, err = cStore.GetUserItems(userID, UserItemFilter{Status: options.Some("not_equipped")})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, common.ID, items[0].ItemID)
+}
+
+func TestEquipUserItemReplacesSlot(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	hat := createTestSlotItem(t, t.Name()+" hat", "common", "head")
+	crown := createTestSlotItem(t, t.Name()+" crown", "legendary", "head")
+	sword := createTestSlotItem(t, t.Name()+" sword", "rare", "weapon")
+	hatItem := createTestUserItem(t, userID, hat.ID)
+	crownItem := createTestUserItem(t, userID, crown.ID)
+	swordItem := createTestUserItem(t, userID, sword.ID)
+
+	for _, id := range []uuid.UUID{hatItem.ID, swordItem.ID, crownItem.ID} {
+		_, err := cStore.EquipUserItem(userID, id)
+		require.NoError(t, err)
+	}
+
+	equipped, err := cStore.GetUserItems(userID, UserItemFilter{Status: options.Some("equipped")})
+	require.NoError(t, err)
+	require.Len(t, equipped, 2)
+	equippedIDs := []uuid.UUID{equipped[0].ID, equipped[1].ID}
+	assert.ElementsMatch(t, []uuid.UUID{crownItem.ID, swordItem.ID}, equippedIDs)
+
+	unequipped, err := cStore.UnequipUserItem(userID, crownItem.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "not_equipped", unequipped.Status)
+
+	// items owned by someone else are not found
+	otherUserID := createTestUser(t, 0)
+	_, err = cStore.EquipUserItem(otherUserID, swordItem.ID)
+	assert.ErrorIs(t, err, ErrItemNotOwned)
+}
+
+func TestSellUserItem(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 5)
+	item, err := queries.CreateChestItem(context.Background(), sqlcdb.CreateChestItemParams{
+		Title:  t.Name(),
+		Rarity: sqlcdb.ItemTypeRare,
+		Slot:   sqlcdb.ItemSlotHead,
+		Price:  pgtype.Int4{Int32: 25, Valid: true},
+	})
+	require.NoError(t, err)
+	equipped := createTestUserItem(t, userID, uuid.UUID(item.ID.Bytes))
+	spare := createTestUserItem(t, userID, uuid.UUID(item.ID.Bytes))
+	_, err = cStore.EquipUserItem(userID, equipped.ID)
+	require.NoError(t, err)
+
+	_, err = cStore.SellUserItem(userID, equipped.ID)
+	assert.ErrorIs(t, err, ErrItemEquipped)
+
+	sale, err := cStore.SellUserItem(userID, spare.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 25, sale.CashEarned)
+	assert.Equal(t, 30, sale.CashAvailable)
+
+	_, err = cStore.SellUserItem(userID, spare.ID)
+	assert.ErrorIs(t, err, ErrItemNotOwned)
+
+	// the equipped copy is the one kept, so there are no duplicates left
+	sale, err = cStore.SellDuplicateUserItems(userID, "rare")
+	require.NoError(t, err)
+	assert.Empty(t, sale.Items)
+	assert.Equal(t, 30, sale.CashAvailable)
+}
+
+func TestGiftChests(t *testing.T) {
+	t.Parallel()
+
+	senderID := createTestUser(t, 300)
+	recipientEmail := t.Name() + "-recipient@mail.com"
+	recipientID := createTestUserWithEmail(t, recipientEmail, 0)
+	chest, err := cStore.CreateChest("bronze", t.Name(), 100)
+	require.NoError(t, err)
+	for range 3 {
+		_, err = cStore.PurchaseChest(senderID, chest.ID)
+		require.NoError(t, err)
+	}
+
+	recipient := GiftRecipient{Email: recipientEmail}
+	contents := GiftContents{ChestID: options.Some(chest.ID), Quantity: 4}
+	_, err = cStore.CreateGift(senderID, recipient, contents, time.Hour)
+	assert.ErrorIs(t, err, ErrNoChestsOwned)
+
+	_, err = cStore.CreateGift(
+		senderID,
+		GiftRecipient{ID: options.Some(senderID)},
+		GiftContents{ChestID: options.Some(chest.ID), Quantity: 1},
+		time.Hour,
+	)
+	assert.ErrorIs(t, err, ErrGiftToSelf)
+
+	contents.Quantity = 2
+	gift, err := cStore.CreateGift(senderID, recipient, contents, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", gift.Status)
+	assert.Equal(t, recipientID, gift.RecipientID)
+	assert.Equal(t, options.Some(2), gift.Quantity)
+
+	// the gifted chests leave the sender's inventory right away
+	owned, err := queries.GetUserChestByUserIdAndChestId(
+		context.Background(),
+		sqlcdb.GetUserChestByUserIdAndChestIdParams{
+			UserID:  db.UUIDToPgxUUID(senderID),
+			ChestID: db.UUIDToPgxUUID(chest.ID),
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), owned.QuantityOwned.Int32)
+
+	// only the recipient can resolve it
+	_, err = cStore.AcceptGift(senderID, gift.ID)
+	assert.ErrorIs(t, err, ErrGiftNotFound)
+
+	accepted, err := cStore.AcceptGift(recipientID, gift.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "accepted", accepted.Status)
+	assert.True(t, accepted.ResolvedAt.IsPresent())
+
+	received, err := queries.GetUserChestByUserIdAndChestId(
+		context.Background(),
+		sqlcdb.GetUserChestByUserIdAndChestIdParams{
+			UserID:  db.UUIDToPgxUUID(recipientID),
+			ChestID: db.UUIDToPgxUUID(chest.ID),
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), received.QuantityOwned.Int32)
+
+	_, err = cStore.DeclineGift(recipientID, gift.ID)
+	assert.ErrorIs(t, err, ErrGiftNotFound)
+}
+
+func TestGiftItem(t *testing.T) {
+	t.Parallel()
+
+	senderID := createTestUser(t, 0)
+	recipientID := createTestUserWithEmail(t, t.Name()+"-recipient@mail.com", 0)
+	item := createTestItem(t, t.Name(), "epic")
+	userItem := createTestUserItem(t, senderID, item.ID)
+	_, err := cStore.EquipUserItem(senderID, userItem.ID)
+	require.NoError(t, err)
+
+	contents := GiftContents{UserItemID: options.Some(userItem.ID)}
+	recipient := GiftRecipient{ID: options.Some(recipientID)}
+	_, err = cStore.CreateGift(senderID, recipient, contents, time.Hour)
+	assert.ErrorIs(t, err, ErrItemEquipped)
+
+	_, err = cStore.UnequipUserItem(senderID, userItem.ID)
+	require.NoError(t, err)
+	gift, err := cStore.CreateGift(senderID, recipient, contents, time.Hour)
+	require.NoError(t, err)
+
+	// the item is held by the gift, out of both inventories
+	items, err := cStore.GetUserItems(senderID, UserItemFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	_, err = cStore.CreateGift(senderID, recipient, contents, time.Hour)
+	assert.ErrorIs(t, err, ErrItemNotOwned)
+
+	declined, err := cStore.DeclineGift(recipientID, gift.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "declined", declined.Status)
+
+	items, err = cStore.GetUserItems(senderID, UserItemFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, userItem.ID, items[0].ID)
+
+	sent, err := cStore.GetGifts(senderID, GiftFilter{Sent: true})
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	assert.Equal(t, gift.ID, sent[0].ID)
+
+	pending, err := cStore.GetGifts(recipientID, GiftFilter{Status: options.Some("pending")})
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestExpireGifts(t *testing.T) {
+	t.Parallel()
+
+	senderID := createTestUser(t, 0)
+	recipientID := createTestUserWithEmail(t, t.Name()+"-recipient@mail.com", 0)
+	userItem := createTestUserItem(t, senderID, createTestItem(t, t.Name(), "rare").ID)
+
+	gift, err := cStore.CreateGift(
+		senderID,
+		GiftRecipient{ID: options.Some(recipientID)},
+		GiftContents{UserItemID: options.Some(userItem.ID)},
+		-time.Minute,
+	)
+	require.NoError(t, err)
+
+	// past its expiry the gift can no longer be accepted
+	_, err = cStore.AcceptGift(recipientID, gift.ID)
+	assert.ErrorIs(t, err, ErrGiftExpired)
+
+	expired, err := cStore.ExpireGifts()
+	require.NoError(t, err)
+	ids := make([]uuid.UUID, len(expired))
+	for i, g := range expired {
+		ids[i] = g.ID
+		if g.ID == gift.ID {
+			assert.Equal(t, "expired", g.Status)
+		}
+	}
+	assert.Contains(t, ids, gift.ID)
+
+	items, err := cStore.GetUserItems(senderID, UserItemFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, userItem.ID, items[0].ID)
+}
+
+func TestMarketplaceListing(t *testing.T) {
+	t.Parallel()
+
+	sellerID := createTestUser(t, 0)
+	buyerID := createTestUserWithEmail(t, t.Name()+"-buyer@mail.com", 150)
+	item := createTestItem(t, t.Name(), "legendary")
+	userItem := createTestUserItem(t, sellerID, item.ID)
+
+	listing, err := cStore.CreateListing(sellerID, userItem.ID, 120)
+	require.NoError(t, err)
+	assert.Equal(t, "active", listing.Status)
+	assert.Equal(t, item.ID, listing.Item.ID)
+
+	// the listed item is locked out of the seller's inventory
+	_, err = cStore.EquipUserItem(sellerID, userItem.ID)
+	assert.ErrorIs(t, err, ErrItemNotOwned)
+	_, err = cStore.SellUserItem(sellerID, userItem.ID)
+	assert.ErrorIs(t, err, ErrItemNotOwned)
+	_, err = cStore.CreateListing(sellerID, userItem.ID, 50)
+	assert.ErrorIs(t, err, ErrItemNotOwned)
+
+	listings, err := cStore.GetListings(
+		// titles match on any part, ignoring case
+		ListingFilter{Title: options.Some("marketplacelisting")},
+		options.None[int64](),
+		10,
+	)
+	require.NoError(t, err)
+	require.Len(t, listings, 1)
+	assert.Equal(t, listing.ID, listings[0].ID)
+
+	tenPercent := func(price int) int { return price / 10 }
+	_, err = cStore.BuyListing(sellerID, listing.ID, tenPercent)
+	assert.ErrorIs(t, err, ErrOwnListing)
+
+	sale, err := cStore.BuyListing(buyerID, listing.ID, tenPercent)
+	require.NoError(t, err)
+	assert.Equal(t, 30, sale.Purchase.CashAvailable)
+	assert.Equal(t, 108, sale.Proceeds)
+	assert.Equal(t, 108, sale.SellerCashAvailable)
+	assert.Equal(t, "sold", sale.Purchase.Listing.Status)
+	assert.Equal(t, options.Some(12), sale.Purchase.Listing.Fee)
+	assert.Equal(t, buyerID, sale.Purchase.UserItem.UserID)
+
+	_, err = cStore.BuyListing(buyerID, listing.ID, tenPercent)
+	assert.ErrorIs(t, err, ErrListingNotFound)
+
+	for _, userID := range []uuid.UUID{sellerID, buyerID} {
+		transactions, err := queries.GetCashTransactionsByUserId(
+			context.Background(),
+			sqlcdb.GetCashTransactionsByUserIdParams{
+				UserID:   db.UUIDToPgxUUID(userID),
+				PageSize: 1,
+			},
+		)
+		require.NoError(t, err)
+		require.Len(t, transactions, 1)
+		assert.Equal(t, userItem.ID, uuid.UUID(transactions[0].ReferenceID.Bytes))
+	}
+}
+
+func TestCancelListing(t *testing.T) {
+	t.Parallel()
+
+	sellerID := createTestUser(t, 0)
+	otherID := createTestUserWithEmail(t, t.Name()+"-other@mail.com", 0)
+	userItem := createTestUserItem(t, sellerID, createTestItem(t, t.Name(), "common").ID)
+
+	listing, err := cStore.CreateListing(sellerID, userItem.ID, 10)
+	require.NoError(t, err)
+
+	_, err = cStore.CancelListing(otherID, listing.ID)
+	assert.ErrorIs(t, err, ErrListingNotFound)
+
+	cancelled, err := cStore.CancelListing(sellerID, listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", cancelled.Status)
+	assert.True(t, cancelled.ClosedAt.IsPresent())
+
+	items, err := cStore.GetUserItems(sellerID, UserItemFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, userItem.ID, items[0].ID)
+
+	mine, err := cStore.GetUserListings(
+		sellerID,
+		options.Some("cancelled"),
+		options.None[int64](),
+		10,
+	)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, listing.ID, mine[0].ID)
+}
+
+func findCollectionEntry(
+	t *testing.T,
+	collection *entities.Collection,
+	itemID uuid.UUID,
+) *entities.CollectionEntry {
+	t.Helper()
+	for _, entry := range collection.Items {
+		if entry.Item.ID == itemID {
+			return entry
+		}
+	}
+	require.FailNow(t, "item missing from collection", itemID)
+	return nil
+}
+
+func TestCollectionDiscoveryOutlivesOwnership(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	item, err := queries.CreateChestItem(context.Background(), sqlcdb.CreateChestItemParams{
+		Title:  t.Name(),
+		Rarity: sqlcdb.ItemTypeRare,
+		Slot:   sqlcdb.ItemSlotHead,
+		Price:  pgtype.Int4{Int32: 10, Valid: true},
+	})
+	require.NoError(t, err)
+	itemID := uuid.UUID(item.ID.Bytes)
+	missing := createTestItem(t, t.Name()+"-missing", "common")
+
+	first := createTestUserItem(t, userID, itemID)
+	createTestUserItem(t, userID, itemID)
+
+	collection, err := cStore.GetCollection(userID)
+	require.NoError(t, err)
+	entry := findCollectionEntry(t, collection, itemID)
+	assert.True(t, entry.Discovered)
+	assert.True(t, entry.FirstObtainedAt.IsPresent())
+	assert.Equal(t, 2, entry.CopiesOwned)
+	assert.False(t, findCollectionEntry(t, collection, missing.ID).Discovered)
+
+	_, err = cStore.SellUserItem(userID, first.ID)
+	require.NoError(t, err)
+	_, err = cStore.SellDuplicateUserItems(userID, "rare")
+	require.NoError(t, err)
+
+	collection, err = cStore.GetCollection(userID)
+	require.NoError(t, err)
+	entry = findCollectionEntry(t, collection, itemID)
+	assert.True(t, entry.Discovered)
+	assert.Equal(t, 1, entry.CopiesOwned)
+}
+
+func TestClaimCollectionBonus(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 5)
+	chest, err := cStore.CreateChest("bronze", t.Name(), 100)
+	require.NoError(t, err)
+	common := createTestItem(t, t.Name()+"-common", "common")
+	rare := createTestItem(t, t.Name()+"-rare", "rare")
+	// consumables drop but aren't part of the collection
+	potion := createTestConsumable(t, sqlcdb.ItemEffectXpMultiplier, 2, 60)
+	createTestDropRate(t, chest.ID, common.ID, 0.8)
+	createTestDropRate(t, chest.ID, rare.ID, 0.1)
+	createTestDropRate(t, chest.ID, potion.ID, 0.1)
+
+	createTestUserItem(t, userID, common.ID)
+	_, err = cStore.ClaimCollectionBonus(userID, chest.ID, 50)
+	assert.ErrorIs(t, err, ErrCollectionIncomplete)
+
+	_, err = cStore.ClaimCollectionBonus(userID, uuid.New(), 50)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	createTestUserItem(t, userID, rare.ID)
+	bonus, err := cStore.ClaimCollectionBonus(userID, chest.ID, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 50, bonus.CashGranted)
+	assert.Equal(t, 55, bonus.CashAvailable)
+
+	_, err = cStore.ClaimCollectionBonus(userID, chest.ID, 50)
+	assert.ErrorIs(t, err, ErrBonusClaimed)
+
+	collection, err := cStore.GetCollection(userID)
+	require.NoError(t, err)
+	var progress *entities.ChestCollection
+	for _, c := range collection.Chests {
+		if c.ChestID == chest.ID {
+			progress = c
+		}
+	}
+	require.NotNil(t, progress)
+	assert.Equal(t, 2, progress.Progress.Discovered)
+	assert.Equal(t, 2, progress.Progress.Total)
+	assert.True(t, progress.Complete)
+	assert.True(t, progress.BonusClaimed)
+	assert.False(t, slices.ContainsFunc(collection.Items, func(e *entities.CollectionEntry) bool {
+		return e.Item.ID == potion.ID
+	}))
+}
+
+func createTestRecipe(
+	t *testing.T,
+	chestID uuid.UUID,
+	inputRarity, outputRarity string,
+	inputCount int32,
+) *entities.CraftingRecipe {
+	t.Helper()
+	recipe, err := queries.UpsertCraftingRecipeBySlug(
+		context.Background(),
+		sqlcdb.UpsertCraftingRecipeBySlugParams{
+			Slug:         t.Name(),
+			ChestID:      db.UUIDToPgxUUID(chestID),
+			InputRarity:  sqlcdb.ItemType(inputRarity),
+			InputCount:   inputCount,
+			OutputRarity: sqlcdb.ItemType(outputRarity),
+		},
+	)
+	require.NoError(t, err)
+	return pgxCraftingRecipeToEntity(recipe)
+}
+
+func TestCraft(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	chest, err := cStore.CreateChest("bronze", t.Name(), 100)
+	require.NoError(t, err)
+	common := createTestItem(t, t.Name()+"-common", "common")
+	rare := createTestItem(t, t.Name()+"-rare", "rare")
+	// a rare consumable in the drop table is never a craft's output
+	potion := createTestConsumable(t, sqlcdb.ItemEffectXpMultiplier, 2, 60)
+	createTestDropRate(t, chest.ID, common.ID, 0.7)
+	createTestDropRate(t, chest.ID, rare.ID, 0.2)
+	createTestDropRate(t, chest.ID, potion.ID, 0.1)
+	recipe := createTestRecipe(t, chest.ID, "common", "rare", 2)
+	seed := commitTestSeed(t, userID)
+
+	first := createTestUserItem(t, userID, common.ID)
+	second := createTestUserItem(t, userID, common.ID)
+	spareRare := createTestUserItem(t, userID, rare.ID)
+
+	var candidates []*entities.ChestItemDropRate
+	pick := func(rates []*entities.ChestItemDropRate, input FairnessInput) (*Draw, error) {
+		candidates = rates
+		assert.Equal(t, 0, input.Nonce)
+		return &Draw{ItemID: rates[0].ItemID, Roll: 0.5}, nil
+	}
+
+	_, err = cStore.Craft(userID, uuid.New(), []uuid.UUID{first.ID, second.ID}, pick)
+	assert.ErrorIs(t, err, ErrRecipeNotFound)
+	_, err = cStore.Craft(userID, recipe.ID, []uuid.UUID{first.ID}, pick)
+	assert.ErrorIs(t, err, ErrCraftInputs)
+	_, err = cStore.Craft(userID, recipe.ID, []uuid.UUID{first.ID, spareRare.ID}, pick)
+	assert.ErrorIs(t, err, ErrCraftInputs)
+	_, err = cStore.Craft(userID, recipe.ID, []uuid.UUID{first.ID, uuid.New()}, pick)
+	assert.ErrorIs(t, err, ErrItemNotOwned)
+
+	_, err = cStore.EquipUserItem(userID, second.ID)
+	require.NoError(t, err)
+	_, err = cStore.Craft(userID, recipe.ID, []uuid.UUID{first.ID, second.ID}, pick)
+	assert.ErrorIs(t, err, ErrItemEquipped)
+	_, err = cStore.UnequipUserItem(userID, second.ID)
+	require.NoError(t, err)
+
+	craft, err := cStore.Craft(userID, recipe.ID, []uuid.UUID{first.ID, second.ID}, pick)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, rare.ID, candidates[0].ItemID)
+	assert.Equal(t, rare.ID, craft.Item.ID)
+	assert.Equal(t, seed.ServerSeedHash, craft.Proof.ServerSeedHash)
+	assert.Equal(t, 0.5, craft.Proof.Roll)
+
+	items, err := cStore.GetUserItems(userID, UserItemFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	for _, item := range items {
+		assert.Equal(t, rare.ID, item.ItemID)
+	}
+}
+
+func TestCraftRefusesListedItems(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	chest, err := cStore.CreateChest("bronze", t.Name(), 100)
+	require.NoError(t, err)
+	common := createTestItem(t, t.Name()+"-common", "common")
+	rare := createTestItem(t, t.Name()+"-rare", "rare")
+	createTestDropRate(t, chest.ID, rare.ID, 1)
+	recipe := createTestRecipe(t, chest.ID, "common", "rare", 2)
+	commitTestSeed(t, userID)
+
+	kept := createTestUserItem(t, userID, common.ID)
+	listed := createTestUserItem(t, userID, common.ID)
+	_, err = cStore.CreateListing(userID, listed.ID, 10)
+	require.NoError(t, err)
+
+	pick := func(rates []*entities.ChestItemDropRate, _ FairnessInput) (*Draw, error) {
+		return &Draw{ItemID: rates[0].ItemID}, nil
+	}
+	_, err = cStore.Craft(userID, recipe.ID, []uuid.UUID{kept.ID, listed.ID}, pick)
+	assert.ErrorIs(t, err, ErrItemNotOwned)
+
+	items, err := cStore.GetUserItems(userID, UserItemFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, kept.ID, items[0].ID)
+}
+
+func createTestConsumable(
+	t *testing.T,
+	effect sqlcdb.ItemEffect,
+	value, durationSeconds int32,
+) *entities.ChestItem {
+	t.Helper()
+	params := sqlcdb.UpsertChestItemBySlugParams{
+		Slug:                  pgtype.Text{String: t.Name() + string(effect), Valid: true},
+		Title:                 t.Name(),
+		Rarity:                sqlcdb.ItemTypeRare,
+		Slot:                  sqlcdb.ItemSlotAccessory,
+		Effect:                sqlcdb.NullItemEffect{ItemEffect: effect, Valid: true},
+		EffectValue:           pgtype.Int4{Int32: value, Valid: true},
+		EffectDurationSeconds: pgtype.Int4{Int32: durationSeconds, Valid: true},
+	}
+	item, err := queries.UpsertChestItemBySlug(context.Background(), params)
+	require.NoError(t, err)
+	return pgxChestItemToEntity(item)
+}
+
+func TestConsumeUserItem(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	potion := createTestConsumable(t, sqlcdb.ItemEffectXpMultiplier, 2, 3600)
+	require.NotNil(t, potion.Effect)
+	assert.Equal(t, "xp_multiplier", potion.Effect.Type)
+	hat := createTestItem(t, t.Name()+"-hat", "common")
+
+	userPotion := createTestUserItem(t, userID, potion.ID)
+	userHat := createTestUserItem(t, userID, hat.ID)
+
+	_, err := cStore.EquipUserItem(userID, userPotion.ID)
+	assert.ErrorIs(t, err, ErrItemConsumable)
+	_, err = cStore.ConsumeUserItem(userID, userHat.ID)
+	assert.ErrorIs(t, err, ErrItemNotConsumable)
+
+	consumption, err := cStore.ConsumeUserItem(userID, userPotion.ID)
+	require.NoError(t, err)
+	assert.Equal(t, userPotion.ID, consumption.UserItem.ID)
+	assert.Equal(t, "xp_multiplier", consumption.Effect.Effect)
+	assert.Equal(t, 2, consumption.Effect.Value)
+	assert.WithinDuration(
+		t,
+		consumption.Effect.CreatedAt.Add(time.Hour),
+		consumption.Effect.ExpiresAt,
+		time.Second,
+	)
+
+	_, err = cStore.ConsumeUserItem(userID, userPotion.ID)
+	assert.ErrorIs(t, err, ErrItemNotOwned)
+
+	effects, err := cStore.GetActiveEffects(userID)
+	require.NoError(t, err)
+	require.Len(t, effects, 1)
+	assert.Equal(t, consumption.Effect.ID, effects[0].ID)
+
+	multiplier, err := queries.GetXpMultiplierByUserId(
+		context.Background(),
+		db.UUIDToPgxUUID(userID),
+	)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, multiplier)
+}
+
+func TestDeleteExpiredEffects(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	freeze := createTestConsumable(t, sqlcdb.ItemEffectStreakFreeze, 1, 3600)
+	_, err := queries.CreateActiveEffect(context.Background(), sqlcdb.CreateActiveEffectParams{
+		UserID:          db.UUIDToPgxUUID(userID),
+		Effect:          sqlcdb.ItemEffectXpMultiplier,
+		Value:           3,
+		DurationSeconds: 0,
+	})
+	require.NoError(t, err)
+	_, err = cStore.ConsumeUserItem(userID, createTestUserItem(t, userID, freeze.ID).ID)
+	require.NoError(t, err)
+
+	// expired effects are ignored before they are deleted
+	multiplier, err := queries.GetXpMultiplierByUserId(
+		context.Background(),
+		db.UUIDToPgxUUID(userID),
+	)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, multiplier)
+
+	deleted, err := cStore.DeleteExpiredEffects()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, 1)
+
+	effects, err := cStore.GetActiveEffects(userID)
+	require.NoError(t, err)
+	require.Len(t, effects, 1)
+	assert.Equal(t, "streak_freeze", effects[0].Effect)
+}
@@ -89,6 +89,41 @@ func QueryTokenAuth(userService service.UserService) func(http.Handler) http.Han
 	}
 }
 
+// AdminOnly rejects requests from users without the admin flag. It must run after an
+// authentication middleware has set the user_id header.
+func AdminOnly(userService service.UserService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				id, err := GetIDFromHeader(r)
+				if err != nil {
+					responses.SendAPIError(
+						w,
+						r,
+						http.StatusUnauthorized,
+						"user is not authenticated",
+						nil,
+					)
+					return
+				}
+
+				isAdmin, err := userService.IsAdmin(id)
+				if err != nil {
+					responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+					return
+				}
+				if !isAdmin {
+					forbiddenErr := fmt.Errorf("%w: admin access required", responses.ErrForbidden)
+					responses.SendAPIError(w, r, http.StatusForbidden, forbiddenErr.Error(), nil)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
 func GetIDFromHeader(r *http.Request) (string, error) {
 	id := r.Header.Get("user_id")
 	if id == "" {
@@ -16,6 +16,7 @@ type (
 		CorsChain           Middleware
 		AuthChain           Middleware
 		QueryTokenAuthChain Middleware
+		AdminChain          Middleware
 	}
 )
 
@@ -47,5 +48,11 @@ func SetupMiddleware(userService service.UserService) MiddleWareChains {
 		CorsChain:           CreateChain(Logging, c.Handler),
 		AuthChain:           CreateChain(Logging, c.Handler, AuthenticatedOnly(userService)),
 		QueryTokenAuthChain: CreateChain(Logging, c.Handler, QueryTokenAuth(userService)),
+		AdminChain: CreateChain(
+			Logging,
+			c.Handler,
+			AuthenticatedOnly(userService),
+			AdminOnly(userService),
+		),
 	}
 }
@@ -18,6 +18,7 @@ var (
 	ErrUnauthorized   = errors.New("unauthorized")
 	ErrInternalServer = errors.New("internal server error")
 	ErrNotFound       = errors.New("not found")
+	ErrForbidden      = errors.New("forbidden")
 )
 
 func GetErrorCode(err error) int {
@@ -30,6 +31,9 @@ func GetErrorCode(err error) int {
 	if errors.Is(err, ErrUnauthorized) {
 		return http.StatusUnauthorized
 	}
+	if errors.Is(err, ErrForbidden) {
+		return http.StatusForbidden
+	}
 	return http.StatusInternalServerError
 }
 
@@ -5,6 +5,7 @@ import (
 	"goalify/pkg/jsonutil"
 	"log/slog"
 	"net/http"
+	"strings"
 )
 
 type (
@@ -20,10 +21,30 @@ type (
 )
 
 const (
-	ObjectGoalCategory Object = "goal_category"
-	ObjectUser         Object = "user"
-	ObjectGoal         Object = "goal"
-	ObjectList         Object = "list"
+	ObjectGoalCategory    Object = "goal_category"
+	ObjectUser            Object = "user"
+	ObjectGoal            Object = "goal"
+	ObjectList            Object = "list"
+	ObjectChest           Object = "chest"
+	ObjectChestPurchase   Object = "chest_purchase"
+	ObjectChestOpening    Object = "chest_opening"
+	ObjectDropTable       Object = "drop_table"
+	ObjectUserItem        Object = "user_item"
+	ObjectItemSale        Object = "item_sale"
+	ObjectFairnessSeed    Object = "fairness_seed"
+	ObjectSeedRotation    Object = "seed_rotation"
+	ObjectGift            Object = "gift"
+	ObjectListing         Object = "listing"
+	ObjectListingPurchase Object = "listing_purchase"
+	ObjectCollection      Object = "collection"
+	ObjectCollectionBonus Object = "collection_bonus"
+	ObjectCraft           Object = "craft"
+	ObjectItemImage       Object = "item_image"
+	ObjectReward          Object = "reward"
+	ObjectRewardPurchase  Object = "reward_purchase"
+	ObjectItemConsumption Object = "item_consumption"
+	ObjectDailyClaim      Object = "daily_claim"
+	ObjectDailyReward     Object = "daily_reward"
 )
 
 func SendResponse[T any | map[string]any](
@@ -37,3 +58,15 @@ func SendResponse[T any | map[string]any](
 		SendAPIError(w, r, http.StatusInternalServerError, ErrInternalServer.Error(), nil)
 	}
 }
+
+// ETagMatches reports whether an If-None-Match header lists etag, comparing weakly as the header
+// requires
+func ETagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// HandleClaimDailyReward grants the user's chest for today, once per day in their timezone
+func (h *RewardHandler) HandleClaimDailyReward(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleClaimDailyReward")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	claim, err := h.rewardService.ClaimDailyReward(parsedUserID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.DailyClaim]{
+		Object: responses.ObjectDailyClaim,
+		Data:   claim,
+	}
+	responses.SendResponse(w, r, http.StatusCreated, res)
+}
+
+func (h *RewardHandler) HandleGetDailyRewardStatus(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetDailyRewardStatus")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	status, err := h.rewardService.GetDailyRewardStatus(parsedUserID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.DailyRewardStatus]{
+		Object: responses.ObjectDailyReward,
+		Data:   status,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,317 @@
+// Package handler is the API request/response handling for user defined rewards
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/middleware"
+	"goalify/internal/responses"
+	"goalify/internal/rewards/stores"
+	"goalify/pkg/jsonutil"
+	"goalify/pkg/options"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+func (h *RewardHandler) HandleCreateReward(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleCreateReward")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	body, problems, err := jsonutil.DecodeValid[CreateRewardRequest](r)
+	if err != nil {
+		responses.HandleDecodeError(w, r, problems, err)
+		return
+	}
+
+	reward, err := h.rewardService.CreateReward(parsedUserID, stores.CreateRewardParams{
+		Title:           body.Title,
+		Description:     body.Description,
+		Cost:            body.Cost,
+		CooldownSeconds: body.CooldownSeconds,
+		Repeatable:      body.Repeatable,
+	})
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Reward]{
+		Object: responses.ObjectReward,
+		Data:   reward,
+	}
+	responses.SendResponse(w, r, http.StatusCreated, res)
+}
+
+func (h *RewardHandler) HandleGetRewards(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetRewards")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	rewards, err := h.rewardService.GetRewardsByUserID(parsedUserID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.Reward]{
+		Object: responses.ObjectList,
+		Data:   rewards,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *RewardHandler) HandleGetRewardByID(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetRewardByID")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	rewardID, err := uuid.Parse(r.PathValue("rewardId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid reward id", nil)
+		return
+	}
+
+	reward, err := h.rewardService.GetRewardByID(rewardID, parsedUserID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Reward]{
+		Object: responses.ObjectReward,
+		Data:   reward,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *RewardHandler) HandleUpdateRewardByID(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleUpdateRewardByID")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	rewardID, err := uuid.Parse(r.PathValue("rewardId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid reward id", nil)
+		return
+	}
+
+	body, problems, err := jsonutil.DecodeValid[UpdateRewardRequest](r)
+	if err != nil {
+		responses.HandleDecodeError(w, r, problems, err)
+		return
+	}
+	if body.empty() {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "no updates provided", nil)
+		return
+	}
+
+	params := stores.UpdateRewardParams{
+		Title:           body.Title,
+		Description:     body.Description,
+		Cost:            body.Cost,
+		CooldownSeconds: body.CooldownSeconds,
+		Repeatable:      body.Repeatable,
+	}
+	reward, err := h.rewardService.UpdateRewardByID(rewardID, parsedUserID, params)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.Reward]{
+		Object: responses.ObjectReward,
+		Data:   reward,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *RewardHandler) HandleDeleteRewardByID(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleDeleteRewardByID")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	rewardID, err := uuid.Parse(r.PathValue("rewardId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid reward id", nil)
+		return
+	}
+
+	if err := h.rewardService.DeleteRewardByID(rewardID, parsedUserID); err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := map[string]any{"id": rewardID, "deleted": true}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *RewardHandler) HandleRedeemReward(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleRedeemReward")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	rewardID, err := uuid.Parse(r.PathValue("rewardId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "bad request: invalid reward id", nil)
+		return
+	}
+
+	purchase, err := h.rewardService.RedeemReward(rewardID, parsedUserID)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[*entities.RewardPurchase]{
+		Object: responses.ObjectRewardPurchase,
+		Data:   purchase,
+	}
+	responses.SendResponse(w, r, http.StatusCreated, res)
+}
+
+// HandleGetRedemptions lists the user's redemption history, newest first. The reward_id query
+// parameter narrows it to one reward.
+func (h *RewardHandler) HandleGetRedemptions(w http.ResponseWriter, r *http.Request) {
+	funcStr := h.traceLogger.GetTrace("handler.HandleGetRedemptions")
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: middleware.GetIDFromHeader:", funcStr), "err", err)
+		responses.SendAPIError(w, r, http.StatusUnauthorized, "user is not authenticated", nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: uuid.Parse:", funcStr), "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	rewardID := options.None[uuid.UUID]()
+	if query.Has("reward_id") {
+		id, err := uuid.Parse(query.Get("reward_id"))
+		if err != nil {
+			responses.SendAPIError(w, r, http.StatusBadRequest, "reward_id must be a uuid", nil)
+			return
+		}
+		rewardID = options.Some(id)
+	}
+
+	cursor := options.None[int64]()
+	if query.Has("cursor") {
+		id, err := strconv.ParseInt(query.Get("cursor"), 10, 64)
+		if err != nil {
+			responses.SendAPIError(w, r, http.StatusBadRequest, "cursor must be an integer", nil)
+			return
+		}
+		cursor = options.Some(id)
+	}
+
+	limit := DefaultRedemptionsLimit
+	if query.Has("limit") {
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil || limit < 1 || limit > MaxRedemptionsLimit {
+			responses.SendAPIError(
+				w,
+				r,
+				http.StatusBadRequest,
+				fmt.Sprintf("limit must be an integer from 1 to %d", MaxRedemptionsLimit),
+				nil,
+			)
+			return
+		}
+	}
+
+	redemptions, hasMore, err := h.rewardService.GetRedemptions(
+		parsedUserID,
+		rewardID,
+		cursor,
+		limit,
+	)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.RewardRedemption]{
+		Object:  responses.ObjectList,
+		Data:    redemptions,
+		HasMore: &hasMore,
+	}
+	if hasMore {
+		nextPage := strconv.FormatInt(redemptions[len(redemptions)-1].ID, 10)
+		res.NextPage = &nextPage
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"fmt"
+	"goalify/internal/rewards/service"
+	"goalify/pkg/options"
+	"goalify/pkg/stacktrace"
+)
+
+type (
+	RewardHandler struct {
+		rewardService service.RewardService
+		traceLogger   stacktrace.TraceLogger
+	}
+	CreateRewardRequest struct {
+		Title           string `json:"title"`
+		Description     string `json:"description"`
+		Cost            int    `json:"cost"`
+		CooldownSeconds int    `json:"cooldown_seconds"`
+		Repeatable      bool   `json:"repeatable"`
+	}
+	UpdateRewardRequest struct {
+		Title           options.Option[string] `json:"title"`
+		Description     options.Option[string] `json:"description"`
+		Cost            options.Option[int]    `json:"cost"`
+		CooldownSeconds options.Option[int]    `json:"cooldown_seconds"`
+		Repeatable      options.Option[bool]   `json:"repeatable"`
+	}
+)
+
+const (
+	DefaultRedemptionsLimit = 20
+	MaxRedemptionsLimit     = 100
+	TextMaxLen              = 255
+	DescriptionMaxLen       = 1000
+	MaxRewardCost           = 1_000_000
+	// MaxCooldownSeconds is one year
+	MaxCooldownSeconds = 365 * 24 * 60 * 60
+)
+
+func NewRewardHandler(
+	rewardService service.RewardService,
+	traceLogger stacktrace.TraceLogger,
+) *RewardHandler {
+	return &RewardHandler{rewardService, traceLogger}
+}
+
+func validTitle(problems map[string]string, title string) {
+	if title == "" {
+		problems["title"] = "title is required"
+	} else if len(title) > TextMaxLen {
+		problems["title"] = "title must be less than 255 characters"
+	}
+}
+
+func validDescription(problems map[string]string, description string) {
+	if len(description) > DescriptionMaxLen {
+		problems["description"] = fmt.Sprintf(
+			"description must be at most %d characters",
+			DescriptionMaxLen,
+		)
+	}
+}
+
+func validCost(problems map[string]string, cost int) {
+	if cost < 1 || cost > MaxRewardCost {
+		problems["cost"] = fmt.Sprintf("cost must be from 1 to %d", MaxRewardCost)
+	}
+}
+
+func validCooldown(problems map[string]string, cooldownSeconds int) {
+	if cooldownSeconds < 0 || cooldownSeconds > MaxCooldownSeconds {
+		problems["cooldown_seconds"] = fmt.Sprintf(
+			"cooldown_seconds must be from 0 to %d",
+			MaxCooldownSeconds,
+		)
+	}
+}
+
+func (r CreateRewardRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	validTitle(problems, r.Title)
+	validDescription(problems, r.Description)
+	validCost(problems, r.Cost)
+	validCooldown(problems, r.CooldownSeconds)
+	if !r.Repeatable && r.CooldownSeconds != 0 {
+		problems["cooldown_seconds"] = "only repeatable rewards can have a cooldown"
+	}
+
+	return problems
+}
+
+func (r UpdateRewardRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+
+	if title, ok := r.Title.GetVal(); ok {
+		validTitle(problems, title)
+	}
+	if description, ok := r.Description.GetVal(); ok {
+		validDescription(problems, description)
+	}
+	if cost, ok := r.Cost.GetVal(); ok {
+		validCost(problems, cost)
+	}
+	if cooldown, ok := r.CooldownSeconds.GetVal(); ok {
+		validCooldown(problems, cooldown)
+		if repeatable, ok := r.Repeatable.GetVal(); ok && !repeatable && cooldown != 0 {
+			problems["cooldown_seconds"] = "only repeatable rewards can have a cooldown"
+		}
+	}
+
+	return problems
+}
+
+func (r UpdateRewardRequest) empty() bool {
+	return !r.Title.IsPresent() &&
+		!r.Description.IsPresent() &&
+		!r.Cost.IsPresent() &&
+		!r.CooldownSeconds.IsPresent() &&
+		!r.Repeatable.IsPresent()
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/events"
+	"goalify/internal/responses"
+	"goalify/internal/rewards/stores"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// DailyStreakCycle is the number of claim days from a bronze chest to a gold one. The tiers start
+// over after each gold chest as long as the streak holds.
+const DailyStreakCycle = 7
+
+// dailyChestType is the chest type a daily claim grants on the given streak day: bronze for the
+// first three days of a cycle, silver for the next three and gold on the last
+func dailyChestType(streak int) string {
+	day := (streak-1)%DailyStreakCycle + 1
+	switch {
+	case day == DailyStreakCycle:
+		return "gold"
+	case day > DailyStreakCycle/2:
+		return "silver"
+	default:
+		return "bronze"
+	}
+}
+
+// dailyChestSlug is the seeded chest for the streak day's chest type
+func dailyChestSlug(streak int) string {
+	return dailyChestType(streak) + "-chest"
+}
+
+// ClaimDailyReward grants the user today's chest and tells their clients about it
+func (rs *rewardService) ClaimDailyReward(userID uuid.UUID) (*entities.DailyClaim, error) {
+	funcStr := rs.traceLogger.GetTrace("service.ClaimDailyReward")
+
+	claim, err := rs.rewardStore.ClaimDailyReward(userID, dailyChestSlug)
+	if errors.Is(err, stores.ErrDailyRewardClaimed) {
+		return nil, fmt.Errorf("%w: daily reward already claimed today", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.ClaimDailyReward:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error claiming daily reward", responses.ErrInternalServer)
+	}
+
+	rs.eventPublisher.Publish(
+		events.NewEventWithUserID(events.DailyRewardClaimed, claim, userID.String()),
+	)
+	return claim, nil
+}
+
+func (rs *rewardService) GetDailyRewardStatus(
+	userID uuid.UUID,
+) (*entities.DailyRewardStatus, error) {
+	funcStr := rs.traceLogger.GetTrace("service.GetDailyRewardStatus")
+
+	status, err := rs.rewardStore.GetDailyRewardStatus(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetDailyRewardStatus:", funcStr), "err", err)
+		return nil, fmt.Errorf(
+			"%w: error fetching daily reward status",
+			responses.ErrInternalServer,
+		)
+	}
+
+	// the next claim is today's, or tomorrow's once today is claimed, and extends the live streak
+	status.NextChestType = dailyChestType(status.Streak + 1)
+	return status, nil
+}
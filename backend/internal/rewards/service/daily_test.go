@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyChestType(t *testing.T) {
+	tests := []struct {
+		streak   int
+		expected string
+	}{
+		{1, "bronze"},
+		{3, "bronze"},
+		{4, "silver"},
+		{6, "silver"},
+		{7, "gold"},
+		// the tiers start over after a gold chest
+		{8, "bronze"},
+		{11, "silver"},
+		{14, "gold"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, dailyChestType(tt.streak), "streak %d", tt.streak)
+	}
+	assert.Equal(t, "gold-chest", dailyChestSlug(7))
+}
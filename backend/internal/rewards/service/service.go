@@ -0,0 +1,184 @@
+// Package service is the business logic layer for user defined rewards
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"goalify/internal/db"
+	"goalify/internal/entities"
+	"goalify/internal/events"
+	"goalify/internal/responses"
+	"goalify/internal/rewards/stores"
+	"goalify/pkg/options"
+	"goalify/pkg/stacktrace"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+type RewardService interface {
+	CreateReward(userID uuid.UUID, params stores.CreateRewardParams) (*entities.Reward, error)
+	GetRewardsByUserID(userID uuid.UUID) ([]*entities.Reward, error)
+	GetRewardByID(rewardID, userID uuid.UUID) (*entities.Reward, error)
+	UpdateRewardByID(
+		rewardID, userID uuid.UUID,
+		params stores.UpdateRewardParams,
+	) (*entities.Reward, error)
+	DeleteRewardByID(rewardID, userID uuid.UUID) error
+
+	RedeemReward(rewardID, userID uuid.UUID) (*entities.RewardPurchase, error)
+	GetRedemptions(
+		userID uuid.UUID,
+		rewardID options.Option[uuid.UUID],
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.RewardRedemption, bool, error)
+
+	ClaimDailyReward(userID uuid.UUID) (*entities.DailyClaim, error)
+	GetDailyRewardStatus(userID uuid.UUID) (*entities.DailyRewardStatus, error)
+}
+
+type rewardService struct {
+	rewardStore    stores.RewardStore
+	traceLogger    stacktrace.TraceLogger
+	eventPublisher events.EventPublisher
+}
+
+func NewRewardService(
+	rewardStore stores.RewardStore,
+	traceLogger stacktrace.TraceLogger,
+	ep events.EventPublisher,
+) RewardService {
+	return &rewardService{
+		rewardStore:    rewardStore,
+		traceLogger:    traceLogger,
+		eventPublisher: ep,
+	}
+}
+
+func (rs *rewardService) CreateReward(
+	userID uuid.UUID,
+	params stores.CreateRewardParams,
+) (*entities.Reward, error) {
+	funcStr := rs.traceLogger.GetTrace("service.CreateReward")
+
+	reward, err := rs.rewardStore.CreateReward(userID, params)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.CreateReward:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error creating reward", responses.ErrInternalServer)
+	}
+	return reward, nil
+}
+
+func (rs *rewardService) GetRewardsByUserID(userID uuid.UUID) ([]*entities.Reward, error) {
+	funcStr := rs.traceLogger.GetTrace("service.GetRewardsByUserID")
+
+	rewards, err := rs.rewardStore.GetRewardsByUserID(userID)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetRewardsByUserID:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching rewards", responses.ErrInternalServer)
+	}
+	return rewards, nil
+}
+
+func (rs *rewardService) GetRewardByID(rewardID, userID uuid.UUID) (*entities.Reward, error) {
+	funcStr := rs.traceLogger.GetTrace("service.GetRewardByID")
+
+	reward, err := rs.rewardStore.GetRewardByID(rewardID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: reward not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetRewardByID:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error fetching reward", responses.ErrInternalServer)
+	}
+	return reward, nil
+}
+
+func (rs *rewardService) UpdateRewardByID(
+	rewardID, userID uuid.UUID,
+	params stores.UpdateRewardParams,
+) (*entities.Reward, error) {
+	funcStr := rs.traceLogger.GetTrace("service.UpdateRewardByID")
+
+	reward, err := rs.rewardStore.UpdateRewardByID(rewardID, userID, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: reward not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.UpdateRewardByID:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error updating reward", responses.ErrInternalServer)
+	}
+	return reward, nil
+}
+
+func (rs *rewardService) DeleteRewardByID(rewardID, userID uuid.UUID) error {
+	funcStr := rs.traceLogger.GetTrace("service.DeleteRewardByID")
+
+	err := rs.rewardStore.DeleteRewardByID(rewardID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: reward not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.DeleteRewardByID:", funcStr), "err", err)
+		return fmt.Errorf("%w: error deleting reward", responses.ErrInternalServer)
+	}
+	return nil
+}
+
+// RedeemReward buys the reward with the user's cash and tells their clients the balance changed
+func (rs *rewardService) RedeemReward(
+	rewardID, userID uuid.UUID,
+) (*entities.RewardPurchase, error) {
+	funcStr := rs.traceLogger.GetTrace("service.RedeemReward")
+
+	purchase, err := rs.rewardStore.RedeemReward(rewardID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: reward not found", responses.ErrNotFound)
+	}
+	if errors.Is(err, stores.ErrRewardRedeemed) {
+		return nil, fmt.Errorf("%w: reward can only be redeemed once", responses.ErrBadRequest)
+	}
+	if errors.Is(err, stores.ErrRewardCoolingDown) {
+		return nil, fmt.Errorf("%w: reward is cooling down", responses.ErrBadRequest)
+	}
+	if errors.Is(err, db.ErrInsufficientCash) {
+		return nil, fmt.Errorf("%w: not enough cash to redeem reward", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.RedeemReward:", funcStr), "err", err)
+		return nil, fmt.Errorf("%w: error redeeming reward", responses.ErrInternalServer)
+	}
+
+	eventData := &events.CashUpdatedData{
+		CashAvailable: purchase.CashAvailable,
+		Amount:        -purchase.Redemption.Cost,
+	}
+	rs.eventPublisher.Publish(
+		events.NewEventWithUserID(events.CashUpdated, eventData, userID.String()),
+	)
+	return purchase, nil
+}
+
+// GetRedemptions returns a page of at most limit redemptions, newest first, and whether older
+// redemptions remain after it
+func (rs *rewardService) GetRedemptions(
+	userID uuid.UUID,
+	rewardID options.Option[uuid.UUID],
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.RewardRedemption, bool, error) {
+	funcStr := rs.traceLogger.GetTrace("service.GetRedemptions")
+
+	redemptions, err := rs.rewardStore.GetRedemptions(userID, rewardID, cursor, limit+1)
+	if err != nil {
+		slog.Error(fmt.Sprintf("%s: store.GetRedemptions:", funcStr), "err", err)
+		return nil, false, fmt.Errorf("%w: error fetching redemptions", responses.ErrInternalServer)
+	}
+
+	if len(redemptions) > limit {
+		return redemptions[:limit], true, nil
+	}
+	return redemptions, false, nil
+}
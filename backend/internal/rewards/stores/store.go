@@ -0,0 +1,476 @@
+// Package stores is the repository layer package for the rewards domain
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"goalify/internal/db"
+	"goalify/internal/entities"
+	"goalify/pkg/options"
+	"time"
+
+	sqlcdb "goalify/internal/db/generated"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrRewardRedeemed is returned when redeeming a one-time reward that was already redeemed
+	ErrRewardRedeemed = errors.New("reward already redeemed")
+	// ErrRewardCoolingDown is returned when redeeming a repeatable reward before its cooldown ends
+	ErrRewardCoolingDown = errors.New("reward cooling down")
+	// ErrDailyRewardClaimed is returned when claiming the daily reward twice on one local date
+	ErrDailyRewardClaimed = errors.New("daily reward already claimed")
+	// ErrDailyChestNotFound is returned when the chest picked for a daily claim does not exist
+	ErrDailyChestNotFound = errors.New("daily reward chest not found")
+)
+
+type CreateRewardParams struct {
+	Title           string
+	Description     string
+	Cost            int
+	CooldownSeconds int
+	Repeatable      bool
+}
+
+type UpdateRewardParams struct {
+	Title           options.Option[string]
+	Description     options.Option[string]
+	Cost            options.Option[int]
+	CooldownSeconds options.Option[int]
+	Repeatable      options.Option[bool]
+}
+
+type (
+	RewardStore interface {
+		CreateReward(userID uuid.UUID, params CreateRewardParams) (*entities.Reward, error)
+		GetRewardsByUserID(userID uuid.UUID) ([]*entities.Reward, error)
+		GetRewardByID(rewardID, userID uuid.UUID) (*entities.Reward, error)
+		UpdateRewardByID(
+			rewardID, userID uuid.UUID,
+			params UpdateRewardParams,
+		) (*entities.Reward, error)
+		DeleteRewardByID(rewardID, userID uuid.UUID) error
+		RedeemReward(rewardID, userID uuid.UUID) (*entities.RewardPurchase, error)
+		GetRedemptions(
+			userID uuid.UUID,
+			rewardID options.Option[uuid.UUID],
+			cursor options.Option[int64],
+			limit int,
+		) ([]*entities.RewardRedemption, error)
+
+		ClaimDailyReward(
+			userID uuid.UUID,
+			chestSlug func(streak int) string,
+		) (*entities.DailyClaim, error)
+		GetDailyRewardStatus(userID uuid.UUID) (*entities.DailyRewardStatus, error)
+	}
+	rewardStore struct {
+		pool    *pgxpool.Pool
+		queries *sqlcdb.Queries
+	}
+)
+
+func NewRewardStore(pool *pgxpool.Pool, queries *sqlcdb.Queries) RewardStore {
+	return &rewardStore{pool: pool, queries: queries}
+}
+
+// pgxRewardToEntity converts a reward and its redemption stats. A reward is redeemable unless it
+// is one-time and already redeemed, or its cooldown is still running.
+func pgxRewardToEntity(
+	r sqlcdb.Reward,
+	redemptionCount int32,
+	lastRedeemedAt pgtype.Timestamp,
+	coolingDown bool,
+) *entities.Reward {
+	reward := &entities.Reward{
+		ID:              uuid.UUID(r.ID.Bytes),
+		UserID:          uuid.UUID(r.UserID.Bytes),
+		Title:           r.Title,
+		Description:     r.Description,
+		Cost:            int(r.Cost),
+		Repeatable:      r.Repeatable,
+		CooldownSeconds: int(r.CooldownSeconds),
+		RedemptionCount: int(redemptionCount),
+		Redeemable:      !coolingDown && (r.Repeatable || redemptionCount == 0),
+		CreatedAt:       r.CreatedAt.Time,
+		UpdatedAt:       r.UpdatedAt.Time,
+	}
+	if lastRedeemedAt.Valid {
+		reward.LastRedeemedAt = options.Some(lastRedeemedAt.Time)
+	}
+	if coolingDown {
+		cooldown := time.Duration(r.CooldownSeconds) * time.Second
+		reward.AvailableAt = options.Some(lastRedeemedAt.Time.Add(cooldown))
+	}
+	return reward
+}
+
+func pgxRewardRedemptionToEntity(rr sqlcdb.RewardRedemption) *entities.RewardRedemption {
+	redemption := &entities.RewardRedemption{
+		ID:        rr.ID,
+		UserID:    uuid.UUID(rr.UserID.Bytes),
+		Title:     rr.Title,
+		Cost:      int(rr.Cost),
+		CreatedAt: rr.CreatedAt.Time,
+	}
+	if rr.RewardID.Valid {
+		redemption.RewardID = options.Some(uuid.UUID(rr.RewardID.Bytes))
+	}
+	return redemption
+}
+
+func pgxDailyClaimToEntity(dc sqlcdb.DailyClaim) *entities.DailyClaim {
+	return &entities.DailyClaim{
+		ID:        dc.ID,
+		UserID:    uuid.UUID(dc.UserID.Bytes),
+		ClaimDate: dc.ClaimDate.Time.Format(time.DateOnly),
+		Streak:    int(dc.Streak),
+		CreatedAt: dc.CreatedAt.Time,
+	}
+}
+
+func pgxChestToEntity(c sqlcdb.Chest) *entities.Chest {
+	return &entities.Chest{
+		ID:               uuid.UUID(c.ID.Bytes),
+		Type:             string(c.Type),
+		Description:      c.Description,
+		Price:            int(c.Price),
+		DropTableVersion: int(c.DropTableVersion),
+		CreatedAt:        c.CreatedAt.Time,
+		UpdatedAt:        c.UpdatedAt.Time,
+	}
+}
+
+// continuesStreak reports whether a claim on today extends a streak whose last claim was on
+// lastClaim, i.e. lastClaim is the day before
+func continuesStreak(lastClaim, today pgtype.Date) bool {
+	return lastClaim.Time.AddDate(0, 0, 1).Equal(today.Time)
+}
+
+func (s *rewardStore) CreateReward(
+	userID uuid.UUID,
+	params CreateRewardParams,
+) (*entities.Reward, error) {
+	reward, err := s.queries.CreateReward(context.Background(), sqlcdb.CreateRewardParams{
+		UserID:          db.UUIDToPgxUUID(userID),
+		Title:           params.Title,
+		Description:     params.Description,
+		Cost:            int32(params.Cost),
+		Repeatable:      params.Repeatable,
+		CooldownSeconds: int32(params.CooldownSeconds),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pgxRewardToEntity(reward, 0, pgtype.Timestamp{}, false), nil
+}
+
+func (s *rewardStore) GetRewardsByUserID(userID uuid.UUID) ([]*entities.Reward, error) {
+	rows, err := s.queries.GetRewardsByUserId(context.Background(), db.UUIDToPgxUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	rewards := make([]*entities.Reward, len(rows))
+	for i, row := range rows {
+		rewards[i] = pgxRewardToEntity(
+			row.Reward,
+			row.RedemptionCount,
+			row.LastRedeemedAt,
+			row.CoolingDown,
+		)
+	}
+	return rewards, nil
+}
+
+func (s *rewardStore) GetRewardByID(rewardID, userID uuid.UUID) (*entities.Reward, error) {
+	return getRewardByID(context.Background(), s.queries, rewardID, userID)
+}
+
+func getRewardByID(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	rewardID, userID uuid.UUID,
+) (*entities.Reward, error) {
+	row, err := q.GetRewardById(ctx, sqlcdb.GetRewardByIdParams{
+		ID:     db.UUIDToPgxUUID(rewardID),
+		UserID: db.UUIDToPgxUUID(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pgxRewardToEntity(
+		row.Reward,
+		row.RedemptionCount,
+		row.LastRedeemedAt,
+		row.CoolingDown,
+	), nil
+}
+
+func (s *rewardStore) UpdateRewardByID(
+	rewardID, userID uuid.UUID,
+	params UpdateRewardParams,
+) (*entities.Reward, error) {
+	ctx := context.Background()
+	var reward *entities.Reward
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		updateParams := sqlcdb.UpdateRewardByIdParams{
+			ID:          db.UUIDToPgxUUID(rewardID),
+			UserID:      db.UUIDToPgxUUID(userID),
+			Title:       db.OptionStringToPgxText(params.Title),
+			Description: db.OptionStringToPgxText(params.Description),
+		}
+		if repeatable, ok := params.Repeatable.GetVal(); ok {
+			updateParams.Repeatable = pgtype.Bool{Bool: repeatable, Valid: true}
+		}
+		var err error
+		if updateParams.Cost, err = db.OptionIntToPgxInt4(params.Cost); err != nil {
+			return err
+		}
+		updateParams.CooldownSeconds, err = db.OptionIntToPgxInt4(params.CooldownSeconds)
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.UpdateRewardById(ctx, updateParams); err != nil {
+			return err
+		}
+		reward, err = getRewardByID(ctx, q, rewardID, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reward, nil
+}
+
+func (s *rewardStore) DeleteRewardByID(rewardID, userID uuid.UUID) error {
+	rows, err := s.queries.DeleteRewardById(context.Background(), sqlcdb.DeleteRewardByIdParams{
+		ID:     db.UUIDToPgxUUID(rewardID),
+		UserID: db.UUIDToPgxUUID(userID),
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RedeemReward pays the reward's cost from the user's cash and records the redemption. It returns
+// sql.ErrNoRows for rewards the user does not have, ErrRewardRedeemed or ErrRewardCoolingDown
+// when the reward can't be redeemed yet, and db.ErrInsufficientCash when the user can't afford it.
+func (s *rewardStore) RedeemReward(rewardID, userID uuid.UUID) (*entities.RewardPurchase, error) {
+	ctx := context.Background()
+	purchase := &entities.RewardPurchase{}
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		_, err := q.LockRewardById(ctx, sqlcdb.LockRewardByIdParams{
+			ID:     db.UUIDToPgxUUID(rewardID),
+			UserID: db.UUIDToPgxUUID(userID),
+		})
+		if err != nil {
+			return err
+		}
+
+		reward, err := getRewardByID(ctx, q, rewardID, userID)
+		if err != nil {
+			return err
+		}
+		if !reward.Redeemable {
+			if reward.Repeatable {
+				return ErrRewardCoolingDown
+			}
+			return ErrRewardRedeemed
+		}
+
+		redemption, err := q.CreateRewardRedemption(ctx, sqlcdb.CreateRewardRedemptionParams{
+			UserID:   db.UUIDToPgxUUID(userID),
+			RewardID: db.UUIDToPgxUUID(rewardID),
+			Title:    reward.Title,
+			Cost:     int32(reward.Cost),
+		})
+		if err != nil {
+			return err
+		}
+		purchase.Redemption = pgxRewardRedemptionToEntity(redemption)
+
+		purchase.CashAvailable, err = db.RecordCashTransaction(
+			ctx,
+			q,
+			userID,
+			-reward.Cost,
+			sqlcdb.CashTransactionReasonRewardRedemption,
+			rewardID,
+		)
+		if err != nil {
+			return err
+		}
+
+		purchase.Reward, err = getRewardByID(ctx, q, rewardID, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purchase, nil
+}
+
+// GetRedemptions returns up to limit of the user's redemptions, newest first, that are older than
+// the cursor redemption id when one is given. rewardID narrows them to one reward.
+func (s *rewardStore) GetRedemptions(
+	userID uuid.UUID,
+	rewardID options.Option[uuid.UUID],
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.RewardRedemption, error) {
+	params := sqlcdb.GetRewardRedemptionsByUserIdParams{
+		UserID:   db.UUIDToPgxUUID(userID),
+		RewardID: db.OptionUUIDToPgxUUID(rewardID),
+		PageSize: int32(limit),
+	}
+	if id, ok := cursor.GetVal(); ok {
+		params.Cursor = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	rows, err := s.queries.GetRewardRedemptionsByUserId(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	redemptions := make([]*entities.RewardRedemption, len(rows))
+	for i, row := range rows {
+		redemptions[i] = pgxRewardRedemptionToEntity(row)
+	}
+	return redemptions, nil
+}
+
+// ClaimDailyReward grants the user one chest for their current local date. The claim extends the
+// user's streak when their last claim was the day before and starts a new one otherwise, and
+// chestSlug picks the chest for the resulting streak. It returns ErrDailyRewardClaimed when the
+// user has already claimed today.
+func (s *rewardStore) ClaimDailyReward(
+	userID uuid.UUID,
+	chestSlug func(streak int) string,
+) (*entities.DailyClaim, error) {
+	ctx := context.Background()
+	var claim *entities.DailyClaim
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		pgUserID := db.UUIDToPgxUUID(userID)
+		// concurrent claims queue up here, so the streak below is read after any earlier claim
+		if err := q.LockUserById(ctx, pgUserID); err != nil {
+			return err
+		}
+
+		today, err := q.GetUserLocalDate(ctx, pgUserID)
+		if err != nil {
+			return err
+		}
+
+		streak := 1
+		last, err := q.GetLatestDailyClaimByUserId(ctx, pgUserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err == nil {
+			if !last.ClaimDate.Time.Before(today.Time) {
+				return ErrDailyRewardClaimed
+			}
+			if continuesStreak(last.ClaimDate, today) {
+				streak = int(last.Streak) + 1
+			}
+		}
+
+		chests, err := q.GetChestsBySlugs(ctx, []string{chestSlug(streak)})
+		if err != nil {
+			return err
+		}
+		if len(chests) == 0 {
+			return ErrDailyChestNotFound
+		}
+		chest := chests[0]
+
+		userChest, err := q.IncrementUserChestQuantity(
+			ctx,
+			sqlcdb.IncrementUserChestQuantityParams{
+				UserID:        pgUserID,
+				ChestID:       chest.ID,
+				QuantityOwned: pgtype.Int4{Int32: 1, Valid: true},
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// the unique (user_id, claim_date) constraint is the last word on double claims
+		row, err := q.CreateDailyClaim(ctx, sqlcdb.CreateDailyClaimParams{
+			UserID:    pgUserID,
+			ClaimDate: today,
+			Streak:    int32(streak),
+			ChestID:   chest.ID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDailyRewardClaimed
+		}
+		if err != nil {
+			return err
+		}
+
+		claim = pgxDailyClaimToEntity(row)
+		claim.Chest = pgxChestToEntity(chest)
+		claim.UserChest = &entities.UserChest{
+			UserID:        uuid.UUID(userChest.UserID.Bytes),
+			ChestID:       uuid.UUID(userChest.ChestID.Bytes),
+			QuantityOwned: int(userChest.QuantityOwned.Int32),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// GetDailyRewardStatus returns the user's local date, their last daily claim and the streak that
+// is still alive. NextChestType is left for the caller to fill in.
+func (s *rewardStore) GetDailyRewardStatus(userID uuid.UUID) (*entities.DailyRewardStatus, error) {
+	ctx := context.Background()
+	pgUserID := db.UUIDToPgxUUID(userID)
+
+	today, err := s.queries.GetUserLocalDate(ctx, pgUserID)
+	if err != nil {
+		return nil, err
+	}
+	status := &entities.DailyRewardStatus{Today: today.Time.Format(time.DateOnly)}
+
+	last, err := s.queries.GetLatestDailyClaimByUserId(ctx, pgUserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status.LastClaim = pgxDailyClaimToEntity(last)
+	if last.ChestID.Valid {
+		chest, err := s.queries.GetChestById(ctx, last.ChestID)
+		if err != nil {
+			return nil, err
+		}
+		status.LastClaim.Chest = pgxChestToEntity(chest)
+	}
+
+	status.ClaimedToday = last.ClaimDate.Time.Equal(today.Time)
+	if status.ClaimedToday || continuesStreak(last.ClaimDate, today) {
+		status.Streak = int(last.Streak)
+	}
+	return status, nil
+}
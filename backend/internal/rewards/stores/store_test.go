@@ -0,0 +1,305 @@
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"goalify/internal/db"
+	"goalify/internal/testsetup"
+	"goalify/pkg/options"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	sqlcdb "goalify/internal/db/generated"
+)
+
+var (
+	rStore      RewardStore
+	queries     *sqlcdb.Queries
+	pgContainer *postgres.PostgresContainer
+)
+
+func setup(ctx context.Context) {
+	var err error
+
+	pgContainer, err = testsetup.GetPgContainer()
+	if err != nil {
+		panic(err)
+	}
+
+	connStr, err := pgContainer.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		panic(err)
+	}
+
+	pgxPool, err := db.NewPgxPoolWithConnString(ctx, connStr)
+	if err != nil {
+		panic(err)
+	}
+
+	queries = sqlcdb.New(pgxPool)
+	rStore = NewRewardStore(pgxPool, queries)
+}
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+
+	setup(ctx)
+
+	code := m.Run()
+
+	if err := pgContainer.Terminate(ctx); err != nil {
+		log.Fatalf("Failed to terminate container: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func createTestUser(t *testing.T, cash int) uuid.UUID {
+	t.Helper()
+	user, err := queries.CreateUser(context.Background(), sqlcdb.CreateUserParams{
+		Email:              t.Name() + "@mail.com",
+		Password:           "Password123!",
+		RefreshTokenExpiry: pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+		LevelID:            pgtype.Int4{Int32: 1, Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = db.RecordCashTransaction(
+		context.Background(),
+		queries,
+		uuid.UUID(user.ID.Bytes),
+		cash,
+		sqlcdb.CashTransactionReasonAdminGrant,
+		uuid.Nil,
+	)
+	require.NoError(t, err)
+	return uuid.UUID(user.ID.Bytes)
+}
+
+func TestRedeemOneTimeReward(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 150)
+	reward, err := rStore.CreateReward(userID, CreateRewardParams{Title: "movie night", Cost: 100})
+	require.NoError(t, err)
+	assert.True(t, reward.Redeemable)
+
+	purchase, err := rStore.RedeemReward(reward.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 50, purchase.CashAvailable)
+	assert.Equal(t, "movie night", purchase.Redemption.Title)
+	assert.Equal(t, 1, purchase.Reward.RedemptionCount)
+	assert.False(t, purchase.Reward.Redeemable)
+	assert.True(t, purchase.Reward.LastRedeemedAt.IsPresent())
+
+	_, err = rStore.RedeemReward(reward.ID, userID)
+	assert.ErrorIs(t, err, ErrRewardRedeemed)
+
+	_, err = rStore.RedeemReward(reward.ID, uuid.New())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRedeemRepeatableReward(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 30)
+	snack, err := rStore.CreateReward(userID, CreateRewardParams{
+		Title:      "snack",
+		Cost:       10,
+		Repeatable: true,
+	})
+	require.NoError(t, err)
+	gaming, err := rStore.CreateReward(userID, CreateRewardParams{
+		Title:           "hour of gaming",
+		Cost:            10,
+		Repeatable:      true,
+		CooldownSeconds: 3600,
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err = rStore.RedeemReward(snack.ID, userID)
+		require.NoError(t, err)
+	}
+
+	purchase, err := rStore.RedeemReward(gaming.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, purchase.CashAvailable)
+	assert.False(t, purchase.Reward.Redeemable)
+	availableAt, ok := purchase.Reward.AvailableAt.GetVal()
+	require.True(t, ok)
+	expected := purchase.Redemption.CreatedAt.Add(time.Hour)
+	assert.WithinDuration(t, expected, availableAt, time.Second)
+
+	_, err = rStore.RedeemReward(gaming.ID, userID)
+	assert.ErrorIs(t, err, ErrRewardCoolingDown)
+
+	// snack has no cooldown, so only the empty balance stops it
+	_, err = rStore.RedeemReward(snack.ID, userID)
+	assert.ErrorIs(t, err, db.ErrInsufficientCash)
+
+	redemptions, err := rStore.GetRedemptions(
+		userID,
+		options.Some(snack.ID),
+		options.None[int64](),
+		10,
+	)
+	require.NoError(t, err)
+	assert.Len(t, redemptions, 2)
+}
+
+func TestUpdateRewardClearsCooldown(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	reward, err := rStore.CreateReward(userID, CreateRewardParams{
+		Title:           "spa day",
+		Cost:            500,
+		Repeatable:      true,
+		CooldownSeconds: 60,
+	})
+	require.NoError(t, err)
+
+	updated, err := rStore.UpdateRewardByID(reward.ID, userID, UpdateRewardParams{
+		Cost:       options.Some(400),
+		Repeatable: options.Some(false),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 400, updated.Cost)
+	assert.Equal(t, "spa day", updated.Title)
+	assert.False(t, updated.Repeatable)
+	assert.Equal(t, 0, updated.CooldownSeconds)
+
+	_, err = rStore.UpdateRewardByID(reward.ID, uuid.New(), UpdateRewardParams{
+		Title: options.Some("stolen"),
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteRewardKeepsHistory(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 100)
+	reward, err := rStore.CreateReward(userID, CreateRewardParams{Title: "concert", Cost: 60})
+	require.NoError(t, err)
+	_, err = rStore.RedeemReward(reward.ID, userID)
+	require.NoError(t, err)
+
+	require.NoError(t, rStore.DeleteRewardByID(reward.ID, userID))
+	assert.ErrorIs(t, rStore.DeleteRewardByID(reward.ID, userID), sql.ErrNoRows)
+
+	redemptions, err := rStore.GetRedemptions(
+		userID,
+		options.None[uuid.UUID](),
+		options.None[int64](),
+		10,
+	)
+	require.NoError(t, err)
+	require.Len(t, redemptions, 1)
+	assert.Equal(t, "concert", redemptions[0].Title)
+	assert.Equal(t, 60, redemptions[0].Cost)
+	assert.False(t, redemptions[0].RewardID.IsPresent())
+}
+
+func createTestChest(t *testing.T) string {
+	t.Helper()
+	slug := t.Name() + "-chest"
+	_, err := queries.UpsertChestBySlug(context.Background(), sqlcdb.UpsertChestBySlugParams{
+		Slug:        pgtype.Text{String: slug, Valid: true},
+		Type:        sqlcdb.ChestTypeBronze,
+		Description: "daily chest",
+		Price:       100,
+	})
+	require.NoError(t, err)
+	return slug
+}
+
+func TestClaimDailyReward(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	slug := createTestChest(t)
+	var streaks []int
+	chestSlug := func(streak int) string {
+		streaks = append(streaks, streak)
+		return slug
+	}
+
+	claim, err := rStore.ClaimDailyReward(userID, chestSlug)
+	require.NoError(t, err)
+	assert.Equal(t, 1, claim.Streak)
+	assert.Equal(t, 1, claim.UserChest.QuantityOwned)
+	assert.Equal(t, claim.Chest.ID, claim.UserChest.ChestID)
+
+	_, err = rStore.ClaimDailyReward(userID, chestSlug)
+	assert.ErrorIs(t, err, ErrDailyRewardClaimed)
+	assert.Equal(t, []int{1}, streaks)
+
+	status, err := rStore.GetDailyRewardStatus(userID)
+	require.NoError(t, err)
+	assert.True(t, status.ClaimedToday)
+	assert.Equal(t, 1, status.Streak)
+	assert.Equal(t, claim.ClaimDate, status.Today)
+	require.NotNil(t, status.LastClaim)
+	assert.Equal(t, claim.Chest.ID, status.LastClaim.Chest.ID)
+
+	_, err = rStore.ClaimDailyReward(userID, func(int) string { return "missing-chest" })
+	assert.ErrorIs(t, err, ErrDailyRewardClaimed)
+}
+
+func TestClaimDailyRewardStreak(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	userID := createTestUser(t, 0)
+	slug := createTestChest(t)
+	today, err := queries.GetUserLocalDate(ctx, db.UUIDToPgxUUID(userID))
+	require.NoError(t, err)
+
+	addClaim := func(daysAgo, streak int) {
+		claimDate := pgtype.Date{Time: today.Time.AddDate(0, 0, -daysAgo), Valid: true}
+		_, err := queries.CreateDailyClaim(ctx, sqlcdb.CreateDailyClaimParams{
+			UserID:    db.UUIDToPgxUUID(userID),
+			ClaimDate: claimDate,
+			Streak:    int32(streak),
+		})
+		require.NoError(t, err)
+	}
+
+	// a missed day resets the streak
+	addClaim(2, 5)
+	status, err := rStore.GetDailyRewardStatus(userID)
+	require.NoError(t, err)
+	assert.False(t, status.ClaimedToday)
+	assert.Equal(t, 0, status.Streak)
+	assert.Nil(t, status.LastClaim.Chest)
+
+	// a claim yesterday carries it on
+	addClaim(1, 6)
+	status, err = rStore.GetDailyRewardStatus(userID)
+	require.NoError(t, err)
+	assert.Equal(t, 6, status.Streak)
+
+	claim, err := rStore.ClaimDailyReward(userID, func(int) string { return slug })
+	require.NoError(t, err)
+	assert.Equal(t, 7, claim.Streak)
+}
+
+func TestClaimDailyRewardMissingChest(t *testing.T) {
+	t.Parallel()
+
+	userID := createTestUser(t, 0)
+	_, err := rStore.ClaimDailyReward(userID, func(int) string { return "missing-chest" })
+	assert.ErrorIs(t, err, ErrDailyChestNotFound)
+
+	status, err := rStore.GetDailyRewardStatus(userID)
+	require.NoError(t, err)
+	assert.Nil(t, status.LastClaim)
+}
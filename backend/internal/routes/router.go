@@ -4,10 +4,15 @@ package routes
 import (
 	"goalify/internal/events"
 	"goalify/internal/middleware"
+	"goalify/internal/storage"
 	"net/http"
 
 	gh "goalify/internal/goals/handler"
 
+	lh "goalify/internal/loot/handler"
+
+	rh "goalify/internal/rewards/handler"
+
 	uh "goalify/internal/users/handler"
 )
 
@@ -21,6 +26,9 @@ func AddRoutes(
 	mux *http.ServeMux,
 	userHandler *uh.UserHandler,
 	goalHandler *gh.GoalHandler,
+	lootHandler *lh.LootHandler,
+	rewardHandler *rh.RewardHandler,
+	blobServer *storage.BlobServer,
 	em *events.EventManager,
 	mw middleware.MiddleWareChains,
 ) http.Handler {
@@ -39,7 +47,31 @@ func AddRoutes(
 	addRoute(mux, http.MethodPost, "/api/users/login", userHandler.HandleLogin, mw.CorsChain)
 	addRoute(mux, http.MethodPost, "/api/users/refresh", userHandler.HandleRefresh, mw.CorsChain)
 	addRoute(mux, http.MethodPut, "/api/users", userHandler.HandleUpdateUserByID, mw.AuthChain)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/users/me/transactions",
+		userHandler.HandleGetCashTransactions,
+		mw.AuthChain,
+	)
 	addRoute(mux, http.MethodGet, "/api/levels/{levelId}", userHandler.GetLevelByID, mw.AuthChain)
+	// public so avatars can be used directly as image sources
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/users/{userId}/avatar.svg",
+		lootHandler.HandleGetAvatar,
+		mw.CorsChain,
+	)
+
+	// uploaded files, public so signed URLs can be used directly as image sources
+	addRoute(
+		mux,
+		http.MethodGet,
+		storage.URLPrefix+"{key...}",
+		blobServer.HandleGetBlob,
+		mw.CorsChain,
+	)
 
 	// goals domain
 	addRoute(mux, http.MethodPost, "/api/goals", goalHandler.HandleCreateGoal, mw.AuthChain)
@@ -57,6 +89,21 @@ func AddRoutes(
 		goalHandler.HandleDeleteGoalByID,
 		mw.AuthChain,
 	)
+	// only /api/goals/{goalId}/completions is served here, see HandleGetGoalCompletions
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/goals/{goalId}/{resource}",
+		goalHandler.HandleGetGoalCompletions,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/completions",
+		goalHandler.HandleGetCompletions,
+		mw.AuthChain,
+	)
 
 	addRoute(
 		mux,
@@ -102,6 +149,283 @@ func AddRoutes(
 		mw.AuthChain,
 	)
 
+	// loot domain
+	addRoute(mux, http.MethodGet, "/api/chests", lootHandler.HandleGetChests, mw.AuthChain)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/chests/{chestId}",
+		lootHandler.HandleGetChestByID,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/chests/{chestId}/items",
+		lootHandler.HandleGetChestItems,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/chests/{chestId}/drop-rates",
+		lootHandler.HandleGetChestDropRates,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/chests/{chestId}/purchase",
+		lootHandler.HandlePurchaseChest,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/users/me/chests/{chestId}/open",
+		lootHandler.HandleOpenChest,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/users/me/items",
+		lootHandler.HandleGetUserItems,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/users/me/items/{userItemId}/equip",
+		lootHandler.HandleEquipItem,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/users/me/items/{userItemId}/unequip",
+		lootHandler.HandleUnequipItem,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/users/me/items/{userItemId}/consume",
+		lootHandler.HandleConsumeItem,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/users/me/effects",
+		lootHandler.HandleGetActiveEffects,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/users/me/items/{userItemId}/sell",
+		lootHandler.HandleSellItem,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/users/me/items/sell-duplicates",
+		lootHandler.HandleSellDuplicateItems,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/users/me/openings",
+		lootHandler.HandleGetChestOpenings,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/users/me/fairness",
+		lootHandler.HandleGetFairnessSeed,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/users/me/fairness/rotate",
+		lootHandler.HandleRotateFairnessSeed,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/users/me/collection",
+		lootHandler.HandleGetCollection,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/users/me/collection/chests/{chestId}/claim",
+		lootHandler.HandleClaimCollectionBonus,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/crafting/recipes",
+		lootHandler.HandleGetCraftingRecipes,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/crafting/{recipeId}",
+		lootHandler.HandleCraft,
+		mw.AuthChain,
+	)
+	addRoute(mux, http.MethodPost, "/api/gifts", lootHandler.HandleSendGift, mw.AuthChain)
+	addRoute(mux, http.MethodGet, "/api/gifts", lootHandler.HandleGetGifts, mw.AuthChain)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/gifts/{giftId}/accept",
+		lootHandler.HandleAcceptGift,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/gifts/{giftId}/decline",
+		lootHandler.HandleDeclineGift,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/marketplace/listings",
+		lootHandler.HandleGetListings,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/marketplace/listings",
+		lootHandler.HandleCreateListing,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/marketplace/listings/{listingId}/buy",
+		lootHandler.HandleBuyListing,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodDelete,
+		"/api/marketplace/listings/{listingId}",
+		lootHandler.HandleCancelListing,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/users/me/listings",
+		lootHandler.HandleGetUserListings,
+		mw.AuthChain,
+	)
+
+	// rewards domain
+	addRoute(mux, http.MethodPost, "/api/rewards", rewardHandler.HandleCreateReward, mw.AuthChain)
+	addRoute(mux, http.MethodGet, "/api/rewards", rewardHandler.HandleGetRewards, mw.AuthChain)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/rewards/redemptions",
+		rewardHandler.HandleGetRedemptions,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/rewards/{rewardId}",
+		rewardHandler.HandleGetRewardByID,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPut,
+		"/api/rewards/{rewardId}",
+		rewardHandler.HandleUpdateRewardByID,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodDelete,
+		"/api/rewards/{rewardId}",
+		rewardHandler.HandleDeleteRewardByID,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/rewards/{rewardId}/redeem",
+		rewardHandler.HandleRedeemReward,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/rewards/daily",
+		rewardHandler.HandleGetDailyRewardStatus,
+		mw.AuthChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/rewards/daily/claim",
+		rewardHandler.HandleClaimDailyReward,
+		mw.AuthChain,
+	)
+
+	// admin routes
+	addRoute(
+		mux,
+		http.MethodPut,
+		"/api/admin/chests/{chestId}/drop-rates",
+		lootHandler.HandleReplaceDropTable,
+		mw.AdminChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/admin/chests/{chestId}/drop-rates/history",
+		lootHandler.HandleGetDropTableHistory,
+		mw.AdminChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPut,
+		"/api/admin/items/{itemId}/image",
+		lootHandler.HandleUploadItemImage,
+		mw.AdminChain,
+	)
+	addRoute(
+		mux,
+		http.MethodPost,
+		"/api/admin/users/{userId}/cash",
+		userHandler.HandleGrantCash,
+		mw.AdminChain,
+	)
+	addRoute(
+		mux,
+		http.MethodGet,
+		"/api/admin/cash/reconciliation",
+		userHandler.HandleReconcileCash,
+		mw.AdminChain,
+	)
+
 	// need options method available on all endpoints for CORS
 	mux.Handle(
 		"OPTIONS /api/",
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"goalify/internal/responses"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// BlobServer serves blobs at the signed URLs made by its URLSigner
+type BlobServer struct {
+	store  BlobStore
+	signer *URLSigner
+}
+
+func NewBlobServer(store BlobStore, signer *URLSigner) *BlobServer {
+	return &BlobServer{store: store, signer: signer}
+}
+
+// HandleGetBlob serves the blob named by the path's key once its signature checks out. The key
+// is the blob's content hash, so responses are cached as immutable.
+func (s *BlobServer) HandleGetBlob(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if !ValidKey(key) || !s.signer.Verify(key, r.URL.Query().Get("signature")) {
+		responses.SendAPIError(w, r, http.StatusForbidden, "forbidden: invalid signature", nil)
+		return
+	}
+
+	etag := `"` + s.signer.signature(key)[:32] + `"`
+	if responses.ETagMatches(r.Header.Get("If-None-Match"), etag) {
+		setCacheHeaders(w, etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, info, err := s.store.Get(r.Context(), key)
+	if errors.Is(err, ErrBlobNotFound) {
+		responses.SendAPIError(w, r, http.StatusNotFound, "not found: blob not found", nil)
+		return
+	}
+	if err != nil {
+		slog.Error("storage.HandleGetBlob: store.Get:", "err", err)
+		responses.SendInternalServerError(w, r)
+		return
+	}
+	defer body.Close()
+
+	setCacheHeaders(w, etag)
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if info.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		slog.Error("storage.HandleGetBlob: io.Copy:", "err", err)
+	}
+}
+
+func setCacheHeaders(w http.ResponseWriter, etag string) {
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", etag)
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+)
+
+// URLPrefix is the path blobs are served under
+const URLPrefix = "/api/blobs/"
+
+// signingKeyLabel derives the URL signing key from the configured secret, so a secret shared
+// with another HMAC use, such as signing JWTs, never signs blob URLs directly. Otherwise every
+// signed URL would hand out a MAC under that key and every JWT signature would pass as a blob
+// signature.
+const signingKeyLabel = "blob-url-signing"
+
+// URLSigner signs blob URLs so the server only hands out blobs it linked to itself. Signatures
+// don't expire: keys are content addressed, so a signed URL always names the same bytes and can be
+// cached indefinitely.
+type URLSigner struct {
+	key []byte
+}
+
+func NewURLSigner(secret string) *URLSigner {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingKeyLabel))
+	return &URLSigner{key: mac.Sum(nil)}
+}
+
+// URL returns the signed path that serves the blob under key
+func (s *URLSigner) URL(key string) string {
+	return URLPrefix + key + "?signature=" + s.signature(key)
+}
+
+// Verify reports whether signature was produced by URL for key
+func (s *URLSigner) Verify(key, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(s.signature(key)))
+}
+
+func (s *URLSigner) signature(key string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(key))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// KeyFromURL returns the blob key a URL made by URLSigner points at. Anything else is taken to be
+// a bare key, such as the relative paths seeded before blobs were served.
+func KeyFromURL(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && strings.HasPrefix(u.Path, URLPrefix) {
+		return strings.TrimPrefix(u.Path, URLPrefix)
+	}
+	return strings.TrimPrefix(rawURL, "/")
+}
@@ -0,0 +1,51 @@
+// Package storage keeps binary blobs such as item artwork and user uploads. Blobs are addressed by
+// slash separated keys, like items/3f2a.png, and live behind the BlobStore interface so the
+// backing store can be the local filesystem or an S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"path"
+	"time"
+)
+
+var (
+	// ErrBlobNotFound is returned when no blob is stored under a key
+	ErrBlobNotFound = errors.New("blob not found")
+	// ErrInvalidKey is returned for keys that are empty, absolute or climb with ..
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobInfo describes a stored blob
+type BlobInfo struct {
+	ModTime     time.Time
+	ContentType string
+	Size        int64
+}
+
+// BlobStore saves and loads blobs by key. Put replaces any blob already under the key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Get returns the blob's contents, which the caller must close, and what is known about it
+	Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidKey reports whether key may address a blob. Keys are relative slash separated paths
+// without . or .. elements, so no store can be made to reach outside its root.
+func ValidKey(key string) bool {
+	return key != "." && fs.ValidPath(key)
+}
+
+// contentTypeOf guesses a blob's media type from its key's extension, for stores that do not
+// record the type given to Put
+func contentTypeOf(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
@@ -2,10 +2,13 @@
 package handler
 
 import (
+	"fmt"
+	"goalify/internal/entities"
 	"goalify/internal/middleware"
 	"goalify/internal/responses"
 	"goalify/internal/users/service"
 	"goalify/pkg/jsonutil"
+	"goalify/pkg/options"
 	"net/http"
 	"strconv"
 
@@ -88,14 +91,8 @@ func (h *UserHandler) HandleUpdateUserByID(w http.ResponseWriter, r *http.Reques
 	}
 
 	updates := make(map[string]any)
-	if decoded.Xp.IsPresent() {
-		updates["xp"] = decoded.Xp.ValueOrZero()
-	}
-	if decoded.LevelID.IsPresent() {
-		updates["level_id"] = decoded.LevelID.ValueOrZero()
-	}
-	if decoded.CashAvailable.IsPresent() {
-		updates["cash_available"] = decoded.CashAvailable.ValueOrZero()
+	if decoded.Timezone.IsPresent() {
+		updates["timezone"] = decoded.Timezone.ValueOrZero()
 	}
 
 	if len(updates) == 0 {
@@ -133,3 +130,108 @@ func (h *UserHandler) GetLevelByID(w http.ResponseWriter, r *http.Request) {
 
 	responses.SendResponse(w, r, http.StatusOK, level)
 }
+
+func (h *UserHandler) HandleGetCashTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "error parsing auth header", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	cursor := options.None[int64]()
+	if query.Has("cursor") {
+		id, err := strconv.ParseInt(query.Get("cursor"), 10, 64)
+		if err != nil {
+			responses.SendAPIError(w, r, http.StatusBadRequest, "cursor must be an integer", nil)
+			return
+		}
+		cursor = options.Some(id)
+	}
+
+	limit := DefaultTransactionsLimit
+	if query.Has("limit") {
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil || limit < 1 || limit > MaxTransactionsLimit {
+			responses.SendAPIError(
+				w,
+				r,
+				http.StatusBadRequest,
+				fmt.Sprintf("limit must be an integer from 1 to %d", MaxTransactionsLimit),
+				nil,
+			)
+			return
+		}
+	}
+
+	transactions, hasMore, err := h.userService.GetCashTransactions(parsedUserID, cursor, limit)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.CashTransaction]{
+		Object:  responses.ObjectList,
+		Data:    transactions,
+		HasMore: &hasMore,
+	}
+	if hasMore {
+		nextPage := strconv.FormatInt(transactions[len(transactions)-1].ID, 10)
+		res.NextPage = &nextPage
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
+
+func (h *UserHandler) HandleGrantCash(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetIDFromHeader(r)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	parsedAdminID, err := uuid.Parse(adminID)
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "error parsing auth header", nil)
+		return
+	}
+
+	userID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		responses.SendAPIError(w, r, http.StatusBadRequest, "url param userId must be a uuid", nil)
+		return
+	}
+
+	decoded, problems, err := jsonutil.DecodeValid[GrantCashRequest](r)
+	if err != nil {
+		responses.HandleDecodeError(w, r, problems, err)
+		return
+	}
+
+	user, err := h.userService.GrantCash(parsedAdminID, userID, decoded.Amount)
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	responses.SendResponse(w, r, http.StatusOK, user)
+}
+
+func (h *UserHandler) HandleReconcileCash(w http.ResponseWriter, r *http.Request) {
+	mismatches, err := h.userService.ReconcileCash()
+	if err != nil {
+		responses.SendAPIError(w, r, responses.GetErrorCode(err), err.Error(), nil)
+		return
+	}
+
+	res := responses.ServerResponse[[]*entities.CashMismatch]{
+		Object: responses.ObjectList,
+		Data:   mismatches,
+	}
+	responses.SendResponse(w, r, http.StatusOK, res)
+}
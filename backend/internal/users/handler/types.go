@@ -3,6 +3,7 @@ package handler
 import (
 	"goalify/pkg/options"
 	"strings"
+	"time"
 )
 
 type (
@@ -23,7 +24,17 @@ type (
 		Xp            options.Option[int] `json:"xp"`
 		LevelID       options.Option[int] `json:"level_id"`
 		CashAvailable options.Option[int] `json:"cash_available"`
+		// Timezone is an IANA zone name such as "Europe/Berlin"
+		Timezone options.Option[string] `json:"timezone"`
 	}
+	GrantCashRequest struct {
+		Amount int `json:"amount"`
+	}
+)
+
+const (
+	DefaultTransactionsLimit = 20
+	MaxTransactionsLimit     = 100
 )
 
 const (
@@ -77,21 +88,26 @@ func (r LoginRequest) Valid() map[string]string {
 	return problems
 }
 
-func checkNonNegativeIntField(
-	problems map[string]string,
-	fieldName string,
-	val options.Option[int],
-) {
-	if val.IsPresent() && val.ValueOrZero() < 0 {
-		problems[fieldName] = fieldName + " must be non-negative"
-	}
-}
-
 func (r UpdateRequest) Valid() map[string]string {
 	problems := make(map[string]string)
-	checkNonNegativeIntField(problems, "xp", r.Xp)
-	checkNonNegativeIntField(problems, "level_id", r.LevelID)
-	checkNonNegativeIntField(problems, "cash_available", r.CashAvailable)
+	// xp and levels only change by completing goals, and cash only through the cash ledger
+	if r.Xp.IsPresent() {
+		problems["xp"] = "xp cannot be updated directly"
+	}
+	if r.LevelID.IsPresent() {
+		problems["level_id"] = "level_id cannot be updated directly"
+	}
+	if r.CashAvailable.IsPresent() {
+		problems["cash_available"] = "cash_available cannot be updated directly"
+	}
+	if timezone, ok := r.Timezone.GetVal(); ok {
+		// LoadLocation treats "" as UTC and "Local" as the server's zone, neither of which
+		// postgres knows by those names
+		_, err := time.LoadLocation(timezone)
+		if timezone == "" || timezone == "Local" || err != nil {
+			problems["timezone"] = "timezone must be an IANA time zone name"
+		}
+	}
 
 	return problems
 }
@@ -103,3 +119,11 @@ func (r RefreshRequest) Valid() map[string]string {
 	}
 	return problems
 }
+
+func (r GrantCashRequest) Valid() map[string]string {
+	problems := make(map[string]string)
+	if r.Amount == 0 {
+		problems["amount"] = "amount must not be zero"
+	}
+	return problems
+}
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"errors"
+	"goalify/internal/entities"
 	"goalify/internal/events"
+	"goalify/internal/users/stores"
 	"log/slog"
 )
 
@@ -21,44 +24,70 @@ func (s *userService) handleGoalUpdatedEvent(event events.Event) {
 		return
 	}
 
-	oldGoal := eventData.OldGoal
-	newGoal := eventData.NewGoal
+	completion := eventData.Completion
+	// only updates that complete or un-complete the goal record a completion
+	if completion == nil {
+		return
+	}
+	userID := eventData.NewGoal.UserID
 
-	if oldGoal.Status != newGoal.Status && newGoal.Status == "complete" {
-		// we need to update the xp of the user
-		user, err := s.userStore.GetUserByID(oldGoal.UserID.String())
-		if err != nil {
-			slog.Error("service.handleGoalUpdatedEvent: store.GetUserById:", "err", err)
+	if completion.UncompletedAt.IsPresent() {
+		progress, err := s.userStore.ReverseCompletionXp(userID, completion.ID)
+		if errors.Is(err, stores.ErrNoXpToReverse) {
 			return
 		}
-		level, err := s.userStore.GetLevelByID(user.LevelID)
 		if err != nil {
-			slog.Error("service.handleGoalUpdatedEvent: store.GetLevelById:", "err", err)
+			slog.Error("service.handleGoalUpdatedEvent: store.ReverseCompletionXp:", "err", err)
 			return
 		}
-		newXp := user.Xp + 1
-		newLevel := user.LevelID
-		if newXp >= level.LevelUpXp {
-			newXp %= level.LevelUpXp
-			newLevel += 1
-		}
+		s.publishProgress(progress)
+		return
+	}
 
-		_, err = s.userStore.UpdateUserByID(user.ID, map[string]any{
-			"xp":       newXp,
-			"level_id": newLevel,
-		})
-		if err != nil {
-			slog.Error("service.handleGoalUpdatedEvent: store.UpdateUserById:", "err", err)
-			return
-		}
+	// a failed lookup shouldn't cost the user their XP, so it falls back to no boost
+	multiplier, err := s.userStore.GetXpMultiplier(userID)
+	if err != nil {
+		slog.Error("service.handleGoalUpdatedEvent: store.GetXpMultiplier:", "err", err)
+		multiplier = 1
+	}
+	progress, err := s.userStore.GrantCompletionXp(userID, completion.ID, multiplier)
+	if errors.Is(err, stores.ErrNoXpToGrant) {
+		return
+	}
+	if err != nil {
+		slog.Error("service.handleGoalUpdatedEvent: store.GrantCompletionXp:", "err", err)
+		return
+	}
+	s.publishProgress(progress)
+}
+
+func (s *userService) publishProgress(progress *entities.UserProgress) {
+	user := progress.User
+	eventData := &events.XpUpdatedData{
+		LevelID: user.LevelID,
+		Xp:      user.Xp,
+	}
+	s.eventPublisher.Publish(
+		events.NewEventWithUserID(events.XPUpdated, eventData, user.ID.String()),
+	)
 
-		eventData := &events.XpUpdatedData{
-			LevelID: newLevel,
-			Xp:      newXp,
+	if user.LevelID > progress.OldLevelID {
+		levelUpData := &events.LevelUpData{
+			OldLevelID:  progress.OldLevelID,
+			NewLevelID:  user.LevelID,
+			CashGranted: progress.CashReward,
 		}
 		s.eventPublisher.Publish(
-			events.NewEventWithUserID(events.XPUpdated, eventData, oldGoal.UserID.String()),
+			events.NewEventWithUserID(events.LevelUp, levelUpData, user.ID.String()),
+		)
+	}
+	if progress.CashReward > 0 {
+		cashData := &events.CashUpdatedData{
+			CashAvailable: user.CashAvailable,
+			Amount:        progress.CashReward,
+		}
+		s.eventPublisher.Publish(
+			events.NewEventWithUserID(events.CashUpdated, cashData, user.ID.String()),
 		)
-
 	}
 }
@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"goalify/internal/config"
+	"goalify/internal/db"
 	"goalify/internal/entities"
 	"goalify/internal/events"
 	"goalify/internal/responses"
 	"goalify/internal/users/stores"
+	"goalify/pkg/options"
 	"log/slog"
 	"time"
 
@@ -28,6 +30,14 @@ type UserService interface {
 	DeleteUserByID(id string) error
 	UpdateUserByID(id uuid.UUID, updates map[string]interface{}) (*entities.UserDTO, error)
 	VerifyToken(tokenString string) (string, error)
+	IsAdmin(id string) (bool, error)
+	GrantCash(adminID, userID uuid.UUID, amount int) (*entities.UserDTO, error)
+	GetCashTransactions(
+		userID uuid.UUID,
+		cursor options.Option[int64],
+		limit int,
+	) ([]*entities.CashTransaction, bool, error)
+	ReconcileCash() ([]*entities.CashMismatch, error)
 
 	GetLevelByID(id int) (*entities.Level, error)
 }
@@ -233,6 +243,18 @@ func (s *userService) UpdateUserByID(
 	return user.ToUserDTO(""), nil
 }
 
+func (s *userService) IsAdmin(id string) (bool, error) {
+	user, err := s.userStore.GetUserByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("%w: user not found", responses.ErrUnauthorized)
+	}
+	if err != nil {
+		slog.Error("service.IsAdmin: store.GetUserByID:", "err", err.Error())
+		return false, responses.ErrInternalServer
+	}
+	return user.IsAdmin, nil
+}
+
 func (s *userService) GetLevelByID(id int) (*entities.Level, error) {
 	level, err := s.userStore.GetLevelByID(id)
 	if err != nil {
@@ -240,3 +262,77 @@ func (s *userService) GetLevelByID(id int) (*entities.Level, error) {
 	}
 	return level, nil
 }
+
+func (s *userService) GrantCash(
+	adminID, userID uuid.UUID,
+	amount int,
+) (*entities.UserDTO, error) {
+	_, err := s.userStore.GetUserByID(userID.String())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: user not found", responses.ErrNotFound)
+	}
+	if err != nil {
+		slog.Error("service.GrantCash: store.GetUserByID:", "err", err.Error())
+		return nil, responses.ErrInternalServer
+	}
+
+	balance, err := s.userStore.GrantCash(userID, adminID, amount)
+	if errors.Is(err, db.ErrInsufficientCash) {
+		return nil, fmt.Errorf("%w: user does not have enough cash", responses.ErrBadRequest)
+	}
+	if err != nil {
+		slog.Error("service.GrantCash: store.GrantCash:", "err", err.Error())
+		return nil, responses.ErrInternalServer
+	}
+
+	user, err := s.userStore.GetUserByID(userID.String())
+	if err != nil {
+		slog.Error("service.GrantCash: store.GetUserByID:", "err", err.Error())
+		return nil, responses.ErrInternalServer
+	}
+
+	cashData := &events.CashUpdatedData{CashAvailable: balance, Amount: amount}
+	s.eventPublisher.Publish(
+		events.NewEventWithUserID(events.CashUpdated, cashData, userID.String()),
+	)
+	return user.ToUserDTO(""), nil
+}
+
+// GetCashTransactions returns a page of at most limit cash transactions, newest first, and
+// whether older transactions remain after it
+func (s *userService) GetCashTransactions(
+	userID uuid.UUID,
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.CashTransaction, bool, error) {
+	transactions, err := s.userStore.GetCashTransactions(userID, cursor, limit+1)
+	if err != nil {
+		slog.Error("service.GetCashTransactions: store.GetCashTransactions:", "err", err.Error())
+		return nil, false, responses.ErrInternalServer
+	}
+
+	if len(transactions) > limit {
+		return transactions[:limit], true, nil
+	}
+	return transactions, false, nil
+}
+
+// ReconcileCash flags every user whose cash_available no longer matches the sum of their cash
+// transactions
+func (s *userService) ReconcileCash() ([]*entities.CashMismatch, error) {
+	mismatches, err := s.userStore.GetCashMismatches()
+	if err != nil {
+		slog.Error("service.ReconcileCash: store.GetCashMismatches:", "err", err.Error())
+		return nil, responses.ErrInternalServer
+	}
+
+	for _, mismatch := range mismatches {
+		slog.Warn(
+			"service.ReconcileCash: cash balance does not match ledger",
+			"userID", mismatch.UserID,
+			"cashAvailable", mismatch.CashAvailable,
+			"ledgerBalance", mismatch.LedgerBalance,
+		)
+	}
+	return mismatches, nil
+}
@@ -3,13 +3,18 @@ package stores
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"goalify/internal/db"
 	"goalify/internal/entities"
+	"goalify/pkg/options"
 	"time"
 
 	sqlcdb "goalify/internal/db/generated"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type (
@@ -20,29 +25,54 @@ type (
 		GetUserByID(id string) (*entities.User, error)
 		DeleteUserByID(id string) error
 		UpdateUserByID(id uuid.UUID, updates map[string]any) (*entities.User, error)
+		GrantCompletionXp(
+			userID uuid.UUID,
+			completionID int64,
+			xp int,
+		) (*entities.UserProgress, error)
+		ReverseCompletionXp(userID uuid.UUID, completionID int64) (*entities.UserProgress, error)
+		GrantCash(userID, adminID uuid.UUID, amount int) (int, error)
+		GetCashTransactions(
+			userID uuid.UUID,
+			cursor options.Option[int64],
+			limit int,
+		) ([]*entities.CashTransaction, error)
+		GetCashMismatches() ([]*entities.CashMismatch, error)
+		GetXpMultiplier(userID uuid.UUID) (int, error)
 
 		GetLevelByID(id int) (*entities.Level, error)
 	}
 	userStore struct {
+		pool    *pgxpool.Pool
 		queries *sqlcdb.Queries
 	}
 )
 
 const DefaultLevel = 1
 
+var (
+	// ErrNoXpToGrant is returned when a completion earns no XP, because it was undone, was already
+	// granted, or another completion of the goal holds the XP for its period
+	ErrNoXpToGrant = errors.New("completion earns no xp")
+	// ErrNoXpToReverse is returned when a completion has no XP to take back
+	ErrNoXpToReverse = errors.New("completion holds no xp")
+)
+
 // Helper functions to convert between sqlc types and entity types
 func pgxUserToEntity(u sqlcdb.User) *entities.User {
 	return &entities.User{
 		ID:                 uuid.UUID(u.ID.Bytes),
 		Email:              u.Email,
 		Password:           u.Password,
+		Timezone:           u.Timezone,
 		Xp:                 int(u.Xp.Int32),
 		LevelID:            int(u.LevelID.Int32),
-		CashAvailable:      int(u.CashAvailable.Int32),
+		CashAvailable:      int(u.CashAvailable),
 		RefreshToken:       uuid.UUID(u.RefreshToken.Bytes),
 		RefreshTokenExpiry: u.RefreshTokenExpiry.Time,
 		CreatedAt:          u.CreatedAt.Time,
 		UpdatedAt:          u.UpdatedAt.Time,
+		IsAdmin:            u.IsAdmin,
 	}
 }
 
@@ -56,8 +86,26 @@ func pgxLevelToEntity(l sqlcdb.Level) *entities.Level {
 	}
 }
 
-func NewUserStore(queries *sqlcdb.Queries) UserStore {
+func pgxCashTransactionToEntity(t sqlcdb.CashTransaction) *entities.CashTransaction {
+	referenceID := options.None[uuid.UUID]()
+	if t.ReferenceID.Valid {
+		referenceID = options.Some(uuid.UUID(t.ReferenceID.Bytes))
+	}
+
+	return &entities.CashTransaction{
+		CreatedAt:    t.CreatedAt.Time,
+		ReferenceID:  referenceID,
+		Reason:       string(t.Reason),
+		ID:           t.ID,
+		Amount:       int(t.Amount),
+		BalanceAfter: int(t.BalanceAfter),
+		UserID:       uuid.UUID(t.UserID.Bytes),
+	}
+}
+
+func NewUserStore(pool *pgxpool.Pool, queries *sqlcdb.Queries) UserStore {
 	return &userStore{
+		pool:    pool,
 		queries: queries,
 	}
 }
@@ -175,10 +223,9 @@ func (s *userStore) UpdateUserByID(id uuid.UUID, updates map[string]any) (*entit
 			params.Xp = pgtype.Int4{Int32: int32(xpInt), Valid: true}
 		}
 	}
-
-	if cash, ok := updates["cash_available"]; ok {
-		if cashInt, ok := cash.(int); ok {
-			params.CashAvailable = pgtype.Int4{Int32: int32(cashInt), Valid: true}
+	if timezone, ok := updates["timezone"]; ok {
+		if timezoneStr, ok := timezone.(string); ok {
+			params.Timezone = pgtype.Text{String: timezoneStr, Valid: true}
 		}
 	}
 
@@ -198,3 +245,229 @@ func (s *userStore) GetLevelByID(id int) (*entities.Level, error) {
 
 	return pgxLevelToEntity(level), nil
 }
+
+// GrantCompletionXp grants xp for a goal completion, levelling the user up when it fills their
+// level. The XP is only granted while the completion stands and no other standing completion of
+// the goal holds XP for its period, otherwise ErrNoXpToGrant is returned. A level's cash reward
+// is paid the first time the user passes it.
+func (s *userStore) GrantCompletionXp(
+	userID uuid.UUID,
+	completionID int64,
+	xp int,
+) (*entities.UserProgress, error) {
+	ctx := context.Background()
+	var progress *entities.UserProgress
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		pgUserID := db.UUIDToPgxUUID(userID)
+		// grants and reversals for the user queue up behind this lock
+		user, err := q.GetUserByIdForUpdate(ctx, pgUserID)
+		if err != nil {
+			return err
+		}
+		completion, err := q.ClaimGoalCompletionXp(ctx, sqlcdb.ClaimGoalCompletionXpParams{
+			XpGranted: int32(xp),
+			ID:        completionID,
+			UserID:    pgUserID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoXpToGrant
+		}
+		if err != nil {
+			return err
+		}
+		level, err := q.GetLevelById(ctx, user.LevelID.Int32)
+		if err != nil {
+			return err
+		}
+
+		newXp := int(user.Xp.Int32) + xp
+		newLevel := int(user.LevelID.Int32)
+		cashReward := 0
+		if newXp >= int(level.LevelUpXp) {
+			newXp %= int(level.LevelUpXp)
+			newLevel += 1
+			if newLevel > int(user.HighestLevelID) {
+				cashReward = int(level.CashReward)
+			}
+		}
+
+		progress, err = updateProgress(ctx, q, user, newXp, newLevel, cashReward, completion.GoalID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// ReverseCompletionXp takes back the XP an undone goal completion holds, dropping the user's level
+// for as long as their XP would go negative. Cash rewards stay paid. ErrNoXpToReverse is returned
+// when the completion still stands or holds no XP.
+func (s *userStore) ReverseCompletionXp(
+	userID uuid.UUID,
+	completionID int64,
+) (*entities.UserProgress, error) {
+	ctx := context.Background()
+	var progress *entities.UserProgress
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		pgUserID := db.UUIDToPgxUUID(userID)
+		user, err := q.GetUserByIdForUpdate(ctx, pgUserID)
+		if err != nil {
+			return err
+		}
+		reversed, err := q.ReverseGoalCompletionXp(ctx, sqlcdb.ReverseGoalCompletionXpParams{
+			ID:     completionID,
+			UserID: pgUserID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoXpToReverse
+		}
+		if err != nil {
+			return err
+		}
+
+		newXp := int(user.Xp.Int32) - int(reversed.XpGranted)
+		newLevel := int(user.LevelID.Int32)
+		for newXp < 0 && newLevel > DefaultLevel {
+			newLevel -= 1
+			level, err := q.GetLevelById(ctx, int32(newLevel))
+			if err != nil {
+				return err
+			}
+			newXp += int(level.LevelUpXp)
+		}
+		newXp = max(newXp, 0)
+
+		progress, err = updateProgress(ctx, q, user, newXp, newLevel, 0, reversed.GoalID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// updateProgress sets the user's xp and level and records cashReward in the cash ledger,
+// referencing the goal that caused the change
+func updateProgress(
+	ctx context.Context,
+	q *sqlcdb.Queries,
+	user sqlcdb.User,
+	xp, levelID, cashReward int,
+	goalID pgtype.UUID,
+) (*entities.UserProgress, error) {
+	updated, err := q.UpdateUserProgress(ctx, sqlcdb.UpdateUserProgressParams{
+		Xp:      pgtype.Int4{Int32: int32(xp), Valid: true},
+		LevelID: pgtype.Int4{Int32: int32(levelID), Valid: true},
+		ID:      user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := db.RecordCashTransaction(
+		ctx,
+		q,
+		uuid.UUID(user.ID.Bytes),
+		cashReward,
+		sqlcdb.CashTransactionReasonLevelReward,
+		uuid.UUID(goalID.Bytes),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &entities.UserProgress{
+		User:       pgxUserToEntity(updated),
+		OldLevelID: int(user.LevelID.Int32),
+		CashReward: cashReward,
+	}
+	progress.User.CashAvailable = balance
+	return progress, nil
+}
+
+// GrantCash records an admin's credit, or debit for a negative amount, to the user's cash and
+// returns the resulting balance
+func (s *userStore) GrantCash(userID, adminID uuid.UUID, amount int) (int, error) {
+	ctx := context.Background()
+	var balance int
+
+	err := db.WithTx(ctx, s.pool, func(q *sqlcdb.Queries) error {
+		var err error
+		balance, err = db.RecordCashTransaction(
+			ctx,
+			q,
+			userID,
+			amount,
+			sqlcdb.CashTransactionReasonAdminGrant,
+			adminID,
+		)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// GetCashTransactions returns up to limit of the user's cash transactions, newest first, that are
+// older than the cursor transaction id when one is given
+func (s *userStore) GetCashTransactions(
+	userID uuid.UUID,
+	cursor options.Option[int64],
+	limit int,
+) ([]*entities.CashTransaction, error) {
+	params := sqlcdb.GetCashTransactionsByUserIdParams{
+		UserID:   db.UUIDToPgxUUID(userID),
+		PageSize: int32(limit),
+	}
+	if id, ok := cursor.GetVal(); ok {
+		params.Cursor = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	rows, err := s.queries.GetCashTransactionsByUserId(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*entities.CashTransaction, len(rows))
+	for i, row := range rows {
+		transactions[i] = pgxCashTransactionToEntity(row)
+	}
+	return transactions, nil
+}
+
+// GetCashMismatches returns every user whose cash_available differs from their ledger sum
+func (s *userStore) GetCashMismatches() ([]*entities.CashMismatch, error) {
+	rows, err := s.queries.GetCashBalanceMismatches(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches := make([]*entities.CashMismatch, len(rows))
+	for i, row := range rows {
+		mismatches[i] = &entities.CashMismatch{
+			UserID:        uuid.UUID(row.UserID.Bytes),
+			CashAvailable: int(row.CashAvailable),
+			LedgerBalance: int(row.LedgerBalance),
+		}
+	}
+	return mismatches, nil
+}
+
+// GetXpMultiplier returns the strongest XP multiplier the user has active, or 1 without one
+func (s *userStore) GetXpMultiplier(userID uuid.UUID) (int, error) {
+	multiplier, err := s.queries.GetXpMultiplierByUserId(
+		context.Background(),
+		db.UUIDToPgxUUID(userID),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(multiplier), nil
+}
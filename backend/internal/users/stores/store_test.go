@@ -5,11 +5,14 @@ import (
 	"database/sql"
 	"goalify/internal/db"
 	"goalify/internal/testsetup"
+	"goalify/pkg/options"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
@@ -19,6 +22,7 @@ import (
 
 var (
 	userStoreVar UserStore
+	queries      *sqlcdb.Queries
 	pgContainer  *postgres.PostgresContainer
 )
 
@@ -40,8 +44,8 @@ func setup(ctx context.Context) {
 		panic(err)
 	}
 
-	queries := sqlcdb.New(pgxPool)
-	userStoreVar = NewUserStore(queries)
+	queries = sqlcdb.New(pgxPool)
+	userStoreVar = NewUserStore(pgxPool, queries)
 }
 
 func TestMain(m *testing.M) {
@@ -138,6 +142,132 @@ func TestUpdateUser(t *testing.T) {
 	assert.Equal(t, "test6@mail.com", user.Email)
 }
 
+// createCompletion records a completion of the goal for today, as completing it would
+func createCompletion(t *testing.T, goal sqlcdb.Goal) int64 {
+	completion, err := queries.CreateGoalCompletion(
+		context.Background(),
+		sqlcdb.CreateGoalCompletionParams{
+			GoalID:     goal.ID,
+			UserID:     goal.UserID,
+			CategoryID: goal.CategoryID,
+			PeriodDate: db.TimeToPgxDate(time.Now()),
+		},
+	)
+	require.NoError(t, err)
+	return completion.ID
+}
+
+func TestCompletionXp(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	user, err := userStoreVar.CreateUser("progress@mail.com", "password")
+	require.NoError(t, err)
+	_, err = userStoreVar.UpdateUserByID(user.ID, map[string]any{"xp": 99})
+	require.NoError(t, err)
+	category, err := queries.CreateGoalCategory(ctx, sqlcdb.CreateGoalCategoryParams{
+		Title:       "progress",
+		UserID:      db.UUIDToPgxUUID(user.ID),
+		ResetPolicy: sqlcdb.CategoryResetPolicyNone,
+		ResetTime:   pgtype.Time{Valid: true},
+	})
+	require.NoError(t, err)
+	goal, err := queries.CreateGoal(ctx, sqlcdb.CreateGoalParams{
+		Title:      "progress",
+		UserID:     category.UserID,
+		CategoryID: category.ID,
+	})
+	require.NoError(t, err)
+
+	first := createCompletion(t, goal)
+	progress, err := userStoreVar.GrantCompletionXp(user.ID, first, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, progress.User.Xp)
+	assert.Equal(t, 2, progress.User.LevelID)
+	assert.Equal(t, 1, progress.OldLevelID)
+	assert.Equal(t, 100, progress.CashReward)
+	assert.Equal(t, 100, progress.User.CashAvailable)
+
+	// neither the same completion nor another one in the same period earns again
+	_, err = userStoreVar.GrantCompletionXp(user.ID, first, 1)
+	assert.ErrorIs(t, err, ErrNoXpToGrant)
+	second := createCompletion(t, goal)
+	_, err = userStoreVar.GrantCompletionXp(user.ID, second, 1)
+	assert.ErrorIs(t, err, ErrNoXpToGrant)
+
+	_, err = userStoreVar.ReverseCompletionXp(user.ID, first)
+	assert.ErrorIs(t, err, ErrNoXpToReverse)
+	_, err = queries.UncompleteGoalCompletion(ctx, goal.ID)
+	require.NoError(t, err)
+	_, err = userStoreVar.ReverseCompletionXp(user.ID, second)
+	assert.ErrorIs(t, err, ErrNoXpToReverse)
+
+	_, err = queries.UncompleteGoalCompletion(ctx, goal.ID)
+	require.NoError(t, err)
+	progress, err = userStoreVar.ReverseCompletionXp(user.ID, first)
+	require.NoError(t, err)
+	assert.Equal(t, 99, progress.User.Xp)
+	assert.Equal(t, 1, progress.User.LevelID)
+	assert.Equal(t, 2, progress.OldLevelID)
+	assert.Equal(t, 100, progress.User.CashAvailable)
+	_, err = userStoreVar.ReverseCompletionXp(user.ID, first)
+	assert.ErrorIs(t, err, ErrNoXpToReverse)
+
+	// with the first undone the period's XP is free again, but level 1's reward was already paid
+	progress, err = userStoreVar.GrantCompletionXp(user.ID, createCompletion(t, goal), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, progress.User.LevelID)
+	assert.Equal(t, 0, progress.CashReward)
+	assert.Equal(t, 100, progress.User.CashAvailable)
+
+	transactions, err := userStoreVar.GetCashTransactions(user.ID, options.None[int64](), 10)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "level_reward", transactions[0].Reason)
+	assert.Equal(t, options.Some(uuid.UUID(goal.ID.Bytes)), transactions[0].ReferenceID)
+}
+
+func TestCashLedger(t *testing.T) {
+	t.Parallel()
+	user, err := userStoreVar.CreateUser("ledger@mail.com", "password")
+	require.NoError(t, err)
+	adminID := uuid.New()
+
+	for _, amount := range []int{50, 25, -40} {
+		_, err = userStoreVar.GrantCash(user.ID, adminID, amount)
+		require.NoError(t, err)
+	}
+
+	_, err = userStoreVar.GrantCash(user.ID, adminID, -36)
+	assert.ErrorIs(t, err, db.ErrInsufficientCash)
+
+	user, err = userStoreVar.GetUserByID(user.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, 35, user.CashAvailable)
+
+	firstPage, err := userStoreVar.GetCashTransactions(user.ID, options.None[int64](), 2)
+	require.NoError(t, err)
+	require.Len(t, firstPage, 2)
+	assert.Equal(t, -40, firstPage[0].Amount)
+	assert.Equal(t, 35, firstPage[0].BalanceAfter)
+	assert.Equal(t, 75, firstPage[1].BalanceAfter)
+
+	secondPage, err := userStoreVar.GetCashTransactions(
+		user.ID,
+		options.Some(firstPage[1].ID),
+		2,
+	)
+	require.NoError(t, err)
+	require.Len(t, secondPage, 1)
+	assert.Equal(t, 50, secondPage[0].Amount)
+	assert.Equal(t, options.Some(adminID), secondPage[0].ReferenceID)
+
+	mismatches, err := userStoreVar.GetCashMismatches()
+	require.NoError(t, err)
+	for _, mismatch := range mismatches {
+		assert.NotEqual(t, user.ID, mismatch.UserID)
+	}
+}
+
 func TestGetLevelById(t *testing.T) {
 	t.Parallel()
 	level := 1
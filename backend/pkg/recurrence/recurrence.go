@@ -0,0 +1,307 @@
+// Package recurrence is for schedules that repeat on calendar dates, written as a subset of
+// RFC 5545 recurrence rules: FREQ (DAILY, WEEKLY or MONTHLY), INTERVAL, BYDAY without ordinals
+// and BYMONTHDAY. Weeks start on Monday. Dates are days at midnight UTC, as postgres DATEs read.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+const (
+	MaxInterval = 365
+	// maxPeriods bounds the search for an occurrence, so rules that can never occur from their
+	// start date (say, every 12 months on the 30th starting in February) give up
+	maxPeriods = 1000
+)
+
+// ErrInvalidRule is wrapped by every parsing and validation error
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule is a recurrence rule. Occurrences are counted from a start date that is kept alongside the
+// rule, the way DTSTART is in RFC 5545. A WEEKLY rule without BYDAY falls on the start's weekday,
+// and a MONTHLY rule without BYMONTHDAY or BYDAY on the start's day of the month.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	// ByMonthDay counts from the end of the month when negative, so -1 is the last day
+	ByMonthDay []int
+}
+
+func DailyRule() Rule {
+	return Rule{Freq: Daily, Interval: 1}
+}
+
+func EveryNDays(n int) Rule {
+	return Rule{Freq: Daily, Interval: n}
+}
+
+func WeekdaysRule(days ...time.Weekday) Rule {
+	return Rule{Freq: Weekly, Interval: 1, ByDay: days}
+}
+
+func MonthlyOn(day int) Rule {
+	return Rule{Freq: Monthly, Interval: 1, ByMonthDay: []int{day}}
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;BYDAY=MO,WE", with or without an "RRULE:" prefix
+func Parse(s string) (Rule, error) {
+	rule := Rule{Interval: 1}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, fmt.Errorf("%w: rule is empty", ErrInvalidRule)
+	}
+
+	seen := make(map[string]bool)
+	for part := range strings.SplitSeq(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		name = strings.ToUpper(name)
+		if seen[name] {
+			return Rule{}, fmt.Errorf("%w: %s given more than once", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("%w: INTERVAL must be an integer", ErrInvalidRule)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for code := range strings.SplitSeq(value, ",") {
+				day, ok := weekdayCodes[strings.ToUpper(code)]
+				if !ok {
+					return Rule{}, fmt.Errorf("%w: unsupported BYDAY %q", ErrInvalidRule, code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for num := range strings.SplitSeq(value, ",") {
+				day, err := strconv.Atoi(num)
+				if err != nil {
+					return Rule{}, fmt.Errorf("%w: BYMONTHDAY must be integers", ErrInvalidRule)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, day)
+			}
+		default:
+			return Rule{}, fmt.Errorf("%w: unsupported part %s", ErrInvalidRule, name)
+		}
+	}
+
+	if !seen["FREQ"] {
+		return Rule{}, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if err := rule.Validate(); err != nil {
+		return Rule{}, err
+	}
+	return rule, nil
+}
+
+// Validate checks the rule is one this package can schedule
+func (r Rule) Validate() error {
+	switch r.Freq {
+	case Daily, Weekly, Monthly:
+	default:
+		return fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalidRule)
+	}
+	if r.Interval < 1 || r.Interval > MaxInterval {
+		return fmt.Errorf("%w: INTERVAL must be from 1 to %d", ErrInvalidRule, MaxInterval)
+	}
+	for _, day := range r.ByDay {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("%w: unknown weekday %d", ErrInvalidRule, day)
+		}
+	}
+	for _, day := range r.ByMonthDay {
+		if day == 0 || day < -31 || day > 31 {
+			return fmt.Errorf("%w: BYMONTHDAY must be from 1 to 31 or -31 to -1", ErrInvalidRule)
+		}
+	}
+	return nil
+}
+
+// String is the rule in its canonical RRULE form, which Parse reads back
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := slices.Clone(r.ByDay)
+		// weeks start on Monday, so Sunday sorts last
+		slices.SortFunc(days, func(a, b time.Weekday) int {
+			return (int(a)+6)%7 - (int(b)+6)%7
+		})
+		codes := make([]string, 0, len(days))
+		for _, day := range slices.Compact(days) {
+			codes = append(codes, strings.ToUpper(day.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := slices.Compact(slices.Sorted(slices.Values(r.ByMonthDay)))
+		nums := make([]string, len(days))
+		for i, day := range days {
+			nums[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(nums, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence after the date after, counting from start. ok is false when
+// the rule has no occurrence within the search bound.
+func (r Rule) Next(start, after time.Time) (next time.Time, ok bool) {
+	start, from := Date(start), Date(after).AddDate(0, 0, 1)
+	if from.Before(start) {
+		from = start
+	}
+
+	period := r.periodStart(from)
+	if offset := r.periodsBetween(r.periodStart(start), period) % r.Interval; offset != 0 {
+		period = r.addPeriods(period, r.Interval-offset)
+	}
+	for range maxPeriods {
+		end := r.addPeriods(period, 1)
+		for day := latest(period, from); day.Before(end); day = day.AddDate(0, 0, 1) {
+			if r.matchesDay(start, day) {
+				return day, true
+			}
+		}
+		period = r.addPeriods(period, r.Interval)
+	}
+	return time.Time{}, false
+}
+
+// Latest returns the last occurrence on or before the date onOrBefore, counting from start. ok is
+// false when there is none.
+func (r Rule) Latest(start, onOrBefore time.Time) (last time.Time, ok bool) {
+	start, until := Date(start), Date(onOrBefore)
+	if until.Before(start) {
+		return time.Time{}, false
+	}
+
+	firstPeriod := r.periodStart(start)
+	period := r.periodStart(until)
+	period = r.addPeriods(period, -(r.periodsBetween(firstPeriod, period) % r.Interval))
+	for range maxPeriods {
+		if period.Before(firstPeriod) {
+			break
+		}
+		day := r.addPeriods(period, 1).AddDate(0, 0, -1)
+		if day.After(until) {
+			day = until
+		}
+		for ; !day.Before(period) && !day.Before(start); day = day.AddDate(0, 0, -1) {
+			if r.matchesDay(start, day) {
+				return day, true
+			}
+		}
+		period = r.addPeriods(period, -r.Interval)
+	}
+	return time.Time{}, false
+}
+
+// Date truncates t to its calendar date at midnight UTC
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// matchesDay reports whether day passes the rule's BYDAY and BYMONTHDAY filters. It doesn't
+// check the interval, which the callers step by.
+func (r Rule) matchesDay(start, day time.Time) bool {
+	byDay, byMonthDay := r.ByDay, r.ByMonthDay
+	if len(byDay) == 0 && len(byMonthDay) == 0 {
+		switch r.Freq {
+		case Weekly:
+			byDay = []time.Weekday{start.Weekday()}
+		case Monthly:
+			byMonthDay = []int{start.Day()}
+		}
+	}
+
+	if len(byDay) > 0 && !slices.Contains(byDay, day.Weekday()) {
+		return false
+	}
+	if len(byMonthDay) > 0 {
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		return slices.ContainsFunc(byMonthDay, func(monthDay int) bool {
+			if monthDay < 0 {
+				monthDay += daysInMonth + 1
+			}
+			return monthDay == day.Day()
+		})
+	}
+	return true
+}
+
+// periodStart is the first day of the day, week or month day falls in
+func (r Rule) periodStart(day time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case Monthly:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func (r Rule) addPeriods(period time.Time, n int) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return period.AddDate(0, 0, 7*n)
+	case Monthly:
+		return period.AddDate(0, n, 0)
+	default:
+		return period.AddDate(0, 0, n)
+	}
+}
+
+// periodsBetween counts the periods from the period starting at a to the one starting at b
+func (r Rule) periodsBetween(a, b time.Time) int {
+	switch r.Freq {
+	case Weekly:
+		return int(b.Sub(a).Hours()/24) / 7
+	case Monthly:
+		return (b.Year()-a.Year())*12 + int(b.Month()-a.Month())
+	default:
+		return int(b.Sub(a).Hours() / 24)
+	}
+}
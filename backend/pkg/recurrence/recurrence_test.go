@@ -0,0 +1,135 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:FREQ=DAILY;INTERVAL=3", "FREQ=DAILY;INTERVAL=3"},
+		{"freq=weekly;byday=su,mo,we,mo", "FREQ=WEEKLY;BYDAY=MO,WE,SU"},
+		{"FREQ=MONTHLY;BYMONTHDAY=15,-1", "FREQ=MONTHLY;BYMONTHDAY=-1,15"},
+		{"FREQ=WEEKLY;INTERVAL=1", "FREQ=WEEKLY"},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.input)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.expected, rule.String())
+
+		reparsed, err := Parse(rule.String())
+		require.NoError(t, err)
+		assert.Equal(t, rule.String(), reparsed.String())
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=366",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=DAILY;COUNT=3",
+		"FREQ",
+	}
+	for _, input := range inputs {
+		_, err := Parse(input)
+		assert.ErrorIs(t, err, ErrInvalidRule, input)
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026-10-14 is a Wednesday
+	start := date("2026-10-14")
+	tests := []struct {
+		name     string
+		rule     Rule
+		after    string
+		expected string
+	}{
+		{"daily", DailyRule(), "2026-10-14", "2026-10-15"},
+		{"daily before start", DailyRule(), "2026-10-01", "2026-10-14"},
+		{"every 3 days", EveryNDays(3), "2026-10-14", "2026-10-17"},
+		{"every 3 days mid cycle", EveryNDays(3), "2026-10-18", "2026-10-20"},
+		{"weekdays", WeekdaysRule(time.Monday, time.Friday), "2026-10-14", "2026-10-16"},
+		{"weekdays wrap", WeekdaysRule(time.Monday, time.Friday), "2026-10-16", "2026-10-19"},
+		{"weekly on start day", Rule{Freq: Weekly, Interval: 1}, "2026-10-14", "2026-10-21"},
+		{
+			"fortnightly",
+			Rule{Freq: Weekly, Interval: 2, ByDay: []time.Weekday{time.Monday}},
+			"2026-10-14",
+			"2026-10-26",
+		},
+		{"monthly", MonthlyOn(20), "2026-10-14", "2026-10-20"},
+		{"monthly skips short months", MonthlyOn(31), "2026-10-31", "2026-12-31"},
+		{"last day of month", MonthlyOn(-1), "2026-10-31", "2026-11-30"},
+		{"monthly on start day", Rule{Freq: Monthly, Interval: 1}, "2026-10-14", "2026-11-14"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok := tt.rule.Next(start, date(tt.after))
+			require.True(t, ok)
+			assert.Equal(t, date(tt.expected), next)
+		})
+	}
+}
+
+func TestNextNeverOccurs(t *testing.T) {
+	rule := Rule{Freq: Monthly, Interval: 12, ByMonthDay: []int{30}}
+	_, ok := rule.Next(date("2026-02-01"), date("2026-02-01"))
+	assert.False(t, ok)
+}
+
+func TestLatest(t *testing.T) {
+	start := date("2026-10-14")
+	tests := []struct {
+		name       string
+		rule       Rule
+		onOrBefore string
+		expected   string
+	}{
+		{"daily", DailyRule(), "2026-10-20", "2026-10-20"},
+		{"every 3 days", EveryNDays(3), "2026-10-19", "2026-10-17"},
+		{"weekdays", WeekdaysRule(time.Monday, time.Friday), "2026-10-21", "2026-10-19"},
+		{
+			"fortnightly",
+			Rule{Freq: Weekly, Interval: 2, ByDay: []time.Weekday{time.Monday}},
+			"2026-11-08",
+			"2026-10-26",
+		},
+		{"monthly", MonthlyOn(15), "2027-01-01", "2026-12-15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last, ok := tt.rule.Latest(start, date(tt.onOrBefore))
+			require.True(t, ok)
+			assert.Equal(t, date(tt.expected), last)
+		})
+	}
+
+	// nothing falls between the start and the first Friday
+	_, ok := WeekdaysRule(time.Friday).Latest(start, date("2026-10-15"))
+	assert.False(t, ok)
+	_, ok = DailyRule().Latest(start, date("2026-10-13"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,205 @@
+package seeds
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "goalify/internal/db/generated"
+)
+
+//go:embed data/chests.json
+var chestsJSON []byte
+
+// ChestSeed represents a single chest and its drop table in the JSON file.
+// Slug is the chest's stable natural key, and drop rates reference items by their slug.
+type ChestSeed struct {
+	Slug        string         `json:"slug"`
+	Type        string         `json:"type"`
+	Description string         `json:"description"`
+	DropRates   []DropRateSeed `json:"drop_rates"`
+	Price       int32          `json:"price"`
+}
+
+// DropRateSeed represents one item's chance of dropping from a seeded chest.
+type DropRateSeed struct {
+	Item     string  `json:"item"`
+	DropRate float64 `json:"drop_rate"`
+}
+
+// loadChests parses the embedded chests.json file.
+func loadChests() ([]ChestSeed, error) {
+	var chests []ChestSeed
+	if err := json.Unmarshal(chestsJSON, &chests); err != nil {
+		return nil, fmt.Errorf("parse chests.json: %w", err)
+	}
+	return chests, nil
+}
+
+// SeedChests upserts all chests from chests.json into the database by slug and syncs their drop
+// tables. Must run after SeedItems, since drop rates reference items by slug.
+// Uses a transaction to ensure atomicity - all chests are seeded or none are.
+func SeedChests(ctx context.Context, pool *pgxpool.Pool) error {
+	chests, err := loadChests()
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	queries := db.New(tx)
+
+	itemIDs, err := seededItemIDs(ctx, queries, chests)
+	if err != nil {
+		return err
+	}
+
+	for _, seed := range chests {
+		chest, err := queries.UpsertChestBySlug(ctx, db.UpsertChestBySlugParams{
+			Slug:        pgtype.Text{String: seed.Slug, Valid: true},
+			Type:        db.ChestType(seed.Type),
+			Description: seed.Description,
+			Price:       seed.Price,
+		})
+		if err != nil {
+			return fmt.Errorf("upsert chest %s: %w", seed.Slug, err)
+		}
+
+		if err := syncDropTable(ctx, queries, chest, seed.DropRates, itemIDs); err != nil {
+			return fmt.Errorf("sync drop table for chest %s: %w", seed.Slug, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// seededItemIDs maps the slug of every item referenced by the chests to its id.
+func seededItemIDs(
+	ctx context.Context,
+	queries *db.Queries,
+	chests []ChestSeed,
+) (map[string]pgtype.UUID, error) {
+	slugs := make([]string, 0)
+	for _, chest := range chests {
+		for _, rate := range chest.DropRates {
+			slugs = append(slugs, rate.Item)
+		}
+	}
+
+	items, err := queries.GetChestItemsBySlugs(ctx, slugs)
+	if err != nil {
+		return nil, fmt.Errorf("get items by slug: %w", err)
+	}
+
+	ids := make(map[string]pgtype.UUID, len(items))
+	for _, item := range items {
+		ids[item.Slug.String] = item.ID
+	}
+	for _, slug := range slugs {
+		if _, ok := ids[slug]; !ok {
+			return nil, fmt.Errorf("unknown item %s", slug)
+		}
+	}
+	return ids, nil
+}
+
+// syncDropTable replaces the chest's drop table with the seeded one when they differ. As with an
+// admin replacement, the outgoing table is archived and the version bumped, so the history keeps
+// every set of rates the chest has used. A brand new chest keeps version 1.
+func syncDropTable(
+	ctx context.Context,
+	queries *db.Queries,
+	chest db.Chest,
+	seeds []DropRateSeed,
+	itemIDs map[string]pgtype.UUID,
+) error {
+	current, err := queries.GetDropRatesByChestId(ctx, chest.ID)
+	if err != nil {
+		return err
+	}
+	if dropTableMatches(current, seeds, itemIDs) {
+		return nil
+	}
+
+	if len(current) > 0 {
+		chest, err = queries.IncrementChestDropTableVersion(ctx, chest.ID)
+		if err != nil {
+			return err
+		}
+		err = queries.ArchiveDropTable(ctx, db.ArchiveDropTableParams{
+			ChestID: chest.ID,
+			Version: chest.DropTableVersion - 1,
+		})
+		if err != nil {
+			return err
+		}
+		if err := queries.DeleteDropRatesByChestId(ctx, chest.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, seed := range seeds {
+		_, err := queries.CreateChestItemDropRate(ctx, db.CreateChestItemDropRateParams{
+			ItemID:   itemIDs[seed.Item],
+			ChestID:  chest.ID,
+			DropRate: seed.DropRate,
+		})
+		if err != nil {
+			return fmt.Errorf("create drop rate for item %s: %w", seed.Item, err)
+		}
+
+		err = queries.CreateDropTableHistoryEntry(ctx, db.CreateDropTableHistoryEntryParams{
+			ChestID:  chest.ID,
+			Version:  chest.DropTableVersion,
+			ItemID:   itemIDs[seed.Item],
+			DropRate: seed.DropRate,
+		})
+		if err != nil {
+			return fmt.Errorf("record drop rate history for item %s: %w", seed.Item, err)
+		}
+	}
+
+	return nil
+}
+
+// dropTableMatches reports whether the chest's live rates are exactly the seeded ones.
+func dropTableMatches(
+	current []db.ChestItemDropRate,
+	seeds []DropRateSeed,
+	itemIDs map[string]pgtype.UUID,
+) bool {
+	if len(current) != len(seeds) {
+		return false
+	}
+
+	rates := make(map[pgtype.UUID]float64, len(current))
+	for _, rate := range current {
+		rates[rate.ItemID] = rate.DropRate
+	}
+	for _, seed := range seeds {
+		rate, ok := rates[itemIDs[seed.Item]]
+		if !ok || rate != seed.DropRate {
+			return false
+		}
+	}
+	return true
+}
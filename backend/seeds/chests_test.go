@@ -0,0 +1,101 @@
+package seeds
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "goalify/internal/db/generated"
+)
+
+// matches the tolerance the loot service accepts for admin drop table replacements
+const dropRateSumTolerance = 1e-6
+
+func TestChestsJSONValid(t *testing.T) {
+	chests, err := loadChests()
+	require.NoError(t, err, "chests.json should parse successfully")
+	require.NotEmpty(t, chests, "chests.json should contain at least one chest")
+
+	types := map[db.ChestType]bool{
+		db.ChestTypeBronze: true,
+		db.ChestTypeSilver: true,
+		db.ChestTypeGold:   true,
+	}
+
+	// Validate each chest's structure and business rules
+	seen := make(map[string]bool, len(chests))
+	for _, chest := range chests {
+		assert.NotEmpty(t, chest.Slug, "chest slugs are required")
+		assert.False(t, seen[chest.Slug], "chest slug %s is duplicated", chest.Slug)
+		seen[chest.Slug] = true
+
+		assert.True(t, types[db.ChestType(chest.Type)],
+			"chest %s: unknown type %q", chest.Slug, chest.Type)
+		assert.NotEmpty(t, chest.Description, "chest %s: description is required", chest.Slug)
+		assert.Greater(t, chest.Price, int32(0), "chest %s: price must be positive", chest.Slug)
+		assert.NotEmpty(t, chest.DropRates, "chest %s: drop table must not be empty", chest.Slug)
+	}
+}
+
+func TestChestsDropRatesSumToOne(t *testing.T) {
+	chests, err := loadChests()
+	require.NoError(t, err)
+
+	for _, chest := range chests {
+		sum := 0.0
+		for _, rate := range chest.DropRates {
+			assert.Greater(t, rate.DropRate, 0.0,
+				"chest %s: drop rate for %s must be positive", chest.Slug, rate.Item)
+			assert.LessOrEqual(t, rate.DropRate, 1.0,
+				"chest %s: drop rate for %s must be at most 1", chest.Slug, rate.Item)
+			sum += rate.DropRate
+		}
+
+		assert.LessOrEqual(t, math.Abs(sum-1), dropRateSumTolerance,
+			"chest %s: drop rates sum to %f, not 1", chest.Slug, sum)
+	}
+}
+
+func TestChestsDropRatesReferenceItems(t *testing.T) {
+	chests, err := loadChests()
+	require.NoError(t, err)
+	items, err := loadItems()
+	require.NoError(t, err)
+
+	known := make(map[string]bool, len(items))
+	for _, item := range items {
+		known[item.Slug] = true
+	}
+
+	// Every drop must be a seeded item, listed at most once per chest
+	for _, chest := range chests {
+		inChest := make(map[string]bool, len(chest.DropRates))
+		for _, rate := range chest.DropRates {
+			assert.True(t, known[rate.Item], "chest %s: unknown item %s", chest.Slug, rate.Item)
+			assert.False(t, inChest[rate.Item],
+				"chest %s: item %s is listed twice", chest.Slug, rate.Item)
+			inChest[rate.Item] = true
+		}
+	}
+}
+
+func TestItemsReferencedByChests(t *testing.T) {
+	chests, err := loadChests()
+	require.NoError(t, err)
+	items, err := loadItems()
+	require.NoError(t, err)
+
+	referenced := make(map[string]bool, len(items))
+	for _, chest := range chests {
+		for _, rate := range chest.DropRates {
+			referenced[rate.Item] = true
+		}
+	}
+
+	// An item no chest can drop is unreachable
+	for _, item := range items {
+		assert.True(t, referenced[item.Slug], "item %s is not in any chest", item.Slug)
+	}
+}
@@ -0,0 +1,105 @@
+package seeds
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "goalify/internal/db/generated"
+)
+
+//go:embed data/items.json
+var itemsJSON []byte
+
+// ItemSeed represents a single chest item's data in the JSON file.
+// Slug is the item's stable natural key, so titles can change without creating a new item.
+// Items with an Effect are consumables.
+type ItemSeed struct {
+	ImageURL *string     `json:"image_url"`
+	Price    *int32      `json:"price"`
+	Effect   *EffectSeed `json:"effect"`
+	Slug     string      `json:"slug"`
+	Title    string      `json:"title"`
+	Rarity   string      `json:"rarity"`
+	Slot     string      `json:"slot"`
+}
+
+// EffectSeed represents what consuming a seeded item grants and for how long.
+type EffectSeed struct {
+	Type            string `json:"type"`
+	Value           int32  `json:"value"`
+	DurationSeconds int32  `json:"duration_seconds"`
+}
+
+// loadItems parses the embedded items.json file.
+func loadItems() ([]ItemSeed, error) {
+	var items []ItemSeed
+	if err := json.Unmarshal(itemsJSON, &items); err != nil {
+		return nil, fmt.Errorf("parse items.json: %w", err)
+	}
+	return items, nil
+}
+
+// SeedItems upserts all chest items from items.json into the database by slug.
+// Uses a transaction to ensure atomicity - all items are seeded or none are.
+func SeedItems(ctx context.Context, pool *pgxpool.Pool) error {
+	items, err := loadItems()
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	queries := db.New(tx)
+
+	for _, item := range items {
+		params := db.UpsertChestItemBySlugParams{
+			Slug:   pgtype.Text{String: item.Slug, Valid: true},
+			Title:  item.Title,
+			Rarity: db.ItemType(item.Rarity),
+			Slot:   db.ItemSlot(item.Slot),
+		}
+		if item.ImageURL != nil {
+			params.ImageUrl = pgtype.Text{String: *item.ImageURL, Valid: true}
+		}
+		if item.Price != nil {
+			params.Price = pgtype.Int4{Int32: *item.Price, Valid: true}
+		}
+		if item.Effect != nil {
+			params.Effect = db.NullItemEffect{
+				ItemEffect: db.ItemEffect(item.Effect.Type),
+				Valid:      true,
+			}
+			params.EffectValue = pgtype.Int4{Int32: item.Effect.Value, Valid: true}
+			params.EffectDurationSeconds = pgtype.Int4{
+				Int32: item.Effect.DurationSeconds,
+				Valid: true,
+			}
+		}
+
+		if _, err := queries.UpsertChestItemBySlug(ctx, params); err != nil {
+			return fmt.Errorf("upsert item %s: %w", item.Slug, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
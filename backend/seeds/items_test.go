@@ -0,0 +1,70 @@
+package seeds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "goalify/internal/db/generated"
+)
+
+func TestItemsJSONValid(t *testing.T) {
+	items, err := loadItems()
+	require.NoError(t, err, "items.json should parse successfully")
+	require.NotEmpty(t, items, "items.json should contain at least one item")
+
+	rarities := map[db.ItemType]bool{
+		db.ItemTypeCommon:    true,
+		db.ItemTypeRare:      true,
+		db.ItemTypeEpic:      true,
+		db.ItemTypeLegendary: true,
+	}
+	slots := map[db.ItemSlot]bool{
+		db.ItemSlotHead:       true,
+		db.ItemSlotBody:       true,
+		db.ItemSlotWeapon:     true,
+		db.ItemSlotAccessory:  true,
+		db.ItemSlotBackground: true,
+	}
+	effects := map[db.ItemEffect]bool{
+		db.ItemEffectXpMultiplier: true,
+		db.ItemEffectStreakFreeze: true,
+	}
+
+	// Validate each item's structure and business rules
+	for _, item := range items {
+		assert.NotEmpty(t, item.Slug, "item slugs are required")
+		assert.NotEmpty(t, item.Title, "item %s: title is required", item.Slug)
+		assert.True(t, rarities[db.ItemType(item.Rarity)],
+			"item %s: unknown rarity %q", item.Slug, item.Rarity)
+		assert.True(t, slots[db.ItemSlot(item.Slot)],
+			"item %s: unknown slot %q", item.Slug, item.Slot)
+
+		// Unpriced items are allowed, they just cannot be sold
+		if item.Price != nil {
+			assert.Greater(t, *item.Price, int32(0), "item %s: price must be positive", item.Slug)
+		}
+
+		if item.Effect != nil {
+			assert.True(t, effects[db.ItemEffect(item.Effect.Type)],
+				"item %s: unknown effect %q", item.Slug, item.Effect.Type)
+			assert.Greater(t, item.Effect.Value, int32(0),
+				"item %s: effect value must be positive", item.Slug)
+			assert.Greater(t, item.Effect.DurationSeconds, int32(0),
+				"item %s: effect duration must be positive", item.Slug)
+		}
+	}
+}
+
+func TestItemsSlugsUnique(t *testing.T) {
+	items, err := loadItems()
+	require.NoError(t, err)
+
+	// Slugs are the natural key the seeder upserts by, so duplicates would overwrite each other
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		assert.False(t, seen[item.Slug], "item slug %s is duplicated", item.Slug)
+		seen[item.Slug] = true
+	}
+}
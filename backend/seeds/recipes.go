@@ -0,0 +1,96 @@
+package seeds
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	db "goalify/internal/db/generated"
+)
+
+//go:embed data/recipes.json
+var recipesJSON []byte
+
+// RecipeSeed represents a single crafting recipe in the JSON file. Chest is the slug of the chest
+// whose drop table the output is rolled from, limited to items of OutputRarity.
+type RecipeSeed struct {
+	Slug         string `json:"slug"`
+	Chest        string `json:"chest"`
+	InputRarity  string `json:"input_rarity"`
+	OutputRarity string `json:"output_rarity"`
+	InputCount   int32  `json:"input_count"`
+}
+
+// loadRecipes parses the embedded recipes.json file.
+func loadRecipes() ([]RecipeSeed, error) {
+	var recipes []RecipeSeed
+	if err := json.Unmarshal(recipesJSON, &recipes); err != nil {
+		return nil, fmt.Errorf("parse recipes.json: %w", err)
+	}
+	return recipes, nil
+}
+
+// SeedRecipes upserts all crafting recipes from recipes.json into the database by slug. Must run
+// after SeedChests, since recipes reference chests by slug.
+// Uses a transaction to ensure atomicity - all recipes are seeded or none are.
+func SeedRecipes(ctx context.Context, pool *pgxpool.Pool) error {
+	recipes, err := loadRecipes()
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	queries := db.New(tx)
+
+	slugs := make([]string, len(recipes))
+	for i, recipe := range recipes {
+		slugs[i] = recipe.Chest
+	}
+	chests, err := queries.GetChestsBySlugs(ctx, slugs)
+	if err != nil {
+		return fmt.Errorf("get chests by slug: %w", err)
+	}
+	bySlug := make(map[string]db.Chest, len(chests))
+	for _, chest := range chests {
+		bySlug[chest.Slug.String] = chest
+	}
+
+	for _, recipe := range recipes {
+		chest, ok := bySlug[recipe.Chest]
+		if !ok {
+			return fmt.Errorf("recipe %s: unknown chest %s", recipe.Slug, recipe.Chest)
+		}
+
+		_, err := queries.UpsertCraftingRecipeBySlug(ctx, db.UpsertCraftingRecipeBySlugParams{
+			Slug:         recipe.Slug,
+			ChestID:      chest.ID,
+			InputRarity:  db.ItemType(recipe.InputRarity),
+			InputCount:   recipe.InputCount,
+			OutputRarity: db.ItemType(recipe.OutputRarity),
+		})
+		if err != nil {
+			return fmt.Errorf("upsert recipe %s: %w", recipe.Slug, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package seeds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "goalify/internal/db/generated"
+)
+
+// the order rarities climb in, so a recipe always trades up
+var rarityRank = map[db.ItemType]int{
+	db.ItemTypeCommon:    0,
+	db.ItemTypeRare:      1,
+	db.ItemTypeEpic:      2,
+	db.ItemTypeLegendary: 3,
+}
+
+func TestRecipesJSONValid(t *testing.T) {
+	recipes, err := loadRecipes()
+	require.NoError(t, err, "recipes.json should parse successfully")
+	require.NotEmpty(t, recipes, "recipes.json should contain at least one recipe")
+
+	seen := make(map[string]bool, len(recipes))
+	for _, recipe := range recipes {
+		assert.NotEmpty(t, recipe.Slug, "recipe slugs are required")
+		assert.False(t, seen[recipe.Slug], "recipe slug %s is duplicated", recipe.Slug)
+		seen[recipe.Slug] = true
+
+		input, ok := rarityRank[db.ItemType(recipe.InputRarity)]
+		assert.True(t, ok, "recipe %s: unknown input rarity %q", recipe.Slug, recipe.InputRarity)
+		output, ok := rarityRank[db.ItemType(recipe.OutputRarity)]
+		assert.True(t, ok, "recipe %s: unknown output rarity %q", recipe.Slug, recipe.OutputRarity)
+		assert.Greater(t, output, input, "recipe %s: output must be rarer than input", recipe.Slug)
+		assert.Greater(t, recipe.InputCount, int32(0),
+			"recipe %s: input count must be positive", recipe.Slug)
+	}
+}
+
+func TestRecipesOutputFromChestPool(t *testing.T) {
+	recipes, err := loadRecipes()
+	require.NoError(t, err)
+	chests, err := loadChests()
+	require.NoError(t, err)
+	items, err := loadItems()
+	require.NoError(t, err)
+
+	rarities := make(map[string]string, len(items))
+	for _, item := range items {
+		rarities[item.Slug] = item.Rarity
+	}
+	pools := make(map[string]map[string]bool, len(chests))
+	for _, chest := range chests {
+		pools[chest.Slug] = make(map[string]bool)
+		for _, rate := range chest.DropRates {
+			pools[chest.Slug][rarities[rate.Item]] = true
+		}
+	}
+
+	// A recipe whose chest cannot drop its output rarity could never be crafted
+	for _, recipe := range recipes {
+		pool, ok := pools[recipe.Chest]
+		require.True(t, ok, "recipe %s: unknown chest %s", recipe.Slug, recipe.Chest)
+		assert.True(t, pool[recipe.OutputRarity],
+			"recipe %s: chest %s drops no %s items", recipe.Slug, recipe.Chest, recipe.OutputRarity)
+	}
+}
@@ -18,6 +18,9 @@ func Run(ctx context.Context, pool *pgxpool.Pool) error {
 		name string
 	}{
 		{SeedLevels, "levels"},
+		{SeedItems, "items"},
+		{SeedChests, "chests"},
+		{SeedRecipes, "recipes"},
 	}
 
 	for _, s := range seeders {
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* Avatar Tests
+* Testing Resource: /api/users/{userId}/avatar.svg
+ */
+
+func getAvatar(t *testing.T, url, etag string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.Nil(t, err)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	return res
+}
+
+func TestGetAvatar(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	url := fmt.Sprintf("%s/api/users/%s/avatar.svg", BaseURL, user.ID)
+
+	res := getAvatar(t, url, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "image/svg+xml", res.Header.Get("Content-Type"))
+	body, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(body), "<svg"))
+	etag := res.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	res = getAvatar(t, url, etag)
+	assert.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	// equipping an item changes the avatar, so the old ETag no longer matches
+	userItemID := createTestUserItem(user.ID, t.Name(), "rare", "head")
+	equipURL := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, userItemID)
+	res, err = buildAndSendRequest("POST", equipURL, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = getAvatar(t, url, etag)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.NotEqual(t, etag, res.Header.Get("ETag"))
+}
+
+func TestGetAvatarPNG(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	url := fmt.Sprintf("%s/api/users/%s/avatar.svg?format=png&size=64", BaseURL, user.ID)
+
+	res := getAvatar(t, url, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "image/png", res.Header.Get("Content-Type"))
+	body, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	img, err := png.Decode(bytes.NewReader(body))
+	require.Nil(t, err)
+	assert.Equal(t, 64, img.Bounds().Dx())
+}
+
+func TestGetAvatarValidation(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{name: "invalid user id", path: "not-a-uuid/avatar.svg", expected: http.StatusBadRequest},
+		{
+			name:     "unknown user",
+			path:     uuid.New().String() + "/avatar.svg",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "unknown format",
+			path:     user.ID.String() + "/avatar.svg?format=gif",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "size too large",
+			path:     user.ID.String() + "/avatar.svg?size=4096",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "malformed size",
+			path:     user.ID.String() + "/avatar.svg?size=big",
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := getAvatar(t, fmt.Sprintf("%s/api/users/%s", BaseURL, tt.path), "")
+			assert.Equal(t, tt.expected, res.StatusCode)
+		})
+	}
+}
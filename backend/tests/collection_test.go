@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"fmt"
+	"goalify/internal/config"
+	"goalify/internal/entities"
+	"goalify/internal/responses"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* Collection Tests
+* Testing Resource: /api/users/me/collection
+ */
+
+func getTestCollection(t *testing.T, token string) *entities.Collection {
+	t.Helper()
+	url := fmt.Sprintf("%s/api/users/me/collection", BaseURL)
+	res, err := buildAndSendRequest("GET", url, nil, token)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.Collection]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectCollection, resBody.Object)
+	return resBody.Data
+}
+
+func findChestCollection(
+	t *testing.T,
+	collection *entities.Collection,
+	chestID uuid.UUID,
+) *entities.ChestCollection {
+	t.Helper()
+	for _, chest := range collection.Chests {
+		if chest.ChestID == chestID {
+			return chest
+		}
+	}
+	require.FailNow(t, "chest missing from collection", chestID)
+	return nil
+}
+
+func TestGetCollection(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("bronze", t.Name(), 100)
+	commonID := createTestChestItem(t.Name()+"-common", "common", chest.ID, 0.75)
+	createTestChestItem(t.Name()+"-rare", "rare", chest.ID, 0.25)
+	giveTestUserItem(user.ID, commonID)
+	giveTestUserItem(user.ID, commonID)
+
+	collection := getTestCollection(t, user.AccessToken)
+	assert.Equal(t, config.DefaultCollectionBonus, collection.CompletionBonus)
+	assert.Equal(t, 1, collection.Overall.Discovered)
+	assert.GreaterOrEqual(t, collection.Overall.Total, 2)
+	require.Contains(t, collection.ByRarity, "common")
+	assert.Equal(t, 1, collection.ByRarity["common"].Discovered)
+
+	var entry *entities.CollectionEntry
+	for _, e := range collection.Items {
+		if e.Item.ID == commonID {
+			entry = e
+		}
+	}
+	require.NotNil(t, entry)
+	assert.True(t, entry.Discovered)
+	assert.True(t, entry.FirstObtainedAt.IsPresent())
+	assert.Equal(t, 2, entry.CopiesOwned)
+
+	progress := findChestCollection(t, collection, chest.ID)
+	assert.Equal(t, 1, progress.Progress.Discovered)
+	assert.Equal(t, 2, progress.Progress.Total)
+	assert.Equal(t, 50.0, progress.Progress.Percent)
+	assert.False(t, progress.Complete)
+}
+
+func TestClaimCollectionBonus(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("bronze", t.Name(), 100)
+	commonID := createTestChestItem(t.Name()+"-common", "common", chest.ID, 0.75)
+	rareID := createTestChestItem(t.Name()+"-rare", "rare", chest.ID, 0.25)
+	giveTestUserItem(user.ID, commonID)
+
+	claimURL := fmt.Sprintf("%s/api/users/me/collection/chests/%s/claim", BaseURL, chest.ID)
+	res, err := buildAndSendRequest("POST", claimURL, nil, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	giveTestUserItem(user.ID, rareID)
+	res, err = buildAndSendRequest("POST", claimURL, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.CollectionBonus]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectCollectionBonus, resBody.Object)
+	assert.Equal(t, config.DefaultCollectionBonus, resBody.Data.CashGranted)
+	assert.Equal(t, config.DefaultCollectionBonus, resBody.Data.CashAvailable)
+
+	res, err = buildAndSendRequest("POST", claimURL, nil, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	dbUser, err := getUserByID(user.ID.String())
+	require.Nil(t, err)
+	assert.Equal(t, config.DefaultCollectionBonus, dbUser.CashAvailable)
+
+	progress := findChestCollection(t, getTestCollection(t, user.AccessToken), chest.ID)
+	assert.True(t, progress.Complete)
+	assert.True(t, progress.BonusClaimed)
+	assert.Equal(t, 100.0, progress.Progress.Percent)
+}
+
+func TestClaimCollectionBonusValidation(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+
+	url := fmt.Sprintf("%s/api/users/me/collection/chests/not-a-uuid/claim", BaseURL)
+	res, err := buildAndSendRequest("POST", url, nil, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	url = fmt.Sprintf("%s/api/users/me/collection/chests/%s/claim", BaseURL, uuid.New())
+	res, err = buildAndSendRequest("POST", url, nil, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
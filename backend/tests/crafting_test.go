@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/responses"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* Crafting Tests
+* Testing Resource: /api/crafting
+ */
+
+func TestGetCraftingRecipes(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("bronze", t.Name(), 100)
+	recipeID := createTestRecipe(t.Name(), chest.ID, "common", "rare", 3)
+
+	url := fmt.Sprintf("%s/api/crafting/recipes", BaseURL)
+	res, err := buildAndSendRequest("GET", url, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.CraftingRecipe]](res)
+	require.Nil(t, err)
+	var recipe *entities.CraftingRecipe
+	for _, r := range resBody.Data {
+		if r.ID == recipeID {
+			recipe = r
+		}
+	}
+	require.NotNil(t, recipe)
+	assert.Equal(t, chest.ID, recipe.ChestID)
+	assert.Equal(t, "common", recipe.InputRarity)
+	assert.Equal(t, "rare", recipe.OutputRarity)
+	assert.Equal(t, 3, recipe.InputCount)
+}
+
+func TestCraft(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("bronze", t.Name(), 100)
+	commonID := createTestChestItem(t.Name()+"-common", "common", chest.ID, 0.9)
+	rareID := createTestChestItem(t.Name()+"-rare", "rare", chest.ID, 0.1)
+	recipeID := createTestRecipe(t.Name(), chest.ID, "common", "rare", 3)
+
+	inputs := make([]string, 3)
+	for i := range inputs {
+		inputs[i] = giveTestUserItem(user.ID, commonID).String()
+	}
+
+	url := fmt.Sprintf("%s/api/crafting/%s", BaseURL, recipeID)
+	body := map[string]any{"user_item_ids": inputs[:2]}
+	res, err := buildAndSendRequest("POST", url, body, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	body = map[string]any{"user_item_ids": inputs}
+	res, err = buildAndSendRequest("POST", url, body, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.Craft]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectCraft, resBody.Object)
+	assert.Equal(t, recipeID, resBody.Data.RecipeID)
+	assert.Equal(t, rareID, resBody.Data.Item.ID)
+	assert.Len(t, resBody.Data.ConsumedItems, 3)
+
+	// the inputs are gone, so the same craft cannot be made twice
+	res, err = buildAndSendRequest("POST", url, body, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	itemsURL := fmt.Sprintf("%s/api/users/me/items", BaseURL)
+	res, err = buildAndSendRequest("GET", itemsURL, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	items, err := unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	require.Len(t, items.Data, 1)
+	assert.Equal(t, resBody.Data.UserItem.ID, items.Data[0].ID)
+}
+
+func TestCraftRefusesEquippedItems(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("bronze", t.Name(), 100)
+	commonID := createTestChestItem(t.Name()+"-common", "common", chest.ID, 0.9)
+	createTestChestItem(t.Name()+"-rare", "rare", chest.ID, 0.1)
+	recipeID := createTestRecipe(t.Name(), chest.ID, "common", "rare", 2)
+	equipped := giveTestUserItem(user.ID, commonID)
+	spare := giveTestUserItem(user.ID, commonID)
+
+	equipURL := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, equipped)
+	res, err := buildAndSendRequest("POST", equipURL, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	url := fmt.Sprintf("%s/api/crafting/%s", BaseURL, recipeID)
+	body := map[string]any{"user_item_ids": []string{equipped.String(), spare.String()}}
+	res, err = buildAndSendRequest("POST", url, body, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestCraftValidation(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	itemID := uuid.New().String()
+
+	tests := []struct {
+		name     string
+		recipe   string
+		body     map[string]any
+		expected int
+	}{
+		{
+			name:     "invalid recipe id",
+			recipe:   "not-a-uuid",
+			body:     map[string]any{"user_item_ids": []string{itemID}},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "no items",
+			recipe:   uuid.New().String(),
+			body:     map[string]any{"user_item_ids": []string{}},
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "invalid item id",
+			recipe:   uuid.New().String(),
+			body:     map[string]any{"user_item_ids": []string{"not-a-uuid"}},
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "duplicate item",
+			recipe:   uuid.New().String(),
+			body:     map[string]any{"user_item_ids": []string{itemID, itemID}},
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "unknown recipe",
+			recipe:   uuid.New().String(),
+			body:     map[string]any{"user_item_ids": []string{itemID}},
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("%s/api/crafting/%s", BaseURL, tt.recipe)
+			res, err := buildAndSendRequest("POST", url, tt.body, user.AccessToken)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/responses"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sqlcdb "goalify/internal/db/generated"
+)
+
+/* Item Effect Tests
+* Testing Resource: /api/users/me/items/{userItemId}/consume and /api/users/me/effects
+ */
+
+func TestConsumeXpMultiplier(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	potionID := createTestConsumable(t.Name(), sqlcdb.ItemEffectXpMultiplier, 2)
+	userItemID := giveTestUserItem(userDto.ID, potionID)
+
+	equipURL := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, userItemID)
+	res, err := buildAndSendRequest("POST", equipURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	consumeURL := fmt.Sprintf("%s/api/users/me/items/%s/consume", BaseURL, userItemID)
+	res, err = buildAndSendRequest("POST", consumeURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	consumption, err := unmarshalResponse[responses.ServerResponse[*entities.ItemConsumption]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectItemConsumption, consumption.Object)
+	assert.Equal(t, "xp_multiplier", consumption.Data.Effect.Effect)
+	assert.Equal(t, 2, consumption.Data.Effect.Value)
+
+	res, err = buildAndSendRequest("POST", consumeURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = buildAndSendRequest(
+		"GET",
+		BaseURL+"/api/users/me/effects",
+		nil,
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	effects, err := unmarshalResponse[responses.ServerResponse[[]*entities.ActiveEffect]](res)
+	require.Nil(t, err)
+	require.Len(t, effects.Data, 1)
+
+	// completing a goal while boosted grants double XP
+	cat := createTestGoalCategory("boosted category", userDto.ID)
+	goal := createTestGoal("boosted goal", "desc", cat.ID, userDto.ID)
+	url := fmt.Sprintf("%s/api/goals/%s", BaseURL, goal.ID)
+	body := map[string]any{"status": "complete"}
+	res, err = buildAndSendRequest("PUT", url, body, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var user *entities.User
+	for range 10 {
+		user, err = getUserByID(userDto.ID.String())
+		require.Nil(t, err)
+		if user.Xp == 2 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Equal(t, 2, user.Xp)
+}
+
+func TestConsumeNonConsumable(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	userItemID := createTestUserItem(userDto.ID, t.Name(), "common", "head")
+
+	url := fmt.Sprintf("%s/api/users/me/items/%s/consume", BaseURL, userItemID)
+	res, err := buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/responses"
+	"goalify/pkg/options"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* Gift Tests
+* Testing Resource: /api/gifts
+ */
+
+func TestGiftChestsByEmail(t *testing.T) {
+	t.Parallel()
+
+	sender := createUser(t.Name()+"@mail.com", "password123!")
+	recipientEmail := t.Name() + "-recipient@mail.com"
+	recipient := createUser(recipientEmail, "password123!")
+	setUserCash(sender.ID, 200)
+	chest := createTestChest("bronze", t.Name(), 100)
+
+	purchaseURL := fmt.Sprintf("%s/api/chests/%s/purchase", BaseURL, chest.ID)
+	for range 2 {
+		res, err := buildAndSendRequest("POST", purchaseURL, nil, sender.AccessToken)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	giftsURL := fmt.Sprintf("%s/api/gifts", BaseURL)
+	body := map[string]any{
+		"recipient_email": recipientEmail,
+		"chest_id":        chest.ID.String(),
+		"quantity":        2,
+	}
+	res, err := buildAndSendRequest("POST", giftsURL, body, sender.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.Gift]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectGift, resBody.Object)
+	assert.Equal(t, "pending", resBody.Data.Status)
+	assert.Equal(t, recipient.ID, resBody.Data.RecipientID)
+	assert.Equal(t, options.Some(2), resBody.Data.Quantity)
+	giftID := resBody.Data.ID
+
+	// the sender has nothing left to gift
+	res, err = buildAndSendRequest("POST", giftsURL, body, sender.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = buildAndSendRequest("GET", giftsURL+"?status=pending", nil, recipient.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	listBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.Gift]](res)
+	require.Nil(t, err)
+	require.Len(t, listBody.Data, 1)
+	assert.Equal(t, giftID, listBody.Data[0].ID)
+
+	acceptURL := fmt.Sprintf("%s/%s/accept", giftsURL, giftID)
+	res, err = buildAndSendRequest("POST", acceptURL, nil, sender.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = buildAndSendRequest("POST", acceptURL, nil, recipient.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	resBody, err = unmarshalResponse[responses.ServerResponse[*entities.Gift]](res)
+	require.Nil(t, err)
+	assert.Equal(t, "accepted", resBody.Data.Status)
+
+	// the recipient now owns both chests and can open them
+	createTestChestItem(t.Name(), "common", chest.ID, 1)
+	openURL := fmt.Sprintf("%s/api/users/me/chests/%s/open", BaseURL, chest.ID)
+	res, err = buildAndSendRequest("POST", openURL, nil, recipient.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	openBody, err := unmarshalResponse[responses.ServerResponse[*entities.ChestOpening]](res)
+	require.Nil(t, err)
+	assert.Equal(t, 1, openBody.Data.QuantityRemaining)
+
+	// resolving twice is not possible
+	res, err = buildAndSendRequest("POST", acceptURL, nil, recipient.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestDeclineGiftedItem(t *testing.T) {
+	t.Parallel()
+
+	sender := createUser(t.Name()+"@mail.com", "password123!")
+	recipient := createUser(t.Name()+"-recipient@mail.com", "password123!")
+	userItemID := createTestUserItem(sender.ID, t.Name(), "epic", "head")
+
+	giftsURL := fmt.Sprintf("%s/api/gifts", BaseURL)
+	body := map[string]any{
+		"recipient_id": recipient.ID.String(),
+		"user_item_id": userItemID.String(),
+	}
+	res, err := buildAndSendRequest("POST", giftsURL, body, sender.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.Gift]](res)
+	require.Nil(t, err)
+
+	// the gifted item cannot be sold while the gift is pending
+	sellURL := fmt.Sprintf("%s/api/users/me/items/%s/sell", BaseURL, userItemID)
+	res, err = buildAndSendRequest("POST", sellURL, nil, sender.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	declineURL := fmt.Sprintf("%s/%s/decline", giftsURL, resBody.Data.ID)
+	res, err = buildAndSendRequest("POST", declineURL, nil, recipient.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	itemsURL := fmt.Sprintf("%s/api/users/me/items", BaseURL)
+	res, err = buildAndSendRequest("GET", itemsURL, nil, sender.AccessToken)
+	require.Nil(t, err)
+	itemsBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	require.Len(t, itemsBody.Data, 1)
+	assert.Equal(t, userItemID, itemsBody.Data[0].ID)
+
+	res, err = buildAndSendRequest("GET", itemsURL, nil, recipient.AccessToken)
+	require.Nil(t, err)
+	itemsBody, err = unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	assert.Empty(t, itemsBody.Data)
+}
+
+func TestSendGiftValidation(t *testing.T) {
+	t.Parallel()
+
+	sender := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("bronze", t.Name(), 100)
+	giftsURL := fmt.Sprintf("%s/api/gifts", BaseURL)
+
+	invalid := []map[string]any{
+		{"chest_id": chest.ID.String(), "quantity": 1},
+		{"recipient_email": "someone@mail.com", "recipient_id": sender.ID.String()},
+		{"recipient_email": "someone@mail.com", "chest_id": chest.ID.String()},
+		{"recipient_email": "someone@mail.com", "chest_id": "not-a-uuid", "quantity": 1},
+	}
+	for _, body := range invalid {
+		res, err := buildAndSendRequest("POST", giftsURL, body, sender.AccessToken)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode, body)
+	}
+
+	body := map[string]any{
+		"recipient_email": t.Name() + "-nobody@mail.com",
+		"chest_id":        chest.ID.String(),
+		"quantity":        1,
+	}
+	res, err := buildAndSendRequest("POST", giftsURL, body, sender.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = buildAndSendRequest("GET", giftsURL+"?direction=sideways", nil, sender.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
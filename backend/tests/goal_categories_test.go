@@ -231,3 +231,128 @@ func TestCategoryNotFoundOnForbiddenAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestGoalCategoryResetSchedule(t *testing.T) {
+	t.Parallel()
+
+	email := t.Name() + "@mail.com"
+	userDto := createUser(email, "password123!")
+	url := fmt.Sprintf("%s/api/goals/categories", BaseURL)
+
+	reqBody := map[string]any{
+		"title": "weekly review",
+		"reset": map[string]any{"policy": "weekly", "time": "18:00", "weekday": "Sunday"},
+	}
+	res, err := buildAndSendRequest("POST", url, reqBody, userDto.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	gc, err := unmarshalResponse[entities.GoalCategory](res)
+	assert.Nil(t, err)
+	assert.Equal(t, "weekly", gc.Reset.Policy)
+	assert.Equal(t, "18:00", gc.Reset.Time)
+	assert.Equal(t, "sunday", gc.Reset.Weekday.ValueOrZero())
+
+	// switching to daily drops the weekday and defaults to midnight
+	reqBody = map[string]any{"reset": map[string]any{"policy": "daily"}}
+	res, err = buildAndSendRequest(
+		"PUT",
+		fmt.Sprintf("%s/%s", url, gc.ID),
+		reqBody,
+		userDto.AccessToken,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	gc, err = unmarshalResponse[entities.GoalCategory](res)
+	assert.Nil(t, err)
+	assert.Equal(t, "weekly review", gc.Title)
+	assert.Equal(t, "daily", gc.Reset.Policy)
+	assert.Equal(t, "00:00", gc.Reset.Time)
+	assert.False(t, gc.Reset.Weekday.IsPresent())
+
+	invalid := []map[string]any{
+		{"policy": "hourly"},
+		{"policy": "weekly"},
+		{"policy": "daily", "weekday": "monday"},
+		{"policy": "daily", "time": "25:00"},
+	}
+	for _, reset := range invalid {
+		reqBody = map[string]any{"title": "invalid", "reset": reset}
+		res, err = buildAndSendRequest("POST", url, reqBody, userDto.AccessToken)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode, reset)
+	}
+}
+
+func TestDefaultCategoryResetsDaily(t *testing.T) {
+	t.Parallel()
+
+	email := t.Name() + "@mail.com"
+	userDto := createUser(email, "password123!")
+
+	res, err := buildAndSendRequest(
+		"GET",
+		fmt.Sprintf("%s/api/goals/categories", BaseURL),
+		nil,
+		userDto.AccessToken,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	resBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.GoalCategory]](res)
+	assert.Nil(t, err)
+
+	// the default category from the user_created event
+	assert.Equal(t, 1, len(resBody.Data))
+	assert.Equal(t, "Daily", resBody.Data[0].Title)
+	assert.Equal(t, "daily", resBody.Data[0].Reset.Policy)
+	assert.Equal(t, "00:00", resBody.Data[0].Reset.Time)
+}
+
+func TestDailyCategoryStreak(t *testing.T) {
+	t.Parallel()
+
+	email := t.Name() + "@mail.com"
+	userDto := createUser(email, "password123!")
+	url := fmt.Sprintf("%s/api/goals/categories", BaseURL)
+
+	reqBody := map[string]any{
+		"title": "habits",
+		"reset": map[string]any{"policy": "daily", "time": "00:00"},
+	}
+	res, err := buildAndSendRequest("POST", url, reqBody, userDto.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	gc, err := unmarshalResponse[entities.GoalCategory](res)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, gc.Streak.Current)
+	assert.False(t, gc.Streak.LastCompletedDate.IsPresent())
+
+	goal := createTestGoal("stretch", "desc", gc.ID, userDto.ID)
+	res, err = buildAndSendRequest(
+		"PUT",
+		fmt.Sprintf("%s/api/goals/%s", BaseURL, goal.ID),
+		map[string]any{"status": "complete"},
+		userDto.AccessToken,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	updated, err := unmarshalResponse[entities.Goal](res)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, updated.Streak.Current)
+	assert.Equal(t, 1, updated.Streak.Longest)
+	assert.True(t, updated.Streak.LastCompletedDate.IsPresent())
+
+	res, err = buildAndSendRequest(
+		"GET",
+		fmt.Sprintf("%s/%s", url, gc.ID),
+		nil,
+		userDto.AccessToken,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	gc, err = unmarshalResponse[entities.GoalCategory](res)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, gc.Streak.Current)
+	assert.Equal(t, updated.Streak.LastCompletedDate, gc.Streak.LastCompletedDate)
+	assert.Equal(t, 1, len(gc.Goals))
+	assert.Equal(t, updated.Streak, gc.Goals[0].Streak)
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"goalify/internal/entities"
 	"goalify/internal/responses"
@@ -204,6 +205,8 @@ func TestUserLevelUpEvent(t *testing.T) {
 		time.Sleep(50 * time.Millisecond)
 	}
 	assert.Equal(t, 2, user.LevelID)
+	// level 1's cash reward is paid on reaching level 2
+	assert.Equal(t, 100, user.CashAvailable)
 }
 
 func TestUserCreatedEvent(t *testing.T) {
@@ -318,3 +321,364 @@ func TestGoalNotFoundOnForbiddenAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateRecurringGoal(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	cat := createTestGoalCategory("recurring goals", userDto.ID)
+	reqBody := map[string]any{
+		"title":       "gym",
+		"description": "lift things",
+		"category_id": cat.ID,
+		"recurrence":  map[string]any{"type": "weekdays", "weekdays": []string{"friday", "monday"}},
+	}
+	res, err := buildAndSendRequest("POST", BaseURL+"/api/goals", reqBody, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	goal, err := unmarshalResponse[entities.Goal](res)
+	require.Nil(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,FR", goal.Recurrence.ValueOrZero())
+	assert.True(t, goal.OccurrenceDate.IsPresent())
+	assert.True(t, goal.NextOccurrenceDate.IsPresent())
+
+	url := fmt.Sprintf("%s/api/goals/%s", BaseURL, goal.ID)
+	reqBody = map[string]any{"recurrence": map[string]any{"type": "rrule", "rrule": "FREQ=DAILY"}}
+	res, err = buildAndSendRequest("PUT", url, reqBody, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	goal, err = unmarshalResponse[entities.Goal](res)
+	require.Nil(t, err)
+	assert.Equal(t, "FREQ=DAILY", goal.Recurrence.ValueOrZero())
+
+	reqBody = map[string]any{"recurrence": map[string]any{"type": "none"}}
+	res, err = buildAndSendRequest("PUT", url, reqBody, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	goal, err = unmarshalResponse[entities.Goal](res)
+	require.Nil(t, err)
+	assert.False(t, goal.Recurrence.IsPresent())
+	assert.False(t, goal.OccurrenceDate.IsPresent())
+}
+
+func TestCreateRecurringGoalInvalid(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	cat := createTestGoalCategory("recurring goals", userDto.ID)
+	tests := []struct {
+		name       string
+		recurrence map[string]any
+	}{
+		{"unknown type", map[string]any{"type": "hourly"}},
+		{"none", map[string]any{"type": "none"}},
+		{"no weekdays", map[string]any{"type": "weekdays"}},
+		{"bad weekday", map[string]any{"type": "weekdays", "weekdays": []string{"someday"}}},
+		{"zero interval", map[string]any{"type": "every_n_days", "interval": 0}},
+		{"bad month day", map[string]any{"type": "monthly", "day": 32}},
+		{"unsupported rrule", map[string]any{"type": "rrule", "rrule": "FREQ=YEARLY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody := map[string]any{
+				"title":       "goal",
+				"description": "desc",
+				"category_id": cat.ID,
+				"recurrence":  tt.recurrence,
+			}
+			url := BaseURL + "/api/goals"
+			res, err := buildAndSendRequest("POST", url, reqBody, userDto.AccessToken)
+			require.Nil(t, err)
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		})
+	}
+}
+
+func TestRecurringGoalCompletesEachOccurrence(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	cat := createTestGoalCategory("recurring goals", userDto.ID)
+	reqBody := map[string]any{
+		"title":       "read",
+		"description": "ten pages",
+		"category_id": cat.ID,
+		"recurrence":  map[string]any{"type": "daily"},
+	}
+	res, err := buildAndSendRequest("POST", BaseURL+"/api/goals", reqBody, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	goal, err := unmarshalResponse[entities.Goal](res)
+	require.Nil(t, err)
+
+	url := fmt.Sprintf("%s/api/goals/%s", BaseURL, goal.ID)
+	complete := map[string]any{"status": "complete"}
+	res, err = buildAndSendRequest("PUT", url, complete, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// pretend that was yesterday's occurrence
+	_, err = pgxPool.Exec(context.Background(), `
+		UPDATE goals
+		SET recurrence_start = recurrence_start - 1,
+			occurrence_date = occurrence_date - 1,
+			next_occurrence_date = occurrence_date
+		WHERE id = $1`, goal.ID)
+	require.Nil(t, err)
+	_, err = pgxPool.Exec(context.Background(), `
+		UPDATE goal_completions
+		SET occurrence_date = occurrence_date - 1, period_date = period_date - 1
+		WHERE goal_id = $1`, goal.ID)
+	require.Nil(t, err)
+
+	// today's occurrence is open again and completing it counts
+	res, err = buildAndSendRequest("PUT", url, complete, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	updated, err := unmarshalResponse[entities.Goal](res)
+	require.Nil(t, err)
+	assert.Equal(t, "complete", updated.Status)
+	assert.Equal(t, goal.OccurrenceDate, updated.OccurrenceDate)
+
+	var user *entities.User
+	for range 10 {
+		user, err = getUserByID(userDto.ID.String())
+		require.Nil(t, err)
+		if user.Xp == 2 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Equal(t, 2, user.Xp)
+}
+
+func TestGoalCompletionHistory(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	cat := createTestGoalCategory("history", userDto.ID)
+	goal := createTestGoal("stretch", "five minutes", cat.ID, userDto.ID)
+	since := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+
+	url := fmt.Sprintf("%s/api/goals/%s", BaseURL, goal.ID)
+	complete := map[string]any{"status": "complete"}
+	res, err := buildAndSendRequest("PUT", url, complete, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// a reset reopens the goal without touching its history
+	res, err = buildAndSendRequest(
+		"POST",
+		fmt.Sprintf("%s/api/goals/categories/%s/reset", BaseURL, cat.ID),
+		nil,
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	res, err = buildAndSendRequest("PUT", url, complete, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, err = buildAndSendRequest(
+		"PUT",
+		url,
+		map[string]any{"status": "not_complete"},
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var completions []*entities.GoalCompletion
+	for range 10 {
+		res, err = buildAndSendRequest("GET", url+"/completions", nil, userDto.AccessToken)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		resBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.GoalCompletion]](
+			res,
+		)
+		require.Nil(t, err)
+		completions = resBody.Data
+		if len(completions) == 2 && completions[1].XpGranted > 0 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	require.Len(t, completions, 2)
+	assert.True(t, completions[0].UncompletedAt.IsPresent())
+	assert.False(t, completions[1].UncompletedAt.IsPresent())
+	assert.Equal(t, 1, completions[1].XpGranted)
+	assert.Equal(t, cat.ID, completions[1].CategoryID.ValueOrZero())
+
+	res, err = buildAndSendRequest(
+		"GET",
+		BaseURL+"/api/completions?limit=1&from="+since,
+		nil,
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	page, err := unmarshalResponse[responses.ServerResponse[[]*entities.GoalCompletion]](res)
+	require.Nil(t, err)
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, completions[0].ID, page.Data[0].ID)
+	require.NotNil(t, page.HasMore)
+	assert.True(t, *page.HasMore)
+
+	res, err = buildAndSendRequest(
+		"GET",
+		BaseURL+"/api/completions?cursor="+*page.NextPage+"&from="+since,
+		nil,
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	page, err = unmarshalResponse[responses.ServerResponse[[]*entities.GoalCompletion]](res)
+	require.Nil(t, err)
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, completions[1].ID, page.Data[0].ID)
+
+	// nothing was completed before the test started
+	res, err = buildAndSendRequest(
+		"GET",
+		BaseURL+"/api/completions?to="+since,
+		nil,
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	page, err = unmarshalResponse[responses.ServerResponse[[]*entities.GoalCompletion]](res)
+	require.Nil(t, err)
+	assert.Empty(t, page.Data)
+}
+
+/* XP Farming Tests
+* Goal events are handled in order, so once a later completion's XP shows up every earlier
+* grant and reversal has been applied
+ */
+
+func setGoalStatus(t *testing.T, goalID uuid.UUID, status, accessToken string) {
+	url := fmt.Sprintf("%s/api/goals/%s", BaseURL, goalID)
+	reqBody := map[string]any{"status": status}
+	res, err := buildAndSendRequest("PUT", url, reqBody, accessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestToggleGoalStatusGrantsXpOnce(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	cat := createTestGoalCategory("farming", userDto.ID)
+	goal := createTestGoal("toggle", "desc", cat.ID, userDto.ID)
+	marker := createTestGoal("marker", "desc", cat.ID, userDto.ID)
+
+	for range 10 {
+		setGoalStatus(t, goal.ID, "complete", userDto.AccessToken)
+		setGoalStatus(t, goal.ID, "not_complete", userDto.AccessToken)
+	}
+	setGoalStatus(t, goal.ID, "complete", userDto.AccessToken)
+	setGoalStatus(t, marker.ID, "complete", userDto.AccessToken)
+
+	user := waitForUser(t, userDto.ID, func(u *entities.User) bool { return u.Xp == 2 })
+	assert.Equal(t, 2, user.Xp)
+	assert.Equal(t, 1, user.LevelID)
+	assert.Equal(t, 0, user.CashAvailable)
+
+	setGoalStatus(t, goal.ID, "not_complete", userDto.AccessToken)
+	user = waitForUser(t, userDto.ID, func(u *entities.User) bool { return u.Xp == 1 })
+	assert.Equal(t, 1, user.Xp)
+}
+
+func TestSecondCompletionInPeriodGrantsNothing(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	cat := createTestGoalCategory("farming", userDto.ID)
+	goal := createTestGoal("reset", "desc", cat.ID, userDto.ID)
+	marker := createTestGoal("marker", "desc", cat.ID, userDto.ID)
+
+	setGoalStatus(t, goal.ID, "complete", userDto.AccessToken)
+	// resetting reopens the goal without taking back its XP
+	res, err := buildAndSendRequest(
+		"POST",
+		fmt.Sprintf("%s/api/goals/categories/%s/reset", BaseURL, cat.ID),
+		nil,
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	setGoalStatus(t, goal.ID, "complete", userDto.AccessToken)
+	setGoalStatus(t, marker.ID, "complete", userDto.AccessToken)
+
+	user := waitForUser(t, userDto.ID, func(u *entities.User) bool { return u.Xp >= 2 })
+	assert.Equal(t, 2, user.Xp)
+
+	url := fmt.Sprintf("%s/api/goals/%s/completions", BaseURL, goal.ID)
+	res, err = buildAndSendRequest("GET", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	resBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.GoalCompletion]](res)
+	require.Nil(t, err)
+	require.Len(t, resBody.Data, 2)
+	assert.Equal(t, resBody.Data[0].PeriodDate, resBody.Data[1].PeriodDate)
+	assert.Equal(t, 0, resBody.Data[0].XpGranted)
+	assert.Equal(t, 1, resBody.Data[1].XpGranted)
+}
+
+func TestUncompleteGoalReversesLevelUp(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	cat := createTestGoalCategory("farming", userDto.ID)
+	goal := createTestGoal("level", "desc", cat.ID, userDto.ID)
+	// one XP short of level 2
+	_, err := pgxPool.Exec(
+		context.Background(),
+		"UPDATE users SET xp = 4 WHERE id = $1",
+		userDto.ID,
+	)
+	require.Nil(t, err)
+
+	setGoalStatus(t, goal.ID, "complete", userDto.AccessToken)
+	user := waitForUser(t, userDto.ID, func(u *entities.User) bool { return u.LevelID == 2 })
+	assert.Equal(t, 2, user.LevelID)
+	assert.Equal(t, 0, user.Xp)
+	assert.Equal(t, 100, user.CashAvailable)
+
+	setGoalStatus(t, goal.ID, "not_complete", userDto.AccessToken)
+	user = waitForUser(t, userDto.ID, func(u *entities.User) bool { return u.LevelID == 1 })
+	assert.Equal(t, 1, user.LevelID)
+	assert.Equal(t, 4, user.Xp)
+
+	// climbing back to level 2 doesn't pay its reward twice
+	setGoalStatus(t, goal.ID, "complete", userDto.AccessToken)
+	user = waitForUser(t, userDto.ID, func(u *entities.User) bool { return u.LevelID == 2 })
+	assert.Equal(t, 2, user.LevelID)
+	assert.Equal(t, 100, user.CashAvailable)
+}
+
+func TestGoalCompletionHistoryInvalid(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	otherDto := createUser(t.Name()+"other@mail.com", "password123!")
+	cat := createTestGoalCategory("history", otherDto.ID)
+	goal := createTestGoal("stretch", "five minutes", cat.ID, otherDto.ID)
+
+	tests := []struct {
+		url    string
+		status int
+	}{
+		{fmt.Sprintf("/api/goals/%s/completions", goal.ID), http.StatusNotFound},
+		{fmt.Sprintf("/api/goals/%s/history", goal.ID), http.StatusNotFound},
+		{"/api/goals/not-a-uuid/completions", http.StatusBadRequest},
+		{"/api/completions?from=yesterday", http.StatusBadRequest},
+		{
+			"/api/completions?from=2026-10-14T00:00:00Z&to=2026-10-13T00:00:00Z",
+			http.StatusBadRequest,
+		},
+		{"/api/completions?limit=0", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		res, err := buildAndSendRequest("GET", BaseURL+tt.url, nil, userDto.AccessToken)
+		require.Nil(t, err)
+		assert.Equal(t, tt.status, res.StatusCode, tt.url)
+	}
+}
@@ -4,15 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"goalify/internal/db"
 	"goalify/internal/entities"
 	"goalify/internal/users/handler"
 	"io"
 	"net/http"
+	"testing"
+	"time"
 
 	sqlcdb "goalify/internal/db/generated"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
 )
 
 // HTTP helpers
@@ -77,9 +81,10 @@ func getUserByID(id string) (*entities.User, error) {
 		ID:                 uuid.UUID(u.ID.Bytes),
 		Email:              u.Email,
 		Password:           u.Password,
+		Timezone:           u.Timezone,
 		Xp:                 int(u.Xp.Int32),
 		LevelID:            int(u.LevelID.Int32),
-		CashAvailable:      int(u.CashAvailable.Int32),
+		CashAvailable:      int(u.CashAvailable),
 		RefreshToken:       uuid.UUID(u.RefreshToken.Bytes),
 		RefreshTokenExpiry: u.RefreshTokenExpiry.Time,
 		CreatedAt:          u.CreatedAt.Time,
@@ -87,11 +92,62 @@ func getUserByID(id string) (*entities.User, error) {
 	}, nil
 }
 
+// waitForUser polls the user until done reports true, for effects of events handled in the
+// background, and returns the last read
+func waitForUser(t *testing.T, id uuid.UUID, done func(*entities.User) bool) *entities.User {
+	var user *entities.User
+	var err error
+	for range 20 {
+		user, err = getUserByID(id.String())
+		require.Nil(t, err)
+		if done(user) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return user
+}
+
+// setUserCash moves the user's balance to cash through the cash ledger, as an admin grant would
+func setUserCash(userID uuid.UUID, cash int) {
+	ctx := context.Background()
+	current, err := queries.GetUserCash(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = db.RecordCashTransaction(
+		ctx,
+		queries,
+		userID,
+		cash-int(current),
+		sqlcdb.CashTransactionReasonAdminGrant,
+		uuid.Nil,
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// admins are only granted through the database, so tests flip the flag directly
+func setUserAdmin(userID uuid.UUID) {
+	_, err := pgxPool.Exec(
+		context.Background(),
+		"UPDATE users SET is_admin = true WHERE id = $1",
+		pgtype.UUID{Bytes: userID, Valid: true},
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // Goal helpers
 func createTestGoalCategory(title string, userID uuid.UUID) *entities.GoalCategory {
 	params := sqlcdb.CreateGoalCategoryParams{
-		Title:  title,
-		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		Title:       title,
+		UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+		ResetPolicy: sqlcdb.CategoryResetPolicyNone,
+		ResetTime:   pgtype.Time{Valid: true},
 	}
 	gc, err := queries.CreateGoalCategory(context.Background(), params)
 	if err != nil {
@@ -129,3 +185,127 @@ func createTestGoal(title, description string, categoryID, userID uuid.UUID) *en
 		UpdatedAt:   g.UpdatedAt.Time,
 	}
 }
+
+// Loot helpers
+func createTestChest(chestType, description string, price int) *entities.Chest {
+	c, err := queries.CreateChest(context.Background(), sqlcdb.CreateChestParams{
+		Type:        sqlcdb.ChestType(chestType),
+		Description: description,
+		Price:       int32(price),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &entities.Chest{
+		ID:               uuid.UUID(c.ID.Bytes),
+		Type:             string(c.Type),
+		Description:      c.Description,
+		Price:            int(c.Price),
+		DropTableVersion: int(c.DropTableVersion),
+		CreatedAt:        c.CreatedAt.Time,
+		UpdatedAt:        c.UpdatedAt.Time,
+	}
+}
+
+func createTestChestItem(title, rarity string, chestID uuid.UUID, dropRate float64) uuid.UUID {
+	item, err := queries.CreateChestItem(context.Background(), sqlcdb.CreateChestItemParams{
+		Title:  title,
+		Rarity: sqlcdb.ItemType(rarity),
+		Slot:   sqlcdb.ItemSlotAccessory,
+	})
+	if err != nil {
+		panic(err)
+	}
+	_, err = queries.CreateChestItemDropRate(
+		context.Background(),
+		sqlcdb.CreateChestItemDropRateParams{
+			ItemID:   item.ID,
+			ChestID:  pgtype.UUID{Bytes: chestID, Valid: true},
+			DropRate: dropRate,
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return uuid.UUID(item.ID.Bytes)
+}
+
+// createTestUserItem gives the user a new item of the given rarity and slot
+func createTestUserItem(userID uuid.UUID, title, rarity, slot string) uuid.UUID {
+	item, err := queries.CreateChestItem(context.Background(), sqlcdb.CreateChestItemParams{
+		Title:  title,
+		Rarity: sqlcdb.ItemType(rarity),
+		Slot:   sqlcdb.ItemSlot(slot),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return giveTestUserItem(userID, uuid.UUID(item.ID.Bytes))
+}
+
+func createTestPricedItem(title, rarity string, price int) uuid.UUID {
+	item, err := queries.CreateChestItem(context.Background(), sqlcdb.CreateChestItemParams{
+		Title:  title,
+		Rarity: sqlcdb.ItemType(rarity),
+		Slot:   sqlcdb.ItemSlotAccessory,
+		Price:  pgtype.Int4{Int32: int32(price), Valid: true},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return uuid.UUID(item.ID.Bytes)
+}
+
+// createTestConsumable adds a consumable item granting effect at value for an hour
+func createTestConsumable(title string, effect sqlcdb.ItemEffect, value int) uuid.UUID {
+	params := sqlcdb.UpsertChestItemBySlugParams{
+		Slug:                  pgtype.Text{String: title, Valid: true},
+		Title:                 title,
+		Rarity:                sqlcdb.ItemTypeRare,
+		Slot:                  sqlcdb.ItemSlotAccessory,
+		Effect:                sqlcdb.NullItemEffect{ItemEffect: effect, Valid: true},
+		EffectValue:           pgtype.Int4{Int32: int32(value), Valid: true},
+		EffectDurationSeconds: pgtype.Int4{Int32: 3600, Valid: true},
+	}
+	item, err := queries.UpsertChestItemBySlug(context.Background(), params)
+	if err != nil {
+		panic(err)
+	}
+	return uuid.UUID(item.ID.Bytes)
+}
+
+// giveTestUserItem adds a copy of an existing chest item to the user's inventory
+func giveTestUserItem(userID, itemID uuid.UUID) uuid.UUID {
+	userItem, err := queries.CreateUserItem(context.Background(), sqlcdb.CreateUserItemParams{
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		ItemID: pgtype.UUID{Bytes: itemID, Valid: true},
+		Status: sqlcdb.NullItemStatus{ItemStatus: sqlcdb.ItemStatusNotEquipped, Valid: true},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return uuid.UUID(userItem.ID.Bytes)
+}
+
+// createTestRecipe adds a crafting recipe rolling its output from the chest's drop table
+func createTestRecipe(
+	slug string,
+	chestID uuid.UUID,
+	inputRarity, outputRarity string,
+	inputCount int,
+) uuid.UUID {
+	recipe, err := queries.UpsertCraftingRecipeBySlug(
+		context.Background(),
+		sqlcdb.UpsertCraftingRecipeBySlugParams{
+			Slug:         slug,
+			ChestID:      pgtype.UUID{Bytes: chestID, Valid: true},
+			InputRarity:  sqlcdb.ItemType(inputRarity),
+			InputCount:   int32(inputCount),
+			OutputRarity: sqlcdb.ItemType(outputRarity),
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return uuid.UUID(recipe.ID.Bytes)
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/responses"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* Item Image Tests
+* Testing Resource: /api/admin/items/{itemId}/image and /api/blobs/{key}
+ */
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	require.Nil(t, png.Encode(&b, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return b.Bytes()
+}
+
+func uploadItemImage(t *testing.T, itemID uuid.UUID, data []byte, token string) *http.Response {
+	t.Helper()
+	url := fmt.Sprintf("%s/api/admin/items/%s/image", BaseURL, itemID)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	require.Nil(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	// the declared type is ignored in favour of the sniffed one
+	req.Header.Set("Content-Type", "application/octet-stream")
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	return res
+}
+
+func TestUploadItemImage(t *testing.T) {
+	t.Parallel()
+
+	admin := createUser(t.Name()+"@mail.com", "password123!")
+	setUserAdmin(admin.ID)
+	itemID := createTestPricedItem(t.Name(), "rare", 20)
+	userItemID := giveTestUserItem(admin.ID, itemID)
+
+	data := encodeTestPNG(t, 128, 128)
+	res := uploadItemImage(t, itemID, data, admin.AccessToken)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.ItemImage]](res)
+	require.Nil(t, err)
+	itemImage := resBody.Data
+	assert.Equal(t, responses.ObjectItemImage, resBody.Object)
+	assert.Equal(t, "image/png", itemImage.ContentType)
+	assert.Equal(t, 128, itemImage.Width)
+	assert.EqualValues(t, len(data), itemImage.Size)
+	assert.Equal(t, itemImage.URL, itemImage.Item.ImageURL)
+
+	// the signed URL serves the upload and may be cached
+	res, err = http.Get(BaseURL + itemImage.URL)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "image/png", res.Header.Get("Content-Type"))
+	assert.Contains(t, res.Header.Get("Cache-Control"), "immutable")
+	body, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.Equal(t, data, body)
+
+	unsigned := strings.Split(itemImage.URL, "?")[0]
+	res, err = http.Get(BaseURL + unsigned)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	// equipped items are drawn with their uploaded art
+	equipURL := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, userItemID)
+	res, err = buildAndSendRequest("POST", equipURL, nil, admin.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res = getAvatar(t, fmt.Sprintf("%s/api/users/%s/avatar.svg", BaseURL, admin.ID), "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	body, err = io.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.Contains(t, string(body), "data:image/png;base64,")
+}
+
+func TestUploadItemImageValidation(t *testing.T) {
+	t.Parallel()
+
+	admin := createUser(t.Name()+"@mail.com", "password123!")
+	setUserAdmin(admin.ID)
+	user := createUser(t.Name()+"user@mail.com", "password123!")
+	itemID := createTestPricedItem(t.Name(), "common", 5)
+	square := encodeTestPNG(t, 64, 64)
+
+	tests := []struct {
+		name     string
+		itemID   uuid.UUID
+		data     []byte
+		token    string
+		expected int
+	}{
+		{"not an admin", itemID, square, user.AccessToken, http.StatusForbidden},
+		{"unknown item", uuid.New(), square, admin.AccessToken, http.StatusNotFound},
+		{"not an image", itemID, []byte("hello"), admin.AccessToken, http.StatusBadRequest},
+		{
+			"not square",
+			itemID,
+			encodeTestPNG(t, 128, 64),
+			admin.AccessToken,
+			http.StatusBadRequest,
+		},
+		{
+			"too large",
+			itemID,
+			make([]byte, 3<<20),
+			admin.AccessToken,
+			http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := uploadItemImage(t, tt.itemID, tt.data, tt.token)
+			assert.Equal(t, tt.expected, res.StatusCode)
+		})
+	}
+}
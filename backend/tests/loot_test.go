@@ -0,0 +1,710 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/responses"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* Loot Domain Tests
+* Testing Resource: /api/chests
+ */
+
+func TestGetChests(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("bronze", t.Name(), 50)
+
+	res, err := buildAndSendRequest("GET", BaseURL+"/api/chests", nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.Chest]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectList, resBody.Object)
+
+	found := false
+	for _, c := range resBody.Data {
+		if c.ID == chest.ID {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestGetChestByID(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("gold", t.Name(), 500)
+
+	url := fmt.Sprintf("%s/api/chests/%s", BaseURL, chest.ID)
+	res, err := buildAndSendRequest("GET", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.Chest]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectChest, resBody.Object)
+	assert.Equal(t, chest.ID, resBody.Data.ID)
+	assert.Equal(t, 500, resBody.Data.Price)
+}
+
+func TestGetChestByIDShowsPity(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	setUserCash(userDto.ID, 100)
+	chest := createTestChest("gold", t.Name(), 100)
+	createTestChestItem(t.Name()+" common", "common", chest.ID, 0.5)
+	createTestChestItem(t.Name()+" legendary", "legendary", chest.ID, 0.5)
+
+	url := fmt.Sprintf("%s/api/chests/%s", BaseURL, chest.ID)
+	res, err := buildAndSendRequest("GET", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.Chest]](res)
+	require.Nil(t, err)
+	require.NotNil(t, resBody.Data.Pity)
+	assert.Equal(t, "legendary", resBody.Data.Pity.Rarity)
+	assert.Equal(t, 0, resBody.Data.Pity.OpensSinceTopDrop)
+	guaranteedAfter := resBody.Data.Pity.OpensUntilGuaranteed
+	assert.Greater(t, guaranteedAfter, 0)
+
+	_, err = buildAndSendRequest("POST", url+"/purchase", nil, userDto.AccessToken)
+	require.Nil(t, err)
+	openURL := fmt.Sprintf("%s/api/users/me/chests/%s/open", BaseURL, chest.ID)
+	res, err = buildAndSendRequest("POST", openURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	opening, err := unmarshalResponse[responses.ServerResponse[*entities.ChestOpening]](res)
+	require.Nil(t, err)
+	require.NotNil(t, opening.Data.Pity)
+
+	// the counter only advances when the opening missed the legendary
+	wantOpens := 1
+	if opening.Data.Item.Rarity == "legendary" {
+		wantOpens = 0
+	}
+	assert.Equal(t, wantOpens, opening.Data.Pity.OpensSinceTopDrop)
+
+	res, err = buildAndSendRequest("GET", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	resBody, err = unmarshalResponse[responses.ServerResponse[*entities.Chest]](res)
+	require.Nil(t, err)
+	assert.Equal(t, wantOpens, resBody.Data.Pity.OpensSinceTopDrop)
+	assert.Equal(t, guaranteedAfter-wantOpens, resBody.Data.Pity.OpensUntilGuaranteed)
+}
+
+func TestGetChestByIDNotFound(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+
+	url := fmt.Sprintf("%s/api/chests/%s", BaseURL, uuid.New())
+	res, err := buildAndSendRequest("GET", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestGetChestItemsAndDropRates(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("silver", t.Name(), 200)
+	commonID := createTestChestItem(t.Name()+" common", "common", chest.ID, 0.9)
+	rareID := createTestChestItem(t.Name()+" rare", "rare", chest.ID, 0.1)
+
+	url := fmt.Sprintf("%s/api/chests/%s/items", BaseURL, chest.ID)
+	res, err := buildAndSendRequest("GET", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	items, err := unmarshalResponse[responses.ServerResponse[[]*entities.ChestItem]](res)
+	require.Nil(t, err)
+	itemIDs := make([]uuid.UUID, 0, len(items.Data))
+	for _, item := range items.Data {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{commonID, rareID}, itemIDs)
+
+	url = fmt.Sprintf("%s/api/chests/%s/drop-rates", BaseURL, chest.ID)
+	res, err = buildAndSendRequest("GET", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	rates, err := unmarshalResponse[responses.ServerResponse[[]*entities.ChestItemDropRate]](res)
+	require.Nil(t, err)
+	assert.Len(t, rates.Data, 2)
+}
+
+func TestPurchaseChest(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	setUserCash(userDto.ID, 150)
+	chest := createTestChest("bronze", t.Name(), 100)
+
+	url := fmt.Sprintf("%s/api/chests/%s/purchase", BaseURL, chest.ID)
+	res, err := buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.ChestPurchase]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectChestPurchase, resBody.Object)
+	assert.Equal(t, 50, resBody.Data.CashAvailable)
+	assert.Equal(t, 1, resBody.Data.UserChest.QuantityOwned)
+
+	// second purchase cannot be afforded
+	res, err = buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	user, err := getUserByID(userDto.ID.String())
+	require.Nil(t, err)
+	assert.Equal(t, 50, user.CashAvailable)
+}
+
+func TestConcurrentPurchasesNeverOverdraw(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	setUserCash(userDto.ID, 250)
+	chest := createTestChest("silver", t.Name(), 100)
+	url := fmt.Sprintf("%s/api/chests/%s/purchase", BaseURL, chest.ID)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for range 10 {
+		wg.Go(func() {
+			res, err := buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+			if err != nil {
+				return
+			}
+			if res.StatusCode == http.StatusOK {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, 2, succeeded)
+	user, err := getUserByID(userDto.ID.String())
+	require.Nil(t, err)
+	assert.Equal(t, 50, user.CashAvailable)
+}
+
+func TestPurchaseChestNotFound(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+
+	url := fmt.Sprintf("%s/api/chests/%s/purchase", BaseURL, uuid.New())
+	res, err := buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestOpenChest(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	setUserCash(userDto.ID, 100)
+	chest := createTestChest("bronze", t.Name(), 100)
+	itemID := createTestChestItem(t.Name(), "common", chest.ID, 1)
+
+	openURL := fmt.Sprintf("%s/api/users/me/chests/%s/open", BaseURL, chest.ID)
+	res, err := buildAndSendRequest("POST", openURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	purchaseURL := fmt.Sprintf("%s/api/chests/%s/purchase", BaseURL, chest.ID)
+	res, err = buildAndSendRequest("POST", purchaseURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = buildAndSendRequest("POST", openURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.ChestOpening]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectChestOpening, resBody.Object)
+	assert.Equal(t, itemID, resBody.Data.Item.ID)
+	assert.Equal(t, "not_equipped", resBody.Data.UserItem.Status)
+	assert.Equal(t, 0, resBody.Data.QuantityRemaining)
+}
+
+func TestOpenChestIsProvablyFair(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	setUserCash(userDto.ID, 100)
+	chest := createTestChest("bronze", t.Name(), 100)
+	createTestChestItem(t.Name()+"-common", "common", chest.ID, 0.5)
+	createTestChestItem(t.Name()+"-rare", "rare", chest.ID, 0.5)
+
+	fairnessURL := fmt.Sprintf("%s/api/users/me/fairness", BaseURL)
+	res, err := buildAndSendRequest("GET", fairnessURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	seedBody, err := unmarshalResponse[responses.ServerResponse[*entities.FairnessSeed]](res)
+	require.Nil(t, err)
+	committed := seedBody.Data
+	assert.False(t, committed.ServerSeed.IsPresent())
+
+	purchaseURL := fmt.Sprintf("%s/api/chests/%s/purchase", BaseURL, chest.ID)
+	res, err = buildAndSendRequest("POST", purchaseURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	openURL := fmt.Sprintf("%s/api/users/me/chests/%s/open", BaseURL, chest.ID)
+	res, err = buildAndSendRequest("POST", openURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	openBody, err := unmarshalResponse[responses.ServerResponse[*entities.ChestOpening]](res)
+	require.Nil(t, err)
+	assert.Equal(t, committed.ServerSeedHash, openBody.Data.Proof.ServerSeedHash)
+	assert.Equal(t, 0, openBody.Data.Proof.Nonce)
+
+	rotateURL := fmt.Sprintf("%s/api/users/me/fairness/rotate", BaseURL)
+	body := map[string]any{"client_seed": "next"}
+	res, err = buildAndSendRequest("POST", rotateURL, body, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	rotateBody, err := unmarshalResponse[responses.ServerResponse[*entities.SeedRotation]](res)
+	require.Nil(t, err)
+	assert.Equal(t, committed.ID, rotateBody.Data.Revealed.ID)
+	assert.Equal(t, "next", rotateBody.Data.Active.ClientSeed)
+
+	openingsURL := fmt.Sprintf("%s/api/users/me/openings", BaseURL)
+	res, err = buildAndSendRequest("GET", openingsURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	type openingsResponse = responses.ServerResponse[[]*entities.ChestOpeningRecord]
+	openingsBody, err := unmarshalResponse[openingsResponse](res)
+	require.Nil(t, err)
+	require.Len(t, openingsBody.Data, 1)
+	opening := openingsBody.Data[0]
+
+	// verify the opening the way a player would, from the response alone
+	serverSeed, ok := opening.Proof.ServerSeed.GetVal()
+	require.True(t, ok)
+	hash := sha256.Sum256([]byte(serverSeed))
+	assert.Equal(t, opening.Proof.ServerSeedHash, hex.EncodeToString(hash[:]))
+
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	fmt.Fprintf(mac, "%s:%d", opening.Proof.ClientSeed, opening.Proof.Nonce)
+	sum := mac.Sum(nil)
+	roll := float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+	assert.Equal(t, opening.Proof.Roll, roll)
+
+	require.False(t, opening.Proof.TopDropChance.IsPresent())
+	require.Len(t, opening.DropTable, 2)
+	total := 0.0
+	for _, rate := range opening.DropTable {
+		total += rate.DropRate
+	}
+	cumulative := 0.0
+	var dropped uuid.UUID
+	for _, rate := range opening.DropTable {
+		cumulative += rate.DropRate
+		dropped = rate.ItemID
+		if roll*total < cumulative {
+			break
+		}
+	}
+	assert.Equal(t, opening.ItemID, dropped)
+	assert.Equal(t, openBody.Data.Item.ID, dropped)
+}
+
+func TestRotateFairnessSeedValidation(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	url := fmt.Sprintf("%s/api/users/me/fairness/rotate", BaseURL)
+	body := map[string]any{"client_seed": strings.Repeat("a", 65)}
+	res, err := buildAndSendRequest("POST", url, body, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+}
+
+func TestReplaceDropTableRequiresAdmin(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	chest := createTestChest("bronze", t.Name(), 100)
+	itemID := createTestChestItem(t.Name(), "common", chest.ID, 1)
+
+	url := fmt.Sprintf("%s/api/admin/chests/%s/drop-rates", BaseURL, chest.ID)
+	body := map[string]any{
+		"rates": []map[string]any{{"item_id": itemID, "drop_rate": 1}},
+	}
+	res, err := buildAndSendRequest("PUT", url, body, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestReplaceDropTable(t *testing.T) {
+	t.Parallel()
+
+	adminDto := createUser(t.Name()+"@mail.com", "password123!")
+	setUserAdmin(adminDto.ID)
+	chest := createTestChest("silver", t.Name(), 100)
+	oldItemID := createTestChestItem(t.Name()+" old", "common", chest.ID, 1)
+	commonID := createTestChestItem(t.Name()+" common", "common", chest.ID, 1)
+	rareID := createTestChestItem(t.Name()+" rare", "rare", chest.ID, 1)
+
+	url := fmt.Sprintf("%s/api/admin/chests/%s/drop-rates", BaseURL, chest.ID)
+	body := map[string]any{
+		"rates": []map[string]any{
+			{"item_id": commonID, "drop_rate": 0.7},
+			{"item_id": rareID, "drop_rate": 0.3},
+		},
+	}
+	res, err := buildAndSendRequest("PUT", url, body, adminDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.DropTable]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectDropTable, resBody.Object)
+	assert.Equal(t, 2, resBody.Data.Version)
+	assert.Len(t, resBody.Data.Rates, 2)
+
+	historyURL := fmt.Sprintf("%s/api/admin/chests/%s/drop-rates/history", BaseURL, chest.ID)
+	res, err = buildAndSendRequest("GET", historyURL, nil, adminDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	history, err := unmarshalResponse[responses.ServerResponse[[]*entities.DropTable]](res)
+	require.Nil(t, err)
+	require.Len(t, history.Data, 2)
+	assert.Equal(t, 2, history.Data[0].Version)
+	assert.Equal(t, 1, history.Data[1].Version)
+	assert.Len(t, history.Data[1].Rates, 3)
+	var oldItemSeen bool
+	for _, rate := range history.Data[1].Rates {
+		oldItemSeen = oldItemSeen || rate.ItemID == oldItemID
+	}
+	assert.True(t, oldItemSeen)
+}
+
+func TestReplaceDropTableValidation(t *testing.T) {
+	t.Parallel()
+
+	adminDto := createUser(t.Name()+"@mail.com", "password123!")
+	setUserAdmin(adminDto.ID)
+	chest := createTestChest("gold", t.Name(), 100)
+	itemID := createTestChestItem(t.Name(), "common", chest.ID, 1)
+	url := fmt.Sprintf("%s/api/admin/chests/%s/drop-rates", BaseURL, chest.ID)
+
+	tests := []struct {
+		name  string
+		rates []map[string]any
+		want  int
+	}{
+		{
+			name:  "does not sum to one",
+			rates: []map[string]any{{"item_id": itemID, "drop_rate": 0.5}},
+			want:  http.StatusBadRequest,
+		},
+		{
+			name:  "negative rate",
+			rates: []map[string]any{{"item_id": itemID, "drop_rate": -1}},
+			want:  http.StatusBadRequest,
+		},
+		{
+			name:  "unknown item",
+			rates: []map[string]any{{"item_id": uuid.New(), "drop_rate": 1}},
+			want:  http.StatusBadRequest,
+		},
+		{
+			name:  "invalid item id",
+			rates: []map[string]any{{"item_id": "not a uuid", "drop_rate": 1}},
+			want:  http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]any{"rates": tt.rates}
+			res, err := buildAndSendRequest("PUT", url, body, adminDto.AccessToken)
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, res.StatusCode)
+		})
+	}
+
+	// rejected tables leave the live table untouched
+	res, err := buildAndSendRequest(
+		"GET",
+		fmt.Sprintf("%s/api/chests/%s", BaseURL, chest.ID),
+		nil,
+		adminDto.AccessToken,
+	)
+	require.Nil(t, err)
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.Chest]](res)
+	require.Nil(t, err)
+	assert.Equal(t, 1, resBody.Data.DropTableVersion)
+}
+
+func TestGetUserItemsFilters(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	createTestUserItem(userDto.ID, t.Name()+" boots", "common", "body")
+	epicID := createTestUserItem(userDto.ID, t.Name()+" cape", "epic", "background")
+
+	url := BaseURL + "/api/users/me/items"
+	res, err := buildAndSendRequest("GET", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	resBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectList, resBody.Object)
+	assert.Len(t, resBody.Data, 2)
+
+	res, err = buildAndSendRequest("GET", url+"?rarity=epic", nil, userDto.AccessToken)
+	require.Nil(t, err)
+	resBody, err = unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	require.Len(t, resBody.Data, 1)
+	assert.Equal(t, epicID, resBody.Data[0].ID)
+	assert.Equal(t, "background", resBody.Data[0].Item.Slot)
+
+	res, err = buildAndSendRequest("GET", url+"?status=equipped", nil, userDto.AccessToken)
+	require.Nil(t, err)
+	resBody, err = unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	assert.Empty(t, resBody.Data)
+
+	res, err = buildAndSendRequest("GET", url+"?rarity=mythic", nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestEquipItemReplacesSlotOccupant(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	hatID := createTestUserItem(userDto.ID, t.Name()+" hat", "common", "head")
+	helmetID := createTestUserItem(userDto.ID, t.Name()+" helmet", "rare", "head")
+
+	for _, id := range []uuid.UUID{hatID, helmetID} {
+		url := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, id)
+		res, err := buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		resBody, err := unmarshalResponse[responses.ServerResponse[*entities.UserItem]](res)
+		require.Nil(t, err)
+		assert.Equal(t, responses.ObjectUserItem, resBody.Object)
+		assert.Equal(t, "equipped", resBody.Data.Status)
+	}
+
+	res, err := buildAndSendRequest(
+		"GET",
+		BaseURL+"/api/users/me/items?status=equipped",
+		nil,
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	resBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	require.Len(t, resBody.Data, 1)
+	assert.Equal(t, helmetID, resBody.Data[0].ID)
+
+	url := fmt.Sprintf("%s/api/users/me/items/%s/unequip", BaseURL, helmetID)
+	res, err = buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	unequipped, err := unmarshalResponse[responses.ServerResponse[*entities.UserItem]](res)
+	require.Nil(t, err)
+	assert.Equal(t, "not_equipped", unequipped.Data.Status)
+}
+
+func TestConcurrentEquipsKeepOneItemPerSlot(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	ids := make([]uuid.UUID, 5)
+	for i := range ids {
+		title := fmt.Sprintf("%s %d", t.Name(), i)
+		ids[i] = createTestUserItem(userDto.ID, title, "common", "weapon")
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Go(func() {
+			url := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, id)
+			res, err := buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		})
+	}
+	wg.Wait()
+
+	res, err := buildAndSendRequest(
+		"GET",
+		BaseURL+"/api/users/me/items?status=equipped",
+		nil,
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	resBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	assert.Len(t, resBody.Data, 1)
+}
+
+func TestEquipItemOwnedByAnotherUser(t *testing.T) {
+	t.Parallel()
+
+	owner := createUser(t.Name()+"owner@mail.com", "password123!")
+	other := createUser(t.Name()+"other@mail.com", "password123!")
+	itemID := createTestUserItem(owner.ID, t.Name(), "common", "head")
+
+	url := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, itemID)
+	res, err := buildAndSendRequest("POST", url, nil, other.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestSellItem(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	setUserCash(userDto.ID, 10)
+	itemID := createTestPricedItem(t.Name(), "rare", 40)
+	userItemID := giveTestUserItem(userDto.ID, itemID)
+
+	url := fmt.Sprintf("%s/api/users/me/items/%s/sell", BaseURL, userItemID)
+	res, err := buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.ItemSale]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectItemSale, resBody.Object)
+	assert.Equal(t, 40, resBody.Data.CashEarned)
+	assert.Equal(t, 50, resBody.Data.CashAvailable)
+
+	user, err := getUserByID(userDto.ID.String())
+	require.Nil(t, err)
+	assert.Equal(t, 50, user.CashAvailable)
+
+	// the item is gone, so selling it again fails
+	res, err = buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestSellEquippedItem(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	userItemID := giveTestUserItem(userDto.ID, createTestPricedItem(t.Name(), "common", 5))
+
+	url := fmt.Sprintf("%s/api/users/me/items/%s", BaseURL, userItemID)
+	res, err := buildAndSendRequest("POST", url+"/equip", nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = buildAndSendRequest("POST", url+"/sell", nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	user, err := getUserByID(userDto.ID.String())
+	require.Nil(t, err)
+	assert.Equal(t, 0, user.CashAvailable)
+}
+
+func TestSellItemWithoutPrice(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	userItemID := createTestUserItem(userDto.ID, t.Name(), "common", "head")
+
+	url := fmt.Sprintf("%s/api/users/me/items/%s/sell", BaseURL, userItemID)
+	res, err := buildAndSendRequest("POST", url, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestSellDuplicateItems(t *testing.T) {
+	t.Parallel()
+
+	userDto := createUser(t.Name()+"@mail.com", "password123!")
+	swordID := createTestPricedItem(t.Name()+" sword", "common", 10)
+	shieldID := createTestPricedItem(t.Name()+" shield", "common", 3)
+	crownID := createTestPricedItem(t.Name()+" crown", "legendary", 500)
+
+	// three swords, one of them equipped: the equipped copy is kept and two are sold
+	swords := []uuid.UUID{
+		giveTestUserItem(userDto.ID, swordID),
+		giveTestUserItem(userDto.ID, swordID),
+		giveTestUserItem(userDto.ID, swordID),
+	}
+	equipURL := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, swords[2])
+	res, err := buildAndSendRequest("POST", equipURL, nil, userDto.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	// a single shield is not a duplicate, and duplicate crowns are a different rarity
+	giveTestUserItem(userDto.ID, shieldID)
+	giveTestUserItem(userDto.ID, crownID)
+	giveTestUserItem(userDto.ID, crownID)
+
+	url := BaseURL + "/api/users/me/items/sell-duplicates"
+	res, err = buildAndSendRequest(
+		"POST",
+		url,
+		map[string]any{"rarity": "common"},
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.ItemSale]](res)
+	require.Nil(t, err)
+	assert.Equal(t, 20, resBody.Data.CashEarned)
+	assert.Equal(t, 20, resBody.Data.CashAvailable)
+	soldIDs := make([]uuid.UUID, len(resBody.Data.Items))
+	for i, item := range resBody.Data.Items {
+		soldIDs[i] = item.ID
+	}
+	assert.ElementsMatch(t, swords[:2], soldIDs)
+
+	res, err = buildAndSendRequest("GET", BaseURL+"/api/users/me/items", nil, userDto.AccessToken)
+	require.Nil(t, err)
+	items, err := unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	assert.Len(t, items.Data, 4)
+
+	// nothing left to sell
+	res, err = buildAndSendRequest(
+		"POST",
+		url,
+		map[string]any{"rarity": "common"},
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	resBody, err = unmarshalResponse[responses.ServerResponse[*entities.ItemSale]](res)
+	require.Nil(t, err)
+	assert.Equal(t, 0, resBody.Data.CashEarned)
+	assert.Empty(t, resBody.Data.Items)
+}
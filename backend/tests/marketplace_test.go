@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/responses"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* Marketplace Tests
+* Testing Resource: /api/marketplace/listings
+ */
+
+func createTestListing(t *testing.T, token, userItemID string, price int) *entities.Listing {
+	t.Helper()
+	url := fmt.Sprintf("%s/api/marketplace/listings", BaseURL)
+	body := map[string]any{"user_item_id": userItemID, "price": price}
+	res, err := buildAndSendRequest("POST", url, body, token)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.Listing]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectListing, resBody.Object)
+	return resBody.Data
+}
+
+func TestBuyListing(t *testing.T) {
+	t.Parallel()
+
+	seller := createUser(t.Name()+"@mail.com", "password123!")
+	buyer := createUser(t.Name()+"-buyer@mail.com", "password123!")
+	setUserCash(buyer.ID, 100)
+	userItemID := createTestUserItem(seller.ID, t.Name(), "epic", "body")
+
+	listing := createTestListing(t, seller.AccessToken, userItemID.String(), 60)
+	assert.Equal(t, "active", listing.Status)
+
+	// listed items cannot be equipped while the listing is active
+	equipURL := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, userItemID)
+	res, err := buildAndSendRequest("POST", equipURL, nil, seller.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	browseURL := fmt.Sprintf(
+		"%s/api/marketplace/listings?rarity=epic&title=%s",
+		BaseURL,
+		t.Name(),
+	)
+	res, err = buildAndSendRequest("GET", browseURL, nil, buyer.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	listBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.Listing]](res)
+	require.Nil(t, err)
+	require.Len(t, listBody.Data, 1)
+	assert.Equal(t, listing.ID, listBody.Data[0].ID)
+
+	buyURL := fmt.Sprintf("%s/api/marketplace/listings/%d/buy", BaseURL, listing.ID)
+	res, err = buildAndSendRequest("POST", buyURL, nil, seller.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = buildAndSendRequest("POST", buyURL, nil, buyer.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	purchase, err := unmarshalResponse[responses.ServerResponse[*entities.ListingPurchase]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectListingPurchase, purchase.Object)
+	assert.Equal(t, 40, purchase.Data.CashAvailable)
+	assert.Equal(t, "sold", purchase.Data.Listing.Status)
+	assert.Equal(t, userItemID, purchase.Data.UserItem.ID)
+
+	sellerUser, err := getUserByID(seller.ID.String())
+	require.Nil(t, err)
+	assert.Equal(t, 60, sellerUser.CashAvailable)
+
+	// the buyer now owns the item and can equip it
+	res, err = buildAndSendRequest("POST", equipURL, nil, buyer.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = buildAndSendRequest("POST", buyURL, nil, buyer.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestConcurrentListingPurchasesSellOnce(t *testing.T) {
+	t.Parallel()
+
+	seller := createUser(t.Name()+"@mail.com", "password123!")
+	userItemID := createTestUserItem(seller.ID, t.Name(), "rare", "weapon")
+	listing := createTestListing(t, seller.AccessToken, userItemID.String(), 50)
+	buyURL := fmt.Sprintf("%s/api/marketplace/listings/%d/buy", BaseURL, listing.ID)
+
+	buyers := make([]*entities.UserDTO, 5)
+	for i := range buyers {
+		buyers[i] = createUser(fmt.Sprintf("%s-buyer%d@mail.com", t.Name(), i), "password123!")
+		setUserCash(buyers[i].ID, 50)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for _, buyer := range buyers {
+		wg.Go(func() {
+			res, err := buildAndSendRequest("POST", buyURL, nil, buyer.AccessToken)
+			if err != nil {
+				return
+			}
+			if res.StatusCode == http.StatusOK {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, succeeded)
+	totalCash := 0
+	for _, buyer := range buyers {
+		user, err := getUserByID(buyer.ID.String())
+		require.Nil(t, err)
+		totalCash += user.CashAvailable
+	}
+	assert.Equal(t, 200, totalCash)
+
+	sellerUser, err := getUserByID(seller.ID.String())
+	require.Nil(t, err)
+	assert.Equal(t, 50, sellerUser.CashAvailable)
+}
+
+func TestCrossingListingPurchasesBothSucceed(t *testing.T) {
+	t.Parallel()
+
+	users := []*entities.UserDTO{
+		createUser(t.Name()+"-a@mail.com", "password123!"),
+		createUser(t.Name()+"-b@mail.com", "password123!"),
+	}
+	for _, user := range users {
+		setUserCash(user.ID, 100)
+	}
+
+	// each user buys the other's listing at the same time, locking the pair in opposite orders
+	for round := range 5 {
+		buyURLs := make([]string, len(users))
+		for i, user := range users {
+			title := fmt.Sprintf("%s-%d-%d", t.Name(), round, i)
+			userItemID := createTestUserItem(user.ID, title, "common", "weapon")
+			listing := createTestListing(t, user.AccessToken, userItemID.String(), 10)
+			buyURLs[i] = fmt.Sprintf("%s/api/marketplace/listings/%d/buy", BaseURL, listing.ID)
+		}
+
+		var wg sync.WaitGroup
+		statuses := make([]int, len(users))
+		for i, buyer := range users {
+			wg.Go(func() {
+				res, err := buildAndSendRequest(
+					"POST",
+					buyURLs[len(users)-1-i],
+					nil,
+					buyer.AccessToken,
+				)
+				if err == nil {
+					statuses[i] = res.StatusCode
+				}
+			})
+		}
+		wg.Wait()
+		assert.Equal(t, []int{http.StatusOK, http.StatusOK}, statuses)
+	}
+}
+
+func TestCancelListingReturnsItem(t *testing.T) {
+	t.Parallel()
+
+	seller := createUser(t.Name()+"@mail.com", "password123!")
+	userItemID := createTestUserItem(seller.ID, t.Name(), "common", "accessory")
+	listing := createTestListing(t, seller.AccessToken, userItemID.String(), 5)
+
+	url := fmt.Sprintf("%s/api/marketplace/listings/%d", BaseURL, listing.ID)
+	res, err := buildAndSendRequest("DELETE", url, nil, seller.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = buildAndSendRequest(
+		"GET",
+		BaseURL+"/api/users/me/listings?status=cancelled",
+		nil,
+		seller.AccessToken,
+	)
+	require.Nil(t, err)
+	listBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.Listing]](res)
+	require.Nil(t, err)
+	require.Len(t, listBody.Data, 1)
+	assert.Equal(t, "cancelled", listBody.Data[0].Status)
+
+	res, err = buildAndSendRequest("GET", BaseURL+"/api/users/me/items", nil, seller.AccessToken)
+	require.Nil(t, err)
+	itemsBody, err := unmarshalResponse[responses.ServerResponse[[]*entities.UserItem]](res)
+	require.Nil(t, err)
+	require.Len(t, itemsBody.Data, 1)
+	assert.Equal(t, userItemID, itemsBody.Data[0].ID)
+}
+
+func TestCreateListingValidation(t *testing.T) {
+	t.Parallel()
+
+	seller := createUser(t.Name()+"@mail.com", "password123!")
+	userItemID := createTestUserItem(seller.ID, t.Name(), "common", "head")
+	url := fmt.Sprintf("%s/api/marketplace/listings", BaseURL)
+
+	invalid := []map[string]any{
+		{"user_item_id": userItemID.String(), "price": 0},
+		{"user_item_id": "not-a-uuid", "price": 10},
+	}
+	for _, body := range invalid {
+		res, err := buildAndSendRequest("POST", url, body, seller.AccessToken)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode, body)
+	}
+
+	equipURL := fmt.Sprintf("%s/api/users/me/items/%s/equip", BaseURL, userItemID)
+	res, err := buildAndSendRequest("POST", equipURL, nil, seller.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body := map[string]any{"user_item_id": userItemID.String(), "price": 10}
+	res, err = buildAndSendRequest("POST", url, body, seller.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = buildAndSendRequest("GET", url+"?limit=0", nil, seller.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
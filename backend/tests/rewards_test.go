@@ -0,0 +1,261 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"goalify/internal/entities"
+	"goalify/internal/responses"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* Reward Tests
+* Testing Resource: /api/rewards
+ */
+
+func createTestReward(t *testing.T, token string, body map[string]any) *entities.Reward {
+	t.Helper()
+	res, err := buildAndSendRequest("POST", BaseURL+"/api/rewards", body, token)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	resBody, err := unmarshalResponse[responses.ServerResponse[*entities.Reward]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectReward, resBody.Object)
+	return resBody.Data
+}
+
+func TestRedeemReward(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	other := createUser(t.Name()+"-other@mail.com", "password123!")
+	setUserCash(user.ID, 120)
+
+	reward := createTestReward(t, user.AccessToken, map[string]any{
+		"title":            "pizza night",
+		"description":      "order from the good place",
+		"cost":             50,
+		"repeatable":       true,
+		"cooldown_seconds": 3600,
+	})
+	assert.True(t, reward.Redeemable)
+
+	redeemURL := fmt.Sprintf("%s/api/rewards/%s/redeem", BaseURL, reward.ID)
+	res, err := buildAndSendRequest("POST", redeemURL, nil, other.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = buildAndSendRequest("POST", redeemURL, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	purchase, err := unmarshalResponse[responses.ServerResponse[*entities.RewardPurchase]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectRewardPurchase, purchase.Object)
+	assert.Equal(t, 70, purchase.Data.CashAvailable)
+	assert.Equal(t, 50, purchase.Data.Redemption.Cost)
+	assert.False(t, purchase.Data.Reward.Redeemable)
+	assert.True(t, purchase.Data.Reward.AvailableAt.IsPresent())
+
+	res, err = buildAndSendRequest("POST", redeemURL, nil, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	updated, err := getUserByID(user.ID.String())
+	require.Nil(t, err)
+	assert.Equal(t, 70, updated.CashAvailable)
+
+	res, err = buildAndSendRequest(
+		"GET",
+		BaseURL+"/api/users/me/transactions",
+		nil,
+		user.AccessToken,
+	)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	transactions, err := unmarshalResponse[responses.ServerResponse[[]*entities.CashTransaction]](
+		res,
+	)
+	require.Nil(t, err)
+	require.NotEmpty(t, transactions.Data)
+	assert.Equal(t, "reward_redemption", transactions.Data[0].Reason)
+	assert.Equal(t, -50, transactions.Data[0].Amount)
+
+	// a one-time reward without the cash for it
+	expensive := createTestReward(t, user.AccessToken, map[string]any{
+		"title": "new bike",
+		"cost":  500,
+	})
+	url := fmt.Sprintf("%s/api/rewards/%s/redeem", BaseURL, expensive.ID)
+	res, err = buildAndSendRequest("POST", url, nil, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestRewardRedemptionHistory(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	setUserCash(user.ID, 100)
+	coffee := createTestReward(t, user.AccessToken, map[string]any{
+		"title":      "fancy coffee",
+		"cost":       5,
+		"repeatable": true,
+	})
+	book := createTestReward(t, user.AccessToken, map[string]any{"title": "book", "cost": 20})
+
+	for _, id := range []string{coffee.ID.String(), coffee.ID.String(), book.ID.String()} {
+		url := fmt.Sprintf("%s/api/rewards/%s/redeem", BaseURL, id)
+		res, err := buildAndSendRequest("POST", url, nil, user.AccessToken)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+	}
+
+	// deleting a reward keeps its redemptions
+	url := fmt.Sprintf("%s/api/rewards/%s", BaseURL, book.ID)
+	res, err := buildAndSendRequest("DELETE", url, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, err = buildAndSendRequest("GET", url, nil, user.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	url = fmt.Sprintf("%s/api/rewards/redemptions?limit=2", BaseURL)
+	res, err = buildAndSendRequest("GET", url, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	page, err := unmarshalResponse[responses.ServerResponse[[]*entities.RewardRedemption]](res)
+	require.Nil(t, err)
+	require.Len(t, page.Data, 2)
+	assert.Equal(t, "book", page.Data[0].Title)
+	assert.False(t, page.Data[0].RewardID.IsPresent())
+	require.NotNil(t, page.HasMore)
+	assert.True(t, *page.HasMore)
+	require.NotNil(t, page.NextPage)
+
+	url = fmt.Sprintf(
+		"%s/api/rewards/redemptions?reward_id=%s&cursor=%s",
+		BaseURL,
+		coffee.ID,
+		*page.NextPage,
+	)
+	res, err = buildAndSendRequest("GET", url, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	page, err = unmarshalResponse[responses.ServerResponse[[]*entities.RewardRedemption]](res)
+	require.Nil(t, err)
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, "fancy coffee", page.Data[0].Title)
+	assert.False(t, *page.HasMore)
+}
+
+func TestCreateRewardValidation(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"missing title", map[string]any{"cost": 10}},
+		{"free", map[string]any{"title": "free", "cost": 0}},
+		{"negative cooldown", map[string]any{
+			"title": "x", "cost": 10, "repeatable": true, "cooldown_seconds": -1,
+		}},
+		{"one-time cooldown", map[string]any{"title": "x", "cost": 10, "cooldown_seconds": 60}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := BaseURL + "/api/rewards"
+			res, err := buildAndSendRequest("POST", url, tt.body, user.AccessToken)
+			require.Nil(t, err)
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		})
+	}
+}
+
+func TestClaimDailyReward(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	url := BaseURL + "/api/rewards/daily/claim"
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	statuses := make(map[int]int)
+	for range 5 {
+		wg.Go(func() {
+			res, err := buildAndSendRequest("POST", url, nil, user.AccessToken)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			statuses[res.StatusCode]++
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, map[int]int{http.StatusCreated: 1, http.StatusBadRequest: 4}, statuses)
+
+	userChests, err := queries.GetUserChestsByUserId(
+		context.Background(),
+		pgtype.UUID{Bytes: user.ID, Valid: true},
+	)
+	require.Nil(t, err)
+	require.Len(t, userChests, 1)
+	assert.Equal(t, int32(1), userChests[0].QuantityOwned.Int32)
+
+	res, err := buildAndSendRequest("GET", BaseURL+"/api/rewards/daily", nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	status, err := unmarshalResponse[responses.ServerResponse[*entities.DailyRewardStatus]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectDailyReward, status.Object)
+	assert.True(t, status.Data.ClaimedToday)
+	assert.Equal(t, 1, status.Data.Streak)
+	assert.Equal(t, "bronze", status.Data.NextChestType)
+	require.NotNil(t, status.Data.LastClaim)
+	assert.Equal(t, "bronze", status.Data.LastClaim.Chest.Type)
+	assert.Equal(t, userChests[0].ChestID.Bytes, [16]byte(status.Data.LastClaim.Chest.ID))
+}
+
+func TestDailyRewardTimezone(t *testing.T) {
+	t.Parallel()
+
+	user := createUser(t.Name()+"@mail.com", "password123!")
+	assert.Equal(t, "UTC", user.Timezone)
+
+	for _, tz := range []string{"", "Local", "Mars/Olympus_Mons"} {
+		body := map[string]any{"timezone": tz}
+		res, err := buildAndSendRequest("PUT", BaseURL+"/api/users", body, user.AccessToken)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode, tz)
+	}
+
+	// the furthest zone ahead of UTC, so its date is never behind the server's
+	body := map[string]any{"timezone": "Pacific/Kiritimati"}
+	res, err := buildAndSendRequest("PUT", BaseURL+"/api/users", body, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	updated, err := getUserByID(user.ID.String())
+	require.Nil(t, err)
+	assert.Equal(t, "Pacific/Kiritimati", updated.Timezone)
+
+	url := BaseURL + "/api/rewards/daily/claim"
+	res, err = buildAndSendRequest("POST", url, nil, user.AccessToken)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	claim, err := unmarshalResponse[responses.ServerResponse[*entities.DailyClaim]](res)
+	require.Nil(t, err)
+	assert.Equal(t, responses.ObjectDailyClaim, claim.Object)
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	require.Nil(t, err)
+	assert.Equal(t, time.Now().In(kiritimati).Format(time.DateOnly), claim.Data.ClaimDate)
+}
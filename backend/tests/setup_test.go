@@ -24,6 +24,7 @@ var (
 	pgContainer   *postgres.PostgresContainer
 	cancelServer  context.CancelFunc
 	serverStopped chan struct{}
+	storageDir    string
 )
 
 func setup(ctx context.Context) {
@@ -43,6 +44,13 @@ func setup(ctx context.Context) {
 	config.SetEnv(config.ENV, string(config.LocalTest))
 	config.SetEnv(config.TestDBConnString, connStr)
 
+	// uploads go to a scratch directory instead of the checkout's assets
+	storageDir, err = os.MkdirTemp("", "goalify-storage-")
+	if err != nil {
+		panic(err)
+	}
+	config.SetEnv(config.StorageLocalDir, storageDir)
+
 	// Reset config singleton to pick up test environment variables
 	config.ResetForTesting()
 
@@ -89,6 +97,8 @@ func TestMain(m *testing.M) {
 		}
 	}
 
+	os.RemoveAll(storageDir)
+
 	if pgContainer != nil {
 		if err := pgContainer.Terminate(ctx); err != nil {
 			log.Fatalf("Failed to terminate container: %s", err)
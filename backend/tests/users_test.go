@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"goalify/internal/entities"
+	"goalify/internal/responses"
 	"goalify/internal/users/handler"
 	"net/http"
 	"testing"
@@ -148,7 +149,7 @@ func TestUpdateUserById(t *testing.T) {
 	email := t.Name() + "@mail.com"
 	userDto := createUser(email, "password123!")
 
-	reqBody := map[string]any{"xp": 100, "cash_available": 100}
+	reqBody := map[string]any{"timezone": "Europe/Berlin"}
 	res, err := buildAndSendRequest(
 		"PUT",
 		fmt.Sprintf("%s/api/users", BaseURL),
@@ -159,8 +160,53 @@ func TestUpdateUserById(t *testing.T) {
 
 	resBody, err := unmarshalResponse[entities.UserDTO](res)
 	assert.Nil(t, err)
-	assert.Equal(t, 100, resBody.Xp)
-	assert.Equal(t, 100, resBody.CashAvailable)
+	assert.Equal(t, "Europe/Berlin", resBody.Timezone)
+	assert.Equal(t, 0, resBody.CashAvailable)
+}
+
+func TestUpdateUserByIdRejectsProgress(t *testing.T) {
+	t.Parallel()
+
+	email := t.Name() + "@mail.com"
+	userDto := createUser(email, "password123!")
+
+	for _, reqBody := range []map[string]any{{"xp": 100}, {"level_id": 5}} {
+		res, err := buildAndSendRequest(
+			"PUT",
+			fmt.Sprintf("%s/api/users", BaseURL),
+			reqBody,
+			userDto.AccessToken,
+		)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	}
+
+	user, err := getUserByID(userDto.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, 0, user.Xp)
+	assert.Equal(t, 1, user.LevelID)
+}
+
+func TestUpdateUserByIdRejectsCash(t *testing.T) {
+	t.Parallel()
+
+	email := t.Name() + "@mail.com"
+	userDto := createUser(email, "password123!")
+
+	reqBody := map[string]any{"xp": 100, "cash_available": 100}
+	res, err := buildAndSendRequest(
+		"PUT",
+		fmt.Sprintf("%s/api/users", BaseURL),
+		reqBody,
+		userDto.AccessToken,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+	user, err := getUserByID(userDto.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, 0, user.CashAvailable)
+	assert.Equal(t, 0, user.Xp)
 }
 
 func TestIncorrectUpdateUserById(t *testing.T) {
@@ -191,3 +237,72 @@ func TestGetLevelById(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	}
 }
+
+func TestGrantCash(t *testing.T) {
+	t.Parallel()
+	admin := createUser(t.Name()+"admin@mail.com", "Password123!")
+	setUserAdmin(admin.ID)
+	user := createUser(t.Name()+"@mail.com", "Password123!")
+	url := fmt.Sprintf("%s/api/admin/users/%s/cash", BaseURL, user.ID)
+
+	res, err := buildAndSendRequest("POST", url, map[string]any{"amount": 50}, user.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	res, err = buildAndSendRequest("POST", url, map[string]any{"amount": 50}, admin.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	resBody, err := unmarshalResponse[entities.UserDTO](res)
+	require.NoError(t, err)
+	assert.Equal(t, 50, resBody.CashAvailable)
+
+	res, err = buildAndSendRequest("POST", url, map[string]any{"amount": -51}, admin.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = buildAndSendRequest("POST", url, map[string]any{"amount": 0}, admin.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+}
+
+func TestGetCashTransactions(t *testing.T) {
+	t.Parallel()
+	user := createUser(t.Name()+"@mail.com", "Password123!")
+	for _, cash := range []int{10, 30, 60} {
+		setUserCash(user.ID, cash)
+	}
+
+	url := fmt.Sprintf("%s/api/users/me/transactions?limit=2", BaseURL)
+	res, err := buildAndSendRequest("GET", url, nil, user.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	firstPage, err := unmarshalResponse[responses.ServerResponse[[]*entities.CashTransaction]](res)
+	require.NoError(t, err)
+	require.Len(t, firstPage.Data, 2)
+	assert.Equal(t, 30, firstPage.Data[0].Amount)
+	assert.Equal(t, 60, firstPage.Data[0].BalanceAfter)
+	assert.Equal(t, "admin_grant", firstPage.Data[0].Reason)
+	require.NotNil(t, firstPage.HasMore)
+	assert.True(t, *firstPage.HasMore)
+	require.NotNil(t, firstPage.NextPage)
+
+	url = fmt.Sprintf(
+		"%s/api/users/me/transactions?limit=2&cursor=%s",
+		BaseURL,
+		*firstPage.NextPage,
+	)
+	res, err = buildAndSendRequest("GET", url, nil, user.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	secondPage, err := unmarshalResponse[responses.ServerResponse[[]*entities.CashTransaction]](res)
+	require.NoError(t, err)
+	require.Len(t, secondPage.Data, 1)
+	assert.Equal(t, 10, secondPage.Data[0].BalanceAfter)
+	assert.False(t, *secondPage.HasMore)
+	assert.Nil(t, secondPage.NextPage)
+
+	url = fmt.Sprintf("%s/api/users/me/transactions?limit=0", BaseURL)
+	res, err = buildAndSendRequest("GET", url, nil, user.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}